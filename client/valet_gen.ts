@@ -0,0 +1,297 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 0e71d6d41de85d8b
+
+/** A 16 byte ID written as 32 hexadecimal characters */
+export type HexID = string;
+/** An RFC 3339 date and time, ex. 2017-06-01T12:00:00Z */
+export type Timestamp = string;
+/** A fixed point number, kept as a string so it doesn't lose precision */
+export type Decimal = string;
+/** Bytes written as standard base64 */
+export type Base64 = string;
+
+/** Node represents a node in the organization permission heirarchy tree */
+export interface Node {
+  ID: HexID;
+  TypeID: HexID;
+  Timestamp: Timestamp;
+  Name: string;
+  CreatedAt: Timestamp;
+  UpdatedAt: Timestamp;
+  DeletedAt: Timestamp | null;
+  CreatedBy: string;
+  UpdatedBy: string;
+}
+
+/** The arguments creating a Node */
+export interface NodeCreate {
+  Name: string;
+}
+
+/** The Node to update and every one of its new values */
+export interface NodeUpdate {
+  ID: HexID;
+  Name: string;
+}
+
+/** A JSON merge patch of the Node it names, null clearing a nullable field */
+export type NodePatch = Pick<NodeUpdate, "ID"> & Partial<NodeUpdate>;
+
+/** Desk where car keys can be stored */
+export interface Desk {
+  ID: HexID;
+  TypeID: HexID;
+  Timestamp: Timestamp;
+  Name: string;
+  Lat: number;
+  Lng: number;
+  NodeID: HexID;
+  State: "open" | "closed" | "out_of_service";
+  Version: number;
+  CreatedAt: Timestamp;
+  UpdatedAt: Timestamp;
+  DeletedAt: Timestamp | null;
+  CreatedBy: string;
+  UpdatedBy: string;
+}
+
+/** The arguments creating a Desk */
+export interface DeskCreate {
+  Name: string;
+  Lat?: number;
+  Lng?: number;
+  NodeID: HexID;
+  State: "open" | "closed" | "out_of_service";
+}
+
+/** The Desk to update and every one of its new values */
+export interface DeskUpdate {
+  ID: HexID;
+  Name: string;
+  Lat: number;
+  Lng: number;
+  NodeID: HexID;
+  State: "open" | "closed" | "out_of_service";
+  Version: number;
+}
+
+/** A JSON merge patch of the Desk it names, null clearing a nullable field */
+export type DeskPatch = Pick<DeskUpdate, "ID"> & Partial<DeskUpdate>;
+
+/** Attendant parks cars and hands out their keys */
+export interface Attendant {
+  ID: HexID;
+  TypeID: HexID;
+  Timestamp: Timestamp;
+  Name: string;
+  CreatedAt: Timestamp;
+  UpdatedAt: Timestamp;
+  DeletedAt: Timestamp | null;
+  CreatedBy: string;
+  UpdatedBy: string;
+}
+
+/** The arguments creating a Attendant */
+export interface AttendantCreate {
+  Name: string;
+}
+
+/** The Attendant to update and every one of its new values */
+export interface AttendantUpdate {
+  ID: HexID;
+  Name: string;
+}
+
+/** A JSON merge patch of the Attendant it names, null clearing a nullable field */
+export type AttendantPatch = Pick<AttendantUpdate, "ID"> & Partial<AttendantUpdate>;
+
+/** DeskAttendant assigns an attendant to a desk they work at */
+export interface DeskAttendant {
+  DeskID: HexID;
+  AttendantID: HexID;
+}
+
+/** A page of a list, the server defaulting to 50 rows and allowing 500 */
+export interface Page {
+  limit?: number;
+  offset?: number;
+}
+
+export interface ClientOptions {
+  /** Prefixes every route, ex. https://valet.ottoq.com */
+  baseURL?: string;
+  /**
+   * Whether the browser sends the session cookie, "include" by default so
+   * it's sent to an API on another origin too
+   */
+  credentials?: RequestCredentials;
+  /** Added to every request */
+  headers?: Record<string, string>;
+  /** Replaces the global fetch, ex. in tests */
+  fetch?: typeof fetch;
+}
+
+/** A response with an error status, message being the body the server wrote */
+export class APIError extends Error {
+  constructor(public readonly status: number, message: string) {
+    super(message);
+    this.name = "APIError";
+  }
+}
+
+type Query = Record<string, string | number | undefined>;
+
+/**
+ * Client calls the generated REST endpoints.
+ *
+ * The server keeps the session in an encrypted HttpOnly cookie that scripts
+ * can't read, so the client leaves it to the browser by sending
+ * credentials. Every response carries the CSRF token of the session in the
+ * X-CSRF-Token header, which the client sends back with each write as the
+ * server refuses writes without it. A write made before any response
+ * brought the token is refused, bringing it, and sent again once. Bodies
+ * are sent as JSON, the only ones the server accepts.
+ */
+export class Client {
+  private readonly baseURL: string;
+  private readonly credentials: RequestCredentials;
+  private readonly headers: Record<string, string>;
+  private readonly fetcher: typeof fetch;
+  private csrfToken = "";
+
+  constructor(options: ClientOptions = {}) {
+    this.baseURL = (options.baseURL ?? "").replace(/\/+$/, "");
+    this.credentials = options.credentials ?? "include";
+    this.headers = options.headers ?? {};
+    this.fetcher = options.fetch ?? fetch.bind(globalThis);
+  }
+
+  private async request<T>(method: string, route: string, query: Query = {}, body?: unknown): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined) {
+        params.set(key, String(value));
+      }
+    }
+    const search = params.toString();
+    const send = async (): Promise<Response> => {
+      const headers: Record<string, string> = { Accept: "application/json", ...this.headers };
+      if (body !== undefined) {
+        headers["Content-Type"] = method === "PATCH" ? "application/merge-patch+json" : "application/json";
+      }
+      if (method !== "GET" && this.csrfToken) {
+        headers["X-CSRF-Token"] = this.csrfToken;
+      }
+      const res = await this.fetcher(this.baseURL + route + (search ? "?" + search : ""), {
+        method,
+        headers,
+        credentials: this.credentials,
+        body: body === undefined ? undefined : JSON.stringify(body),
+      });
+      this.csrfToken = res.headers.get("X-CSRF-Token") ?? this.csrfToken;
+      return res;
+    };
+    const sent = this.csrfToken;
+    let res = await send();
+    if (res.status === 403 && method !== "GET" && this.csrfToken !== sent) {
+      // sent without the token of the session, which the response brought
+      res = await send();
+    }
+    const text = await res.text();
+    if (!res.ok) {
+      throw new APIError(res.status, text.trim() || res.statusText);
+    }
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+
+  /** Creates a Node */
+  createNode(input: NodeCreate): Promise<Node> {
+    return this.request("POST", "/node", {}, input);
+  }
+
+  /** Reads a Node, failing with a 404 APIError if it doesn't exist */
+  readNode(id: HexID): Promise<Node> {
+    return this.request("GET", "/node", { id });
+  }
+
+  /** Updates a Node, failing with a 404 APIError if it doesn't exist */
+  updateNode(input: NodeUpdate): Promise<Node> {
+    return this.request("PUT", "/node", {}, input);
+  }
+
+  /** Applies a merge patch to a Node, only changing the fields it holds */
+  patchNode(input: NodePatch): Promise<Node> {
+    return this.request("PATCH", "/node", {}, input);
+  }
+
+  /** Deletes a Node, failing with a 404 APIError if it doesn't exist */
+  deleteNode(id: HexID): Promise<void> {
+    return this.request("DELETE", "/node", { id });
+  }
+
+  /** Lists a page of Nodes */
+  listNodes(page: Page = {}): Promise<Node[]> {
+    return this.request("GET", "/node", { limit: page.limit, offset: page.offset });
+  }
+
+  /** Creates a Desk */
+  createDesk(input: DeskCreate): Promise<Desk> {
+    return this.request("POST", "/desk", {}, input);
+  }
+
+  /** Reads a Desk, failing with a 404 APIError if it doesn't exist */
+  readDesk(id: HexID): Promise<Desk> {
+    return this.request("GET", "/desk", { id });
+  }
+
+  /** Updates a Desk, failing with a 404 APIError if it doesn't exist and a 409 if its Version is stale */
+  updateDesk(input: DeskUpdate): Promise<Desk> {
+    return this.request("PUT", "/desk", {}, input);
+  }
+
+  /** Applies a merge patch to a Desk, only changing the fields it holds */
+  patchDesk(input: DeskPatch): Promise<Desk> {
+    return this.request("PATCH", "/desk", {}, input);
+  }
+
+  /** Deletes a Desk, failing with a 404 APIError if it doesn't exist */
+  deleteDesk(id: HexID): Promise<void> {
+    return this.request("DELETE", "/desk", { id });
+  }
+
+  /** Lists a page of Desks */
+  listDesks(page: Page = {}): Promise<Desk[]> {
+    return this.request("GET", "/desk", { limit: page.limit, offset: page.offset });
+  }
+
+  /** Creates a Attendant */
+  createAttendant(input: AttendantCreate): Promise<Attendant> {
+    return this.request("POST", "/attendant", {}, input);
+  }
+
+  /** Reads a Attendant, failing with a 404 APIError if it doesn't exist */
+  readAttendant(id: HexID): Promise<Attendant> {
+    return this.request("GET", "/attendant", { id });
+  }
+
+  /** Updates a Attendant, failing with a 404 APIError if it doesn't exist */
+  updateAttendant(input: AttendantUpdate): Promise<Attendant> {
+    return this.request("PUT", "/attendant", {}, input);
+  }
+
+  /** Applies a merge patch to a Attendant, only changing the fields it holds */
+  patchAttendant(input: AttendantPatch): Promise<Attendant> {
+    return this.request("PATCH", "/attendant", {}, input);
+  }
+
+  /** Deletes a Attendant, failing with a 404 APIError if it doesn't exist */
+  deleteAttendant(id: HexID): Promise<void> {
+    return this.request("DELETE", "/attendant", { id });
+  }
+
+  /** Lists a page of Attendants */
+  listAttendants(page: Page = {}): Promise<Attendant[]> {
+    return this.request("GET", "/attendant", { limit: page.limit, offset: page.offset });
+  }
+}
@@ -1,19 +1,110 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"strings"
+
+	"git.ottoq.com/otto-backend/valet/domain"
 )
 
+// Dialect returns the SQL dialect of the database. Statements are written
+// with ? placeholders, which every method rebinds for it.
+func (d *Database) Dialect() domain.Dialect {
+	return d.dialect
+}
+
 func (d *Database) Exec(query string, args ...interface{}) (sql.Result, error) {
-	if len(args) == 0 {
-		return d.db.Exec(query)
-	}
-	return d.db.Exec(query, args)
+	return d.db.Exec(d.dialect.Rebind(query), args...)
 }
 
 func (d *Database) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	if len(args) == 0 {
-		return d.db.Query(query)
+	return d.db.Query(d.dialect.Rebind(query), args...)
+}
+
+func (d *Database) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.db.QueryRow(d.dialect.Rebind(query), args...)
+}
+
+func (d *Database) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.db.ExecContext(ctx, d.dialect.Rebind(query), args...)
+}
+
+func (d *Database) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, d.dialect.Rebind(query), args...)
+}
+
+func (d *Database) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.db.QueryRowContext(ctx, d.dialect.Rebind(query), args...)
+}
+
+// Close closes the database
+func (d *Database) Close() error {
+	return d.db.Close()
+}
+
+// CreateTables creates every table in Tables that doesn't exist yet, for
+// development and tests. Deployed databases are changed by migrations.
+func (d *Database) CreateTables(ctx context.Context) error {
+	for _, t := range Tables {
+		for _, stmt := range splitStatements(t.Schema[d.dialect]) {
+			if _, err := d.db.ExecContext(ctx, ifNotExists(stmt)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ifNotExists makes a CREATE TABLE or CREATE INDEX statement do nothing
+// if what it creates exists
+func ifNotExists(stmt string) string {
+	for _, create := range []string{"CREATE TABLE ", "CREATE INDEX ", "CREATE UNIQUE INDEX "} {
+		if strings.HasPrefix(stmt, create) {
+			return create + "IF NOT EXISTS " + strings.TrimPrefix(stmt, create)
+		}
 	}
-	return d.db.Query(query, args)
+	return stmt
+}
+
+// Tx is a transaction on a Database, rebinding placeholders like it does
+type Tx struct {
+	tx      *sql.Tx
+	dialect domain.Dialect
+}
+
+// BeginTx starts a transaction
+func (d *Database) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, dialect: d.dialect}, nil
+}
+
+// Dialect returns the SQL dialect of the transaction's database
+func (t *Tx) Dialect() domain.Dialect {
+	return t.dialect
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, t.dialect.Rebind(query), args...)
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, t.dialect.Rebind(query), args...)
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.tx.QueryRowContext(ctx, t.dialect.Rebind(query), args...)
+}
+
+// Commit commits the transaction
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback aborts the transaction
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
 }
@@ -1,5 +1,6 @@
 //go run gen/gen.go
 // VERY GENERATED PLZ NO MODIFY
+// checksum b88d6dc2aa69a55c
 
 // Package database
 // Database persists domain objects
@@ -8,67 +9,95 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
+
+	"git.ottoq.com/otto-backend/valet/domain"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
 type Database struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect domain.Dialect
 }
 
+// New connects to the MySQL database. Tables are created and changed by
+// migrations, see MigrateUp.
 func New(addr, dbname, user, pass string) (*Database, error) {
-	var err error
-	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@%s/%s?parseTime=true", user, pass, addr, dbname))
+	return Open(domain.MySQL, fmt.Sprintf("%s:%s@%s/%s?parseTime=true", user, pass, addr, dbname))
+}
+
+// Open connects to the database of the given dialect at dsn, whose driver
+// must be compiled in: MySQL's always is, Postgres' with the postgres
+// build tag and SQLite's with the sqlite tag. A MySQL dsn needs
+// parseTime=true.
+func Open(dialect domain.Dialect, dsn string) (*Database, error) {
+	db, err := sql.Open(string(dialect), dsn)
 	if err != nil {
 		return nil, err
 	}
-	err = EnsureTablesExist(db)
-	if err != nil {
+	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 	return &Database{
-		db: db,
+		db:      db,
+		dialect: dialect,
 	}, nil
 }
 
-func EnsureTablesExist(db *sql.DB) error {
-	tablequery := "SHOW TABLES LIKE '%s'"
-	for _, ts := range Tables {
-		var table string
-		err := db.QueryRow(fmt.Sprintf(tablequery, ts.Table)).Scan(&table)
-		if err != nil {
-			_, err := db.Exec(ts.Schema)
-			if err != nil {
-				log.Fatal(err)
-			}
-			log.Printf("created TABLE %s\n", ts.Table)
-		}
-	}
-	return nil
-}
-
 // TableSchema holds an association between a table and its schema
 type TableSchema struct {
 	Table  string
-	Schema string
+	Schema domain.Statements // Schema creates the table in each dialect
 }
 
-// Tables is an array of TableSchemas
+// Tables is an array of TableSchemas, referenced tables first
 var Tables = []TableSchema{
 	TableSchema{
 		Table: "Node",
-		Schema: `CREATE TABLE Node (
+		Schema: domain.Statements{
+			domain.MySQL: `CREATE TABLE Node (
 ID BINARY(16),
 TypeID BINARY(16),
 Timestamp DATETIME,
 Name VARCHAR(100),
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);`,
+			domain.Postgres: `CREATE TABLE Node (
+ID BYTEA,
+TypeID BYTEA,
+Timestamp TIMESTAMP,
+Name VARCHAR(100),
+CreatedAt TIMESTAMP,
+UpdatedAt TIMESTAMP,
+DeletedAt TIMESTAMP,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);`,
+			domain.SQLite: `CREATE TABLE Node (
+ID BLOB,
+TypeID BLOB,
+Timestamp DATETIME,
+Name VARCHAR(100),
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
 PRIMARY KEY (ID)
 );`,
+		},
 	},
 	TableSchema{
 		Table: "Desk",
-		Schema: `CREATE TABLE Desk (
+		Schema: domain.Statements{
+			domain.MySQL: `CREATE TABLE Desk (
 ID BINARY(16),
 TypeID BINARY(16),
 Timestamp DATETIME,
@@ -76,8 +105,125 @@ Name VARCHAR(100),
 Lat FLOAT,
 Lng FLOAT,
 NodeID BINARY(16),
+State ENUM('open','closed','out_of_service') NOT NULL DEFAULT 'open',
+Version BIGINT,
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID),
+UNIQUE INDEX uniq_NodeID_Name (NodeID, Name),
+FOREIGN KEY (NodeID) REFERENCES Node(ID)
+);`,
+			domain.Postgres: `CREATE TABLE Desk (
+ID BYTEA,
+TypeID BYTEA,
+Timestamp TIMESTAMP,
+Name VARCHAR(100),
+Lat REAL,
+Lng REAL,
+NodeID BYTEA,
+State VARCHAR(14) CHECK (State IN ('open', 'closed', 'out_of_service')) NOT NULL DEFAULT 'open',
+Version BIGINT,
+CreatedAt TIMESTAMP,
+UpdatedAt TIMESTAMP,
+DeletedAt TIMESTAMP,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
 PRIMARY KEY (ID),
 FOREIGN KEY (NodeID) REFERENCES Node(ID)
+);
+CREATE UNIQUE INDEX Desk_uniq_NodeID_Name ON Desk (NodeID, Name);`,
+			domain.SQLite: `CREATE TABLE Desk (
+ID BLOB,
+TypeID BLOB,
+Timestamp DATETIME,
+Name VARCHAR(100),
+Lat REAL,
+Lng REAL,
+NodeID BLOB,
+State VARCHAR(14) CHECK (State IN ('open', 'closed', 'out_of_service')) NOT NULL DEFAULT 'open',
+Version INTEGER,
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID),
+FOREIGN KEY (NodeID) REFERENCES Node(ID)
+);
+CREATE UNIQUE INDEX Desk_uniq_NodeID_Name ON Desk (NodeID, Name);`,
+		},
+	},
+	TableSchema{
+		Table: "Attendant",
+		Schema: domain.Statements{
+			domain.MySQL: `CREATE TABLE Attendant (
+ID BINARY(16),
+TypeID BINARY(16),
+Timestamp DATETIME,
+Name VARCHAR(100),
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);`,
+			domain.Postgres: `CREATE TABLE Attendant (
+ID BYTEA,
+TypeID BYTEA,
+Timestamp TIMESTAMP,
+Name VARCHAR(100),
+CreatedAt TIMESTAMP,
+UpdatedAt TIMESTAMP,
+DeletedAt TIMESTAMP,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);`,
+			domain.SQLite: `CREATE TABLE Attendant (
+ID BLOB,
+TypeID BLOB,
+Timestamp DATETIME,
+Name VARCHAR(100),
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);`,
+		},
+	},
+	TableSchema{
+		Table: "DeskAttendant",
+		Schema: domain.Statements{
+			domain.MySQL: `CREATE TABLE DeskAttendant (
+DeskID BINARY(16),
+AttendantID BINARY(16),
+PRIMARY KEY (DeskID, AttendantID),
+INDEX idx_AttendantID (AttendantID),
+FOREIGN KEY (DeskID) REFERENCES Desk(ID) ON DELETE CASCADE,
+FOREIGN KEY (AttendantID) REFERENCES Attendant(ID) ON DELETE CASCADE
 );`,
+			domain.Postgres: `CREATE TABLE DeskAttendant (
+DeskID BYTEA,
+AttendantID BYTEA,
+PRIMARY KEY (DeskID, AttendantID),
+FOREIGN KEY (DeskID) REFERENCES Desk(ID) ON DELETE CASCADE,
+FOREIGN KEY (AttendantID) REFERENCES Attendant(ID) ON DELETE CASCADE
+);
+CREATE INDEX DeskAttendant_idx_AttendantID ON DeskAttendant (AttendantID);`,
+			domain.SQLite: `CREATE TABLE DeskAttendant (
+DeskID BLOB,
+AttendantID BLOB,
+PRIMARY KEY (DeskID, AttendantID),
+FOREIGN KEY (DeskID) REFERENCES Desk(ID) ON DELETE CASCADE,
+FOREIGN KEY (AttendantID) REFERENCES Attendant(ID) ON DELETE CASCADE
+);
+CREATE INDEX DeskAttendant_idx_AttendantID ON DeskAttendant (AttendantID);`,
+		},
 	},
 }
@@ -0,0 +1,327 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+)
+
+// MigrationTable records the migrations applied to a database
+const MigrationTable = "schema_migrations"
+
+var migrationSchema = domain.Statements{
+	domain.MySQL: `CREATE TABLE IF NOT EXISTS ` + MigrationTable + ` (
+Version INT NOT NULL,
+Name VARCHAR(255) NOT NULL,
+AppliedAt DATETIME NOT NULL,
+PRIMARY KEY (Version)
+);`,
+	domain.Postgres: `CREATE TABLE IF NOT EXISTS ` + MigrationTable + ` (
+Version INT NOT NULL,
+Name VARCHAR(255) NOT NULL,
+AppliedAt TIMESTAMP NOT NULL,
+PRIMARY KEY (Version)
+);`,
+	domain.SQLite: `CREATE TABLE IF NOT EXISTS ` + MigrationTable + ` (
+Version INT NOT NULL,
+Name VARCHAR(255) NOT NULL,
+AppliedAt DATETIME NOT NULL,
+PRIMARY KEY (Version)
+);`,
+}
+
+// ErrMigrationDialect is returned when migrating a database other than
+// MySQL. Migration files are written in MySQL's DDL, Postgres and SQLite
+// databases are created by CreateTables.
+var ErrMigrationDialect = errors.New("migrations are written for MySQL, create other databases with CreateTables")
+
+var (
+	migrationFileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	createTableRegexp   = regexp.MustCompile("(?im)^\\s*CREATE TABLE\\s+(?:IF NOT EXISTS\\s+)?`?(\\w+)")
+)
+
+// Migration is a versioned schema change read from a pair of files named
+// <version>_<name>.up.sql and <version>_<name>.down.sql
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// String returns the migration's file prefix
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Tables returns the tables the migration's up statements create
+func (m Migration) Tables() []string {
+	tables := []string{}
+	for _, match := range createTableRegexp.FindAllStringSubmatch(m.Up, -1) {
+		tables = append(tables, match[1])
+	}
+	return tables
+}
+
+// MigrationState pairs a migration with whether it has been applied
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// ErrMigration is a failed migration
+type ErrMigration struct {
+	Migration Migration
+	Reason    string
+}
+
+// Error returns the error string
+func (err *ErrMigration) Error() string {
+	return fmt.Sprintf("migration %s: %s", err.Migration, err.Reason)
+}
+
+// LoadMigrations reads every migration in dir, ordered by version
+func LoadMigrations(dir string) ([]Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, f := range files {
+		m := migrationFileRegexp.FindStringSubmatch(f.Name())
+		if m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, mig.Name, m[2])
+		}
+		b, err := ioutil.ReadFile(path.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if m[3] == "up" {
+			mig.Up = string(b)
+		} else {
+			mig.Down = string(b)
+		}
+	}
+	migrations := []Migration{}
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, &ErrMigration{*m, "missing up statements"}
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// MigrationStatus reports which of the migrations have been applied, in
+// any dialect
+func (d *Database) MigrationStatus(ctx context.Context, migrations []Migration) ([]MigrationState, error) {
+	if _, err := d.ExecContext(ctx, migrationSchema[d.dialect]); err != nil {
+		return nil, err
+	}
+	rows, err := d.QueryContext(ctx, "SELECT Version, AppliedAt FROM "+MigrationTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	states := make([]MigrationState, len(migrations))
+	for i, m := range migrations {
+		at, ok := applied[m.Version]
+		states[i] = MigrationState{Migration: m, Applied: ok, AppliedAt: at}
+	}
+	return states, nil
+}
+
+// MigrateUp applies every pending migration in version order and returns
+// the ones it applied. It stops at the first failure. Migrations are
+// written for MySQL, other dialects get ErrMigrationDialect.
+func (d *Database) MigrateUp(ctx context.Context, migrations []Migration) ([]Migration, error) {
+	if d.dialect != domain.MySQL {
+		return nil, ErrMigrationDialect
+	}
+	states, err := d.MigrationStatus(ctx, migrations)
+	if err != nil {
+		return nil, err
+	}
+	done := []Migration{}
+	for _, s := range states {
+		if s.Applied {
+			continue
+		}
+		for _, table := range s.Tables() {
+			exists, err := d.tableExists(ctx, table)
+			if err != nil {
+				return done, &ErrMigration{s.Migration, err.Error()}
+			}
+			if exists {
+				return done, &ErrMigration{s.Migration, "table " + table + " exists, record the migrations the database already has with Baseline"}
+			}
+		}
+		if err := d.runMigration(ctx, s.Migration, s.Up); err != nil {
+			return done, err
+		}
+		if err := d.recordMigration(ctx, s.Migration); err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// Baseline records the pending migrations up to version as applied
+// without running them and returns the ones it recorded. It's for
+// databases whose tables were created before they were migrated, ex. by
+// the EnsureTablesExist of earlier releases. Every table the migrations
+// create must exist already. Like MigrateUp it's MySQL only.
+func (d *Database) Baseline(ctx context.Context, migrations []Migration, version int) ([]Migration, error) {
+	if d.dialect != domain.MySQL {
+		return nil, ErrMigrationDialect
+	}
+	states, err := d.MigrationStatus(ctx, migrations)
+	if err != nil {
+		return nil, err
+	}
+	done := []Migration{}
+	for _, s := range states {
+		if s.Version > version {
+			break
+		}
+		if s.Applied {
+			continue
+		}
+		for _, table := range s.Tables() {
+			exists, err := d.tableExists(ctx, table)
+			if err != nil {
+				return done, &ErrMigration{s.Migration, err.Error()}
+			}
+			if !exists {
+				return done, &ErrMigration{s.Migration, "table " + table + " doesn't exist, apply the migration with MigrateUp"}
+			}
+		}
+		if err := d.recordMigration(ctx, s.Migration); err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// recordMigration marks a migration as applied
+func (d *Database) recordMigration(ctx context.Context, m Migration) error {
+	_, err := d.ExecContext(ctx,
+		"INSERT INTO "+MigrationTable+" (Version, Name, AppliedAt) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now().UTC())
+	if err != nil {
+		return &ErrMigration{m, err.Error()}
+	}
+	return nil
+}
+
+// tableExists reports whether the MySQL database has the table
+func (d *Database) tableExists(ctx context.Context, table string) (bool, error) {
+	var n int
+	err := d.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		table).Scan(&n)
+	return n > 0, err
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first, and
+// returns the ones it reverted. Like MigrateUp it's MySQL only.
+func (d *Database) MigrateDown(ctx context.Context, migrations []Migration, steps int) ([]Migration, error) {
+	if d.dialect != domain.MySQL {
+		return nil, ErrMigrationDialect
+	}
+	states, err := d.MigrationStatus(ctx, migrations)
+	if err != nil {
+		return nil, err
+	}
+	done := []Migration{}
+	for i := len(states) - 1; i >= 0 && len(done) < steps; i-- {
+		s := states[i]
+		if !s.Applied {
+			continue
+		}
+		if strings.TrimSpace(s.Down) == "" {
+			return done, &ErrMigration{s.Migration, "missing down statements"}
+		}
+		if err := d.runMigration(ctx, s.Migration, s.Down); err != nil {
+			return done, err
+		}
+		_, err := d.ExecContext(ctx, "DELETE FROM "+MigrationTable+" WHERE Version = ?", s.Version)
+		if err != nil {
+			return done, &ErrMigration{s.Migration, err.Error()}
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// runMigration executes each statement of a migration file in order.
+// MySQL commits DDL implicitly so a failure part way through is reported
+// along with the statement that failed.
+func (d *Database) runMigration(ctx context.Context, m Migration, sql string) error {
+	for _, stmt := range splitStatements(sql) {
+		if _, err := d.ExecContext(ctx, stmt); err != nil {
+			return &ErrMigration{m, fmt.Sprintf("%s\n%s", err, stmt)}
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a migration file on semicolons ending a line,
+// dropping statements that are only comments
+func splitStatements(sql string) []string {
+	stmts := []string{}
+	current := []string{}
+	code := false
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		current = append(current, line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+			code = true
+		}
+		if strings.HasSuffix(trimmed, ";") && code {
+			stmts = append(stmts, strings.TrimSpace(strings.Join(current, "\n")))
+			current = current[:0]
+			code = false
+		}
+	}
+	if code {
+		stmts = append(stmts, strings.TrimSpace(strings.Join(current, "\n")))
+	}
+	return stmts
+}
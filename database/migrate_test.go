@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations("migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations loaded")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migration %s is number %d", m, i+1)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			t.Fatalf("migration %s has no down statements", m)
+		}
+	}
+}
+
+func TestMigrationTables(t *testing.T) {
+	tests := []struct {
+		up   string
+		want []string
+	}{
+		{"ALTER TABLE A ADD B INT;", []string{}},
+		{"CREATE TABLE A (\nID INT\n);\n\nCREATE TABLE IF NOT EXISTS `B` (\nID INT\n);", []string{"A", "B"}},
+		{"-- CREATE TABLE C\ncreate table d (ID INT);", []string{"d"}},
+	}
+	for _, test := range tests {
+		if got := (Migration{Up: test.up}).Tables(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Tables of %q = %q, want %q", test.up, got, test.want)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"", []string{}},
+		{"-- only a comment\n", []string{}},
+		{"DROP TABLE A;", []string{"DROP TABLE A;"}},
+		{"DROP TABLE A;\n\nDROP TABLE B;\n", []string{"DROP TABLE A;", "DROP TABLE B;"}},
+		{"CREATE TABLE A (\nID INT\n);\n-- trailing comment", []string{"CREATE TABLE A (\nID INT\n);"}},
+		{"-- leading comment\nDROP TABLE A;", []string{"-- leading comment\nDROP TABLE A;"}},
+		{"UPDATE A SET B = 'x;y'\nWHERE C = 1;", []string{"UPDATE A SET B = 'x;y'\nWHERE C = 1;"}},
+		{"DROP TABLE A", []string{"DROP TABLE A"}},
+	}
+	for _, test := range tests {
+		if got := splitStatements(test.sql); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitStatements(%q) = %q, want %q", test.sql, got, test.want)
+		}
+	}
+}
+
+func TestIfNotExists(t *testing.T) {
+	tests := map[string]string{
+		"CREATE TABLE A (ID INT);":         "CREATE TABLE IF NOT EXISTS A (ID INT);",
+		"CREATE INDEX i ON A (ID);":        "CREATE INDEX IF NOT EXISTS i ON A (ID);",
+		"CREATE UNIQUE INDEX i ON A (ID);": "CREATE UNIQUE INDEX IF NOT EXISTS i ON A (ID);",
+		"ALTER TABLE A ADD B INT;":         "ALTER TABLE A ADD B INT;",
+	}
+	for stmt, want := range tests {
+		if got := ifNotExists(stmt); got != want {
+			t.Errorf("ifNotExists(%q) = %q, want %q", stmt, got, want)
+		}
+	}
+}
+
+func TestMigrationSchema(t *testing.T) {
+	for _, d := range domain.Dialects {
+		if migrationSchema[d] == "" {
+			t.Errorf("no %s schema for %s", d, MigrationTable)
+		}
+	}
+}
+
+// testDialect creates every table twice in a database that isn't MySQL,
+// reads the migrations' status and checks they're refused. It's run by
+// the tests built with the driver's tag.
+func testDialect(t *testing.T, dialect domain.Dialect, dsn string) {
+	db, err := Open(dialect, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := db.CreateTables(ctx); err != nil {
+			t.Fatalf("CreateTables %d: %s", i+1, err)
+		}
+	}
+	for _, table := range Tables {
+		var n int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table.Table).Scan(&n); err != nil {
+			t.Fatalf("%s: %s", table.Table, err)
+		}
+	}
+
+	migrations, err := LoadMigrations("migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	states, err := db.MigrationStatus(ctx, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range states {
+		if s.Applied {
+			t.Fatalf("migration %s is applied", s.Migration)
+		}
+	}
+	if _, err := db.MigrateUp(ctx, migrations); err != ErrMigrationDialect {
+		t.Fatalf("MigrateUp got %v, want ErrMigrationDialect", err)
+	}
+	if _, err := db.MigrateDown(ctx, migrations, 1); err != ErrMigrationDialect {
+		t.Fatalf("MigrateDown got %v, want ErrMigrationDialect", err)
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE Desk;
+
+DROP TABLE Node;
@@ -0,0 +1,19 @@
+CREATE TABLE Node (
+ID BINARY(16),
+TypeID BINARY(16),
+Timestamp DATETIME,
+Name VARCHAR(100),
+PRIMARY KEY (ID)
+);
+
+CREATE TABLE Desk (
+ID BINARY(16),
+TypeID BINARY(16),
+Timestamp DATETIME,
+Name VARCHAR(100),
+Lat FLOAT,
+Lng FLOAT,
+NodeID BINARY(16),
+PRIMARY KEY (ID),
+FOREIGN KEY (NodeID) REFERENCES Node(ID)
+);
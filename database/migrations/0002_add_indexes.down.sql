@@ -0,0 +1,3 @@
+ALTER TABLE Desk
+ADD INDEX NodeID (NodeID),
+DROP INDEX uniq_NodeID_Name;
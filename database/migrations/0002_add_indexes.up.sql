@@ -0,0 +1,2 @@
+ALTER TABLE Desk
+ADD UNIQUE INDEX uniq_NodeID_Name (NodeID, Name);
@@ -0,0 +1,13 @@
+ALTER TABLE Desk
+DROP COLUMN UpdatedBy,
+DROP COLUMN CreatedBy,
+DROP COLUMN DeletedAt,
+DROP COLUMN UpdatedAt,
+DROP COLUMN CreatedAt;
+
+ALTER TABLE Node
+DROP COLUMN UpdatedBy,
+DROP COLUMN CreatedBy,
+DROP COLUMN DeletedAt,
+DROP COLUMN UpdatedAt,
+DROP COLUMN CreatedAt;
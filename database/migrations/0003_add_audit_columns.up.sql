@@ -0,0 +1,18 @@
+ALTER TABLE Node
+ADD COLUMN CreatedAt DATETIME AFTER Name,
+ADD COLUMN UpdatedAt DATETIME AFTER CreatedAt,
+ADD COLUMN DeletedAt DATETIME AFTER UpdatedAt,
+ADD COLUMN CreatedBy VARCHAR(100) AFTER DeletedAt,
+ADD COLUMN UpdatedBy VARCHAR(100) AFTER CreatedBy;
+
+ALTER TABLE Desk
+ADD COLUMN CreatedAt DATETIME AFTER NodeID,
+ADD COLUMN UpdatedAt DATETIME AFTER CreatedAt,
+ADD COLUMN DeletedAt DATETIME AFTER UpdatedAt,
+ADD COLUMN CreatedBy VARCHAR(100) AFTER DeletedAt,
+ADD COLUMN UpdatedBy VARCHAR(100) AFTER CreatedBy;
+
+-- existing rows were created and last changed at their Timestamp
+UPDATE Node SET CreatedAt = Timestamp, UpdatedAt = Timestamp, CreatedBy = '', UpdatedBy = '';
+
+UPDATE Desk SET CreatedAt = Timestamp, UpdatedAt = Timestamp, CreatedBy = '', UpdatedBy = '';
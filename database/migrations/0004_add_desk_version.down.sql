@@ -0,0 +1,2 @@
+ALTER TABLE Desk
+DROP COLUMN Version;
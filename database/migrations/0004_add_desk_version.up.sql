@@ -0,0 +1,5 @@
+ALTER TABLE Desk
+ADD COLUMN Version BIGINT AFTER NodeID;
+
+-- every existing row starts at the first version
+UPDATE Desk SET Version = 1;
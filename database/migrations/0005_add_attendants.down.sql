@@ -0,0 +1,3 @@
+DROP TABLE DeskAttendant;
+
+DROP TABLE Attendant;
@@ -0,0 +1,21 @@
+CREATE TABLE Attendant (
+ID BINARY(16),
+TypeID BINARY(16),
+Timestamp DATETIME,
+Name VARCHAR(100),
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);
+
+CREATE TABLE DeskAttendant (
+DeskID BINARY(16),
+AttendantID BINARY(16),
+PRIMARY KEY (DeskID, AttendantID),
+INDEX idx_AttendantID (AttendantID),
+FOREIGN KEY (DeskID) REFERENCES Desk(ID) ON DELETE CASCADE,
+FOREIGN KEY (AttendantID) REFERENCES Attendant(ID) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+-- existing desks were in use, so they start open
+ALTER TABLE Desk
+ADD COLUMN State ENUM('open','closed','out_of_service') NOT NULL DEFAULT 'open' AFTER NodeID;
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/node"
+)
+
+// MySQLDSNEnv names the environment variable holding the MySQL test
+// database, ex. VALET_TEST_MYSQL_DSN="root@tcp(127.0.0.1:3306)/valet_test?parseTime=true"
+const MySQLDSNEnv = "VALET_TEST_MYSQL_DSN"
+
+// TestMySQLHexIDs reads an ID back with a text protocol query, which has
+// no arguments, and a prepared one, the driver returning hex for the
+// first and bytes for the second
+func TestMySQLHexIDs(t *testing.T) {
+	dsn := os.Getenv(MySQLDSNEnv)
+	if dsn == "" {
+		t.Skipf("set %s to run against MySQL", MySQLDSNEnv)
+	}
+	db, err := Open(domain.MySQL, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if err := db.CreateTables(ctx); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	n := node.Random()
+	if err := n.Insert(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	queries := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{"text", "SELECT ID, TypeID FROM Node WHERE ID = X'" + n.ID + "'", nil},
+		{"prepared", "SELECT ID, TypeID FROM Node WHERE ID = ?", []interface{}{domain.Hex(n.ID)}},
+	}
+	for _, q := range queries {
+		var id, typeID string
+		if err := tx.QueryRowContext(ctx, q.query, q.args...).Scan(domain.ScanHex(&id), domain.ScanHex(&typeID)); err != nil {
+			t.Fatalf("%s: %s", q.name, err)
+		}
+		if id != n.ID || typeID != n.TypeID {
+			t.Errorf("%s query read %s, %s, want %s, %s", q.name, id, typeID, n.ID, n.TypeID)
+		}
+	}
+}
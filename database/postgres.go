@@ -0,0 +1,8 @@
+//go:build postgres
+// +build postgres
+
+package database
+
+// The Postgres driver is only compiled in with the postgres build tag,
+// ex. go test -tags postgres ./...
+import _ "github.com/lib/pq"
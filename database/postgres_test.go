@@ -0,0 +1,23 @@
+//go:build postgres
+// +build postgres
+
+package database
+
+import (
+	"os"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+)
+
+// PostgresDSNEnv names the environment variable holding the Postgres test
+// database, ex. VALET_TEST_POSTGRES_DSN="postgres://localhost/valet_test?sslmode=disable"
+const PostgresDSNEnv = "VALET_TEST_POSTGRES_DSN"
+
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv(PostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("set %s to run against Postgres", PostgresDSNEnv)
+	}
+	testDialect(t, domain.Postgres, dsn)
+}
@@ -0,0 +1,8 @@
+//go:build sqlite
+// +build sqlite
+
+package database
+
+// The SQLite driver needs cgo, so it's only compiled in with the sqlite
+// build tag, ex. go test -tags sqlite ./...
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,14 @@
+//go:build sqlite
+// +build sqlite
+
+package database
+
+import (
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+)
+
+func TestSQLite(t *testing.T) {
+	testDialect(t, domain.SQLite, "file:TestSQLite?mode=memory&cache=shared")
+}
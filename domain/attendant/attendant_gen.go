@@ -0,0 +1,976 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 294450f1eff7db67
+
+// Package Attendant
+// Attendant parks cars and hands out their keys
+package attendant
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/entity"
+)
+
+type Attendant struct {
+	ID        string
+	TypeID    string
+	Timestamp time.Time
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+	CreatedBy string
+	UpdatedBy string
+
+	// changed holds the value of each field when o was last read or
+	// written, or before its first setter call if it wasn't
+	changed map[string]interface{}
+}
+
+func New(
+	name string,
+) (*Attendant, error) {
+	d := &Attendant{
+		ID:        entity.UUID(),
+		TypeID:    "63BC55059181485E9077C98DCC322985",
+		Timestamp: entity.Now(),
+		Name:      name,
+		CreatedAt: entity.Now(),
+		UpdatedAt: entity.Now(),
+		DeletedAt: nil,
+		CreatedBy: "",
+		UpdatedBy: "",
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Validate checks every field against its constraints, returning a
+// domain.ValidationError listing each field that failed
+func (o *Attendant) Validate() error {
+	errs := domain.ValidationError{}
+	if o.ID == "" {
+		errs = append(errs, &domain.FieldError{Field: "ID", Reason: "is required"})
+	}
+	if o.ID != "" && !domain.IsHexID(o.ID) {
+		errs = append(errs, &domain.FieldError{Field: "ID", Reason: "must be 32 hexadecimal characters"})
+	}
+	if o.TypeID != "" && !domain.IsHexID(o.TypeID) {
+		errs = append(errs, &domain.FieldError{Field: "TypeID", Reason: "must be 32 hexadecimal characters"})
+	}
+	if o.Name == "" {
+		errs = append(errs, &domain.FieldError{Field: "Name", Reason: "is required"})
+	}
+	if utf8.RuneCountInString(o.Name) > 100 {
+		errs = append(errs, &domain.FieldError{Field: "Name", Reason: "must be at most 100 characters"})
+	}
+	if utf8.RuneCountInString(o.CreatedBy) > 100 {
+		errs = append(errs, &domain.FieldError{Field: "CreatedBy", Reason: "must be at most 100 characters"})
+	}
+	if utf8.RuneCountInString(o.UpdatedBy) > 100 {
+		errs = append(errs, &domain.FieldError{Field: "UpdatedBy", Reason: "must be at most 100 characters"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+///////////////////
+// CHANGES
+///////////////////
+
+// SetName sets Name, recording the change for Changes() and Update
+func (o *Attendant) SetName(v string) {
+	o.track("Name", o.Name)
+	o.Name = v
+}
+
+// track records the value a field had before it was first set
+func (o *Attendant) track(field string, old interface{}) {
+	if o.changed == nil {
+		o.changed = map[string]interface{}{}
+	}
+	if _, ok := o.changed[field]; !ok {
+		o.changed[field] = domain.Copy(old)
+	}
+}
+
+// saved records the value of every field as the row holds it
+func (o *Attendant) saved() {
+	o.changed = map[string]interface{}{
+		"Name": domain.Copy(o.Name),
+	}
+}
+
+// Changes lists the fields holding a new value since o was read or last
+// written, in column order, however they were assigned. Of an o that
+// wasn't, only the fields set through a setter are tracked.
+func (o *Attendant) Changes() []domain.Change {
+	changes := []domain.Change{}
+	if old, ok := o.changed["Name"]; ok && domain.Changed(old, o.Name) {
+		changes = append(changes, domain.Change{Field: "Name", Old: old, New: o.Name})
+	}
+	return changes
+}
+
+// Patch applies a JSON merge patch (RFC 7386) to o through its setters and
+// validates the result, returning a domain.ValidationError and leaving o
+// unchanged if either fails. The primary key may be named but
+// not changed, other fields can't be patched.
+func (o *Attendant) Patch(patch []byte) error {
+	fields, err := domain.ParsePatch(patch)
+	if err != nil {
+		return err
+	}
+	patched := *o
+	patched.changed = nil
+	for field, old := range o.changed {
+		patched.track(field, old)
+	}
+	errs := domain.ValidationError{}
+	for _, f := range fields {
+		switch f.Name {
+		case "Name":
+			var v string
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else {
+				patched.SetName(v)
+			}
+		case "ID":
+			var v string
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else if v != o.ID {
+				errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be changed"})
+			}
+		default:
+			errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be patched"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if err := patched.Validate(); err != nil {
+		return err
+	}
+	*o = patched
+	return nil
+}
+
+type Scannable interface {
+	Scan(dest ...interface{}) error
+}
+
+func NewFromRow(row Scannable) (*Attendant, error) {
+	d := Attendant{}
+	err := row.Scan(
+		domain.ScanHex(&d.ID),
+		domain.ScanHex(&d.TypeID),
+		&d.Timestamp,
+		&d.Name,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+		&d.DeletedAt,
+		&d.CreatedBy,
+		&d.UpdatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.saved()
+	return &d, nil
+}
+
+// schema creates the table in each dialect
+var schema = domain.Statements{
+	domain.MySQL: `CREATE TABLE Attendant (
+ID BINARY(16),
+TypeID BINARY(16),
+Timestamp DATETIME,
+Name VARCHAR(100),
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);`,
+	domain.Postgres: `CREATE TABLE Attendant (
+ID BYTEA,
+TypeID BYTEA,
+Timestamp TIMESTAMP,
+Name VARCHAR(100),
+CreatedAt TIMESTAMP,
+UpdatedAt TIMESTAMP,
+DeletedAt TIMESTAMP,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);`,
+	domain.SQLite: `CREATE TABLE Attendant (
+ID BLOB,
+TypeID BLOB,
+Timestamp DATETIME,
+Name VARCHAR(100),
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID)
+);`,
+}
+
+// Schema returns the statements creating the table in each dialect
+func Schema() domain.Statements {
+	return schema
+}
+
+func TableName() string {
+	return "Attendant"
+}
+
+// TypeID identifies Attendant objects
+const TypeID = "63BC55059181485E9077C98DCC322985"
+
+func Random() *Attendant {
+	d := &Attendant{
+		ID:        entity.UUID(),
+		TypeID:    "63BC55059181485E9077C98DCC322985",
+		Timestamp: entity.Now(),
+		Name:      entity.RANDname(),
+		CreatedAt: entity.Now(),
+		UpdatedAt: entity.Now(),
+		DeletedAt: nil,
+		CreatedBy: "",
+		UpdatedBy: "",
+	}
+	return d
+}
+
+///////////////////
+// REPOSITORY
+///////////////////
+
+const (
+	sqlSelect            = `SELECT ID, TypeID, Timestamp, Name, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy FROM (SELECT * FROM Attendant WHERE DeletedAt IS NULL) AS Attendant`
+	sqlSelectWithDeleted = `SELECT ID, TypeID, Timestamp, Name, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy FROM Attendant`
+	sqlInsert            = `INSERT INTO Attendant (ID, TypeID, Timestamp, Name, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	sqlUpdate = `UPDATE Attendant SET TypeID = ?, Timestamp = ?, Name = ?, UpdatedAt = ?, UpdatedBy = ?
+WHERE ID = ? AND DeletedAt IS NULL`
+	sqlDelete = `UPDATE Attendant SET UpdatedAt = ?, DeletedAt = ?, UpdatedBy = ?
+WHERE ID = ? AND DeletedAt IS NULL`
+	sqlRestore = `UPDATE Attendant SET UpdatedAt = ?, DeletedAt = ?, UpdatedBy = ?
+WHERE ID = ? AND DeletedAt IS NOT NULL`
+	sqlSelectCreated = `SELECT CreatedAt, CreatedBy FROM Attendant WHERE ID = ?`
+)
+
+// sqlUpsert differs in each dialect
+var sqlUpsert = domain.Statements{
+	domain.MySQL: `INSERT INTO Attendant (ID, TypeID, Timestamp, Name, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE TypeID = IF(DeletedAt IS NULL, VALUES(TypeID), TypeID), Timestamp = IF(DeletedAt IS NULL, VALUES(Timestamp), Timestamp), Name = IF(DeletedAt IS NULL, VALUES(Name), Name), UpdatedAt = IF(DeletedAt IS NULL, VALUES(UpdatedAt), UpdatedAt), UpdatedBy = IF(DeletedAt IS NULL, VALUES(UpdatedBy), UpdatedBy)`,
+	domain.Postgres: `INSERT INTO Attendant (ID, TypeID, Timestamp, Name, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (ID) DO UPDATE SET TypeID = excluded.TypeID, Timestamp = excluded.Timestamp, Name = excluded.Name, UpdatedAt = excluded.UpdatedAt, UpdatedBy = excluded.UpdatedBy
+WHERE Attendant.DeletedAt IS NULL`,
+	domain.SQLite: `INSERT INTO Attendant (ID, TypeID, Timestamp, Name, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (ID) DO UPDATE SET TypeID = excluded.TypeID, Timestamp = excluded.Timestamp, Name = excluded.Name, UpdatedAt = excluded.UpdatedAt, UpdatedBy = excluded.UpdatedBy
+WHERE Attendant.DeletedAt IS NULL`,
+}
+
+var _ domain.Domain = (*Attendant)(nil)
+
+// SQLSelect returns the SELECT of every column, in NewFromRow scan order
+func SQLSelect() string {
+	return sqlSelect
+}
+
+// GetByID returns the Attendant with the given primary key
+func GetByID(ctx context.Context, db domain.DB, id string) (*Attendant, error) {
+	row := db.QueryRowContext(ctx, sqlSelect+" WHERE ID = ?", domain.Hex(id))
+	return NewFromRow(row)
+}
+
+// List returns up to limit Attendants ordered by primary key, skipping the first offset
+func List(ctx context.Context, db domain.DB, limit, offset int) ([]*Attendant, error) {
+	return Select(ctx, db, "ORDER BY ID LIMIT ? OFFSET ?", limit, offset)
+}
+
+// Select returns every Attendant matched by clause, which is appended to
+// the SELECT statement, ex. "WHERE Name = ? LIMIT 10"
+func Select(ctx context.Context, db domain.DB, clause string, args ...interface{}) ([]*Attendant, error) {
+	return query(ctx, db, sqlSelect+" "+clause, args...)
+}
+
+func query(ctx context.Context, db domain.DB, stmt string, args ...interface{}) ([]*Attendant, error) {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []*Attendant{}
+	for rows.Next() {
+		o, err := NewFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// Insert writes o as a new row
+func (o *Attendant) Insert(ctx context.Context, db domain.DB) error {
+	o.stamp(ctx, true)
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, sqlInsert, o.values()...); err != nil {
+		return err
+	}
+	o.saved()
+	return nil
+}
+
+// Upsert writes o as a new row, or overwrites the row that shares its primary key
+// unless it's deleted, returning a *domain.ErrDeleted if it is
+func (o *Attendant) Upsert(ctx context.Context, db domain.DB) error {
+	o.stamp(ctx, true)
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx, sqlUpsert.For(db), o.values()...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	// a deleted row is left as it is, which isn't counted, nor is a row
+	// MySQL leaves unchanged
+	if n == 0 {
+		found, err := o.exists(ctx, db)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return &domain.ErrDeleted{Table: TableName()}
+		}
+	}
+	// MySQL counts an inserted row once, other databases can't tell an
+	// insert from an update
+	if n != 1 || domain.DialectOf(db) != domain.MySQL {
+		if err := o.readCreated(ctx, db); err != nil {
+			return err
+		}
+	}
+	o.saved()
+	return nil
+}
+
+// Update overwrites the row that shares o's primary key, returning
+// sql.ErrNoRows if there's none or it's deleted.
+// Only the changed columns are written, and nothing if there are none.
+// Every column is written for an o that wasn't read or written, unless a
+// setter was used.
+func (o *Attendant) Update(ctx context.Context, db domain.DB) error {
+	changes := o.Changes()
+	if o.changed != nil && len(changes) == 0 {
+		return o.Validate()
+	}
+	o.stamp(ctx, false)
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	stmt, args := sqlUpdate, []interface{}{
+		domain.Hex(o.TypeID),
+		o.Timestamp,
+		o.Name,
+		o.UpdatedAt,
+		o.UpdatedBy,
+		domain.Hex(o.ID),
+	}
+	if o.changed != nil {
+		stmt, args = o.sqlUpdateChanges(changes)
+	}
+	res, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	// MySQL doesn't count a row it leaves unchanged
+	if n == 0 {
+		found, err := o.exists(ctx, db)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return sql.ErrNoRows
+		}
+	}
+	o.saved()
+	return nil
+}
+
+// updateColumn returns the SET assignment of a column a setter changes
+// and its argument
+func (o *Attendant) updateColumn(field string) (string, interface{}) {
+	switch field {
+	case "Name":
+		return "Name = ?", o.Name
+	}
+	panic("no column " + field)
+}
+
+// sqlUpdateChanges returns an UPDATE of the changed columns, along with
+// those every Update sets, and its arguments
+func (o *Attendant) sqlUpdateChanges(changes []domain.Change) (string, []interface{}) {
+	sets := []string{}
+	args := []interface{}{}
+	for _, c := range changes {
+		set, arg := o.updateColumn(c.Field)
+		sets = append(sets, set)
+		args = append(args, arg)
+	}
+	sets = append(sets, "UpdatedAt = ?")
+	args = append(args, o.UpdatedAt)
+	sets = append(sets, "UpdatedBy = ?")
+	args = append(args, o.UpdatedBy)
+	args = append(args, domain.Hex(o.ID))
+	return "UPDATE Attendant SET " + strings.Join(sets, ", ") + " WHERE ID = ? AND DeletedAt IS NULL", args
+}
+
+// Delete marks the row that shares o's primary key deleted. It's left out
+// of every query but QueryBuilder.WithDeleted until it's restored. It
+// returns sql.ErrNoRows if there's no such row or it's already deleted.
+func (o *Attendant) Delete(ctx context.Context, db domain.DB) error {
+	now := entity.Now().Truncate(time.Second)
+	return o.setDeleted(ctx, db, sqlDelete, &now)
+}
+
+// Restore undoes Delete, returning sql.ErrNoRows if there's no such
+// row or it isn't deleted
+func (o *Attendant) Restore(ctx context.Context, db domain.DB) error {
+	return o.setDeleted(ctx, db, sqlRestore, nil)
+}
+
+// setDeleted sets DeletedAt with stmt, sqlDelete or sqlRestore
+func (o *Attendant) setDeleted(ctx context.Context, db domain.DB, stmt string, deletedAt *time.Time) error {
+	o.stamp(ctx, false)
+	res, err := db.ExecContext(ctx, stmt, o.UpdatedAt, deletedAt, o.UpdatedBy, domain.Hex(o.ID))
+	if err != nil {
+		return err
+	}
+	// the row always changes, so MySQL counts it
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	o.DeletedAt = deletedAt
+	return nil
+}
+
+// stamp sets the audit columns of a write, created being true for a new
+// row. Upsert reads back the created columns of a row that already
+// existed, see readCreated.
+func (o *Attendant) stamp(ctx context.Context, created bool) {
+	now := entity.Now().Truncate(time.Second)
+	actor := domain.Actor(ctx)
+	if created {
+		o.CreatedAt = now
+		o.CreatedBy = actor
+	}
+	o.UpdatedAt = now
+	o.UpdatedBy = actor
+}
+
+// readCreated reads the audit columns set when o's row was created, which
+// Upsert leaves as they were when the row exists
+func (o *Attendant) readCreated(ctx context.Context, db domain.DB) error {
+	return db.QueryRowContext(ctx, sqlSelectCreated, domain.Hex(o.ID)).Scan(&o.CreatedAt, &o.CreatedBy)
+}
+
+// exists reports whether the row that shares o's primary key is there
+// and not deleted, which a write counting no row can't tell
+func (o *Attendant) exists(ctx context.Context, db domain.DB) (bool, error) {
+	n, err := Query().WhereID(o.ID).Count(ctx, db)
+	return n > 0, err
+}
+
+// values returns the argument writing each column in table order
+func (o *Attendant) values() []interface{} {
+	return []interface{}{
+		domain.Hex(o.ID),
+		domain.Hex(o.TypeID),
+		o.Timestamp,
+		o.Name,
+		o.CreatedAt,
+		o.UpdatedAt,
+		o.DeletedAt,
+		o.CreatedBy,
+		o.UpdatedBy,
+	}
+}
+
+///////////////////
+// QUERY BUILDER
+///////////////////
+
+// QueryBuilder builds a SELECT of Attendants, ex.
+// Query().WhereID(v).Limit(10).All(ctx, db)
+type QueryBuilder struct {
+	q           domain.Query
+	withDeleted bool
+}
+
+// Query starts a query matching every Attendant
+func Query() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// WhereID matches ID equal to v
+func (b *QueryBuilder) WhereID(v string) *QueryBuilder {
+	b.q.Cond("ID = ?", domain.Hex(v))
+	return b
+}
+
+// IDIn matches ID equal to any of vs
+func (b *QueryBuilder) IDIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.In("ID", "?", args)
+	return b
+}
+
+// OrderByID sorts by ID, after any order added before it
+func (b *QueryBuilder) OrderByID(o domain.Order) *QueryBuilder {
+	b.q.Order("ID", o)
+	return b
+}
+
+// WhereTypeID matches TypeID equal to v
+func (b *QueryBuilder) WhereTypeID(v string) *QueryBuilder {
+	b.q.Cond("TypeID = ?", domain.Hex(v))
+	return b
+}
+
+// TypeIDIn matches TypeID equal to any of vs
+func (b *QueryBuilder) TypeIDIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.In("TypeID", "?", args)
+	return b
+}
+
+// OrderByTypeID sorts by TypeID, after any order added before it
+func (b *QueryBuilder) OrderByTypeID(o domain.Order) *QueryBuilder {
+	b.q.Order("TypeID", o)
+	return b
+}
+
+// WhereTimestamp matches Timestamp equal to v
+func (b *QueryBuilder) WhereTimestamp(v time.Time) *QueryBuilder {
+	b.q.Cond("Timestamp = ?", v)
+	return b
+}
+
+// TimestampIn matches Timestamp equal to any of vs
+func (b *QueryBuilder) TimestampIn(vs ...time.Time) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("Timestamp", "?", args)
+	return b
+}
+
+// OrderByTimestamp sorts by Timestamp, after any order added before it
+func (b *QueryBuilder) OrderByTimestamp(o domain.Order) *QueryBuilder {
+	b.q.Order("Timestamp", o)
+	return b
+}
+
+// TimestampAfter matches Timestamp later than t
+func (b *QueryBuilder) TimestampAfter(t time.Time) *QueryBuilder {
+	b.q.Cond("Timestamp > ?", t)
+	return b
+}
+
+// TimestampBefore matches Timestamp earlier than t
+func (b *QueryBuilder) TimestampBefore(t time.Time) *QueryBuilder {
+	b.q.Cond("Timestamp < ?", t)
+	return b
+}
+
+// WhereName matches Name equal to v
+func (b *QueryBuilder) WhereName(v string) *QueryBuilder {
+	b.q.Cond("Name = ?", v)
+	return b
+}
+
+// NameIn matches Name equal to any of vs
+func (b *QueryBuilder) NameIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("Name", "?", args)
+	return b
+}
+
+// OrderByName sorts by Name, after any order added before it
+func (b *QueryBuilder) OrderByName(o domain.Order) *QueryBuilder {
+	b.q.Order("Name", o)
+	return b
+}
+
+// NameLike matches Name against a LIKE pattern, ex. "T%"
+func (b *QueryBuilder) NameLike(pattern string) *QueryBuilder {
+	b.q.Cond("Name LIKE ?", pattern)
+	return b
+}
+
+// WhereCreatedAt matches CreatedAt equal to v
+func (b *QueryBuilder) WhereCreatedAt(v time.Time) *QueryBuilder {
+	b.q.Cond("CreatedAt = ?", v)
+	return b
+}
+
+// CreatedAtIn matches CreatedAt equal to any of vs
+func (b *QueryBuilder) CreatedAtIn(vs ...time.Time) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("CreatedAt", "?", args)
+	return b
+}
+
+// OrderByCreatedAt sorts by CreatedAt, after any order added before it
+func (b *QueryBuilder) OrderByCreatedAt(o domain.Order) *QueryBuilder {
+	b.q.Order("CreatedAt", o)
+	return b
+}
+
+// CreatedAtAfter matches CreatedAt later than t
+func (b *QueryBuilder) CreatedAtAfter(t time.Time) *QueryBuilder {
+	b.q.Cond("CreatedAt > ?", t)
+	return b
+}
+
+// CreatedAtBefore matches CreatedAt earlier than t
+func (b *QueryBuilder) CreatedAtBefore(t time.Time) *QueryBuilder {
+	b.q.Cond("CreatedAt < ?", t)
+	return b
+}
+
+// WhereUpdatedAt matches UpdatedAt equal to v
+func (b *QueryBuilder) WhereUpdatedAt(v time.Time) *QueryBuilder {
+	b.q.Cond("UpdatedAt = ?", v)
+	return b
+}
+
+// UpdatedAtIn matches UpdatedAt equal to any of vs
+func (b *QueryBuilder) UpdatedAtIn(vs ...time.Time) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("UpdatedAt", "?", args)
+	return b
+}
+
+// OrderByUpdatedAt sorts by UpdatedAt, after any order added before it
+func (b *QueryBuilder) OrderByUpdatedAt(o domain.Order) *QueryBuilder {
+	b.q.Order("UpdatedAt", o)
+	return b
+}
+
+// UpdatedAtAfter matches UpdatedAt later than t
+func (b *QueryBuilder) UpdatedAtAfter(t time.Time) *QueryBuilder {
+	b.q.Cond("UpdatedAt > ?", t)
+	return b
+}
+
+// UpdatedAtBefore matches UpdatedAt earlier than t
+func (b *QueryBuilder) UpdatedAtBefore(t time.Time) *QueryBuilder {
+	b.q.Cond("UpdatedAt < ?", t)
+	return b
+}
+
+// WhereDeletedAt matches DeletedAt equal to v
+func (b *QueryBuilder) WhereDeletedAt(v time.Time) *QueryBuilder {
+	b.q.Cond("DeletedAt = ?", v)
+	return b
+}
+
+// DeletedAtIn matches DeletedAt equal to any of vs
+func (b *QueryBuilder) DeletedAtIn(vs ...time.Time) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("DeletedAt", "?", args)
+	return b
+}
+
+// OrderByDeletedAt sorts by DeletedAt, after any order added before it
+func (b *QueryBuilder) OrderByDeletedAt(o domain.Order) *QueryBuilder {
+	b.q.Order("DeletedAt", o)
+	return b
+}
+
+// DeletedAtAfter matches DeletedAt later than t
+func (b *QueryBuilder) DeletedAtAfter(t time.Time) *QueryBuilder {
+	b.q.Cond("DeletedAt > ?", t)
+	return b
+}
+
+// DeletedAtBefore matches DeletedAt earlier than t
+func (b *QueryBuilder) DeletedAtBefore(t time.Time) *QueryBuilder {
+	b.q.Cond("DeletedAt < ?", t)
+	return b
+}
+
+// DeletedAtIsNull matches rows without a DeletedAt
+func (b *QueryBuilder) DeletedAtIsNull() *QueryBuilder {
+	b.q.Cond("DeletedAt IS NULL")
+	return b
+}
+
+// DeletedAtIsNotNull matches rows with a DeletedAt
+func (b *QueryBuilder) DeletedAtIsNotNull() *QueryBuilder {
+	b.q.Cond("DeletedAt IS NOT NULL")
+	return b
+}
+
+// WhereCreatedBy matches CreatedBy equal to v
+func (b *QueryBuilder) WhereCreatedBy(v string) *QueryBuilder {
+	b.q.Cond("CreatedBy = ?", v)
+	return b
+}
+
+// CreatedByIn matches CreatedBy equal to any of vs
+func (b *QueryBuilder) CreatedByIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("CreatedBy", "?", args)
+	return b
+}
+
+// OrderByCreatedBy sorts by CreatedBy, after any order added before it
+func (b *QueryBuilder) OrderByCreatedBy(o domain.Order) *QueryBuilder {
+	b.q.Order("CreatedBy", o)
+	return b
+}
+
+// CreatedByLike matches CreatedBy against a LIKE pattern, ex. "T%"
+func (b *QueryBuilder) CreatedByLike(pattern string) *QueryBuilder {
+	b.q.Cond("CreatedBy LIKE ?", pattern)
+	return b
+}
+
+// WhereUpdatedBy matches UpdatedBy equal to v
+func (b *QueryBuilder) WhereUpdatedBy(v string) *QueryBuilder {
+	b.q.Cond("UpdatedBy = ?", v)
+	return b
+}
+
+// UpdatedByIn matches UpdatedBy equal to any of vs
+func (b *QueryBuilder) UpdatedByIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("UpdatedBy", "?", args)
+	return b
+}
+
+// OrderByUpdatedBy sorts by UpdatedBy, after any order added before it
+func (b *QueryBuilder) OrderByUpdatedBy(o domain.Order) *QueryBuilder {
+	b.q.Order("UpdatedBy", o)
+	return b
+}
+
+// UpdatedByLike matches UpdatedBy against a LIKE pattern, ex. "T%"
+func (b *QueryBuilder) UpdatedByLike(pattern string) *QueryBuilder {
+	b.q.Cond("UpdatedBy LIKE ?", pattern)
+	return b
+}
+
+// Limit returns at most n Attendants
+func (b *QueryBuilder) Limit(n int) *QueryBuilder {
+	b.q.Limit(n)
+	return b
+}
+
+// Offset skips the first n Attendants
+func (b *QueryBuilder) Offset(n int) *QueryBuilder {
+	b.q.Offset(n)
+	return b
+}
+
+// LinkedToDesks matches the Attendants linked to any of the Desks with
+// deskIDs, partitioned by the Desk
+func (b *QueryBuilder) LinkedToDesks(deskIDs ...string) *QueryBuilder {
+	args := make([]interface{}, len(deskIDs))
+	for i, v := range deskIDs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.Join("JOIN (SELECT AttendantID AS Linked, DeskID AS LinkedTo FROM DeskAttendant WHERE DeskID IN ("+domain.Placeholders("?", len(args))+")) AS Link ON Link.Linked = Attendant.ID", args...)
+	b.q.Partition("Link.LinkedTo")
+	return b
+}
+
+// WithDeleted includes soft deleted Attendants
+func (b *QueryBuilder) WithDeleted() *QueryBuilder {
+	b.withDeleted = true
+	return b
+}
+
+// SQL returns the parameterized statement and its arguments
+func (b *QueryBuilder) SQL() (string, []interface{}) {
+	if b.withDeleted {
+		return b.q.Select(sqlSelectWithDeleted)
+	}
+	return b.q.Select(sqlSelect)
+}
+
+// CountSQL returns the statement counting the matching Attendants, ignoring
+// Limit and Offset, and its arguments. Partitioned, it selects each
+// partition and its count.
+func (b *QueryBuilder) CountSQL() (string, []interface{}) {
+	if b.withDeleted {
+		return b.q.Count("Attendant")
+	}
+	return b.q.Count("(SELECT * FROM Attendant WHERE DeletedAt IS NULL) AS Attendant")
+}
+
+// All returns every matching Attendant
+func (b *QueryBuilder) All(ctx context.Context, db domain.DB) ([]*Attendant, error) {
+	stmt, args := b.SQL()
+	return query(ctx, db, stmt, args...)
+}
+
+// First returns the first matching Attendant, or sql.ErrNoRows
+func (b *QueryBuilder) First(ctx context.Context, db domain.DB) (*Attendant, error) {
+	b.q.Limit(1)
+	query, args := b.SQL()
+	return NewFromRow(db.QueryRowContext(ctx, query, args...))
+}
+
+// Count returns the number of matching Attendants, ignoring Limit and Offset
+func (b *QueryBuilder) Count(ctx context.Context, db domain.DB) (int, error) {
+	stmt, args := b.CountSQL()
+	var n int
+	err := db.QueryRowContext(ctx, stmt, args...).Scan(&n)
+	return n, err
+}
+
+///////////////////
+// DESKATTENDANTS
+///////////////////
+
+// DeskAttendantsLoader lists the DeskAttendants whose AttendantID is attendantID.
+// The deskattendant package sets it, as it imports this one.
+var DeskAttendantsLoader func(ctx context.Context, db domain.DB, attendantID string) ([]domain.Domain, error)
+
+// DeskAttendants returns every DeskAttendant whose AttendantID references o, each a
+// *deskattendant.DeskAttendant. They're loaded by the deskattendant package, which
+// imports this one, so it must be imported too.
+func (o *Attendant) DeskAttendants(ctx context.Context, db domain.DB) ([]domain.Domain, error) {
+	if DeskAttendantsLoader == nil {
+		return nil, fmt.Errorf("attendant.DeskAttendants needs the deskattendant package imported")
+	}
+	return DeskAttendantsLoader(ctx, db, o.ID)
+}
+
+///////////////////
+// DESK LINKS
+///////////////////
+
+// sqlAddDesk differs in each dialect
+var sqlAddDesk = domain.Statements{
+	domain.MySQL: `INSERT INTO DeskAttendant (AttendantID, DeskID)
+VALUES (?, ?)
+ON DUPLICATE KEY UPDATE AttendantID = AttendantID`,
+	domain.Postgres: `INSERT INTO DeskAttendant (AttendantID, DeskID)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING`,
+	domain.SQLite: `INSERT INTO DeskAttendant (AttendantID, DeskID)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING`,
+}
+
+// AddDesk links the Desk with deskID to o through
+// DeskAttendant. Adding a link that exists does nothing.
+func (o *Attendant) AddDesk(ctx context.Context, db domain.DB, deskID string) error {
+	_, err := db.ExecContext(ctx, sqlAddDesk.For(db), domain.Hex(o.ID), domain.Hex(deskID))
+	return err
+}
+
+// RemoveDesk unlinks the Desk with deskID from o
+func (o *Attendant) RemoveDesk(ctx context.Context, db domain.DB, deskID string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM DeskAttendant
+WHERE AttendantID = ? AND DeskID = ?`, domain.Hex(o.ID), domain.Hex(deskID))
+	return err
+}
+
+// DeskIDs returns the keys of the Desks linked to o.
+// deskattendant.DesksOfAttendant returns them whole, it lives there to
+// avoid an import cycle.
+func (o *Attendant) DeskIDs(ctx context.Context, db domain.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT DeskID FROM DeskAttendant
+WHERE AttendantID = ? AND DeskID IN (SELECT ID FROM (SELECT * FROM Desk WHERE DeletedAt IS NULL) AS Desk)
+ORDER BY DeskID`, domain.Hex(o.ID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := []string{}
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(domain.ScanHex(&k)); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (o *Attendant) String() string {
+	b, _ := json.MarshalIndent(o, "", "    ")
+	return string(b)
+}
+
+func (o *Attendant) PPrint() {
+	fmt.Println(o.String())
+}
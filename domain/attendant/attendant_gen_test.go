@@ -0,0 +1,105 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum fb99bd8c70a0b0ed
+
+package attendant_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/attendant"
+	"git.ottoq.com/otto-backend/valet/domain/domaintest"
+)
+
+// object describes Attendant to the tests every object shares
+var object = domaintest.Object{
+	Name:   "Attendant",
+	Table:  attendant.TableName(),
+	Schema: attendant.Schema(),
+	Random: func() domaintest.Record {
+		return attendant.Random()
+	},
+	Get: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*attendant.Attendant)
+		return attendant.GetByID(ctx, db, o.ID)
+	},
+	GetDeleted: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*attendant.Attendant)
+		return attendant.Query().WithDeleted().WhereID(o.ID).First(ctx, db)
+	},
+	Keys:      []string{"ID"},
+	Created:   []string{"CreatedAt", "CreatedBy"},
+	Mutable:   []string{"Name"},
+	Versioned: false,
+}
+
+func TestAttendant(t *testing.T) {
+	object.Test(t)
+}
+
+// TestAttendantKeys reads a Attendant back by its primary key, and nothing by a key
+// differing in one column
+func TestAttendantKeys(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	want := attendant.Random()
+	if err := want.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := attendant.GetByID(ctx, db, want.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := domaintest.Diff(want, got); d != "" {
+		t.Fatal(d)
+	}
+
+	other := attendant.Random()
+	if _, err := attendant.GetByID(ctx, db, other.ID); err != sql.ErrNoRows {
+		t.Errorf("GetByID of another ID got %v, want sql.ErrNoRows", err)
+	}
+}
+
+// TestAttendantColumns updates each column of a Attendant on its own and reads it
+// back
+func TestAttendantColumns(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	o := attendant.Random()
+	if err := o.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	columns := []struct {
+		name string
+		set  func(o, values *attendant.Attendant)
+		get  func(o *attendant.Attendant) interface{}
+	}{
+		{
+			"Name",
+			func(o, values *attendant.Attendant) { o.SetName(values.Name) },
+			func(o *attendant.Attendant) interface{} { return o.Name },
+		},
+	}
+	for _, c := range columns {
+		got, err := attendant.GetByID(ctx, db, o.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.set(got, attendant.Random())
+		if err := got.Update(ctx, db); err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		saved, err := attendant.GetByID(ctx, db, o.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := domaintest.Diff(c.get(got), c.get(saved)); d != "" {
+			t.Errorf("%s %s", c.name, d)
+		}
+	}
+}
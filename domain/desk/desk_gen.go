@@ -1,15 +1,23 @@
 // go run gen/gen.go
 // VERY GENERATED PLZ NO MODIFY
+// checksum c977bfc6d6f1e179
 
 // Package Desk
 // Desk where car keys can be stored
 package desk
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/enums"
+	"git.ottoq.com/otto-backend/valet/domain/node"
 	"git.ottoq.com/otto-backend/valet/entity"
 )
 
@@ -21,6 +29,17 @@ type Desk struct {
 	Lat       float64
 	Lng       float64
 	NodeID    string
+	State     enums.DeskState
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+	CreatedBy string
+	UpdatedBy string
+
+	// changed holds the value of each field when o was last read or
+	// written, or before its first setter call if it wasn't
+	changed map[string]interface{}
 }
 
 func New(
@@ -28,6 +47,7 @@ func New(
 	lat float64,
 	lng float64,
 	nodeID string,
+	state enums.DeskState,
 ) (*Desk, error) {
 	d := &Desk{
 		ID:        entity.UUID(),
@@ -37,10 +57,233 @@ func New(
 		Lat:       lat,
 		Lng:       lng,
 		NodeID:    nodeID,
+		State:     state,
+		Version:   1,
+		CreatedAt: entity.Now(),
+		UpdatedAt: entity.Now(),
+		DeletedAt: nil,
+		CreatedBy: "",
+		UpdatedBy: "",
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
 	}
 	return d, nil
 }
 
+// Validate checks every field against its constraints, returning a
+// domain.ValidationError listing each field that failed
+func (o *Desk) Validate() error {
+	errs := domain.ValidationError{}
+	if o.ID == "" {
+		errs = append(errs, &domain.FieldError{Field: "ID", Reason: "is required"})
+	}
+	if o.ID != "" && !domain.IsHexID(o.ID) {
+		errs = append(errs, &domain.FieldError{Field: "ID", Reason: "must be 32 hexadecimal characters"})
+	}
+	if o.TypeID != "" && !domain.IsHexID(o.TypeID) {
+		errs = append(errs, &domain.FieldError{Field: "TypeID", Reason: "must be 32 hexadecimal characters"})
+	}
+	if o.Name == "" {
+		errs = append(errs, &domain.FieldError{Field: "Name", Reason: "is required"})
+	}
+	if utf8.RuneCountInString(o.Name) > 100 {
+		errs = append(errs, &domain.FieldError{Field: "Name", Reason: "must be at most 100 characters"})
+	}
+	if float64(o.Lat) < -90 {
+		errs = append(errs, &domain.FieldError{Field: "Lat", Reason: "must be at least -90"})
+	}
+	if float64(o.Lat) > 90 {
+		errs = append(errs, &domain.FieldError{Field: "Lat", Reason: "must be at most 90"})
+	}
+	if float64(o.Lng) < -180 {
+		errs = append(errs, &domain.FieldError{Field: "Lng", Reason: "must be at least -180"})
+	}
+	if float64(o.Lng) > 180 {
+		errs = append(errs, &domain.FieldError{Field: "Lng", Reason: "must be at most 180"})
+	}
+	if o.NodeID == "" {
+		errs = append(errs, &domain.FieldError{Field: "NodeID", Reason: "is required"})
+	}
+	if o.NodeID != "" && !domain.IsHexID(o.NodeID) {
+		errs = append(errs, &domain.FieldError{Field: "NodeID", Reason: "must be 32 hexadecimal characters"})
+	}
+	if !o.State.Valid() {
+		errs = append(errs, &domain.FieldError{Field: "State", Reason: "must be one of open, closed, out_of_service"})
+	}
+	if float64(o.Version) < 1 {
+		errs = append(errs, &domain.FieldError{Field: "Version", Reason: "must be at least 1"})
+	}
+	if utf8.RuneCountInString(o.CreatedBy) > 100 {
+		errs = append(errs, &domain.FieldError{Field: "CreatedBy", Reason: "must be at most 100 characters"})
+	}
+	if utf8.RuneCountInString(o.UpdatedBy) > 100 {
+		errs = append(errs, &domain.FieldError{Field: "UpdatedBy", Reason: "must be at most 100 characters"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+///////////////////
+// CHANGES
+///////////////////
+
+// SetName sets Name, recording the change for Changes() and Update
+func (o *Desk) SetName(v string) {
+	o.track("Name", o.Name)
+	o.Name = v
+}
+
+// SetLat sets Lat, recording the change for Changes() and Update
+func (o *Desk) SetLat(v float64) {
+	o.track("Lat", o.Lat)
+	o.Lat = v
+}
+
+// SetLng sets Lng, recording the change for Changes() and Update
+func (o *Desk) SetLng(v float64) {
+	o.track("Lng", o.Lng)
+	o.Lng = v
+}
+
+// SetNodeID sets NodeID, recording the change for Changes() and Update
+func (o *Desk) SetNodeID(v string) {
+	o.track("NodeID", o.NodeID)
+	o.NodeID = v
+}
+
+// SetState sets State, recording the change for Changes() and Update
+func (o *Desk) SetState(v enums.DeskState) {
+	o.track("State", o.State)
+	o.State = v
+}
+
+// track records the value a field had before it was first set
+func (o *Desk) track(field string, old interface{}) {
+	if o.changed == nil {
+		o.changed = map[string]interface{}{}
+	}
+	if _, ok := o.changed[field]; !ok {
+		o.changed[field] = domain.Copy(old)
+	}
+}
+
+// saved records the value of every field as the row holds it
+func (o *Desk) saved() {
+	o.changed = map[string]interface{}{
+		"Name":   domain.Copy(o.Name),
+		"Lat":    domain.Copy(o.Lat),
+		"Lng":    domain.Copy(o.Lng),
+		"NodeID": domain.Copy(o.NodeID),
+		"State":  domain.Copy(o.State),
+	}
+}
+
+// Changes lists the fields holding a new value since o was read or last
+// written, in column order, however they were assigned. Of an o that
+// wasn't, only the fields set through a setter are tracked.
+func (o *Desk) Changes() []domain.Change {
+	changes := []domain.Change{}
+	if old, ok := o.changed["Name"]; ok && domain.Changed(old, o.Name) {
+		changes = append(changes, domain.Change{Field: "Name", Old: old, New: o.Name})
+	}
+	if old, ok := o.changed["Lat"]; ok && domain.Changed(old, o.Lat) {
+		changes = append(changes, domain.Change{Field: "Lat", Old: old, New: o.Lat})
+	}
+	if old, ok := o.changed["Lng"]; ok && domain.Changed(old, o.Lng) {
+		changes = append(changes, domain.Change{Field: "Lng", Old: old, New: o.Lng})
+	}
+	if old, ok := o.changed["NodeID"]; ok && domain.Changed(old, o.NodeID) {
+		changes = append(changes, domain.Change{Field: "NodeID", Old: old, New: o.NodeID})
+	}
+	if old, ok := o.changed["State"]; ok && domain.Changed(old, o.State) {
+		changes = append(changes, domain.Change{Field: "State", Old: old, New: o.State})
+	}
+	return changes
+}
+
+// Patch applies a JSON merge patch (RFC 7386) to o through its setters and
+// validates the result, returning a domain.ValidationError and leaving o
+// unchanged if either fails. The primary key and version may be named but
+// not changed, other fields can't be patched.
+func (o *Desk) Patch(patch []byte) error {
+	fields, err := domain.ParsePatch(patch)
+	if err != nil {
+		return err
+	}
+	patched := *o
+	patched.changed = nil
+	for field, old := range o.changed {
+		patched.track(field, old)
+	}
+	errs := domain.ValidationError{}
+	for _, f := range fields {
+		switch f.Name {
+		case "Name":
+			var v string
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else {
+				patched.SetName(v)
+			}
+		case "Lat":
+			var v float64
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else {
+				patched.SetLat(v)
+			}
+		case "Lng":
+			var v float64
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else {
+				patched.SetLng(v)
+			}
+		case "NodeID":
+			var v string
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else {
+				patched.SetNodeID(v)
+			}
+		case "State":
+			var v enums.DeskState
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else {
+				patched.SetState(v)
+			}
+		case "ID":
+			var v string
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else if v != o.ID {
+				errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be changed"})
+			}
+		case "Version":
+			var v int64
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else if v != o.Version {
+				errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be changed"})
+			}
+		default:
+			errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be patched"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if err := patched.Validate(); err != nil {
+		return err
+	}
+	*o = patched
+	return nil
+}
+
 type Scannable interface {
 	Scan(dest ...interface{}) error
 }
@@ -48,22 +291,31 @@ type Scannable interface {
 func NewFromRow(row Scannable) (*Desk, error) {
 	d := Desk{}
 	err := row.Scan(
-		&d.ID,
-		&d.TypeID,
+		domain.ScanHex(&d.ID),
+		domain.ScanHex(&d.TypeID),
 		&d.Timestamp,
 		&d.Name,
 		&d.Lat,
 		&d.Lng,
-		&d.NodeID,
+		domain.ScanHex(&d.NodeID),
+		&d.State,
+		&d.Version,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+		&d.DeletedAt,
+		&d.CreatedBy,
+		&d.UpdatedBy,
 	)
 	if err != nil {
 		return nil, err
 	}
+	d.saved()
 	return &d, nil
 }
 
-func Schema() string {
-	return `CREATE TABLE Desk (
+// schema creates the table in each dialect
+var schema = domain.Statements{
+	domain.MySQL: `CREATE TABLE Desk (
 ID BINARY(16),
 TypeID BINARY(16),
 Timestamp DATETIME,
@@ -71,62 +323,1064 @@ Name VARCHAR(100),
 Lat FLOAT,
 Lng FLOAT,
 NodeID BINARY(16),
+State ENUM('open','closed','out_of_service') NOT NULL DEFAULT 'open',
+Version BIGINT,
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
 PRIMARY KEY (ID),
+UNIQUE INDEX uniq_NodeID_Name (NodeID, Name),
 FOREIGN KEY (NodeID) REFERENCES Node(ID)
-); `
+);`,
+	domain.Postgres: `CREATE TABLE Desk (
+ID BYTEA,
+TypeID BYTEA,
+Timestamp TIMESTAMP,
+Name VARCHAR(100),
+Lat REAL,
+Lng REAL,
+NodeID BYTEA,
+State VARCHAR(14) CHECK (State IN ('open', 'closed', 'out_of_service')) NOT NULL DEFAULT 'open',
+Version BIGINT,
+CreatedAt TIMESTAMP,
+UpdatedAt TIMESTAMP,
+DeletedAt TIMESTAMP,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID),
+FOREIGN KEY (NodeID) REFERENCES Node(ID)
+);
+CREATE UNIQUE INDEX Desk_uniq_NodeID_Name ON Desk (NodeID, Name);`,
+	domain.SQLite: `CREATE TABLE Desk (
+ID BLOB,
+TypeID BLOB,
+Timestamp DATETIME,
+Name VARCHAR(100),
+Lat REAL,
+Lng REAL,
+NodeID BLOB,
+State VARCHAR(14) CHECK (State IN ('open', 'closed', 'out_of_service')) NOT NULL DEFAULT 'open',
+Version INTEGER,
+CreatedAt DATETIME,
+UpdatedAt DATETIME,
+DeletedAt DATETIME,
+CreatedBy VARCHAR(100),
+UpdatedBy VARCHAR(100),
+PRIMARY KEY (ID),
+FOREIGN KEY (NodeID) REFERENCES Node(ID)
+);
+CREATE UNIQUE INDEX Desk_uniq_NodeID_Name ON Desk (NodeID, Name);`,
+}
+
+// Schema returns the statements creating the table in each dialect
+func Schema() domain.Statements {
+	return schema
 }
 
 func TableName() string {
 	return "Desk"
 }
 
+// TypeID identifies Desk objects
+const TypeID = "E1874C161CDB492FB95EF210E653B886"
+
 func Random() *Desk {
 	d := &Desk{
 		ID:        entity.UUID(),
 		TypeID:    "E1874C161CDB492FB95EF210E653B886",
 		Timestamp: entity.Now(),
-		Name:      entity.RANDstring(),
-		Lat:       entity.RANDfloat64(),
-		Lng:       entity.RANDfloat64(),
-		NodeID:    entity.RANDstring(),
+		Name:      entity.RANDstreet(),
+		Lat:       entity.RANDlatitude(),
+		Lng:       entity.RANDlongitude(),
+		NodeID:    entity.UUID(),
+		State:     enums.DeskState(entity.RANDchoice("open", "closed", "out_of_service")),
+		Version:   1,
+		CreatedAt: entity.Now(),
+		UpdatedAt: entity.Now(),
+		DeletedAt: nil,
+		CreatedBy: "",
+		UpdatedBy: "",
 	}
 	return d
 }
 
-func (o *Desk) InsertString() string {
-	istr := fmt.Sprintf(`INSERT INTO Desk VALUES(
-UNHEX( '%s' ),
-UNHEX( '%s' ),
-'%s',
-'%s',
-%f,
-%f,
-UNHEX( '%s' )
+///////////////////
+// REPOSITORY
+///////////////////
+
+const (
+	sqlSelect            = `SELECT ID, TypeID, Timestamp, Name, Lat, Lng, NodeID, State, Version, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy FROM (SELECT * FROM Desk WHERE DeletedAt IS NULL) AS Desk`
+	sqlSelectWithDeleted = `SELECT ID, TypeID, Timestamp, Name, Lat, Lng, NodeID, State, Version, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy FROM Desk`
+	sqlInsert            = `INSERT INTO Desk (ID, TypeID, Timestamp, Name, Lat, Lng, NodeID, State, Version, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	sqlUpdate = `UPDATE Desk SET TypeID = ?, Timestamp = ?, Name = ?, Lat = ?, Lng = ?, NodeID = ?, State = ?, UpdatedAt = ?, UpdatedBy = ?, Version = Version + 1
+WHERE ID = ? AND Version = ? AND DeletedAt IS NULL`
+	sqlDelete = `UPDATE Desk SET UpdatedAt = ?, DeletedAt = ?, UpdatedBy = ?, Version = Version + 1
+WHERE ID = ? AND Version = ? AND DeletedAt IS NULL`
+	sqlRestore = `UPDATE Desk SET UpdatedAt = ?, DeletedAt = ?, UpdatedBy = ?, Version = Version + 1
+WHERE ID = ? AND Version = ? AND DeletedAt IS NOT NULL`
+	sqlSelectCreated = `SELECT CreatedAt, CreatedBy FROM Desk WHERE ID = ?`
 )
-ON DUPLICATE KEY UPDATE
-TypeID=UNHEX( '%s' ),
-Timestamp='%s',
-Name='%s',
-Lat=%f,
-Lng=%f,
-NodeID=UNHEX( '%s' )
-;`,
-		o.ID,
-		o.TypeID,
-		o.Timestamp.Format("2006-01-02 15:04:05"),
+
+// sqlUpsert differs in each dialect
+var sqlUpsert = domain.Statements{
+	domain.MySQL: `INSERT INTO Desk (ID, TypeID, Timestamp, Name, Lat, Lng, NodeID, State, Version, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE TypeID = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(TypeID), TypeID), Timestamp = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(Timestamp), Timestamp), Name = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(Name), Name), Lat = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(Lat), Lat), Lng = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(Lng), Lng), NodeID = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(NodeID), NodeID), State = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(State), State), UpdatedAt = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(UpdatedAt), UpdatedAt), UpdatedBy = IF(Version = VALUES(Version) AND DeletedAt IS NULL, VALUES(UpdatedBy), UpdatedBy), Version = IF(Version = VALUES(Version) AND DeletedAt IS NULL, Version + 1, Version)`,
+	domain.Postgres: `INSERT INTO Desk (ID, TypeID, Timestamp, Name, Lat, Lng, NodeID, State, Version, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (ID) DO UPDATE SET TypeID = excluded.TypeID, Timestamp = excluded.Timestamp, Name = excluded.Name, Lat = excluded.Lat, Lng = excluded.Lng, NodeID = excluded.NodeID, State = excluded.State, UpdatedAt = excluded.UpdatedAt, UpdatedBy = excluded.UpdatedBy, Version = Desk.Version + 1
+WHERE Desk.Version = excluded.Version AND Desk.DeletedAt IS NULL
+RETURNING Version`,
+	domain.SQLite: `INSERT INTO Desk (ID, TypeID, Timestamp, Name, Lat, Lng, NodeID, State, Version, CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (ID) DO UPDATE SET TypeID = excluded.TypeID, Timestamp = excluded.Timestamp, Name = excluded.Name, Lat = excluded.Lat, Lng = excluded.Lng, NodeID = excluded.NodeID, State = excluded.State, UpdatedAt = excluded.UpdatedAt, UpdatedBy = excluded.UpdatedBy, Version = Desk.Version + 1
+WHERE Desk.Version = excluded.Version AND Desk.DeletedAt IS NULL
+RETURNING Version`,
+}
+
+var _ domain.Domain = (*Desk)(nil)
+
+// SQLSelect returns the SELECT of every column, in NewFromRow scan order
+func SQLSelect() string {
+	return sqlSelect
+}
+
+// GetByID returns the Desk with the given primary key
+func GetByID(ctx context.Context, db domain.DB, id string) (*Desk, error) {
+	row := db.QueryRowContext(ctx, sqlSelect+" WHERE ID = ?", domain.Hex(id))
+	return NewFromRow(row)
+}
+
+// GetByNodeIDName returns the Desk with the given NodeID, Name
+func GetByNodeIDName(ctx context.Context, db domain.DB, nodeID string, name string) (*Desk, error) {
+	row := db.QueryRowContext(ctx, sqlSelect+" WHERE NodeID = ? AND Name = ?", domain.Hex(nodeID), name)
+	return NewFromRow(row)
+}
+
+// List returns up to limit Desks ordered by primary key, skipping the first offset
+func List(ctx context.Context, db domain.DB, limit, offset int) ([]*Desk, error) {
+	return Select(ctx, db, "ORDER BY ID LIMIT ? OFFSET ?", limit, offset)
+}
+
+// Select returns every Desk matched by clause, which is appended to
+// the SELECT statement, ex. "WHERE Name = ? LIMIT 10"
+func Select(ctx context.Context, db domain.DB, clause string, args ...interface{}) ([]*Desk, error) {
+	return query(ctx, db, sqlSelect+" "+clause, args...)
+}
+
+func query(ctx context.Context, db domain.DB, stmt string, args ...interface{}) ([]*Desk, error) {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []*Desk{}
+	for rows.Next() {
+		o, err := NewFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// Insert writes o as a new row
+func (o *Desk) Insert(ctx context.Context, db domain.DB) error {
+	o.stamp(ctx, true)
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, sqlInsert, o.values()...); err != nil {
+		return err
+	}
+	o.saved()
+	return nil
+}
+
+// Upsert writes o as a new row, or overwrites the row that shares its
+// primary key if it's still at o's version, returning a
+// *domain.ErrStaleObject if it isn't or it's deleted
+func (o *Desk) Upsert(ctx context.Context, db domain.DB) error {
+	o.stamp(ctx, true)
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if domain.DialectOf(db) != domain.MySQL {
+		// the version written is returned, or no row if o is stale
+		err := db.QueryRowContext(ctx, sqlUpsert.For(db), o.values()...).Scan(&o.Version)
+		if err == sql.ErrNoRows {
+			return &domain.ErrStaleObject{Table: TableName(), Version: o.Version}
+		}
+		if err != nil {
+			return err
+		}
+		// the row may have existed
+		if err := o.readCreated(ctx, db); err != nil {
+			return err
+		}
+		o.saved()
+		return nil
+	}
+	res, err := db.ExecContext(ctx, sqlUpsert.For(db), o.values()...)
+	if err != nil {
+		return err
+	}
+	// MySQL counts an inserted row once, an updated row twice and an
+	// unchanged one not at all
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	switch n {
+	case 0:
+		return &domain.ErrStaleObject{Table: TableName(), Version: o.Version}
+	case 2:
+		o.Version++
+		if err := o.readCreated(ctx, db); err != nil {
+			return err
+		}
+	}
+	o.saved()
+	return nil
+}
+
+// Update overwrites the row that shares o's primary key and version,
+// incrementing the version. It returns a *domain.ErrStaleObject if the
+// row changed since o was read or it's deleted.
+// Only the changed columns are written, and nothing if there are none.
+// Every column is written for an o that wasn't read or written, unless a
+// setter was used.
+func (o *Desk) Update(ctx context.Context, db domain.DB) error {
+	changes := o.Changes()
+	if o.changed != nil && len(changes) == 0 {
+		return o.Validate()
+	}
+	o.stamp(ctx, false)
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	stmt, args := sqlUpdate, []interface{}{
+		domain.Hex(o.TypeID),
+		o.Timestamp,
 		o.Name,
 		o.Lat,
 		o.Lng,
-		o.NodeID,
+		domain.Hex(o.NodeID),
+		o.State,
+		o.UpdatedAt,
+		o.UpdatedBy,
+		domain.Hex(o.ID),
+		o.Version,
+	}
+	if o.changed != nil {
+		stmt, args = o.sqlUpdateChanges(changes)
+	}
+	res, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &domain.ErrStaleObject{Table: TableName(), Version: o.Version}
+	}
+	o.Version++
+	o.saved()
+	return nil
+}
+
+// updateColumn returns the SET assignment of a column a setter changes
+// and its argument
+func (o *Desk) updateColumn(field string) (string, interface{}) {
+	switch field {
+	case "Name":
+		return "Name = ?", o.Name
+	case "Lat":
+		return "Lat = ?", o.Lat
+	case "Lng":
+		return "Lng = ?", o.Lng
+	case "NodeID":
+		return "NodeID = ?", domain.Hex(o.NodeID)
+	case "State":
+		return "State = ?", o.State
+	}
+	panic("no column " + field)
+}
+
+// sqlUpdateChanges returns an UPDATE of the changed columns, along with
+// those every Update sets, and its arguments
+func (o *Desk) sqlUpdateChanges(changes []domain.Change) (string, []interface{}) {
+	sets := []string{}
+	args := []interface{}{}
+	for _, c := range changes {
+		set, arg := o.updateColumn(c.Field)
+		sets = append(sets, set)
+		args = append(args, arg)
+	}
+	sets = append(sets, "UpdatedAt = ?")
+	args = append(args, o.UpdatedAt)
+	sets = append(sets, "UpdatedBy = ?")
+	args = append(args, o.UpdatedBy)
+	sets = append(sets, "Version = Version + 1")
+	args = append(args, domain.Hex(o.ID), o.Version)
+	return "UPDATE Desk SET " + strings.Join(sets, ", ") + " WHERE ID = ? AND Version = ? AND DeletedAt IS NULL", args
+}
+
+// Delete marks the row that shares o's primary key deleted. It's left out
+// of every query but QueryBuilder.WithDeleted until it's restored. It
+// returns a *domain.ErrStaleObject if the row changed since o was read
+// or it's already deleted.
+func (o *Desk) Delete(ctx context.Context, db domain.DB) error {
+	now := entity.Now().Truncate(time.Second)
+	return o.setDeleted(ctx, db, sqlDelete, &now)
+}
+
+// Restore undoes Delete, returning a *domain.ErrStaleObject if the row
+// changed since o was read or it isn't deleted
+func (o *Desk) Restore(ctx context.Context, db domain.DB) error {
+	return o.setDeleted(ctx, db, sqlRestore, nil)
+}
+
+// setDeleted sets DeletedAt with stmt, sqlDelete or sqlRestore
+func (o *Desk) setDeleted(ctx context.Context, db domain.DB, stmt string, deletedAt *time.Time) error {
+	o.stamp(ctx, false)
+	res, err := db.ExecContext(ctx, stmt, o.UpdatedAt, deletedAt, o.UpdatedBy, domain.Hex(o.ID), o.Version)
+	if err != nil {
+		return err
+	}
+	// the row always changes, so MySQL counts it
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &domain.ErrStaleObject{Table: TableName(), Version: o.Version}
+	}
+	o.DeletedAt = deletedAt
+	o.Version++
+	return nil
+}
+
+// stamp sets the audit columns of a write, created being true for a new
+// row. Upsert reads back the created columns of a row that already
+// existed, see readCreated.
+func (o *Desk) stamp(ctx context.Context, created bool) {
+	now := entity.Now().Truncate(time.Second)
+	actor := domain.Actor(ctx)
+	if created {
+		o.CreatedAt = now
+		o.CreatedBy = actor
+	}
+	o.UpdatedAt = now
+	o.UpdatedBy = actor
+}
 
-		o.TypeID,
-		o.Timestamp.Format("2006-01-02 15:04:05"),
+// readCreated reads the audit columns set when o's row was created, which
+// Upsert leaves as they were when the row exists
+func (o *Desk) readCreated(ctx context.Context, db domain.DB) error {
+	return db.QueryRowContext(ctx, sqlSelectCreated, domain.Hex(o.ID)).Scan(&o.CreatedAt, &o.CreatedBy)
+}
+
+// values returns the argument writing each column in table order
+func (o *Desk) values() []interface{} {
+	return []interface{}{
+		domain.Hex(o.ID),
+		domain.Hex(o.TypeID),
+		o.Timestamp,
 		o.Name,
 		o.Lat,
 		o.Lng,
-		o.NodeID,
-	)
-	return istr
+		domain.Hex(o.NodeID),
+		o.State,
+		o.Version,
+		o.CreatedAt,
+		o.UpdatedAt,
+		o.DeletedAt,
+		o.CreatedBy,
+		o.UpdatedBy,
+	}
+}
+
+///////////////////
+// QUERY BUILDER
+///////////////////
+
+// QueryBuilder builds a SELECT of Desks, ex.
+// Query().WhereID(v).Limit(10).All(ctx, db)
+type QueryBuilder struct {
+	q           domain.Query
+	withDeleted bool
+}
+
+// Query starts a query matching every Desk
+func Query() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// WhereID matches ID equal to v
+func (b *QueryBuilder) WhereID(v string) *QueryBuilder {
+	b.q.Cond("ID = ?", domain.Hex(v))
+	return b
+}
+
+// IDIn matches ID equal to any of vs
+func (b *QueryBuilder) IDIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.In("ID", "?", args)
+	return b
+}
+
+// OrderByID sorts by ID, after any order added before it
+func (b *QueryBuilder) OrderByID(o domain.Order) *QueryBuilder {
+	b.q.Order("ID", o)
+	return b
+}
+
+// WhereTypeID matches TypeID equal to v
+func (b *QueryBuilder) WhereTypeID(v string) *QueryBuilder {
+	b.q.Cond("TypeID = ?", domain.Hex(v))
+	return b
+}
+
+// TypeIDIn matches TypeID equal to any of vs
+func (b *QueryBuilder) TypeIDIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.In("TypeID", "?", args)
+	return b
+}
+
+// OrderByTypeID sorts by TypeID, after any order added before it
+func (b *QueryBuilder) OrderByTypeID(o domain.Order) *QueryBuilder {
+	b.q.Order("TypeID", o)
+	return b
+}
+
+// WhereTimestamp matches Timestamp equal to v
+func (b *QueryBuilder) WhereTimestamp(v time.Time) *QueryBuilder {
+	b.q.Cond("Timestamp = ?", v)
+	return b
+}
+
+// TimestampIn matches Timestamp equal to any of vs
+func (b *QueryBuilder) TimestampIn(vs ...time.Time) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("Timestamp", "?", args)
+	return b
+}
+
+// OrderByTimestamp sorts by Timestamp, after any order added before it
+func (b *QueryBuilder) OrderByTimestamp(o domain.Order) *QueryBuilder {
+	b.q.Order("Timestamp", o)
+	return b
+}
+
+// TimestampAfter matches Timestamp later than t
+func (b *QueryBuilder) TimestampAfter(t time.Time) *QueryBuilder {
+	b.q.Cond("Timestamp > ?", t)
+	return b
+}
+
+// TimestampBefore matches Timestamp earlier than t
+func (b *QueryBuilder) TimestampBefore(t time.Time) *QueryBuilder {
+	b.q.Cond("Timestamp < ?", t)
+	return b
+}
+
+// WhereName matches Name equal to v
+func (b *QueryBuilder) WhereName(v string) *QueryBuilder {
+	b.q.Cond("Name = ?", v)
+	return b
+}
+
+// NameIn matches Name equal to any of vs
+func (b *QueryBuilder) NameIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("Name", "?", args)
+	return b
+}
+
+// OrderByName sorts by Name, after any order added before it
+func (b *QueryBuilder) OrderByName(o domain.Order) *QueryBuilder {
+	b.q.Order("Name", o)
+	return b
+}
+
+// NameLike matches Name against a LIKE pattern, ex. "T%"
+func (b *QueryBuilder) NameLike(pattern string) *QueryBuilder {
+	b.q.Cond("Name LIKE ?", pattern)
+	return b
+}
+
+// WhereLat matches Lat equal to v
+func (b *QueryBuilder) WhereLat(v float64) *QueryBuilder {
+	b.q.Cond("Lat = ?", v)
+	return b
+}
+
+// LatIn matches Lat equal to any of vs
+func (b *QueryBuilder) LatIn(vs ...float64) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("Lat", "?", args)
+	return b
+}
+
+// OrderByLat sorts by Lat, after any order added before it
+func (b *QueryBuilder) OrderByLat(o domain.Order) *QueryBuilder {
+	b.q.Order("Lat", o)
+	return b
+}
+
+// LatGreaterThan matches Lat greater than v
+func (b *QueryBuilder) LatGreaterThan(v float64) *QueryBuilder {
+	b.q.Cond("Lat > ?", v)
+	return b
+}
+
+// LatLessThan matches Lat less than v
+func (b *QueryBuilder) LatLessThan(v float64) *QueryBuilder {
+	b.q.Cond("Lat < ?", v)
+	return b
+}
+
+// WhereLng matches Lng equal to v
+func (b *QueryBuilder) WhereLng(v float64) *QueryBuilder {
+	b.q.Cond("Lng = ?", v)
+	return b
+}
+
+// LngIn matches Lng equal to any of vs
+func (b *QueryBuilder) LngIn(vs ...float64) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("Lng", "?", args)
+	return b
+}
+
+// OrderByLng sorts by Lng, after any order added before it
+func (b *QueryBuilder) OrderByLng(o domain.Order) *QueryBuilder {
+	b.q.Order("Lng", o)
+	return b
+}
+
+// LngGreaterThan matches Lng greater than v
+func (b *QueryBuilder) LngGreaterThan(v float64) *QueryBuilder {
+	b.q.Cond("Lng > ?", v)
+	return b
+}
+
+// LngLessThan matches Lng less than v
+func (b *QueryBuilder) LngLessThan(v float64) *QueryBuilder {
+	b.q.Cond("Lng < ?", v)
+	return b
+}
+
+// WhereNodeID matches NodeID equal to v
+func (b *QueryBuilder) WhereNodeID(v string) *QueryBuilder {
+	b.q.Cond("NodeID = ?", domain.Hex(v))
+	return b
+}
+
+// NodeIDIn matches NodeID equal to any of vs
+func (b *QueryBuilder) NodeIDIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.In("NodeID", "?", args)
+	return b
+}
+
+// OrderByNodeID sorts by NodeID, after any order added before it
+func (b *QueryBuilder) OrderByNodeID(o domain.Order) *QueryBuilder {
+	b.q.Order("NodeID", o)
+	return b
+}
+
+// PartitionByNodeID pages the Desks of each Node apart, see
+// domain.Query.Partition. Each row of SQL then ends with the
+// NodeID, so All can't read them.
+func (b *QueryBuilder) PartitionByNodeID() *QueryBuilder {
+	b.q.Partition("NodeID")
+	return b
+}
+
+// WhereState matches State equal to v
+func (b *QueryBuilder) WhereState(v enums.DeskState) *QueryBuilder {
+	b.q.Cond("State = ?", v)
+	return b
+}
+
+// StateIn matches State equal to any of vs
+func (b *QueryBuilder) StateIn(vs ...enums.DeskState) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("State", "?", args)
+	return b
+}
+
+// OrderByState sorts by State, after any order added before it
+func (b *QueryBuilder) OrderByState(o domain.Order) *QueryBuilder {
+	b.q.Order("State", o)
+	return b
+}
+
+// WhereVersion matches Version equal to v
+func (b *QueryBuilder) WhereVersion(v int64) *QueryBuilder {
+	b.q.Cond("Version = ?", v)
+	return b
+}
+
+// VersionIn matches Version equal to any of vs
+func (b *QueryBuilder) VersionIn(vs ...int64) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("Version", "?", args)
+	return b
+}
+
+// OrderByVersion sorts by Version, after any order added before it
+func (b *QueryBuilder) OrderByVersion(o domain.Order) *QueryBuilder {
+	b.q.Order("Version", o)
+	return b
+}
+
+// VersionGreaterThan matches Version greater than v
+func (b *QueryBuilder) VersionGreaterThan(v int64) *QueryBuilder {
+	b.q.Cond("Version > ?", v)
+	return b
+}
+
+// VersionLessThan matches Version less than v
+func (b *QueryBuilder) VersionLessThan(v int64) *QueryBuilder {
+	b.q.Cond("Version < ?", v)
+	return b
+}
+
+// WhereCreatedAt matches CreatedAt equal to v
+func (b *QueryBuilder) WhereCreatedAt(v time.Time) *QueryBuilder {
+	b.q.Cond("CreatedAt = ?", v)
+	return b
+}
+
+// CreatedAtIn matches CreatedAt equal to any of vs
+func (b *QueryBuilder) CreatedAtIn(vs ...time.Time) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("CreatedAt", "?", args)
+	return b
+}
+
+// OrderByCreatedAt sorts by CreatedAt, after any order added before it
+func (b *QueryBuilder) OrderByCreatedAt(o domain.Order) *QueryBuilder {
+	b.q.Order("CreatedAt", o)
+	return b
+}
+
+// CreatedAtAfter matches CreatedAt later than t
+func (b *QueryBuilder) CreatedAtAfter(t time.Time) *QueryBuilder {
+	b.q.Cond("CreatedAt > ?", t)
+	return b
+}
+
+// CreatedAtBefore matches CreatedAt earlier than t
+func (b *QueryBuilder) CreatedAtBefore(t time.Time) *QueryBuilder {
+	b.q.Cond("CreatedAt < ?", t)
+	return b
+}
+
+// WhereUpdatedAt matches UpdatedAt equal to v
+func (b *QueryBuilder) WhereUpdatedAt(v time.Time) *QueryBuilder {
+	b.q.Cond("UpdatedAt = ?", v)
+	return b
+}
+
+// UpdatedAtIn matches UpdatedAt equal to any of vs
+func (b *QueryBuilder) UpdatedAtIn(vs ...time.Time) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("UpdatedAt", "?", args)
+	return b
+}
+
+// OrderByUpdatedAt sorts by UpdatedAt, after any order added before it
+func (b *QueryBuilder) OrderByUpdatedAt(o domain.Order) *QueryBuilder {
+	b.q.Order("UpdatedAt", o)
+	return b
+}
+
+// UpdatedAtAfter matches UpdatedAt later than t
+func (b *QueryBuilder) UpdatedAtAfter(t time.Time) *QueryBuilder {
+	b.q.Cond("UpdatedAt > ?", t)
+	return b
+}
+
+// UpdatedAtBefore matches UpdatedAt earlier than t
+func (b *QueryBuilder) UpdatedAtBefore(t time.Time) *QueryBuilder {
+	b.q.Cond("UpdatedAt < ?", t)
+	return b
+}
+
+// WhereDeletedAt matches DeletedAt equal to v
+func (b *QueryBuilder) WhereDeletedAt(v time.Time) *QueryBuilder {
+	b.q.Cond("DeletedAt = ?", v)
+	return b
+}
+
+// DeletedAtIn matches DeletedAt equal to any of vs
+func (b *QueryBuilder) DeletedAtIn(vs ...time.Time) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("DeletedAt", "?", args)
+	return b
+}
+
+// OrderByDeletedAt sorts by DeletedAt, after any order added before it
+func (b *QueryBuilder) OrderByDeletedAt(o domain.Order) *QueryBuilder {
+	b.q.Order("DeletedAt", o)
+	return b
+}
+
+// DeletedAtAfter matches DeletedAt later than t
+func (b *QueryBuilder) DeletedAtAfter(t time.Time) *QueryBuilder {
+	b.q.Cond("DeletedAt > ?", t)
+	return b
+}
+
+// DeletedAtBefore matches DeletedAt earlier than t
+func (b *QueryBuilder) DeletedAtBefore(t time.Time) *QueryBuilder {
+	b.q.Cond("DeletedAt < ?", t)
+	return b
+}
+
+// DeletedAtIsNull matches rows without a DeletedAt
+func (b *QueryBuilder) DeletedAtIsNull() *QueryBuilder {
+	b.q.Cond("DeletedAt IS NULL")
+	return b
+}
+
+// DeletedAtIsNotNull matches rows with a DeletedAt
+func (b *QueryBuilder) DeletedAtIsNotNull() *QueryBuilder {
+	b.q.Cond("DeletedAt IS NOT NULL")
+	return b
+}
+
+// WhereCreatedBy matches CreatedBy equal to v
+func (b *QueryBuilder) WhereCreatedBy(v string) *QueryBuilder {
+	b.q.Cond("CreatedBy = ?", v)
+	return b
+}
+
+// CreatedByIn matches CreatedBy equal to any of vs
+func (b *QueryBuilder) CreatedByIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("CreatedBy", "?", args)
+	return b
+}
+
+// OrderByCreatedBy sorts by CreatedBy, after any order added before it
+func (b *QueryBuilder) OrderByCreatedBy(o domain.Order) *QueryBuilder {
+	b.q.Order("CreatedBy", o)
+	return b
+}
+
+// CreatedByLike matches CreatedBy against a LIKE pattern, ex. "T%"
+func (b *QueryBuilder) CreatedByLike(pattern string) *QueryBuilder {
+	b.q.Cond("CreatedBy LIKE ?", pattern)
+	return b
+}
+
+// WhereUpdatedBy matches UpdatedBy equal to v
+func (b *QueryBuilder) WhereUpdatedBy(v string) *QueryBuilder {
+	b.q.Cond("UpdatedBy = ?", v)
+	return b
+}
+
+// UpdatedByIn matches UpdatedBy equal to any of vs
+func (b *QueryBuilder) UpdatedByIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = v
+	}
+	b.q.In("UpdatedBy", "?", args)
+	return b
+}
+
+// OrderByUpdatedBy sorts by UpdatedBy, after any order added before it
+func (b *QueryBuilder) OrderByUpdatedBy(o domain.Order) *QueryBuilder {
+	b.q.Order("UpdatedBy", o)
+	return b
+}
+
+// UpdatedByLike matches UpdatedBy against a LIKE pattern, ex. "T%"
+func (b *QueryBuilder) UpdatedByLike(pattern string) *QueryBuilder {
+	b.q.Cond("UpdatedBy LIKE ?", pattern)
+	return b
+}
+
+// Limit returns at most n Desks
+func (b *QueryBuilder) Limit(n int) *QueryBuilder {
+	b.q.Limit(n)
+	return b
+}
+
+// Offset skips the first n Desks
+func (b *QueryBuilder) Offset(n int) *QueryBuilder {
+	b.q.Offset(n)
+	return b
+}
+
+// LinkedToAttendants matches the Desks linked to any of the Attendants with
+// attendantIDs, partitioned by the Attendant
+func (b *QueryBuilder) LinkedToAttendants(attendantIDs ...string) *QueryBuilder {
+	args := make([]interface{}, len(attendantIDs))
+	for i, v := range attendantIDs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.Join("JOIN (SELECT DeskID AS Linked, AttendantID AS LinkedTo FROM DeskAttendant WHERE AttendantID IN ("+domain.Placeholders("?", len(args))+")) AS Link ON Link.Linked = Desk.ID", args...)
+	b.q.Partition("Link.LinkedTo")
+	return b
+}
+
+// WithDeleted includes soft deleted Desks
+func (b *QueryBuilder) WithDeleted() *QueryBuilder {
+	b.withDeleted = true
+	return b
+}
+
+// SQL returns the parameterized statement and its arguments
+func (b *QueryBuilder) SQL() (string, []interface{}) {
+	if b.withDeleted {
+		return b.q.Select(sqlSelectWithDeleted)
+	}
+	return b.q.Select(sqlSelect)
+}
+
+// CountSQL returns the statement counting the matching Desks, ignoring
+// Limit and Offset, and its arguments. Partitioned, it selects each
+// partition and its count.
+func (b *QueryBuilder) CountSQL() (string, []interface{}) {
+	if b.withDeleted {
+		return b.q.Count("Desk")
+	}
+	return b.q.Count("(SELECT * FROM Desk WHERE DeletedAt IS NULL) AS Desk")
+}
+
+// All returns every matching Desk
+func (b *QueryBuilder) All(ctx context.Context, db domain.DB) ([]*Desk, error) {
+	stmt, args := b.SQL()
+	return query(ctx, db, stmt, args...)
+}
+
+// First returns the first matching Desk, or sql.ErrNoRows
+func (b *QueryBuilder) First(ctx context.Context, db domain.DB) (*Desk, error) {
+	b.q.Limit(1)
+	query, args := b.SQL()
+	return NewFromRow(db.QueryRowContext(ctx, query, args...))
+}
+
+// Count returns the number of matching Desks, ignoring Limit and Offset
+func (b *QueryBuilder) Count(ctx context.Context, db domain.DB) (int, error) {
+	stmt, args := b.CountSQL()
+	var n int
+	err := db.QueryRowContext(ctx, stmt, args...).Scan(&n)
+	return n, err
+}
+
+///////////////////
+// NODE RELATION
+///////////////////
+
+// Node returns the Node referenced by NodeID
+func (o *Desk) Node(ctx context.Context, db domain.DB) (*node.Node, error) {
+	row := db.QueryRowContext(ctx, node.SQLSelect()+" WHERE ID = ?", domain.Hex(o.NodeID))
+	return node.NewFromRow(row)
+}
+
+// LoadNodes fetches the Node of every Desk in list with a single
+// query, keyed by NodeID as written, whatever the case of its hex digits
+func LoadNodes(ctx context.Context, db domain.DB, list []*Desk) (map[string]*node.Node, error) {
+	keys := map[string]*node.Node{}
+	args := []interface{}{}
+	for _, o := range list {
+		if _, ok := keys[o.NodeID]; !ok {
+			keys[o.NodeID] = nil
+			args = append(args, domain.Hex(o.NodeID))
+		}
+	}
+	if len(args) == 0 {
+		return keys, nil
+	}
+	found, err := node.Select(ctx, db, "WHERE ID IN ("+domain.Placeholders("?", len(args))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	// scanned IDs are upper case
+	byID := map[string]*node.Node{}
+	for _, f := range found {
+		byID[f.ID] = f
+	}
+	for k := range keys {
+		keys[k] = byID[strings.ToUpper(k)]
+	}
+	return keys, nil
+}
+
+// ListByNodeID returns every Desk referencing the given Node.
+// It lives here rather than on Node to avoid an import cycle.
+func ListByNodeID(ctx context.Context, db domain.DB, nodeID string) ([]*Desk, error) {
+	return Select(ctx, db, "WHERE NodeID = ? ORDER BY ID", domain.Hex(nodeID))
+}
+
+// ListByNodeIDs fetches the Desks of every given Node with a
+// single query, grouped by NodeID as given, whatever the case of its
+// hex digits
+func ListByNodeIDs(ctx context.Context, db domain.DB, nodeIDs ...string) (map[string][]*Desk, error) {
+	groups := map[string][]*Desk{}
+	if len(nodeIDs) == 0 {
+		return groups, nil
+	}
+	args := make([]interface{}, len(nodeIDs))
+	for i, k := range nodeIDs {
+		args[i] = domain.Hex(k)
+	}
+	found, err := Select(ctx, db, "WHERE NodeID IN ("+domain.Placeholders("?", len(args))+") ORDER BY ID", args...)
+	if err != nil {
+		return nil, err
+	}
+	// scanned IDs are upper case
+	byID := map[string][]*Desk{}
+	for _, f := range found {
+		byID[f.NodeID] = append(byID[f.NodeID], f)
+	}
+	for _, k := range nodeIDs {
+		if g := byID[strings.ToUpper(k)]; g != nil {
+			groups[k] = g
+		}
+	}
+	return groups, nil
+}
+
+// the Nodes list the Desks referencing them with this package's
+// loader, as it imports theirs
+func init() {
+	node.DesksLoader = func(ctx context.Context, db domain.DB, nodeID string) ([]domain.Domain, error) {
+		list, err := ListByNodeID(ctx, db, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		found := make([]domain.Domain, len(list))
+		for i, o := range list {
+			found[i] = o
+		}
+		return found, nil
+	}
+}
+
+///////////////////
+// DESKATTENDANTS
+///////////////////
+
+// DeskAttendantsLoader lists the DeskAttendants whose DeskID is deskID.
+// The deskattendant package sets it, as it imports this one.
+var DeskAttendantsLoader func(ctx context.Context, db domain.DB, deskID string) ([]domain.Domain, error)
+
+// DeskAttendants returns every DeskAttendant whose DeskID references o, each a
+// *deskattendant.DeskAttendant. They're loaded by the deskattendant package, which
+// imports this one, so it must be imported too.
+func (o *Desk) DeskAttendants(ctx context.Context, db domain.DB) ([]domain.Domain, error) {
+	if DeskAttendantsLoader == nil {
+		return nil, fmt.Errorf("desk.DeskAttendants needs the deskattendant package imported")
+	}
+	return DeskAttendantsLoader(ctx, db, o.ID)
+}
+
+///////////////////
+// ATTENDANT LINKS
+///////////////////
+
+// sqlAddAttendant differs in each dialect
+var sqlAddAttendant = domain.Statements{
+	domain.MySQL: `INSERT INTO DeskAttendant (DeskID, AttendantID)
+VALUES (?, ?)
+ON DUPLICATE KEY UPDATE DeskID = DeskID`,
+	domain.Postgres: `INSERT INTO DeskAttendant (DeskID, AttendantID)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING`,
+	domain.SQLite: `INSERT INTO DeskAttendant (DeskID, AttendantID)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING`,
+}
+
+// AddAttendant links the Attendant with attendantID to o through
+// DeskAttendant. Adding a link that exists does nothing.
+func (o *Desk) AddAttendant(ctx context.Context, db domain.DB, attendantID string) error {
+	_, err := db.ExecContext(ctx, sqlAddAttendant.For(db), domain.Hex(o.ID), domain.Hex(attendantID))
+	return err
+}
+
+// RemoveAttendant unlinks the Attendant with attendantID from o
+func (o *Desk) RemoveAttendant(ctx context.Context, db domain.DB, attendantID string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM DeskAttendant
+WHERE DeskID = ? AND AttendantID = ?`, domain.Hex(o.ID), domain.Hex(attendantID))
+	return err
+}
+
+// AttendantIDs returns the keys of the Attendants linked to o.
+// deskattendant.AttendantsOfDesk returns them whole, it lives there to
+// avoid an import cycle.
+func (o *Desk) AttendantIDs(ctx context.Context, db domain.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT AttendantID FROM DeskAttendant
+WHERE DeskID = ? AND AttendantID IN (SELECT ID FROM (SELECT * FROM Attendant WHERE DeletedAt IS NULL) AS Attendant)
+ORDER BY AttendantID`, domain.Hex(o.ID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := []string{}
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(domain.ScanHex(&k)); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
 }
 
 func (o *Desk) String() string {
@@ -0,0 +1,125 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 3eaf26a0b686669c
+
+package desk_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/desk"
+	"git.ottoq.com/otto-backend/valet/domain/domaintest"
+)
+
+// object describes Desk to the tests every object shares
+var object = domaintest.Object{
+	Name:   "Desk",
+	Table:  desk.TableName(),
+	Schema: desk.Schema(),
+	Random: func() domaintest.Record {
+		return desk.Random()
+	},
+	Get: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*desk.Desk)
+		return desk.GetByID(ctx, db, o.ID)
+	},
+	GetDeleted: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*desk.Desk)
+		return desk.Query().WithDeleted().WhereID(o.ID).First(ctx, db)
+	},
+	Keys:      []string{"ID"},
+	Created:   []string{"CreatedAt", "CreatedBy"},
+	Mutable:   []string{"Name", "Lat", "Lng", "NodeID", "State"},
+	Versioned: true,
+}
+
+func TestDesk(t *testing.T) {
+	object.Test(t)
+}
+
+// TestDeskKeys reads a Desk back by its primary key, and nothing by a key
+// differing in one column
+func TestDeskKeys(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	want := desk.Random()
+	if err := want.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := desk.GetByID(ctx, db, want.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := domaintest.Diff(want, got); d != "" {
+		t.Fatal(d)
+	}
+
+	other := desk.Random()
+	if _, err := desk.GetByID(ctx, db, other.ID); err != sql.ErrNoRows {
+		t.Errorf("GetByID of another ID got %v, want sql.ErrNoRows", err)
+	}
+}
+
+// TestDeskColumns updates each column of a Desk on its own and reads it
+// back
+func TestDeskColumns(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	o := desk.Random()
+	if err := o.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	columns := []struct {
+		name string
+		set  func(o, values *desk.Desk)
+		get  func(o *desk.Desk) interface{}
+	}{
+		{
+			"Name",
+			func(o, values *desk.Desk) { o.SetName(values.Name) },
+			func(o *desk.Desk) interface{} { return o.Name },
+		},
+		{
+			"Lat",
+			func(o, values *desk.Desk) { o.SetLat(values.Lat) },
+			func(o *desk.Desk) interface{} { return o.Lat },
+		},
+		{
+			"Lng",
+			func(o, values *desk.Desk) { o.SetLng(values.Lng) },
+			func(o *desk.Desk) interface{} { return o.Lng },
+		},
+		{
+			"NodeID",
+			func(o, values *desk.Desk) { o.SetNodeID(values.NodeID) },
+			func(o *desk.Desk) interface{} { return o.NodeID },
+		},
+		{
+			"State",
+			func(o, values *desk.Desk) { o.SetState(values.State) },
+			func(o *desk.Desk) interface{} { return o.State },
+		},
+	}
+	for _, c := range columns {
+		got, err := desk.GetByID(ctx, db, o.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.set(got, desk.Random())
+		if err := got.Update(ctx, db); err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		saved, err := desk.GetByID(ctx, db, o.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := domaintest.Diff(c.get(got), c.get(saved)); d != "" {
+			t.Errorf("%s %s", c.name, d)
+		}
+	}
+}
@@ -0,0 +1,632 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum e7ca164580f8ac6c
+
+// Package DeskAttendant
+// DeskAttendant assigns an attendant to a desk they work at
+package deskattendant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/attendant"
+	"git.ottoq.com/otto-backend/valet/domain/desk"
+	"git.ottoq.com/otto-backend/valet/entity"
+)
+
+type DeskAttendant struct {
+	DeskID      string
+	AttendantID string
+
+	// changed holds the value of each field when o was last read or
+	// written, or before its first setter call if it wasn't
+	changed map[string]interface{}
+}
+
+func New(
+	deskID string,
+	attendantID string,
+) (*DeskAttendant, error) {
+	d := &DeskAttendant{
+		DeskID:      deskID,
+		AttendantID: attendantID,
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Validate checks every field against its constraints, returning a
+// domain.ValidationError listing each field that failed
+func (o *DeskAttendant) Validate() error {
+	errs := domain.ValidationError{}
+	if o.DeskID == "" {
+		errs = append(errs, &domain.FieldError{Field: "DeskID", Reason: "is required"})
+	}
+	if o.DeskID != "" && !domain.IsHexID(o.DeskID) {
+		errs = append(errs, &domain.FieldError{Field: "DeskID", Reason: "must be 32 hexadecimal characters"})
+	}
+	if o.AttendantID == "" {
+		errs = append(errs, &domain.FieldError{Field: "AttendantID", Reason: "is required"})
+	}
+	if o.AttendantID != "" && !domain.IsHexID(o.AttendantID) {
+		errs = append(errs, &domain.FieldError{Field: "AttendantID", Reason: "must be 32 hexadecimal characters"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+///////////////////
+// CHANGES
+///////////////////
+
+// track records the value a field had before it was first set
+func (o *DeskAttendant) track(field string, old interface{}) {
+	if o.changed == nil {
+		o.changed = map[string]interface{}{}
+	}
+	if _, ok := o.changed[field]; !ok {
+		o.changed[field] = domain.Copy(old)
+	}
+}
+
+// saved records the value of every field as the row holds it
+func (o *DeskAttendant) saved() {
+	o.changed = map[string]interface{}{}
+}
+
+// Changes lists the fields holding a new value since o was read or last
+// written, in column order, however they were assigned. Of an o that
+// wasn't, only the fields set through a setter are tracked.
+func (o *DeskAttendant) Changes() []domain.Change {
+	changes := []domain.Change{}
+	return changes
+}
+
+// Patch applies a JSON merge patch (RFC 7386) to o through its setters and
+// validates the result, returning a domain.ValidationError and leaving o
+// unchanged if either fails. The primary key may be named but
+// not changed, other fields can't be patched.
+func (o *DeskAttendant) Patch(patch []byte) error {
+	fields, err := domain.ParsePatch(patch)
+	if err != nil {
+		return err
+	}
+	patched := *o
+	patched.changed = nil
+	for field, old := range o.changed {
+		patched.track(field, old)
+	}
+	errs := domain.ValidationError{}
+	for _, f := range fields {
+		switch f.Name {
+		case "DeskID":
+			var v string
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else if v != o.DeskID {
+				errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be changed"})
+			}
+		case "AttendantID":
+			var v string
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else if v != o.AttendantID {
+				errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be changed"})
+			}
+		default:
+			errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be patched"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if err := patched.Validate(); err != nil {
+		return err
+	}
+	*o = patched
+	return nil
+}
+
+type Scannable interface {
+	Scan(dest ...interface{}) error
+}
+
+func NewFromRow(row Scannable) (*DeskAttendant, error) {
+	d := DeskAttendant{}
+	err := row.Scan(
+		domain.ScanHex(&d.DeskID),
+		domain.ScanHex(&d.AttendantID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.saved()
+	return &d, nil
+}
+
+// schema creates the table in each dialect
+var schema = domain.Statements{
+	domain.MySQL: `CREATE TABLE DeskAttendant (
+DeskID BINARY(16),
+AttendantID BINARY(16),
+PRIMARY KEY (DeskID, AttendantID),
+INDEX idx_AttendantID (AttendantID),
+FOREIGN KEY (DeskID) REFERENCES Desk(ID) ON DELETE CASCADE,
+FOREIGN KEY (AttendantID) REFERENCES Attendant(ID) ON DELETE CASCADE
+);`,
+	domain.Postgres: `CREATE TABLE DeskAttendant (
+DeskID BYTEA,
+AttendantID BYTEA,
+PRIMARY KEY (DeskID, AttendantID),
+FOREIGN KEY (DeskID) REFERENCES Desk(ID) ON DELETE CASCADE,
+FOREIGN KEY (AttendantID) REFERENCES Attendant(ID) ON DELETE CASCADE
+);
+CREATE INDEX DeskAttendant_idx_AttendantID ON DeskAttendant (AttendantID);`,
+	domain.SQLite: `CREATE TABLE DeskAttendant (
+DeskID BLOB,
+AttendantID BLOB,
+PRIMARY KEY (DeskID, AttendantID),
+FOREIGN KEY (DeskID) REFERENCES Desk(ID) ON DELETE CASCADE,
+FOREIGN KEY (AttendantID) REFERENCES Attendant(ID) ON DELETE CASCADE
+);
+CREATE INDEX DeskAttendant_idx_AttendantID ON DeskAttendant (AttendantID);`,
+}
+
+// Schema returns the statements creating the table in each dialect
+func Schema() domain.Statements {
+	return schema
+}
+
+func TableName() string {
+	return "DeskAttendant"
+}
+
+// TypeID identifies DeskAttendant objects
+const TypeID = "A8396DD4EBA347C5B2248FCED480229B"
+
+func Random() *DeskAttendant {
+	d := &DeskAttendant{
+		DeskID:      entity.UUID(),
+		AttendantID: entity.UUID(),
+	}
+	return d
+}
+
+///////////////////
+// REPOSITORY
+///////////////////
+
+const (
+	sqlSelect = `SELECT DeskID, AttendantID FROM DeskAttendant`
+	sqlInsert = `INSERT INTO DeskAttendant (DeskID, AttendantID)
+VALUES (?, ?)`
+	sqlDelete = `DELETE FROM DeskAttendant WHERE DeskID = ? AND AttendantID = ?`
+)
+
+// sqlUpsert differs in each dialect
+var sqlUpsert = domain.Statements{
+	domain.MySQL: `INSERT INTO DeskAttendant (DeskID, AttendantID)
+VALUES (?, ?)
+ON DUPLICATE KEY UPDATE DeskID = DeskID`,
+	domain.Postgres: `INSERT INTO DeskAttendant (DeskID, AttendantID)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING`,
+	domain.SQLite: `INSERT INTO DeskAttendant (DeskID, AttendantID)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING`,
+}
+
+var _ domain.Domain = (*DeskAttendant)(nil)
+
+// SQLSelect returns the SELECT of every column, in NewFromRow scan order
+func SQLSelect() string {
+	return sqlSelect
+}
+
+// GetByID returns the DeskAttendant with the given primary key
+func GetByID(ctx context.Context, db domain.DB, deskID string, attendantID string) (*DeskAttendant, error) {
+	row := db.QueryRowContext(ctx, sqlSelect+" WHERE DeskID = ? AND AttendantID = ?", domain.Hex(deskID), domain.Hex(attendantID))
+	return NewFromRow(row)
+}
+
+// List returns up to limit DeskAttendants ordered by primary key, skipping the first offset
+func List(ctx context.Context, db domain.DB, limit, offset int) ([]*DeskAttendant, error) {
+	return Select(ctx, db, "ORDER BY DeskID, AttendantID LIMIT ? OFFSET ?", limit, offset)
+}
+
+// Select returns every DeskAttendant matched by clause, which is appended to
+// the SELECT statement, ex. "WHERE Name = ? LIMIT 10"
+func Select(ctx context.Context, db domain.DB, clause string, args ...interface{}) ([]*DeskAttendant, error) {
+	return query(ctx, db, sqlSelect+" "+clause, args...)
+}
+
+func query(ctx context.Context, db domain.DB, stmt string, args ...interface{}) ([]*DeskAttendant, error) {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []*DeskAttendant{}
+	for rows.Next() {
+		o, err := NewFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// Insert writes o as a new row
+func (o *DeskAttendant) Insert(ctx context.Context, db domain.DB) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, sqlInsert, o.values()...); err != nil {
+		return err
+	}
+	o.saved()
+	return nil
+}
+
+// Upsert writes o as a new row, or overwrites the row that shares its primary key
+func (o *DeskAttendant) Upsert(ctx context.Context, db domain.DB) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, sqlUpsert.For(db), o.values()...); err != nil {
+		return err
+	}
+	o.saved()
+	return nil
+}
+
+// Update only validates o, every column being part of its primary key
+func (o *DeskAttendant) Update(ctx context.Context, db domain.DB) error {
+	return o.Validate()
+}
+
+// Delete removes the row that shares o's primary key
+func (o *DeskAttendant) Delete(ctx context.Context, db domain.DB) error {
+	_, err := db.ExecContext(ctx, sqlDelete, domain.Hex(o.DeskID), domain.Hex(o.AttendantID))
+	return err
+}
+
+// values returns the argument writing each column in table order
+func (o *DeskAttendant) values() []interface{} {
+	return []interface{}{
+		domain.Hex(o.DeskID),
+		domain.Hex(o.AttendantID),
+	}
+}
+
+///////////////////
+// QUERY BUILDER
+///////////////////
+
+// QueryBuilder builds a SELECT of DeskAttendants, ex.
+// Query().WhereDeskID(v).Limit(10).All(ctx, db)
+type QueryBuilder struct {
+	q domain.Query
+}
+
+// Query starts a query matching every DeskAttendant
+func Query() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// WhereDeskID matches DeskID equal to v
+func (b *QueryBuilder) WhereDeskID(v string) *QueryBuilder {
+	b.q.Cond("DeskID = ?", domain.Hex(v))
+	return b
+}
+
+// DeskIDIn matches DeskID equal to any of vs
+func (b *QueryBuilder) DeskIDIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.In("DeskID", "?", args)
+	return b
+}
+
+// OrderByDeskID sorts by DeskID, after any order added before it
+func (b *QueryBuilder) OrderByDeskID(o domain.Order) *QueryBuilder {
+	b.q.Order("DeskID", o)
+	return b
+}
+
+// PartitionByDeskID pages the DeskAttendants of each Desk apart, see
+// domain.Query.Partition. Each row of SQL then ends with the
+// DeskID, so All can't read them.
+func (b *QueryBuilder) PartitionByDeskID() *QueryBuilder {
+	b.q.Partition("DeskID")
+	return b
+}
+
+// WhereAttendantID matches AttendantID equal to v
+func (b *QueryBuilder) WhereAttendantID(v string) *QueryBuilder {
+	b.q.Cond("AttendantID = ?", domain.Hex(v))
+	return b
+}
+
+// AttendantIDIn matches AttendantID equal to any of vs
+func (b *QueryBuilder) AttendantIDIn(vs ...string) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = domain.Hex(v)
+	}
+	b.q.In("AttendantID", "?", args)
+	return b
+}
+
+// OrderByAttendantID sorts by AttendantID, after any order added before it
+func (b *QueryBuilder) OrderByAttendantID(o domain.Order) *QueryBuilder {
+	b.q.Order("AttendantID", o)
+	return b
+}
+
+// PartitionByAttendantID pages the DeskAttendants of each Attendant apart, see
+// domain.Query.Partition. Each row of SQL then ends with the
+// AttendantID, so All can't read them.
+func (b *QueryBuilder) PartitionByAttendantID() *QueryBuilder {
+	b.q.Partition("AttendantID")
+	return b
+}
+
+// Limit returns at most n DeskAttendants
+func (b *QueryBuilder) Limit(n int) *QueryBuilder {
+	b.q.Limit(n)
+	return b
+}
+
+// Offset skips the first n DeskAttendants
+func (b *QueryBuilder) Offset(n int) *QueryBuilder {
+	b.q.Offset(n)
+	return b
+}
+
+// SQL returns the parameterized statement and its arguments
+func (b *QueryBuilder) SQL() (string, []interface{}) {
+	return b.q.Select(sqlSelect)
+}
+
+// CountSQL returns the statement counting the matching DeskAttendants, ignoring
+// Limit and Offset, and its arguments. Partitioned, it selects each
+// partition and its count.
+func (b *QueryBuilder) CountSQL() (string, []interface{}) {
+	return b.q.Count("DeskAttendant")
+}
+
+// All returns every matching DeskAttendant
+func (b *QueryBuilder) All(ctx context.Context, db domain.DB) ([]*DeskAttendant, error) {
+	stmt, args := b.SQL()
+	return query(ctx, db, stmt, args...)
+}
+
+// First returns the first matching DeskAttendant, or sql.ErrNoRows
+func (b *QueryBuilder) First(ctx context.Context, db domain.DB) (*DeskAttendant, error) {
+	b.q.Limit(1)
+	query, args := b.SQL()
+	return NewFromRow(db.QueryRowContext(ctx, query, args...))
+}
+
+// Count returns the number of matching DeskAttendants, ignoring Limit and Offset
+func (b *QueryBuilder) Count(ctx context.Context, db domain.DB) (int, error) {
+	stmt, args := b.CountSQL()
+	var n int
+	err := db.QueryRowContext(ctx, stmt, args...).Scan(&n)
+	return n, err
+}
+
+///////////////////
+// DESK RELATION
+///////////////////
+
+// Desk returns the Desk referenced by DeskID
+func (o *DeskAttendant) Desk(ctx context.Context, db domain.DB) (*desk.Desk, error) {
+	row := db.QueryRowContext(ctx, desk.SQLSelect()+" WHERE ID = ?", domain.Hex(o.DeskID))
+	return desk.NewFromRow(row)
+}
+
+// LoadDesks fetches the Desk of every DeskAttendant in list with a single
+// query, keyed by DeskID as written, whatever the case of its hex digits
+func LoadDesks(ctx context.Context, db domain.DB, list []*DeskAttendant) (map[string]*desk.Desk, error) {
+	keys := map[string]*desk.Desk{}
+	args := []interface{}{}
+	for _, o := range list {
+		if _, ok := keys[o.DeskID]; !ok {
+			keys[o.DeskID] = nil
+			args = append(args, domain.Hex(o.DeskID))
+		}
+	}
+	if len(args) == 0 {
+		return keys, nil
+	}
+	found, err := desk.Select(ctx, db, "WHERE ID IN ("+domain.Placeholders("?", len(args))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	// scanned IDs are upper case
+	byID := map[string]*desk.Desk{}
+	for _, f := range found {
+		byID[f.ID] = f
+	}
+	for k := range keys {
+		keys[k] = byID[strings.ToUpper(k)]
+	}
+	return keys, nil
+}
+
+// ListByDeskID returns every DeskAttendant referencing the given Desk.
+// It lives here rather than on Desk to avoid an import cycle.
+func ListByDeskID(ctx context.Context, db domain.DB, deskID string) ([]*DeskAttendant, error) {
+	return Select(ctx, db, "WHERE DeskID = ? ORDER BY DeskID, AttendantID", domain.Hex(deskID))
+}
+
+// ListByDeskIDs fetches the DeskAttendants of every given Desk with a
+// single query, grouped by DeskID as given, whatever the case of its
+// hex digits
+func ListByDeskIDs(ctx context.Context, db domain.DB, deskIDs ...string) (map[string][]*DeskAttendant, error) {
+	groups := map[string][]*DeskAttendant{}
+	if len(deskIDs) == 0 {
+		return groups, nil
+	}
+	args := make([]interface{}, len(deskIDs))
+	for i, k := range deskIDs {
+		args[i] = domain.Hex(k)
+	}
+	found, err := Select(ctx, db, "WHERE DeskID IN ("+domain.Placeholders("?", len(args))+") ORDER BY DeskID, AttendantID", args...)
+	if err != nil {
+		return nil, err
+	}
+	// scanned IDs are upper case
+	byID := map[string][]*DeskAttendant{}
+	for _, f := range found {
+		byID[f.DeskID] = append(byID[f.DeskID], f)
+	}
+	for _, k := range deskIDs {
+		if g := byID[strings.ToUpper(k)]; g != nil {
+			groups[k] = g
+		}
+	}
+	return groups, nil
+}
+
+// the Desks list the DeskAttendants referencing them with this package's
+// loader, as it imports theirs
+func init() {
+	desk.DeskAttendantsLoader = func(ctx context.Context, db domain.DB, deskID string) ([]domain.Domain, error) {
+		list, err := ListByDeskID(ctx, db, deskID)
+		if err != nil {
+			return nil, err
+		}
+		found := make([]domain.Domain, len(list))
+		for i, o := range list {
+			found[i] = o
+		}
+		return found, nil
+	}
+}
+
+///////////////////
+// ATTENDANT RELATION
+///////////////////
+
+// Attendant returns the Attendant referenced by AttendantID
+func (o *DeskAttendant) Attendant(ctx context.Context, db domain.DB) (*attendant.Attendant, error) {
+	row := db.QueryRowContext(ctx, attendant.SQLSelect()+" WHERE ID = ?", domain.Hex(o.AttendantID))
+	return attendant.NewFromRow(row)
+}
+
+// LoadAttendants fetches the Attendant of every DeskAttendant in list with a single
+// query, keyed by AttendantID as written, whatever the case of its hex digits
+func LoadAttendants(ctx context.Context, db domain.DB, list []*DeskAttendant) (map[string]*attendant.Attendant, error) {
+	keys := map[string]*attendant.Attendant{}
+	args := []interface{}{}
+	for _, o := range list {
+		if _, ok := keys[o.AttendantID]; !ok {
+			keys[o.AttendantID] = nil
+			args = append(args, domain.Hex(o.AttendantID))
+		}
+	}
+	if len(args) == 0 {
+		return keys, nil
+	}
+	found, err := attendant.Select(ctx, db, "WHERE ID IN ("+domain.Placeholders("?", len(args))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	// scanned IDs are upper case
+	byID := map[string]*attendant.Attendant{}
+	for _, f := range found {
+		byID[f.ID] = f
+	}
+	for k := range keys {
+		keys[k] = byID[strings.ToUpper(k)]
+	}
+	return keys, nil
+}
+
+// ListByAttendantID returns every DeskAttendant referencing the given Attendant.
+// It lives here rather than on Attendant to avoid an import cycle.
+func ListByAttendantID(ctx context.Context, db domain.DB, attendantID string) ([]*DeskAttendant, error) {
+	return Select(ctx, db, "WHERE AttendantID = ? ORDER BY DeskID, AttendantID", domain.Hex(attendantID))
+}
+
+// ListByAttendantIDs fetches the DeskAttendants of every given Attendant with a
+// single query, grouped by AttendantID as given, whatever the case of its
+// hex digits
+func ListByAttendantIDs(ctx context.Context, db domain.DB, attendantIDs ...string) (map[string][]*DeskAttendant, error) {
+	groups := map[string][]*DeskAttendant{}
+	if len(attendantIDs) == 0 {
+		return groups, nil
+	}
+	args := make([]interface{}, len(attendantIDs))
+	for i, k := range attendantIDs {
+		args[i] = domain.Hex(k)
+	}
+	found, err := Select(ctx, db, "WHERE AttendantID IN ("+domain.Placeholders("?", len(args))+") ORDER BY DeskID, AttendantID", args...)
+	if err != nil {
+		return nil, err
+	}
+	// scanned IDs are upper case
+	byID := map[string][]*DeskAttendant{}
+	for _, f := range found {
+		byID[f.AttendantID] = append(byID[f.AttendantID], f)
+	}
+	for _, k := range attendantIDs {
+		if g := byID[strings.ToUpper(k)]; g != nil {
+			groups[k] = g
+		}
+	}
+	return groups, nil
+}
+
+// the Attendants list the DeskAttendants referencing them with this package's
+// loader, as it imports theirs
+func init() {
+	attendant.DeskAttendantsLoader = func(ctx context.Context, db domain.DB, attendantID string) ([]domain.Domain, error) {
+		list, err := ListByAttendantID(ctx, db, attendantID)
+		if err != nil {
+			return nil, err
+		}
+		found := make([]domain.Domain, len(list))
+		for i, o := range list {
+			found[i] = o
+		}
+		return found, nil
+	}
+}
+
+///////////////////
+// LINKED OBJECTS
+///////////////////
+
+// AttendantsOfDesk returns the Attendants linked to the Desk with deskID
+func AttendantsOfDesk(ctx context.Context, db domain.DB, deskID string) ([]*attendant.Attendant, error) {
+	return attendant.Select(ctx, db, "WHERE ID IN (SELECT AttendantID FROM DeskAttendant WHERE DeskID = ?) ORDER BY ID", domain.Hex(deskID))
+}
+
+// DesksOfAttendant returns the Desks linked to the Attendant with attendantID
+func DesksOfAttendant(ctx context.Context, db domain.DB, attendantID string) ([]*desk.Desk, error) {
+	return desk.Select(ctx, db, "WHERE ID IN (SELECT DeskID FROM DeskAttendant WHERE AttendantID = ?) ORDER BY ID", domain.Hex(attendantID))
+}
+
+func (o *DeskAttendant) String() string {
+	b, _ := json.MarshalIndent(o, "", "    ")
+	return string(b)
+}
+
+func (o *DeskAttendant) PPrint() {
+	fmt.Println(o.String())
+}
@@ -0,0 +1,64 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 212844dcc2823179
+
+package deskattendant_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/deskattendant"
+	"git.ottoq.com/otto-backend/valet/domain/domaintest"
+)
+
+// object describes DeskAttendant to the tests every object shares
+var object = domaintest.Object{
+	Name:   "DeskAttendant",
+	Table:  deskattendant.TableName(),
+	Schema: deskattendant.Schema(),
+	Random: func() domaintest.Record {
+		return deskattendant.Random()
+	},
+	Get: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*deskattendant.DeskAttendant)
+		return deskattendant.GetByID(ctx, db, o.DeskID, o.AttendantID)
+	},
+	Keys:      []string{"DeskID", "AttendantID"},
+	Created:   []string{},
+	Mutable:   []string{},
+	Versioned: false,
+}
+
+func TestDeskAttendant(t *testing.T) {
+	object.Test(t)
+}
+
+// TestDeskAttendantKeys reads a DeskAttendant back by its primary key, and nothing by a key
+// differing in one column
+func TestDeskAttendantKeys(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	want := deskattendant.Random()
+	if err := want.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := deskattendant.GetByID(ctx, db, want.DeskID, want.AttendantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := domaintest.Diff(want, got); d != "" {
+		t.Fatal(d)
+	}
+
+	other := deskattendant.Random()
+	if _, err := deskattendant.GetByID(ctx, db, other.DeskID, want.AttendantID); err != sql.ErrNoRows {
+		t.Errorf("GetByID of another DeskID got %v, want sql.ErrNoRows", err)
+	}
+	if _, err := deskattendant.GetByID(ctx, db, want.DeskID, other.AttendantID); err != sql.ErrNoRows {
+		t.Errorf("GetByID of another AttendantID got %v, want sql.ErrNoRows", err)
+	}
+}
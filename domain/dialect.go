@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL flavour of a database, named like its database/sql
+// driver
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite3"
+)
+
+// Dialects lists every supported dialect
+var Dialects = []Dialect{MySQL, Postgres, SQLite}
+
+// DialectOf returns the dialect of db, which is MySQL unless db has a
+// Dialect method as *database.Database and *database.Tx do
+func DialectOf(db DB) Dialect {
+	if d, ok := db.(interface{ Dialect() Dialect }); ok {
+		return d.Dialect()
+	}
+	return MySQL
+}
+
+// Rebind rewrites the ? placeholders of query into those of the dialect,
+// $1, $2... for Postgres. Question marks in quoted strings and
+// identifiers are left alone.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+	b := strings.Builder{}
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Statements holds a statement written for each dialect
+type Statements map[Dialect]string
+
+// For returns the statement for the dialect of db
+func (s Statements) For(db DB) string {
+	return s[DialectOf(db)]
+}
+
+// Hex returns the argument writing a 16 byte ID held as 32 hex
+// characters, id being a string or a *string. nil and malformed IDs
+// write NULL like MySQL's UNHEX, so looking one up finds nothing.
+func Hex(id interface{}) driver.Valuer {
+	switch id := id.(type) {
+	case string:
+		return hexValue{&id}
+	case *string:
+		return hexValue{id}
+	}
+	panic(fmt.Sprintf("domain.Hex of %T", id))
+}
+
+type hexValue struct {
+	id *string
+}
+
+// Value implements driver.Valuer
+func (v hexValue) Value() (driver.Value, error) {
+	if v.id == nil {
+		return nil, nil
+	}
+	if !IsHexID(*v.id) {
+		return nil, nil
+	}
+	b, _ := hex.DecodeString(*v.id)
+	return b, nil
+}
+
+// JSON returns the argument writing a JSON document as text, nil writing
+// NULL. Drivers would write a []byte as binary, which Postgres doesn't
+// accept for a JSONB column.
+func JSON(doc []byte) driver.Valuer {
+	return jsonValue(doc)
+}
+
+type jsonValue []byte
+
+// Value implements driver.Valuer
+func (v jsonValue) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return string(v), nil
+}
+
+// ScanJSON returns the destination reading a JSON document into dest
+// from either the text or the bytes drivers return for it
+func ScanJSON(dest *json.RawMessage) interface{} {
+	return jsonScanner{dest}
+}
+
+type jsonScanner struct {
+	dest *json.RawMessage
+}
+
+// Scan implements sql.Scanner
+func (s jsonScanner) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*s.dest = nil
+	case []byte:
+		*s.dest = append(json.RawMessage{}, src...)
+	case string:
+		*s.dest = json.RawMessage(src)
+	default:
+		return fmt.Errorf("can't scan %T into a JSON document", src)
+	}
+	return nil
+}
+
+// ScanHex returns the destination reading a 16 byte ID into dest, a
+// *string or a **string, as 32 uppercase hex characters. Drivers return
+// the bytes of the ID, except MySQL's for a text protocol query, which
+// returns them as hex already.
+func ScanHex(dest interface{}) interface{} {
+	switch dest.(type) {
+	case *string, **string:
+		return hexScanner{dest}
+	}
+	panic(fmt.Sprintf("domain.ScanHex into %T", dest))
+}
+
+type hexScanner struct {
+	dest interface{}
+}
+
+// Scan implements sql.Scanner
+func (s hexScanner) Scan(src interface{}) error {
+	var id *string
+	switch src := src.(type) {
+	case nil:
+	case []byte:
+		v := strings.ToUpper(hex.EncodeToString(src))
+		id = &v
+	case string:
+		// the forked MySQL driver has already written the bytes of an ID
+		// column read by a text protocol query as hex
+		if !IsHexID(src) {
+			return fmt.Errorf("can't scan %q into a hex ID", src)
+		}
+		v := strings.ToUpper(src)
+		id = &v
+	default:
+		return fmt.Errorf("can't scan %T into a hex ID", src)
+	}
+	switch dest := s.dest.(type) {
+	case **string:
+		*dest = id
+	case *string:
+		if id == nil {
+			return fmt.Errorf("can't scan NULL into a hex ID")
+		}
+		*dest = *id
+	}
+	return nil
+}
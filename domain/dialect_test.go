@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestScanHex(t *testing.T) {
+	const id = "0C74DFC158C646C280BCB0DAF9E015D1"
+	bytes := []byte{0x0c, 0x74, 0xdf, 0xc1, 0x58, 0xc6, 0x46, 0xc2, 0x80, 0xbc, 0xb0, 0xda, 0xf9, 0xe0, 0x15, 0xd1}
+	tests := []struct {
+		src  interface{}
+		want string // want is "" when src can't be scanned
+	}{
+		{bytes, id},
+		{id, id},
+		{"0c74dfc158c646c280bcb0daf9e015d1", id},
+		{string(bytes), ""},
+		{"0C74", ""},
+		{int64(1), ""},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		var got string
+		err := ScanHex(&got).(hexScanner).Scan(test.src)
+		switch {
+		case test.want == "" && err == nil:
+			t.Errorf("%#v scanned as %q", test.src, got)
+		case test.want != "" && err != nil:
+			t.Errorf("%#v: %s", test.src, err)
+		case got != test.want:
+			t.Errorf("%#v scanned as %q, want %q", test.src, got, test.want)
+		}
+	}
+
+	var null *string
+	if err := ScanHex(&null).(hexScanner).Scan(nil); err != nil || null != nil {
+		t.Errorf("NULL scanned as %v, %v", null, err)
+	}
+}
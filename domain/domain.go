@@ -1,5 +1,115 @@
 package domain
 
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DB is the subset of database.Database used by the generated domain
+// packages. *sql.Tx satisfies it as well, for MySQL only: statements are
+// written with ? placeholders, which database.Database and database.Tx
+// rebind for their dialect.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Domain is implemented by every generated domain object
 type Domain interface {
-	InsertString() string
+	// Validate checks every field against its declared constraints,
+	// returning a ValidationError
+	Validate() error
+	// Insert writes a new row, failing if it already exists.
+	// Every write validates first.
+	Insert(ctx context.Context, db DB) error
+	// Upsert writes a new row or overwrites the existing one
+	Upsert(ctx context.Context, db DB) error
+	// Update overwrites the existing row, only the changed columns of
+	// an object that was read or written
+	Update(ctx context.Context, db DB) error
+	// Delete removes the row, or marks it deleted when the object has a
+	// DeletedAt audit column
+	Delete(ctx context.Context, db DB) error
+	// Changes lists the fields holding a new value since the object was
+	// read or last written
+	Changes() []Change
+}
+
+type actorKey struct{}
+
+// WithActor returns a context naming who is making the writes done with
+// it, recorded in the CreatedBy and UpdatedBy audit columns
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor set by WithActor, or ""
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Placeholders returns n comma separated copies of placeholder for use in
+// an IN clause, ex. Placeholders("?", 2) is "?, ?"
+func Placeholders(placeholder string, n int) string {
+	return strings.TrimSuffix(strings.Repeat(placeholder+", ", n), ", ")
+}
+
+// FieldError is a constraint violated by a single field
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+// Error returns the error string
+func (err *FieldError) Error() string {
+	return err.Field + " " + err.Reason
+}
+
+// ValidationError lists every field of an object that failed validation
+type ValidationError []*FieldError
+
+// Error returns the error string
+func (errs ValidationError) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return "invalid: " + strings.Join(s, ", ")
+}
+
+// ErrStaleObject is returned by the write of a versioned object that
+// changed since it was read, or no longer exists
+type ErrStaleObject struct {
+	Table   string
+	Version int64
+}
+
+// Error returns the error string
+func (err *ErrStaleObject) Error() string {
+	return fmt.Sprintf("stale %s: version %d is no longer current", err.Table, err.Version)
+}
+
+// ErrDeleted is returned by the Upsert of an object that isn't versioned
+// when its row is soft deleted, which only Restore undoes
+type ErrDeleted struct {
+	Table string
+}
+
+// Error returns the error string
+func (err *ErrDeleted) Error() string {
+	return fmt.Sprintf("deleted %s: restore it to write it", err.Table)
+}
+
+// IsHexID reports whether s is a 16 byte ID written as 32 hex characters
+func IsHexID(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
 }
@@ -0,0 +1,155 @@
+// Package domaintest holds helpers for the generated domain package tests
+package domaintest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"git.ottoq.com/otto-backend/valet/database"
+	"git.ottoq.com/otto-backend/valet/domain"
+)
+
+// DSNEnv names the environment variable holding the test database, ex.
+// VALET_TEST_DSN="root@tcp(127.0.0.1:3306)/valet_test?parseTime=true".
+// Tests that need a database use an in-memory SQLite one without it.
+const DSNEnv = "VALET_TEST_DSN"
+
+// DialectEnv names the environment variable holding the dialect of the
+// test database at DSNEnv, mysql by default, ex. VALET_TEST_DIALECT=postgres
+// with the postgres build tag
+const DialectEnv = "VALET_TEST_DIALECT"
+
+// Open returns a transaction on the test database that's rolled back
+// when the test ends, an in-memory SQLite database of its own if DSNEnv
+// isn't set. The test is skipped if SQLite isn't compiled in, which takes
+// the sqlite build tag. Tables are created if they don't exist and foreign keys aren't
+// checked so objects can be written without their parents.
+func Open(t testing.TB) domain.DB {
+	dsn := os.Getenv(DSNEnv)
+	dialect := domain.MySQL
+	if d := os.Getenv(DialectEnv); d != "" {
+		dialect = domain.Dialect(d)
+	}
+	if dsn == "" {
+		dsn, dialect = memory(t.Name()), domain.SQLite
+	}
+	db, err := database.Open(dialect, dsn)
+	if err != nil && os.Getenv(DSNEnv) == "" {
+		t.Skipf("set %s to run against a database, SQLite failed: %s", DSNEnv, err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := db.CreateTables(ctx); err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	if stmt := noForeignKeys[dialect]; stmt != "" {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			db.Close()
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+		db.Close()
+	})
+	return tx
+}
+
+// memory returns the DSN of an in-memory SQLite database named after a
+// test, shared by its connections until they're all closed
+func memory(test string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, test)
+	return "file:" + name + "?mode=memory&cache=shared"
+}
+
+// noForeignKeys turns off foreign key checks for the rest of a
+// transaction. SQLite doesn't check them unless asked to in the DSN.
+var noForeignKeys = map[domain.Dialect]string{
+	domain.MySQL:    "SET FOREIGN_KEY_CHECKS = 0",
+	domain.Postgres: "SET LOCAL session_replication_role = replica",
+}
+
+// Diff compares the exported fields of two objects as they survive a
+// database round trip, returning the first difference or "" if there's
+// none. Times may differ by less than a second, floats by the precision
+// of a FLOAT column and JSON by formatting.
+func Diff(want, got interface{}) string {
+	return diff("", reflect.ValueOf(want), reflect.ValueOf(got))
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	jsonType = reflect.TypeOf(json.RawMessage{})
+)
+
+func diff(path string, want, got reflect.Value) string {
+	if want.Kind() == reflect.Ptr {
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				return fmt.Sprintf("%s: got %v, want %v", path, got, want)
+			}
+			return ""
+		}
+		return diff(path, want.Elem(), got.Elem())
+	}
+	switch {
+	case want.Type() == timeType:
+		w, g := want.Interface().(time.Time), got.Interface().(time.Time)
+		if d := w.Sub(g); d >= time.Second || d <= -time.Second {
+			return fmt.Sprintf("%s: got %s, want %s", path, g, w)
+		}
+		return ""
+	case want.Type() == jsonType:
+		var w, g interface{}
+		json.Unmarshal(want.Bytes(), &w)
+		json.Unmarshal(got.Bytes(), &g)
+		if !reflect.DeepEqual(w, g) {
+			return fmt.Sprintf("%s: got %s, want %s", path, got.Bytes(), want.Bytes())
+		}
+		return ""
+	case want.Kind() == reflect.Struct:
+		for i := 0; i < want.NumField(); i++ {
+			if want.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			name := want.Type().Field(i).Name
+			if d := diff(strings.TrimPrefix(path+"."+name, "."), want.Field(i), got.Field(i)); d != "" {
+				return d
+			}
+		}
+		return ""
+	case want.Kind() == reflect.Float64 || want.Kind() == reflect.Float32:
+		w, g := want.Float(), got.Float()
+		if math.Abs(w-g) > 1e-6*math.Max(1, math.Abs(w)) {
+			return fmt.Sprintf("%s: got %v, want %v", path, g, w)
+		}
+		return ""
+	case want.Kind() == reflect.Slice && want.Len() == 0 && got.Len() == 0:
+		return ""
+	}
+	if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+		return fmt.Sprintf("%s: got %v, want %v", path, got.Interface(), want.Interface())
+	}
+	return ""
+}
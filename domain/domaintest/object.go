@@ -0,0 +1,382 @@
+package domaintest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/database"
+	"git.ottoq.com/otto-backend/valet/domain"
+)
+
+// Record is what the tests need of a generated domain object
+type Record interface {
+	domain.Domain
+	Patch(patch []byte) error
+}
+
+// restorer is a Record deleting only marks deleted
+type restorer interface {
+	Restore(ctx context.Context, db domain.DB) error
+}
+
+// Object describes a generated domain object to the tests every object
+// shares, see Test
+type Object struct {
+	Name   string
+	Table  string            // the object's TableName()
+	Schema domain.Statements // the object's Schema()
+
+	// Random returns a new random object
+	Random func() Record
+	// Get reads the row sharing r's primary key
+	Get func(ctx context.Context, db domain.DB, r Record) (Record, error)
+	// GetDeleted reads the row sharing r's primary key even if it's
+	// deleted, nil unless the object is soft deleted
+	GetDeleted func(ctx context.Context, db domain.DB, r Record) (Record, error)
+
+	Keys      []string // fields of the primary key
+	Created   []string // audit fields Update leaves as they were
+	Mutable   []string // fields Patch sets, but for JSON ones
+	Versioned bool
+}
+
+// Test runs the tests shared by every object as subtests of t. The round
+// trip is run against an in-memory SQLite database unless DSNEnv is set.
+func (o Object) Test(t *testing.T) {
+	t.Run("Random", o.testRandom)
+	t.Run("Patch", o.testPatch)
+	t.Run("Schema", o.testSchema)
+	t.Run("RoundTrip", o.testRoundTrip)
+	t.Run("Upsert", o.testUpsert)
+	t.Run("UpdateChanges", o.testUpdateChanges)
+	t.Run("Missing", o.testMissing)
+}
+
+func (o Object) testRandom(t *testing.T) {
+	if err := o.Random().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (o Object) testPatch(t *testing.T) {
+	r := o.Random()
+	if err := r.Patch([]byte(`{"NotAField": 1}`)); err == nil {
+		t.Fatal("Patch of an unknown field succeeded")
+	}
+	key, err := json.Marshal(map[string]interface{}{o.Keys[0]: field(o.Random(), o.Keys[0])})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Patch(key); err == nil {
+		t.Fatal("Patch of the primary key succeeded")
+	}
+	if changes := r.Changes(); len(changes) != 0 {
+		t.Fatalf("failed Patch changed %v", changes)
+	}
+	if len(o.Mutable) == 0 {
+		return
+	}
+
+	want := o.Random()
+	fields := map[string]interface{}{}
+	for _, f := range o.Mutable {
+		fields[f] = field(want, f)
+	}
+	patch, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Patch(patch); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range o.Mutable {
+		if d := Diff(field(want, f), field(r, f)); d != "" {
+			t.Fatalf("%s %s", f, d)
+		}
+	}
+}
+
+func (o Object) testSchema(t *testing.T) {
+	for _, table := range database.Tables {
+		if table.Table != o.Table {
+			continue
+		}
+		if !reflect.DeepEqual(table.Schema, o.Schema) {
+			t.Fatalf("database.Tables has\n%v\nSchema() is\n%v", table.Schema, o.Schema)
+		}
+		return
+	}
+	t.Fatalf("database.Tables has no %s table", o.Table)
+}
+
+// testRoundTrip writes a random object and reads it back after each write
+func (o Object) testRoundTrip(t *testing.T) {
+	db := Open(t)
+	ctx := context.Background()
+
+	want := o.Random()
+	if err := want.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := o.Get(ctx, db, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Diff(want, got); d != "" {
+		t.Fatalf("after Insert %s", d)
+	}
+
+	changed := o.Random()
+	for _, f := range append(o.Keys, o.Created...) {
+		reflect.ValueOf(changed).Elem().FieldByName(f).Set(reflect.ValueOf(field(want, f)))
+	}
+	if err := changed.Update(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = o.Get(ctx, db, want); err != nil {
+		t.Fatal(err)
+	}
+	if d := Diff(changed, got); d != "" {
+		t.Fatalf("after Update %s", d)
+	}
+	if o.Versioned && o.change(t, want) {
+		if err := want.Update(ctx, db); err == nil {
+			t.Fatal("Update of a stale version succeeded")
+		} else if _, ok := err.(*domain.ErrStaleObject); !ok {
+			t.Fatalf("Update of a stale version got %v, want *domain.ErrStaleObject", err)
+		}
+	}
+
+	if err := changed.Delete(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Get(ctx, db, want); err != sql.ErrNoRows {
+		t.Fatalf("after Delete got %v, want sql.ErrNoRows", err)
+	}
+	if o.GetDeleted == nil {
+		return
+	}
+	if got, err = o.GetDeleted(ctx, db, want); err != nil {
+		t.Fatalf("after Delete WithDeleted %s", err)
+	}
+	if reflect.ValueOf(field(got, "DeletedAt")).IsNil() {
+		t.Fatal("after Delete DeletedAt is nil")
+	}
+	if err := changed.Delete(ctx, db); err == nil {
+		t.Fatal("Delete of a deleted row succeeded")
+	}
+	if o.Versioned && o.change(t, got) {
+		if err := got.Update(ctx, db); err == nil {
+			t.Fatal("Update of a deleted row succeeded")
+		}
+	}
+
+	if err := got.(restorer).Restore(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Get(ctx, db, want); err != nil {
+		t.Fatalf("after Restore %s", err)
+	}
+	if err := got.(restorer).Restore(ctx, db); err == nil {
+		t.Fatal("Restore of a row that isn't deleted succeeded")
+	}
+	// Restore kept got at the row's version
+	o.change(t, got)
+	if err := got.Update(ctx, db); err != nil {
+		t.Fatalf("Update after Restore %s", err)
+	}
+}
+
+// testUpsert writes a random object with Upsert, then overwrites it as
+// another actor
+func (o Object) testUpsert(t *testing.T) {
+	db := Open(t)
+	ctx := context.Background()
+
+	want := o.Random()
+	if err := want.Upsert(domain.WithActor(ctx, "creator"), db); err != nil {
+		t.Fatal(err)
+	}
+	changed := o.Random()
+	for _, f := range o.Keys {
+		reflect.ValueOf(changed).Elem().FieldByName(f).Set(reflect.ValueOf(field(want, f)))
+	}
+	if err := changed.Upsert(domain.WithActor(ctx, "updater"), db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := o.Get(ctx, db, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Diff(changed, got); d != "" {
+		t.Fatalf("after Upsert %s", d)
+	}
+	for _, f := range o.Created {
+		if d := Diff(field(want, f), field(got, f)); d != "" {
+			t.Fatalf("Upsert changed %s", d)
+		}
+	}
+}
+
+// testUpdateChanges assigns a field of a row read back and checks Update
+// writes its column alone
+func (o Object) testUpdateChanges(t *testing.T) {
+	if len(o.Mutable) == 0 {
+		t.Skip("no mutable fields")
+	}
+	db := &recorder{DB: Open(t)}
+	ctx := context.Background()
+
+	r := o.Random()
+	if err := r.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := o.Get(ctx, db, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.stmts = nil
+	if err := got.Update(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.stmts) != 0 {
+		t.Fatalf("Update without changes wrote %q", db.stmts)
+	}
+
+	o.change(t, got)
+	if changes := got.Changes(); len(changes) != 1 || changes[0].Field != o.Mutable[0] {
+		t.Fatalf("assigning %s changed %v", o.Mutable[0], changes)
+	}
+	if err := got.Update(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.stmts) != 1 {
+		t.Fatalf("Update wrote %q", db.stmts)
+	}
+	for _, f := range o.Mutable {
+		if set := strings.Contains(db.stmts[0], " "+f+" = ?"); set != (f == o.Mutable[0]) {
+			t.Fatalf("Update of %s wrote %s: %s", o.Mutable[0], f, db.stmts[0])
+		}
+	}
+	if changes := got.Changes(); len(changes) != 0 {
+		t.Fatalf("after Update changed %v", changes)
+	}
+	saved, err := o.Get(ctx, db, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Diff(got, saved); d != "" {
+		t.Fatalf("after Update %s", d)
+	}
+}
+
+// testMissing updates an object that was never written and one that's
+// deleted, then upserts over the deleted row, each failing without
+// taking o's changes as written
+func (o Object) testMissing(t *testing.T) {
+	if len(o.Mutable) == 0 {
+		t.Skip("no mutable fields")
+	}
+	db := Open(t)
+	ctx := context.Background()
+
+	if err := o.Random().Update(ctx, db); !o.missing(err) {
+		t.Fatalf("Update of a missing row got %v", err)
+	}
+	if o.GetDeleted == nil {
+		return
+	}
+
+	r := o.Random()
+	if err := r.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := o.Get(ctx, db, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := got.Delete(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	o.change(t, got)
+	if err := got.Update(ctx, db); !o.missing(err) {
+		t.Fatalf("Update of a deleted row got %v", err)
+	}
+	if changes := got.Changes(); len(changes) != 1 {
+		t.Fatalf("after a failed Update changed %v", changes)
+	}
+
+	over := o.Random()
+	for _, f := range o.Keys {
+		reflect.ValueOf(over).Elem().FieldByName(f).Set(reflect.ValueOf(field(r, f)))
+	}
+	err = over.Upsert(ctx, db)
+	refused := false
+	if o.Versioned {
+		_, refused = err.(*domain.ErrStaleObject)
+	} else {
+		_, refused = err.(*domain.ErrDeleted)
+	}
+	if !refused {
+		t.Fatalf("Upsert of a deleted row got %v", err)
+	}
+	deleted, err := o.GetDeleted(ctx, db, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Diff(field(r, o.Mutable[0]), field(deleted, o.Mutable[0])); d != "" {
+		t.Fatalf("Upsert of a deleted row wrote %s %s", o.Mutable[0], d)
+	}
+}
+
+// missing reports whether err is what the Update of a row that isn't
+// there returns
+func (o Object) missing(err error) bool {
+	if o.Versioned {
+		_, ok := err.(*domain.ErrStaleObject)
+		return ok
+	}
+	return err == sql.ErrNoRows
+}
+
+// change assigns the first mutable field of r a new random value, or
+// reports there's none
+func (o Object) change(t *testing.T, r Record) bool {
+	if len(o.Mutable) == 0 {
+		return false
+	}
+	f := o.Mutable[0]
+	for i := 0; i < 100; i++ {
+		v := field(o.Random(), f)
+		if Diff(v, field(r, f)) != "" {
+			reflect.ValueOf(r).Elem().FieldByName(f).Set(reflect.ValueOf(v))
+			return true
+		}
+	}
+	t.Fatalf("no random %s differs from %v", f, field(r, f))
+	return false
+}
+
+// recorder records the statements executed on a database
+type recorder struct {
+	domain.DB
+	stmts []string
+}
+
+func (r *recorder) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.stmts = append(r.stmts, query)
+	return r.DB.ExecContext(ctx, query, args...)
+}
+
+func (r *recorder) Dialect() domain.Dialect {
+	return domain.DialectOf(r.DB)
+}
+
+// field returns the value of r's exported field name
+func field(r Record, name string) interface{} {
+	return reflect.ValueOf(r).Elem().FieldByName(name).Interface()
+}
@@ -0,0 +1,102 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 668fcee0da0bd2bd
+
+// Package enums holds the types of the enum parameters of every domain
+// object. Values outside an enum are rejected when parsed, unmarshalled
+// from JSON, scanned from or written to the database.
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// DeskState is one of open, closed, out_of_service
+type DeskState string
+
+const (
+	DeskStateOpen         DeskState = "open"
+	DeskStateClosed       DeskState = "closed"
+	DeskStateOutOfService DeskState = "out_of_service"
+)
+
+// DeskStateValues lists every DeskState in declaration order
+var DeskStateValues = []DeskState{
+	DeskStateOpen,
+	DeskStateClosed,
+	DeskStateOutOfService,
+}
+
+// ParseDeskState returns the DeskState of s or an error if s isn't one
+func ParseDeskState(s string) (DeskState, error) {
+	v := DeskState(s)
+	if !v.Valid() {
+		return "", fmt.Errorf("invalid DeskState %q", s)
+	}
+	return v, nil
+}
+
+// Valid reports whether v is one of DeskStateValues
+func (v DeskState) Valid() bool {
+	switch v {
+	case DeskStateOpen, DeskStateClosed, DeskStateOutOfService:
+		return true
+	}
+	return false
+}
+
+func (v DeskState) String() string {
+	return string(v)
+}
+
+// MarshalJSON implements json.Marshaler
+func (v DeskState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(v))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, rejecting unknown values.
+// null leaves v unchanged, as it does other types.
+func (v *DeskState) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDeskState(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting NULL and unknown values
+func (v *DeskState) Scan(src interface{}) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return fmt.Errorf("can't scan %T into DeskState", src)
+	}
+	parsed, err := ParseDeskState(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, rejecting unknown values
+func (v DeskState) Value() (driver.Value, error) {
+	if !v.Valid() {
+		return nil, fmt.Errorf("invalid DeskState %q", string(v))
+	}
+	return string(v), nil
+}
@@ -0,0 +1,105 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 0b945820c28ab08a
+
+package node_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/domaintest"
+	"git.ottoq.com/otto-backend/valet/domain/node"
+)
+
+// object describes Node to the tests every object shares
+var object = domaintest.Object{
+	Name:   "Node",
+	Table:  node.TableName(),
+	Schema: node.Schema(),
+	Random: func() domaintest.Record {
+		return node.Random()
+	},
+	Get: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*node.Node)
+		return node.GetByID(ctx, db, o.ID)
+	},
+	GetDeleted: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*node.Node)
+		return node.Query().WithDeleted().WhereID(o.ID).First(ctx, db)
+	},
+	Keys:      []string{"ID"},
+	Created:   []string{"CreatedAt", "CreatedBy"},
+	Mutable:   []string{"Name"},
+	Versioned: false,
+}
+
+func TestNode(t *testing.T) {
+	object.Test(t)
+}
+
+// TestNodeKeys reads a Node back by its primary key, and nothing by a key
+// differing in one column
+func TestNodeKeys(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	want := node.Random()
+	if err := want.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := node.GetByID(ctx, db, want.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := domaintest.Diff(want, got); d != "" {
+		t.Fatal(d)
+	}
+
+	other := node.Random()
+	if _, err := node.GetByID(ctx, db, other.ID); err != sql.ErrNoRows {
+		t.Errorf("GetByID of another ID got %v, want sql.ErrNoRows", err)
+	}
+}
+
+// TestNodeColumns updates each column of a Node on its own and reads it
+// back
+func TestNodeColumns(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	o := node.Random()
+	if err := o.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	columns := []struct {
+		name string
+		set  func(o, values *node.Node)
+		get  func(o *node.Node) interface{}
+	}{
+		{
+			"Name",
+			func(o, values *node.Node) { o.SetName(values.Name) },
+			func(o *node.Node) interface{} { return o.Name },
+		},
+	}
+	for _, c := range columns {
+		got, err := node.GetByID(ctx, db, o.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.set(got, node.Random())
+		if err := got.Update(ctx, db); err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		saved, err := node.GetByID(ctx, db, o.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := domaintest.Diff(c.get(got), c.get(saved)); d != "" {
+			t.Errorf("%s %s", c.name, d)
+		}
+	}
+}
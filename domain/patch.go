@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// Change is a field holding a new value since it was read or written
+type Change struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// Changed reports whether a field set from old to new holds a different value
+func Changed(old, new interface{}) bool {
+	return !reflect.DeepEqual(old, new)
+}
+
+// Copy returns the value of a field to compare it with later, copying
+// what a pointer or slice refers to so changes made through the field
+// don't show in the copy
+func Copy(v interface{}) interface{} {
+	r := reflect.ValueOf(v)
+	switch {
+	case r.Kind() == reflect.Ptr && !r.IsNil():
+		c := reflect.New(r.Type().Elem())
+		c.Elem().Set(r.Elem())
+		return c.Interface()
+	case r.Kind() == reflect.Slice && !r.IsNil():
+		c := reflect.MakeSlice(r.Type(), r.Len(), r.Len())
+		reflect.Copy(c, r)
+		return c.Interface()
+	}
+	return v
+}
+
+// PatchField is a member of a JSON merge patch (RFC 7386)
+type PatchField struct {
+	Name  string
+	Value json.RawMessage
+}
+
+// ParsePatch returns the members of a merge patch sorted by name. A patch
+// must be a JSON object, replacing the whole object isn't supported.
+func ParsePatch(patch []byte) ([]PatchField, error) {
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(patch, &members); err != nil || members == nil {
+		return nil, ValidationError{&FieldError{Field: "patch", Reason: "must be a JSON object"}}
+	}
+	fields := make([]PatchField, 0, len(members))
+	for name, value := range members {
+		fields = append(fields, PatchField{Name: name, Value: value})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields, nil
+}
+
+// IsNull reports whether the member removes the field
+func (f PatchField) IsNull() bool {
+	return bytes.Equal(bytes.TrimSpace(f.Value), []byte("null"))
+}
+
+// Decode unmarshals the member into v, a pointer to the field. null is
+// only allowed when the field is nullable.
+func (f PatchField) Decode(v interface{}, nullable bool) *FieldError {
+	if f.IsNull() && !nullable {
+		return &FieldError{Field: f.Name, Reason: "can't be null"}
+	}
+	if err := json.Unmarshal(f.Value, v); err != nil {
+		return &FieldError{Field: f.Name, Reason: "is invalid: " + err.Error()}
+	}
+	return nil
+}
+
+// MergeJSON applies the member to a JSON field as a merge patch of its
+// own, so {"a": null} removes a from the document rather than replacing it
+func (f PatchField) MergeJSON(doc json.RawMessage, nullable bool) (json.RawMessage, *FieldError) {
+	if f.IsNull() {
+		if !nullable {
+			return nil, &FieldError{Field: f.Name, Reason: "can't be null"}
+		}
+		return nil, nil
+	}
+	merged, err := MergePatch(doc, f.Value)
+	if err != nil {
+		return nil, &FieldError{Field: f.Name, Reason: "is invalid: " + err.Error()}
+	}
+	return merged, nil
+}
+
+// MergePatch returns doc with patch applied as described by RFC 7386
+func MergePatch(doc, patch json.RawMessage) (json.RawMessage, error) {
+	var target, p interface{}
+	if len(doc) > 0 {
+		if err := json.Unmarshal(doc, &target); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	doc, ok := target.(map[string]interface{})
+	if !ok {
+		doc = map[string]interface{}{}
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(doc, name)
+			continue
+		}
+		doc[name] = mergePatch(doc[name], value)
+	}
+	return doc
+}
@@ -0,0 +1,125 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a": 1}`, `{"a": 2}`, `{"a": 2}`},
+		{`{"a": 1}`, `{"b": 2}`, `{"a": 1, "b": 2}`},
+		{`{"a": 1, "b": 2}`, `{"a": null}`, `{"b": 2}`},
+		{`{"a": 1}`, `{"b": null}`, `{"a": 1}`},
+		{`{"a": {"b": 1, "c": 2}}`, `{"a": {"b": null, "d": 3}}`, `{"a": {"c": 2, "d": 3}}`},
+		{`{"a": {"b": 1}}`, `{"a": [1, 2]}`, `{"a": [1, 2]}`},
+		{`{"a": [1, 2]}`, `{"a": {"b": null, "c": 1}}`, `{"a": {"c": 1}}`},
+		{`{"a": 1}`, `[1]`, `[1]`},
+		{`[1]`, `{"a": 1}`, `{"a": 1}`},
+		{``, `{"a": {"b": null}}`, `{"a": {}}`},
+		{`{"a": 1}`, `{}`, `{"a": 1}`},
+	}
+	for _, test := range tests {
+		got, err := domain.MergePatch(json.RawMessage(test.doc), json.RawMessage(test.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s): %s", test.doc, test.patch, err)
+			continue
+		}
+		if !equalJSON(t, got, test.want) {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", test.doc, test.patch, got, test.want)
+		}
+	}
+
+	for _, bad := range [][2]string{{`{`, `{}`}, {`{}`, `{"a": }`}} {
+		if _, err := domain.MergePatch(json.RawMessage(bad[0]), json.RawMessage(bad[1])); err == nil {
+			t.Errorf("MergePatch(%s, %s) succeeded", bad[0], bad[1])
+		}
+	}
+}
+
+func TestParsePatch(t *testing.T) {
+	fields, err := domain.ParsePatch([]byte(`{"b": null, "a": {"c": 1}, "Unknown": "x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	// unknown members are returned for the object to refuse
+	if want := []string{"Unknown", "a", "b"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("ParsePatch names %q, want %q", names, want)
+	}
+	if !fields[2].IsNull() || fields[1].IsNull() {
+		t.Fatal("only b is null")
+	}
+	var s string
+	if err := fields[2].Decode(&s, false); err == nil {
+		t.Fatal("Decode of null into a field that isn't nullable succeeded")
+	}
+	var p *string
+	if err := fields[2].Decode(&p, true); err != nil || p != nil {
+		t.Fatalf("Decode of null got %v, %v", p, err)
+	}
+	if err := fields[0].Decode(&s, false); err != nil || s != "x" {
+		t.Fatalf("Decode got %q, %v", s, err)
+	}
+	if err := fields[1].Decode(&s, false); err == nil {
+		t.Fatal("Decode of an object into a string succeeded")
+	}
+
+	// a member holding an object is merged into the document
+	doc, ferr := fields[1].MergeJSON(json.RawMessage(`{"c": 0, "d": 1}`), false)
+	if ferr != nil || !equalJSON(t, doc, `{"c": 1, "d": 1}`) {
+		t.Fatalf("MergeJSON got %s, %v", doc, ferr)
+	}
+	if _, ferr := fields[2].MergeJSON(json.RawMessage(`{}`), false); ferr == nil {
+		t.Fatal("MergeJSON of null into a field that isn't nullable succeeded")
+	}
+	if doc, ferr := fields[2].MergeJSON(json.RawMessage(`{}`), true); ferr != nil || doc != nil {
+		t.Fatalf("MergeJSON of null got %s, %v", doc, ferr)
+	}
+
+	for _, bad := range []string{``, `null`, `[]`, `"a"`, `{"a": 1`} {
+		if _, err := domain.ParsePatch([]byte(bad)); err == nil {
+			t.Errorf("ParsePatch(%q) succeeded", bad)
+		} else if _, ok := err.(domain.ValidationError); !ok {
+			t.Errorf("ParsePatch(%q) got %T, want domain.ValidationError", bad, err)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	s := "a"
+	p := domain.Copy(&s).(*string)
+	raw := json.RawMessage(`{}`)
+	c := domain.Copy(raw).(json.RawMessage)
+	s, raw[0] = "b", '['
+	if *p != "a" || string(c) != "{}" {
+		t.Fatalf("Copy changed with its original to %q and %s", *p, c)
+	}
+	if !domain.Changed(p, &s) || domain.Changed(domain.Copy(&s), &s) {
+		t.Fatal("Changed compares what pointers refer to")
+	}
+	var nilPtr *string
+	if domain.Copy(nilPtr).(*string) != nil || domain.Copy(1) != 1 {
+		t.Fatal("Copy changed a nil pointer or a value")
+	}
+}
+
+// equalJSON reports whether got holds the same JSON as want
+func equalJSON(t *testing.T, got json.RawMessage, want string) bool {
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("%s: %s", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("%s: %s", want, err)
+	}
+	return reflect.DeepEqual(g, w)
+}
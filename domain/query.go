@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Order is the direction of an ORDER BY
+type Order string
+
+const (
+	Asc  Order = "ASC"
+	Desc Order = "DESC"
+)
+
+// Query accumulates the clauses of a SELECT. The generated QueryBuilder
+// of each domain package wraps it with typed methods per column.
+type Query struct {
+	joins     []string
+	joinArgs  []interface{}
+	conds     []string
+	args      []interface{}
+	orders    []string
+	limit     int
+	offset    int
+	partition string
+}
+
+// Join joins a table to the rows, ex. to match or partition them by its
+// columns. Its arguments come before those of the conditions.
+func (q *Query) Join(join string, args ...interface{}) {
+	q.joins = append(q.joins, join)
+	q.joinArgs = append(q.joinArgs, args...)
+}
+
+// Cond adds a condition, ANDed with the others
+func (q *Query) Cond(cond string, args ...interface{}) {
+	q.conds = append(q.conds, cond)
+	q.args = append(q.args, args...)
+}
+
+// In adds a condition matching column against any of values, each
+// written with placeholder. No values matches nothing.
+func (q *Query) In(column, placeholder string, values []interface{}) {
+	if len(values) == 0 {
+		q.Cond("1 = 0")
+		return
+	}
+	q.Cond(column+" IN ("+Placeholders(placeholder, len(values))+")", values...)
+}
+
+// Order adds a column to sort by, after any added before it
+func (q *Query) Order(column string, o Order) {
+	if o != Desc {
+		o = Asc
+	}
+	q.orders = append(q.orders, column+" "+string(o))
+}
+
+// Limit caps the number of rows returned, 0 being no limit
+func (q *Query) Limit(n int) {
+	q.limit = n
+}
+
+// Offset skips the first n rows
+func (q *Query) Offset(n int) {
+	q.offset = n
+}
+
+// Partition pages the rows of each value of column apart, ex. the first
+// 10 Desks of each Node: Limit, Offset and the orders apply within each
+// partition. Select then selects the partition after the columns.
+func (q *Query) Partition(column string) {
+	q.partition = column
+}
+
+// WhereClause returns the WHERE clause and its arguments
+func (q *Query) WhereClause() (string, []interface{}) {
+	if len(q.conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(q.conds, " AND "), q.args
+}
+
+// from returns table along with the joins, and their arguments
+func (q *Query) from(table string) (string, []interface{}) {
+	return strings.Join(append([]string{table}, q.joins...), " "), append([]interface{}{}, q.joinArgs...)
+}
+
+// Select completes stmt, a SELECT of columns FROM a table, with the
+// joins and clauses, and returns its arguments
+func (q *Query) Select(stmt string) (string, []interface{}) {
+	columns, table, _ := strings.Cut(strings.TrimPrefix(stmt, "SELECT "), " FROM ")
+	from, args := q.from(table)
+	if q.partition == "" {
+		clause, clauseArgs := q.Clause()
+		return strings.TrimSpace("SELECT " + columns + " FROM " + from + " " + clause), append(args, clauseArgs...)
+	}
+
+	where, whereArgs := q.WhereClause()
+	over := "PARTITION BY " + q.partition
+	if len(q.orders) > 0 {
+		over += " ORDER BY " + strings.Join(q.orders, ", ")
+	}
+	page := "PartitionRow > " + strconv.Itoa(q.offset)
+	if q.limit > 0 {
+		page += " AND PartitionRow <= " + strconv.Itoa(q.offset+q.limit)
+	}
+	return "SELECT " + columns + ", PartitionKey FROM (" +
+		strings.TrimSpace("SELECT "+columns+", "+q.partition+" AS PartitionKey, ROW_NUMBER() OVER ("+over+") AS PartitionRow FROM "+from+" "+where) +
+		") AS Partitioned WHERE " + page + " ORDER BY PartitionKey, PartitionRow", append(args, whereArgs...)
+}
+
+// Count returns the statement counting the rows of table matching the
+// joins and conditions, or, when partitioned, selecting each partition
+// and the count of its rows
+func (q *Query) Count(table string) (string, []interface{}) {
+	from, args := q.from(table)
+	where, whereArgs := q.WhereClause()
+	if q.partition == "" {
+		return strings.TrimSpace("SELECT COUNT(*) FROM " + from + " " + where), append(args, whereArgs...)
+	}
+	return strings.TrimSpace("SELECT "+q.partition+", COUNT(*) FROM "+from+" "+where) + " GROUP BY " + q.partition, append(args, whereArgs...)
+}
+
+// Clause returns the WHERE, ORDER BY, LIMIT and OFFSET clauses and their
+// arguments, to be appended to a SELECT
+func (q *Query) Clause() (string, []interface{}) {
+	where, args := q.WhereClause()
+	clauses := []string{}
+	if where != "" {
+		clauses = append(clauses, where)
+	}
+	if len(q.orders) > 0 {
+		clauses = append(clauses, "ORDER BY "+strings.Join(q.orders, ", "))
+	}
+	if q.limit > 0 {
+		clauses = append(clauses, "LIMIT "+strconv.Itoa(q.limit))
+	}
+	if q.offset > 0 {
+		if q.limit == 0 {
+			// MySQL needs a LIMIT to use OFFSET, the largest every
+			// dialect takes
+			clauses = append(clauses, "LIMIT 9223372036854775807")
+		}
+		clauses = append(clauses, "OFFSET "+strconv.Itoa(q.offset))
+	}
+	return strings.Join(clauses, " "), append([]interface{}{}, args...)
+}
@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain/desk"
+	"git.ottoq.com/otto-backend/valet/domain/domaintest"
+	"git.ottoq.com/otto-backend/valet/domain/node"
+)
+
+// TestRelationKeys loads relations by IDs written in lower case, which
+// are scanned back in upper case
+func TestRelationKeys(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	n := node.Random()
+	if err := n.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	d := desk.Random()
+	d.NodeID = strings.ToLower(n.ID)
+	if err := d.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := desk.LoadNodes(ctx, db, []*desk.Desk{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[d.NodeID] == nil || nodes[d.NodeID].ID != n.ID {
+		t.Fatalf("LoadNodes got %v, want %s keyed by %s", nodes, n.ID, d.NodeID)
+	}
+
+	groups, err := desk.ListByNodeIDs(ctx, db, d.NodeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || len(groups[d.NodeID]) != 1 || groups[d.NodeID][0].ID != d.ID {
+		t.Fatalf("ListByNodeIDs got %v, want %s keyed by %s", groups, d.ID, d.NodeID)
+	}
+	if list, err := desk.ListByNodeID(ctx, db, d.NodeID); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Fatalf("ListByNodeID got %d Desks, want 1", len(list))
+	}
+
+	if list, err := n.Desks(ctx, db); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].(*desk.Desk).ID != d.ID {
+		t.Fatalf("Desks got %v, want %s", list, d.ID)
+	}
+}
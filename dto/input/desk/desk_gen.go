@@ -0,0 +1,306 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 0b32acbccfe02b0d
+
+// Package inputdesk
+// Input DTOs for the Desk REST endpoints
+package inputdesk
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/wardn/uuid"
+
+	"git.ottoq.com/otto-backend/valet/domain/enums"
+	"git.ottoq.com/otto-backend/valet/dto/input"
+	"git.ottoq.com/otto-backend/valet/server"
+	"git.ottoq.com/otto-backend/valet/server/securecookie"
+)
+
+// Route is the path every Desk endpoint is served on
+const Route = "/desk"
+
+const (
+	CreateTypeID = "A38DEB757B4FE844646CFE0774D324D8"
+	ReadTypeID   = "0837E576F865D06C7CE8681C8FA92B1C"
+	UpdateTypeID = "326155337E6F2ED5812F2FD7763042EA"
+	DeleteTypeID = "7894EC06103F2D6F7A06D68F21D28F28"
+	ListTypeID   = "48AE23E3561B6DDEF966DC2A462B9C08"
+)
+
+const (
+	// DefaultLimit is the page size of a list without a limit
+	DefaultLimit = 50
+	// MaxLimit is the largest page a list returns
+	MaxLimit = 500
+)
+
+// Converters returns the converters of every Desk endpoint, for
+// registering on Route
+func Converters() server.HTTPConverterMap {
+	return server.HTTPConverterMap{
+		"POST":   FromCreateRequest,
+		"GET":    fromGetRequest,
+		"PUT":    FromUpdateRequest,
+		"PATCH":  FromPatchRequest,
+		"DELETE": FromDeleteRequest,
+	}
+}
+
+// fromGetRequest reads a single Desk when the request names
+// one, otherwise lists them
+func fromGetRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+	if r.URL.Query().Get("id") != "" {
+		return FromReadRequest(w, r, sc, sessionCookieName)
+	}
+	return FromListRequest(w, r, sc, sessionCookieName)
+}
+
+// base holds what every payload shares
+type base struct {
+	id   string
+	w    http.ResponseWriter
+	r    *http.Request
+	sesh string
+}
+
+func newBase(w http.ResponseWriter, r *http.Request, sesh string) base {
+	return base{id: uuid.NewNoDash(), w: w, r: r, sesh: sesh}
+}
+
+func (p *base) Writer() http.ResponseWriter {
+	return p.w
+}
+func (p *base) Request() *http.Request {
+	return p.r
+}
+func (p *base) SessionID() string {
+	return p.sesh
+}
+func (p *base) ID() string {
+	return p.id
+}
+
+// Key identifies a single Desk, read from the query string
+type Key struct {
+	ID string
+}
+
+func keyFromQuery(r *http.Request) (Key, error) {
+	q := r.URL.Query()
+	k := Key{
+		ID: q.Get("id"),
+	}
+	if k.ID == "" {
+		return k, &server.Error{Status: http.StatusBadRequest, Message: "missing id"}
+	}
+	return k, nil
+}
+
+////////////////////////////////////////////////////////////
+// CREATE
+////////////////////////////////////////////////////////////
+
+// CreateContents are the arguments of desk.New
+type CreateContents struct {
+	Name   string
+	Lat    float64
+	Lng    float64
+	NodeID string
+	State  enums.DeskState
+}
+
+type CreatePayload struct {
+	base
+	Contents CreateContents
+}
+
+func (p *CreatePayload) TypeID() string {
+	return CreateTypeID
+}
+
+// FromCreateRequest converts a POST with CreateContents as its body
+func FromCreateRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var c CreateContents
+	seshID, err := input.ParseRW(&c, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatePayload{base: newBase(w, r, seshID), Contents: c}, nil
+}
+
+////////////////////////////////////////////////////////////
+// READ
+////////////////////////////////////////////////////////////
+
+type ReadPayload struct {
+	base
+	Key Key
+}
+
+func (p *ReadPayload) TypeID() string {
+	return ReadTypeID
+}
+
+// FromReadRequest converts a GET naming the Desk in its query string
+func FromReadRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	k, err := keyFromQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	return &ReadPayload{base: newBase(w, r, seshID), Key: k}, nil
+}
+
+////////////////////////////////////////////////////////////
+// UPDATE
+////////////////////////////////////////////////////////////
+
+// UpdateContents names the Desk and holds its new values, along
+// with the version they're based on. A PUT must name the version.
+type UpdateContents struct {
+	ID      string
+	Name    string
+	Lat     float64
+	Lng     float64
+	NodeID  string
+	State   enums.DeskState
+	Version int64
+}
+
+// UpdatePayload of a PATCH holds the merge patch as well, Contents then
+// only having the members the patch names
+type UpdatePayload struct {
+	base
+	Contents UpdateContents
+	Patch    json.RawMessage // Patch is the body of a PATCH, nil for a PUT
+}
+
+func (p *UpdatePayload) TypeID() string {
+	return UpdateTypeID
+}
+
+// FromUpdateRequest converts a PUT with UpdateContents as its body
+func FromUpdateRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var c UpdateContents
+	seshID, err := input.ParseRW(&c, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	if c.ID == "" {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "missing ID"}
+	}
+	if c.Version == 0 {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "missing Version"}
+	}
+	return &UpdatePayload{base: newBase(w, r, seshID), Contents: c}, nil
+}
+
+// FromPatchRequest converts a PATCH with a JSON merge patch (RFC 7386) of
+// the Desk as its body. The patch must name the Desk and may
+// name the version it's based on.
+func FromPatchRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var patch json.RawMessage
+	seshID, err := input.ParseRW(&patch, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	var c UpdateContents
+	if err := json.Unmarshal(patch, &c); err != nil {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: err.Error()}
+	}
+	if c.ID == "" {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "missing ID"}
+	}
+	return &UpdatePayload{base: newBase(w, r, seshID), Contents: c, Patch: patch}, nil
+}
+
+////////////////////////////////////////////////////////////
+// DELETE
+////////////////////////////////////////////////////////////
+
+type DeletePayload struct {
+	base
+	Key Key
+}
+
+func (p *DeletePayload) TypeID() string {
+	return DeleteTypeID
+}
+
+// FromDeleteRequest converts a DELETE naming the Desk in its query string
+func FromDeleteRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	k, err := keyFromQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	return &DeletePayload{base: newBase(w, r, seshID), Key: k}, nil
+}
+
+////////////////////////////////////////////////////////////
+// LIST
+////////////////////////////////////////////////////////////
+
+type ListPayload struct {
+	base
+	Limit  int
+	Offset int
+}
+
+func (p *ListPayload) TypeID() string {
+	return ListTypeID
+}
+
+// FromListRequest converts a GET with optional limit and offset query
+// parameters. The limit defaults to DefaultLimit and is capped at MaxLimit.
+func FromListRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	p := &ListPayload{base: newBase(w, r, seshID), Limit: DefaultLimit}
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		if p.Limit, err = strconv.Atoi(v); err != nil || p.Limit < 1 {
+			return nil, &server.Error{Status: http.StatusBadRequest, Message: "limit must be a positive number"}
+		}
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+	if v := q.Get("offset"); v != "" {
+		if p.Offset, err = strconv.Atoi(v); err != nil || p.Offset < 0 {
+			return nil, &server.Error{Status: http.StatusBadRequest, Message: "offset must be a non negative number"}
+		}
+	}
+	return p, nil
+}
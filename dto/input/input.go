@@ -0,0 +1,130 @@
+// Package input holds helpers shared by the input DTO packages
+package input
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"time"
+
+	"git.ottoq.com/otto-backend/valet/server"
+	"git.ottoq.com/otto-backend/valet/server/securecookie"
+	"git.ottoq.com/otto-backend/valet/server/session"
+)
+
+// MaxBodySize is the largest request body ParseRW reads
+const MaxBodySize = 1024 * 64
+
+// CSRFHeader names the header every response sets to the CSRF token of
+// the session, which a request other than a GET, HEAD or OPTIONS must
+// send back in it. Only a script of the page's origin can read the
+// token, so a form another site posts with the session cookie fails.
+const CSRFHeader = "X-CSRF-Token"
+
+// Parses object and returns a sessionID
+func ParseRW(
+	obj interface{},
+	w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config, sessionCookieName string) (string, error) {
+	return ParseRWLimit(obj, MaxBodySize, w, r, sc, sessionCookieName)
+}
+
+// ParseRWLimit is ParseRW reading at most maxBodySize bytes of the body.
+// It fails with a 403 *server.Error if the request should carry the CSRF
+// token and doesn't, and a 415 if its body isn't JSON.
+func ParseRWLimit(
+	obj interface{}, maxBodySize int64,
+	w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config, sessionCookieName string) (string, error) {
+
+	if r == nil {
+		return "", fmt.Errorf("NIL REQUEST")
+	}
+	cookies := r.Cookies()
+
+	// decode the session info from the cookies
+	now := time.Now().UTC()
+	sesh, err := session.FromCookies(cookies, sessionCookieName, sc)
+	if err != nil {
+		return "", err
+	}
+	if sesh == nil {
+		return "", fmt.Errorf("NIL SESSION")
+	}
+	if sesh.Timestamp.After(now) {
+		if err := session.SetCookie(sesh, sessionCookieName, w, sc); err != nil {
+			return "", err
+		}
+	}
+	if w != nil {
+		w.Header().Set(CSRFHeader, sesh.Token)
+	}
+	if !safeMethod(r.Method) && !validToken(r.Header.Get(CSRFHeader), sesh.Token) {
+		return "", &server.Error{Status: http.StatusForbidden, Message: "missing or wrong " + CSRFHeader}
+	}
+
+	// unmarshall the request into the object interface
+	raw, err := requestBody(maxBodySize, w, r)
+	if err != nil {
+		return "", err
+	}
+
+	if len(raw) == 0 {
+		return sesh.ID, nil
+	}
+	if !jsonBody(r.Header.Get("Content-Type")) {
+		return "", &server.Error{Status: http.StatusUnsupportedMediaType, Message: "the body must be application/json or application/merge-patch+json"}
+	}
+	if err := json.Unmarshal([]byte(raw), &obj); err != nil {
+		return "", err
+	}
+	return sesh.ID, nil
+}
+
+// safeMethod reports whether method only reads, so needn't carry the
+// CSRF token
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// validToken reports whether a request sent the CSRF token of its session
+func validToken(sent, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(sent), []byte(token)) == 1
+}
+
+// jsonBody reports whether a body of contentType is JSON or a JSON merge
+// patch, the only bodies a browser won't post to another origin without
+// asking first
+func jsonBody(contentType string) bool {
+	t, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (t == "application/json" || t == "application/merge-patch+json")
+}
+
+// requestBody reads the body of r, failing with a 413 *server.Error if
+// it's longer than maxBodySize and a 400 if it can't be read
+func requestBody(maxBodySize int64, w http.ResponseWriter, r *http.Request) (string, error) {
+	if r == nil || r.Body == nil {
+		return "", nil
+	}
+	// enforce a size limit to prevent abuse
+	mbr := http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	// read the bytes
+	b, err := ioutil.ReadAll(mbr)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", &server.Error{Status: http.StatusRequestEntityTooLarge}
+		}
+		return "", &server.Error{Status: http.StatusBadRequest, Message: err.Error()}
+	}
+	return string(b), nil
+}
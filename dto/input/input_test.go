@@ -0,0 +1,115 @@
+package input
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"git.ottoq.com/otto-backend/valet/server"
+	"git.ottoq.com/otto-backend/valet/server/securecookie"
+	"git.ottoq.com/otto-backend/valet/server/session"
+)
+
+func TestRequestBody(t *testing.T) {
+	tests := []struct {
+		body   string
+		want   string
+		status int // of the *server.Error, 0 for none
+	}{
+		{"", "", 0},
+		{`{"ID": "1"}`, `{"ID": "1"}`, 0},
+		{strings.Repeat("x", 16), strings.Repeat("x", 16), 0},
+		{strings.Repeat("x", 17), "", http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("PUT", "/", strings.NewReader(test.body))
+		got, err := requestBody(16, httptest.NewRecorder(), r)
+		if test.status != 0 {
+			if e, ok := err.(*server.Error); !ok || e.Status != test.status {
+				t.Errorf("requestBody of %d bytes got error %v, want status %d", len(test.body), err, test.status)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("requestBody of %q = %q, %v", test.body, got, err)
+		}
+	}
+
+	r := httptest.NewRequest("PUT", "/", iotest.ErrReader(errors.New("reset")))
+	if _, err := requestBody(16, httptest.NewRecorder(), r); err == nil {
+		t.Error("requestBody of a failing body succeeded")
+	} else if e, ok := err.(*server.Error); !ok || e.Status != http.StatusBadRequest {
+		t.Errorf("requestBody of a failing body got %v, want status 400", err)
+	}
+}
+
+// TestParseRW sends requests with the cookie of a session, whose token
+// only a request that changes data must send back
+func TestParseRW(t *testing.T) {
+	sc, err := securecookie.New(securecookie.GenerateRandomKey(32), securecookie.GenerateRandomKey(32), "", "/", 0, 3600, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sesh, err := session.FromCookies(nil, "v", sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	if err := session.SetCookie(sesh, "v", w, sc); err != nil {
+		t.Fatal(err)
+	}
+	cookie := w.Result().Cookies()[0]
+
+	tests := []struct {
+		method, contentType, token, body string
+		status                           int // of the *server.Error, 0 for none
+	}{
+		{"GET", "", "", "", 0},
+		{"GET", "text/plain", "", `{"ID": "1"}`, http.StatusUnsupportedMediaType},
+		{"POST", "application/json", sesh.Token, `{"ID": "1"}`, 0},
+		{"POST", "application/json; charset=utf-8", sesh.Token, `{"ID": "1"}`, 0},
+		{"PATCH", "application/merge-patch+json", sesh.Token, `{"ID": "1"}`, 0},
+		{"DELETE", "", sesh.Token, "", 0},
+		{"POST", "application/json", "", `{"ID": "1"}`, http.StatusForbidden},
+		{"DELETE", "", sesh.Token + "x", "", http.StatusForbidden},
+		{"POST", "text/plain", sesh.Token, `{"ID": "1"}`, http.StatusUnsupportedMediaType},
+		{"POST", "application/x-www-form-urlencoded", sesh.Token, "ID=1", http.StatusUnsupportedMediaType},
+		{"PUT", "", sesh.Token, `{"ID": "1"}`, http.StatusUnsupportedMediaType},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/", strings.NewReader(test.body))
+		r.AddCookie(cookie)
+		if test.contentType != "" {
+			r.Header.Set("Content-Type", test.contentType)
+		}
+		if test.token != "" {
+			r.Header.Set(CSRFHeader, test.token)
+		}
+		w := httptest.NewRecorder()
+		var obj struct{ ID string }
+		id, err := ParseRW(&obj, w, r, sc, "v")
+		if test.status != 0 {
+			if e, ok := err.(*server.Error); !ok || e.Status != test.status {
+				t.Errorf("%s %s got error %v, want status %d", test.method, test.contentType, err, test.status)
+			}
+			continue
+		}
+		if err != nil || id != sesh.ID {
+			t.Errorf("%s %s got session %s, %v", test.method, test.contentType, id, err)
+		}
+		if got := w.Header().Get(CSRFHeader); got != sesh.Token {
+			t.Errorf("%s %s responded with token %q", test.method, test.contentType, got)
+		}
+	}
+
+	// a new session has no token to send back yet
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"ID": "1"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(CSRFHeader, sesh.Token)
+	if _, err := ParseRW(nil, httptest.NewRecorder(), r, sc, "v"); err == nil {
+		t.Error("a POST without a session succeeded")
+	}
+}
@@ -0,0 +1,291 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 1e18f6981b1a2e1e
+
+// Package inputnode
+// Input DTOs for the Node REST endpoints
+package inputnode
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/wardn/uuid"
+
+	"git.ottoq.com/otto-backend/valet/dto/input"
+	"git.ottoq.com/otto-backend/valet/server"
+	"git.ottoq.com/otto-backend/valet/server/securecookie"
+)
+
+// Route is the path every Node endpoint is served on
+const Route = "/node"
+
+const (
+	CreateTypeID = "FB77C6B9087AFC6B6B8FE30CB76B5423"
+	ReadTypeID   = "F82E683BE13A9B5CA4581349860AB61D"
+	UpdateTypeID = "63EE7BF0669E9801375B7CE8B3770480"
+	DeleteTypeID = "B544B65D093636E5CEDEAFEC37AAC16D"
+	ListTypeID   = "EFBBD82DAE7110F82C75D7BD05E5AD7D"
+)
+
+const (
+	// DefaultLimit is the page size of a list without a limit
+	DefaultLimit = 50
+	// MaxLimit is the largest page a list returns
+	MaxLimit = 500
+)
+
+// Converters returns the converters of every Node endpoint, for
+// registering on Route
+func Converters() server.HTTPConverterMap {
+	return server.HTTPConverterMap{
+		"POST":   FromCreateRequest,
+		"GET":    fromGetRequest,
+		"PUT":    FromUpdateRequest,
+		"PATCH":  FromPatchRequest,
+		"DELETE": FromDeleteRequest,
+	}
+}
+
+// fromGetRequest reads a single Node when the request names
+// one, otherwise lists them
+func fromGetRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+	if r.URL.Query().Get("id") != "" {
+		return FromReadRequest(w, r, sc, sessionCookieName)
+	}
+	return FromListRequest(w, r, sc, sessionCookieName)
+}
+
+// base holds what every payload shares
+type base struct {
+	id   string
+	w    http.ResponseWriter
+	r    *http.Request
+	sesh string
+}
+
+func newBase(w http.ResponseWriter, r *http.Request, sesh string) base {
+	return base{id: uuid.NewNoDash(), w: w, r: r, sesh: sesh}
+}
+
+func (p *base) Writer() http.ResponseWriter {
+	return p.w
+}
+func (p *base) Request() *http.Request {
+	return p.r
+}
+func (p *base) SessionID() string {
+	return p.sesh
+}
+func (p *base) ID() string {
+	return p.id
+}
+
+// Key identifies a single Node, read from the query string
+type Key struct {
+	ID string
+}
+
+func keyFromQuery(r *http.Request) (Key, error) {
+	q := r.URL.Query()
+	k := Key{
+		ID: q.Get("id"),
+	}
+	if k.ID == "" {
+		return k, &server.Error{Status: http.StatusBadRequest, Message: "missing id"}
+	}
+	return k, nil
+}
+
+////////////////////////////////////////////////////////////
+// CREATE
+////////////////////////////////////////////////////////////
+
+// CreateContents are the arguments of node.New
+type CreateContents struct {
+	Name string
+}
+
+type CreatePayload struct {
+	base
+	Contents CreateContents
+}
+
+func (p *CreatePayload) TypeID() string {
+	return CreateTypeID
+}
+
+// FromCreateRequest converts a POST with CreateContents as its body
+func FromCreateRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var c CreateContents
+	seshID, err := input.ParseRW(&c, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatePayload{base: newBase(w, r, seshID), Contents: c}, nil
+}
+
+////////////////////////////////////////////////////////////
+// READ
+////////////////////////////////////////////////////////////
+
+type ReadPayload struct {
+	base
+	Key Key
+}
+
+func (p *ReadPayload) TypeID() string {
+	return ReadTypeID
+}
+
+// FromReadRequest converts a GET naming the Node in its query string
+func FromReadRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	k, err := keyFromQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	return &ReadPayload{base: newBase(w, r, seshID), Key: k}, nil
+}
+
+////////////////////////////////////////////////////////////
+// UPDATE
+////////////////////////////////////////////////////////////
+
+// UpdateContents names the Node and holds its new values
+type UpdateContents struct {
+	ID   string
+	Name string
+}
+
+// UpdatePayload of a PATCH holds the merge patch as well, Contents then
+// only having the members the patch names
+type UpdatePayload struct {
+	base
+	Contents UpdateContents
+	Patch    json.RawMessage // Patch is the body of a PATCH, nil for a PUT
+}
+
+func (p *UpdatePayload) TypeID() string {
+	return UpdateTypeID
+}
+
+// FromUpdateRequest converts a PUT with UpdateContents as its body
+func FromUpdateRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var c UpdateContents
+	seshID, err := input.ParseRW(&c, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	if c.ID == "" {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "missing ID"}
+	}
+	return &UpdatePayload{base: newBase(w, r, seshID), Contents: c}, nil
+}
+
+// FromPatchRequest converts a PATCH with a JSON merge patch (RFC 7386) of
+// the Node as its body. The patch must name the Node.
+func FromPatchRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var patch json.RawMessage
+	seshID, err := input.ParseRW(&patch, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	var c UpdateContents
+	if err := json.Unmarshal(patch, &c); err != nil {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: err.Error()}
+	}
+	if c.ID == "" {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "missing ID"}
+	}
+	return &UpdatePayload{base: newBase(w, r, seshID), Contents: c, Patch: patch}, nil
+}
+
+////////////////////////////////////////////////////////////
+// DELETE
+////////////////////////////////////////////////////////////
+
+type DeletePayload struct {
+	base
+	Key Key
+}
+
+func (p *DeletePayload) TypeID() string {
+	return DeleteTypeID
+}
+
+// FromDeleteRequest converts a DELETE naming the Node in its query string
+func FromDeleteRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	k, err := keyFromQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	return &DeletePayload{base: newBase(w, r, seshID), Key: k}, nil
+}
+
+////////////////////////////////////////////////////////////
+// LIST
+////////////////////////////////////////////////////////////
+
+type ListPayload struct {
+	base
+	Limit  int
+	Offset int
+}
+
+func (p *ListPayload) TypeID() string {
+	return ListTypeID
+}
+
+// FromListRequest converts a GET with optional limit and offset query
+// parameters. The limit defaults to DefaultLimit and is capped at MaxLimit.
+func FromListRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	p := &ListPayload{base: newBase(w, r, seshID), Limit: DefaultLimit}
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		if p.Limit, err = strconv.Atoi(v); err != nil || p.Limit < 1 {
+			return nil, &server.Error{Status: http.StatusBadRequest, Message: "limit must be a positive number"}
+		}
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+	if v := q.Get("offset"); v != "" {
+		if p.Offset, err = strconv.Atoi(v); err != nil || p.Offset < 0 {
+			return nil, &server.Error{Status: http.StatusBadRequest, Message: "offset must be a non negative number"}
+		}
+	}
+	return p, nil
+}
@@ -1,7 +1,13 @@
 package entity
 
 import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/wardn/uuid"
@@ -24,24 +30,277 @@ func Now() time.Time {
 // RANDOM
 /////////////////////////////////////////////////////////
 
+// source is the source of random, seeded with the time unless Seed is
+// called
+var source = &lockedSource{src: rand.NewSource(time.Now().UnixNano()).(rand.Source64)}
+
+// random is used by every RAND function
+var random = rand.New(source)
+
+// Seed makes the RAND functions return the same values for the same seed,
+// apart from times which are relative to now. It's safe to call while
+// they're in use.
+func Seed(seed int64) {
+	source.Seed(seed)
+}
+
+// lockedSource lets random be used from several goroutines like the
+// math/rand functions
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
 var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
 func RANDstring() string {
 	b := make([]rune, 10)
 	for i := range b {
-		b[i] = letterRunes[rand.Intn(len(letterRunes))]
+		b[i] = letterRunes[random.Intn(len(letterRunes))]
 	}
 	return string(b)
 }
 
 func RANDfloat64() float64 {
-	return rand.Float64()
+	return random.Float64()
 }
 
 func RANDint() int {
-	return rand.Int()
+	return random.Int()
+}
+
+func RANDint32() int32 {
+	return random.Int31()
+}
+
+func RANDint64() int64 {
+	return random.Int63()
+}
+
+func RANDuint() uint {
+	return uint(random.Uint32())
+}
+
+// RANDrange returns a number in [min, max)
+func RANDrange(min, max float64) float64 {
+	return min + random.Float64()*(max-min)
+}
+
+// RANDchoice returns one of the given values
+func RANDchoice(values ...string) string {
+	return values[random.Intn(len(values))]
+}
+
+// RANDintn returns a number in [0, n)
+func RANDintn(n int) int {
+	return random.Intn(n)
+}
+
+// RANDuuid returns an ID like UUID() drawn from the seeded source, for
+// reproducible data
+func RANDuuid() string {
+	b := randomBytes(16)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+func RANDbool() bool {
+	return random.Intn(2) == 1
+}
+
+// RANDtext returns a few random words
+func RANDtext() string {
+	words := make([]string, 5+random.Intn(20))
+	for i := range words {
+		words[i] = RANDstring()
+	}
+	return strings.Join(words, " ")
+}
+
+// RANDdecimal returns a random number that fits DECIMAL(precision, scale)
+func RANDdecimal(precision, scale int) string {
+	digits := make([]byte, precision)
+	for i := range digits {
+		digits[i] = byte('0' + random.Intn(10))
+	}
+	whole := strings.TrimLeft(string(digits[:precision-scale]), "0")
+	if whole == "" {
+		whole = "0"
+	}
+	if scale == 0 {
+		return whole
+	}
+	return whole + "." + string(digits[precision-scale:])
+}
+
+func RANDjson() json.RawMessage {
+	b, _ := json.Marshal(map[string]string{RANDstring(): RANDstring()})
+	return b
+}
+
+func RANDbytes() []byte {
+	return randomBytes(16)
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+// randomBytes returns n random bytes. random.Read isn't safe to use from
+// several goroutines.
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(random.Intn(256))
+	}
+	return b
+}
+
+// RANDtime returns a time within a year of now, truncated to the second
+// like a DATETIME column
+func RANDtime() time.Time {
+	offset := time.Duration(random.Int63n(int64(365*24*time.Hour))) - 182*24*time.Hour
+	return Now().Add(offset).Truncate(time.Second)
+}
+
+/////////////////////////////////////////////////////////
+// REALISTIC
+/////////////////////////////////////////////////////////
+
+var (
+	firstNames = []string{"Ava", "Ben", "Carla", "David", "Elena", "Farah", "George", "Hana", "Ivan", "Julia",
+		"Kenji", "Laura", "Marco", "Nina", "Omar", "Priya", "Quinn", "Rosa", "Sam", "Tariq",
+		"Uma", "Victor", "Wendy", "Xavier", "Yara", "Zoe"}
+	lastNames = []string{"Anderson", "Brown", "Chen", "Diaz", "Evans", "Fischer", "Garcia", "Hughes", "Ito", "Jensen",
+		"Kim", "Lopez", "Martin", "Novak", "Okafor", "Patel", "Quintero", "Rossi", "Silva", "Thompson",
+		"Ueda", "Vargas", "Walker", "Xu", "Young", "Zimmerman"}
+	companyWords = []string{"Harbor", "Summit", "Union", "Pioneer", "Bayside", "Granite", "Metro", "Northgate",
+		"Riverside", "Lakeshore", "Crescent", "Beacon", "Keystone", "Parkway", "Cedar", "Liberty"}
+	companySuffixes = []string{"Parking", "Valet", "Garages", "Mobility", "Hospitality", "Properties", "Group"}
+	streetNames     = []string{"Main", "Oak", "Market", "Mission", "Broadway", "Elm", "Park", "Pine", "Lake", "Hill",
+		"Washington", "Maple", "Cedar", "Sunset", "River", "Church"}
+	streetSuffixes = []string{"St", "Ave", "Blvd", "Rd", "Way", "Ln", "Dr"}
+	emailDomains   = []string{"example.com", "example.org", "example.net"}
+)
+
+// place is a city RANDlatitude and RANDlongitude put points around
+type place struct {
+	City     string
+	Lat, Lng float64
+}
+
+var places = []place{
+	{"New York", 40.7128, -74.0060},
+	{"Los Angeles", 34.0522, -118.2437},
+	{"Chicago", 41.8781, -87.6298},
+	{"Houston", 29.7604, -95.3698},
+	{"San Francisco", 37.7749, -122.4194},
+	{"Seattle", 47.6062, -122.3321},
+	{"Miami", 25.7617, -80.1918},
+	{"Toronto", 43.6532, -79.3832},
+	{"Mexico City", 19.4326, -99.1332},
+	{"London", 51.5074, -0.1278},
+	{"Paris", 48.8566, 2.3522},
+	{"Berlin", 52.5200, 13.4050},
+	{"Madrid", 40.4168, -3.7038},
+	{"Tokyo", 35.6762, 139.6503},
+	{"Singapore", 1.3521, 103.8198},
+	{"Sydney", -33.8688, 151.2093},
+	{"Sao Paulo", -23.5505, -46.6333},
+	{"Dubai", 25.2048, 55.2708},
+}
+
+// lastPlace is the place of the last RANDlatitude, so the RANDlongitude
+// that follows it lands in the same city
+var (
+	lastPlaceMu sync.Mutex
+	lastPlace   = -1
+)
+
+// RANDfirstName returns a first name
+func RANDfirstName() string {
+	return firstNames[random.Intn(len(firstNames))]
+}
+
+// RANDlastName returns a last name
+func RANDlastName() string {
+	return lastNames[random.Intn(len(lastNames))]
+}
+
+// RANDname returns a person's full name, ex. "Julia Okafor"
+func RANDname() string {
+	return RANDfirstName() + " " + RANDlastName()
+}
+
+// RANDcompany returns a company name, ex. "Granite Valet"
+func RANDcompany() string {
+	return companyWords[random.Intn(len(companyWords))] + " " + companySuffixes[random.Intn(len(companySuffixes))]
+}
+
+// RANDcity returns the name of a city
+func RANDcity() string {
+	return places[random.Intn(len(places))].City
+}
+
+// RANDstreet returns a street address, ex. "1200 Market St"
+func RANDstreet() string {
+	return strconv.Itoa(1+random.Intn(9999)) + " " + streetNames[random.Intn(len(streetNames))] + " " +
+		streetSuffixes[random.Intn(len(streetSuffixes))]
+}
+
+// RANDemail returns an address at a reserved example domain
+func RANDemail() string {
+	return strings.ToLower(RANDfirstName()+"."+RANDlastName()) + strconv.Itoa(random.Intn(100)) +
+		"@" + emailDomains[random.Intn(len(emailDomains))]
+}
+
+// RANDphone returns a phone number in the reserved 555-01XX range
+func RANDphone() string {
+	return fmt.Sprintf("+1-%03d-555-01%02d", 200+random.Intn(800), random.Intn(100))
+}
+
+// RANDlatitude returns a latitude within about 10km of a city, the one
+// the next RANDlongitude is near
+func RANDlatitude() float64 {
+	lastPlaceMu.Lock()
+	defer lastPlaceMu.Unlock()
+	lastPlace = random.Intn(len(places))
+	return places[lastPlace].Lat + RANDrange(-0.1, 0.1)
+}
+
+// RANDlongitude returns a longitude within about 10km of the city of the
+// last RANDlatitude, or of a random city
+func RANDlongitude() float64 {
+	lastPlaceMu.Lock()
+	defer lastPlaceMu.Unlock()
+	i := lastPlace
+	if i < 0 {
+		i = random.Intn(len(places))
+	}
+	lastPlace = -1
+	return places[i].Lng + RANDrange(-0.1, 0.1)
+}
+
+// Truncate returns at most n characters of s
+func Truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
 }
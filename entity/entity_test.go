@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestSeed reseeds while the RAND functions are in use, then checks the
+// same seed repeats the same values. Run with -race.
+func TestSeed(t *testing.T) {
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					RANDstring()
+				}
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		Seed(int64(i))
+	}
+	close(done)
+	wg.Wait()
+
+	Seed(7)
+	want := RANDstring() + RANDuuid()
+	Seed(7)
+	if got := RANDstring() + RANDuuid(); got != want {
+		t.Errorf("seed 7 gave %s, then %s", want, got)
+	}
+}
@@ -1,8 +1,5 @@
 package database
 
-import (
-	"os"
-	"path"
-)
-
-var BasePath = path.Join(os.Getenv("GOPATH"), "/src/git.ottoq.com/otto-backend/valet/database")
+// BasePath is where the database package is generated, relative to the
+// repository root
+var BasePath = "database"
@@ -12,58 +12,58 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
+
+	"git.ottoq.com/otto-backend/valet/domain"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
 type Database struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect domain.Dialect
 }
 
+// New connects to the MySQL database. Tables are created and changed by
+// migrations, see MigrateUp.
 func New(addr, dbname, user, pass string) (*Database, error) {
-	var err error
-	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@%s/%s?parseTime=true", user, pass, addr, dbname))
+	return Open(domain.MySQL, fmt.Sprintf("%s:%s@%s/%s?parseTime=true", user, pass, addr, dbname))
+}
+
+// Open connects to the database of the given dialect at dsn, whose driver
+// must be compiled in: MySQL's always is, Postgres' with the postgres
+// build tag and SQLite's with the sqlite tag. A MySQL dsn needs
+// parseTime=true.
+func Open(dialect domain.Dialect, dsn string) (*Database, error) {
+	db, err := sql.Open(string(dialect), dsn)
 	if err != nil {
 		return nil, err
 	}
-	err = EnsureTablesExist(db)
-	if err != nil {
+	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 	return &Database{
-		db: db,
+		db:      db,
+		dialect: dialect,
 	}, nil
 }
 
-func EnsureTablesExist(db *sql.DB) error {
-	tablequery := "SHOW TABLES LIKE '%s'"
-	for _, ts := range Tables {
-		var table string
-		err := db.QueryRow(fmt.Sprintf(tablequery, ts.Table)).Scan(&table)
-		if err != nil {
-			_, err := db.Exec(ts.Schema)
-			if err != nil {
-				log.Fatal(err)
-			}
-			log.Printf("created TABLE %s\n", ts.Table)
-		} 
-	}
-	return nil
-}
-
 // TableSchema holds an association between a table and its schema
 type TableSchema struct {
 	Table string
-	Schema string
+	Schema domain.Statements // Schema creates the table in each dialect
 }
 
-// Tables is an array of TableSchemas
+// Tables is an array of TableSchemas, referenced tables first
 var Tables = []TableSchema{
 	{{ range $k, $v := . -}}
 	TableSchema{
 		Table: "{{ $v.Name.UpperCamel }}",
-		Schema: ` + "`" + `{{ $v.SQLSchema }}` + "`" + `,
+		Schema: domain.Statements{
+			{{- range $v.SQLSchemas }}
+			{{ .Dialect.Const }}: {{ literal .SQL }},
+			{{- end }}
+		},
 	},
 	{{ end }}
 }
@@ -0,0 +1,133 @@
+// Package diff renders line based unified diffs for the generator's
+// dry run output
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change
+const context = 3
+
+type op struct {
+	kind byte // kind is ' ', '-' or '+'
+	line string
+}
+
+// Unified returns a unified diff turning a into b, or "" if they're equal
+func Unified(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := lines(split(a), split(b))
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", aName, bName)
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		// extend the hunk while changes are within 2*context lines
+		end, same := start, 0
+		for i := start; i < len(ops) && same <= 2*context; i++ {
+			if ops[i].kind == ' ' {
+				same++
+			} else {
+				same, end = 0, i+1
+			}
+		}
+		from, to := max(start-context, 0), min(end+context, len(ops))
+		writeHunk(out, ops, from, to)
+		start = to
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, ops []op, from, to int) {
+	aStart, bStart := 1, 1
+	for _, o := range ops[:from] {
+		if o.kind != '+' {
+			aStart++
+		}
+		if o.kind != '-' {
+			bStart++
+		}
+	}
+	aLen, bLen := 0, 0
+	for _, o := range ops[from:to] {
+		if o.kind != '+' {
+			aLen++
+		}
+		if o.kind != '-' {
+			bLen++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+	for _, o := range ops[from:to] {
+		fmt.Fprintf(out, "%c%s\n", o.kind, o.line)
+	}
+}
+
+// lines returns the edit script from a longest common subsequence table
+func lines(a, b []string) []op {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	ops := []op{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,228 @@
+// Package docs generates the entity-relationship diagram and the data
+// dictionary of the domain model, so the schema can be learned without
+// reading description.go
+package docs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+)
+
+// BasePath is where the documents are generated, relative to the
+// repository root
+var BasePath = "docs"
+
+// Table is an object as documented
+type Table struct {
+	domain.Object
+	Columns    []Column
+	Indexes    []Index
+	Edges      []Edge // Edges are the foreign keys of the table
+	References []Edge // References are the foreign keys of other tables referencing this one
+}
+
+// Column is a parameter as documented
+type Column struct {
+	domain.Parameter
+	// Go, MySQL, Postgres and SQLite are the type of the column in Go and
+	// in each dialect, as Markdown code
+	Go, MySQL, Postgres, SQLite string
+	Keys                        []string // Keys are PK, FK and UK as the column is part of them
+	Notes                       string   // Notes lists the constraints Validate() checks and how the column is set
+}
+
+// KeyList returns the keys separated by commas
+func (c Column) KeyList() string {
+	return strings.Join(c.Keys, ", ")
+}
+
+// Mermaid returns the column as a Mermaid erDiagram attribute, typed
+// with its MySQL type, cut before its arguments when Mermaid can't take
+// them, ex. ENUM('open','closed') is ENUM
+func (c Column) Mermaid() string {
+	t := c.SQLType
+	if strings.IndexFunc(t, func(r rune) bool {
+		return !(r == '_' || r == '(' || r == ')' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	}) >= 0 {
+		if i := strings.Index(t, "("); i >= 0 {
+			t = t[:i]
+		}
+		t = strings.Replace(strings.TrimSpace(t), " ", "_", -1)
+	}
+	s := t + " " + c.Name.UpperCamel
+	if len(c.Keys) > 0 {
+		s += " " + c.KeyList()
+	}
+	return s
+}
+
+// Index is an index of a table, its primary key included
+type Index struct {
+	Name    string
+	Columns string
+	Unique  bool
+}
+
+// Edge is a foreign key from a column of Table to one of Other
+type Edge struct {
+	Table       string
+	Column      string
+	Other       string
+	OtherColumn string
+	Cascade     bool
+	Unique      bool // Unique is set when the column alone is unique, so Other has at most one Table
+}
+
+// OnDelete describes what deleting the referenced row does
+func (e Edge) OnDelete() string {
+	if e.Cascade {
+		return "cascade"
+	}
+	return "restrict"
+}
+
+// Anchor links the section of Other in the data dictionary
+func (e Edge) Anchor() string {
+	return strings.ToLower(e.Other)
+}
+
+// TableAnchor links the section of Table in the data dictionary
+func (e Edge) TableAnchor() string {
+	return strings.ToLower(e.Table)
+}
+
+// Mermaid returns the relationship in a Mermaid erDiagram
+func (e Edge) Mermaid() string {
+	many := "o{"
+	if e.Unique {
+		many = "o|"
+	}
+	return fmt.Sprintf("%s ||--%s %s : %s", e.Other, many, e.Table, e.Column)
+}
+
+// Tables returns a Table for each object, in the order given
+func Tables(objects []domain.Object) []Table {
+	tables := []Table{}
+	for _, o := range objects {
+		tables = append(tables, table(o))
+	}
+	for i := range tables {
+		for _, t := range tables {
+			for _, e := range t.Edges {
+				if e.Other == tables[i].Name.UpperCamel {
+					tables[i].References = append(tables[i].References, e)
+				}
+			}
+		}
+	}
+	return tables
+}
+
+func table(o domain.Object) Table {
+	t := Table{Object: o}
+	unique := map[string]bool{}
+	primary := []string{}
+	for _, p := range o.PrimaryKeys() {
+		primary = append(primary, p.Name.UpperCamel)
+	}
+	if len(primary) > 0 {
+		t.Indexes = append(t.Indexes, Index{Name: "PRIMARY", Columns: strings.Join(primary, ", "), Unique: true})
+	}
+	if len(primary) == 1 {
+		unique[primary[0]] = true
+	}
+	for _, i := range o.AllIndexes() {
+		columns := []string{}
+		for _, c := range i.Columns {
+			if c.Length > 0 {
+				columns = append(columns, fmt.Sprintf("%s(%d)", c.Name, c.Length))
+			} else {
+				columns = append(columns, c.Name)
+			}
+		}
+		t.Indexes = append(t.Indexes, Index{Name: i.Name, Columns: strings.Join(columns, ", "), Unique: i.Unique})
+		if i.Unique && len(i.Columns) == 1 && i.Columns[0].Length == 0 {
+			unique[i.Columns[0].Name] = true
+		}
+	}
+	for _, p := range o.Parameters {
+		c := Column{
+			Parameter: p,
+			Go:        code(p.GoType()),
+			MySQL:     code(domain.MySQL.ColumnType(p)),
+			Postgres:  code(domain.Postgres.ColumnType(p)),
+			SQLite:    code(domain.SQLite.ColumnType(p)),
+			Notes:     notes(p),
+		}
+		if p.PrimaryKey {
+			c.Keys = append(c.Keys, "PK")
+		}
+		if p.ForeignKey != nil {
+			c.Keys = append(c.Keys, "FK")
+			t.Edges = append(t.Edges, Edge{
+				Table:       o.Name.UpperCamel,
+				Column:      p.Name.UpperCamel,
+				Other:       p.ForeignKey.Table,
+				OtherColumn: p.ForeignKey.Column,
+				Cascade:     p.ForeignKey.Cascade,
+				Unique:      unique[p.Name.UpperCamel],
+			})
+		}
+		if unique[p.Name.UpperCamel] && !p.PrimaryKey {
+			c.Keys = append(c.Keys, "UK")
+		}
+		t.Columns = append(t.Columns, c)
+	}
+	return t
+}
+
+// notes describes the constraints of a parameter and how it's set
+func notes(p domain.Parameter) string {
+	n := []string{}
+	switch {
+	case p.Audit != "":
+		n = append(n, "audit, set on write")
+	case p.Version:
+		n = append(n, "version, incremented by every update")
+	case p.ConstructorOverride != "":
+		n = append(n, "set by New to "+code(p.ConstructorOverride))
+	}
+	if p.Required {
+		n = append(n, "required")
+	}
+	if p.Hex() {
+		n = append(n, "32 hexadecimal characters")
+	}
+	if c := p.MaxChars(); c > 0 {
+		n = append(n, fmt.Sprintf("at most %d characters", c))
+	}
+	if p.Min != nil {
+		n = append(n, fmt.Sprintf("at least %v", *p.Min))
+	}
+	if p.Max != nil {
+		n = append(n, fmt.Sprintf("at most %v", *p.Max))
+	}
+	if p.Pattern != "" {
+		n = append(n, "matches "+code(p.Pattern))
+	}
+	if len(p.OneOf) > 0 {
+		values := []string{}
+		for _, v := range p.OneOf {
+			values = append(values, code(v))
+		}
+		n = append(n, "one of "+strings.Join(values, ", "))
+	}
+	return strings.Join(n, "; ")
+}
+
+// code returns s as Markdown code, escaped for a table cell
+func code(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
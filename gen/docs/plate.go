@@ -0,0 +1,93 @@
+package docs
+
+var Plate = map[string]string{
+	"Dictionary": `<!-- go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+-->
+
+# Data dictionary
+
+The tables of the domain model, as described by gen/domain/schema.json:
+their columns, keys, indexes and foreign keys. schema_gen.dot draws the
+same diagram for Graphviz.
+
+~~~mermaid
+erDiagram
+{{- range . }}
+    {{ .Name.UpperCamel }} {
+    {{- range .Columns }}
+        {{ .Mermaid }}
+    {{- end }}
+    }
+{{- end }}
+{{- range . }}{{ range .Edges }}
+    {{ .Mermaid }}
+{{- end }}{{ end }}
+~~~
+
+## Tables
+{{ range . }}
+- [{{ .Name.UpperCamel }}](#{{ .Name.Lower }}){{ if .Description }}: {{ .Description }}{{ end }}
+{{- end }}
+{{ range . }}
+## {{ .Name.UpperCamel }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}
+| Column | Go | MySQL | PostgreSQL | SQLite | Null | Key | Notes |
+| --- | --- | --- | --- | --- | --- | --- | --- |
+{{- range .Columns }}
+| {{ .Name.UpperCamel }} | {{ .Go }} | {{ .MySQL }} | {{ .Postgres }} | {{ .SQLite }} | {{ if .Nullable }}yes{{ end }} | {{ .KeyList }} | {{ .Notes }} |
+{{- end }}
+{{ if .Indexes }}
+### Indexes
+
+| Name | Columns | Unique |
+| --- | --- | --- |
+{{- range .Indexes }}
+| {{ .Name }} | {{ .Columns }} | {{ if .Unique }}yes{{ end }} |
+{{- end }}
+{{ end }}{{ if .Edges }}
+### Foreign keys
+
+| Column | References | On delete |
+| --- | --- | --- |
+{{- range .Edges }}
+| {{ .Column }} | [{{ .Other }}](#{{ .Anchor }}).{{ .OtherColumn }} | {{ .OnDelete }} |
+{{- end }}
+{{ end }}{{ if .References }}
+### Referenced by
+
+| Table | Column | On delete |
+| --- | --- | --- |
+{{- range .References }}
+| [{{ .Table }}](#{{ .TableAnchor }}) | {{ .Column }} | {{ .OnDelete }} |
+{{- end }}
+{{ end }}{{ end }}`,
+
+	"Graphviz": `// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+// The entity-relationship diagram of the domain model, bold edges
+// cascading deletes, ex.
+// dot -Tsvg docs/schema_gen.dot > schema.svg
+digraph schema {
+	rankdir=LR;
+	node [shape=plaintext, fontname="Helvetica", fontsize=10];
+	edge [arrowtail=teetee, dir=both];
+{{ range . }}
+	{{ .Name.UpperCamel }} [tooltip={{ printf "%q" .Description }}, label=<
+		<table border="0" cellborder="1" cellspacing="0" cellpadding="4">
+			<tr><td colspan="2" bgcolor="lightgrey"><b>{{ .Name.UpperCamel }}</b></td></tr>
+			{{- range .Columns }}
+			<tr><td port="{{ .Name.UpperCamel }}" align="left">{{ if .PrimaryKey }}<u>{{ .Name.UpperCamel }}</u>{{ else }}{{ .Name.UpperCamel }}{{ end }}</td><td align="left">{{ .SQLType | html }}</td></tr>
+			{{- end }}
+		</table>
+	>];
+{{- end }}
+{{ range . }}{{ range .Edges }}
+	{{ .Other }}:{{ .OtherColumn }} -> {{ .Table }}:{{ .Column }} [arrowhead={{ if .Unique }}teeodot{{ else }}crowodot{{ end }}{{ if .Cascade }}, style=bold{{ end }}];
+{{- end }}{{ end }}
+}
+`,
+}
@@ -2,54 +2,23 @@ package domain
 
 import (
 	"fmt"
-	"os"
-	"path"
 	"reflect"
 	"time"
 
 	"git.ottoq.com/otto-backend/valet/gen/namecase"
 )
 
-var BasePath = path.Join(os.Getenv("GOPATH"), "/src/git.ottoq.com/otto-backend/valet/domain")
+// BasePath is where the domain packages are generated, relative to the
+// repository root
+var BasePath = "domain"
 
-var TypeIDOf = map[string]string{
-	"Node": "0C74DFC158C646C280BCB0DAF9E015D1",
-	"Desk": "E1874C161CDB492FB95EF210E653B886",
-}
+// SchemaPath is the schema file describing every domain object, relative
+// to the repository root. It's YAML if it ends in .yaml or .yml.
+var SchemaPath = "gen/domain/schema.json"
 
-var List = []Object{ //ORDER MATTERS HERE VVV
-	Object{
-		Name:        namecase.New("Node"),
-		Description: "Node represents a node in the organization permission heirarchy tree",
-		TypeID:      TypeIDOf["Node"],
-		Imports: []string{
-			"time",
-		},
-		Parameters: []Parameter{
-			ID(),
-			TypeID(TypeIDOf["Node"]),
-			Timestamp(),
-			String("Name"),
-		},
-	},
-	Object{
-		Name:        namecase.New("Desk"),
-		Description: "Desk where car keys can be stored",
-		TypeID:      TypeIDOf["Desk"],
-		Imports: []string{
-			"time",
-		},
-		Parameters: []Parameter{
-			ID(),
-			TypeID(TypeIDOf["Desk"]),
-			Timestamp(),
-			String("Name"),
-			Float("Lat"),
-			Float("Lng"),
-			ForeignK("NodeID", "Node", "ID"),
-		},
-	},
-}
+// List holds the domain objects loaded from the schema file, sorted so
+// every object follows those its foreign keys reference
+var List []Object
 
 ///////////////////
 // HELPERS
@@ -82,6 +51,8 @@ func PrimaryK(name string) Parameter {
 		Index:      true,
 		PrimaryKey: true,
 		ForeignKey: nil,
+		Random:     "entity.UUID()",
+		Required:   true,
 	}
 }
 
@@ -96,38 +67,149 @@ func ForeignK(name, table, column string) Parameter {
 			Table:  table,
 			Column: column,
 		},
+		Random: "entity.UUID()",
 	}
 }
 
-func String(name string) Parameter {
+// Scalar returns a plain column of the given Go and SQL type. random is
+// the expression Random() uses to fill it.
+func Scalar(name string, t reflect.Type, sqlType, random string) Parameter {
 	return Parameter{
 		Name:       namecase.New(name),
-		Type:       reflect.TypeOf(""),
-		SQLType:    "VARCHAR(100)",
+		Type:       t,
+		SQLType:    sqlType,
 		Index:      false,
 		PrimaryKey: false,
 		ForeignKey: nil,
+		Random:     random,
 	}
 }
 
+func String(name string) Parameter {
+	return Scalar(name, reflect.TypeOf(""), "VARCHAR(100)", "entity.RANDstring()")
+}
+
+func Text(name string) Parameter {
+	return Scalar(name, reflect.TypeOf(""), "TEXT", "entity.RANDtext()")
+}
+
+func MediumText(name string) Parameter {
+	return Scalar(name, reflect.TypeOf(""), "MEDIUMTEXT", "entity.RANDtext()")
+}
+
 func Float(name string) Parameter {
-	return Parameter{
-		Name:       namecase.New(name),
-		Type:       reflect.TypeOf(float64(0)),
-		SQLType:    "FLOAT",
-		Index:      false,
-		PrimaryKey: false,
-		ForeignKey: nil,
+	return Scalar(name, reflect.TypeOf(float64(0)), "FLOAT", "entity.RANDfloat64()")
+}
+
+func Bool(name string) Parameter {
+	return Scalar(name, reflect.TypeOf(false), "TINYINT(1)", "entity.RANDbool()")
+}
+
+func Int(name string) Parameter {
+	return Scalar(name, reflect.TypeOf(int(0)), "BIGINT", "entity.RANDint()")
+}
+
+func Int32(name string) Parameter {
+	return Scalar(name, reflect.TypeOf(int32(0)), "INT", "entity.RANDint32()")
+}
+
+func Int64(name string) Parameter {
+	return Scalar(name, reflect.TypeOf(int64(0)), "BIGINT", "entity.RANDint64()")
+}
+
+func Uint(name string) Parameter {
+	return Scalar(name, reflect.TypeOf(uint(0)), "BIGINT UNSIGNED", "entity.RANDuint()")
+}
+
+// Decimal is an exact number such as money. It's kept as a string in Go
+// so no precision is lost, ex. "1234.50" for DECIMAL(12,2).
+func Decimal(name string, precision, scale int) Parameter {
+	p := Scalar(name, reflect.TypeOf(""),
+		fmt.Sprintf("DECIMAL(%d,%d)", precision, scale),
+		fmt.Sprintf("entity.RANDdecimal(%d, %d)", precision, scale))
+	p.Pattern = fmt.Sprintf(`^-?\d{1,%d}(\.\d{1,%d})?$`, precision-scale, scale)
+	if scale == 0 {
+		p.Pattern = fmt.Sprintf(`^-?\d{1,%d}$`, precision)
 	}
+	return p
+}
+
+func JSON(name string) Parameter {
+	p := Scalar(name, reflect.TypeOf([]byte{}), "JSON", "entity.RANDjson()")
+	p.TypeName = "json.RawMessage"
+	p.TypeImport = "encoding/json"
+	return p
+}
+
+func Bytes(name string) Parameter {
+	p := Scalar(name, reflect.TypeOf([]byte{}), "BLOB", "entity.RANDbytes()")
+	p.TypeName = "[]byte"
+	return p
 }
 
 func Datetime(name string) Parameter {
-	return Parameter{
-		Name:       namecase.New(name),
-		Type:       reflect.TypeOf(time.Now()),
-		SQLType:    "DATETIME",
-		Index:      false,
-		PrimaryKey: false,
-		ForeignKey: nil,
+	return Scalar(name, reflect.TypeOf(time.Now()), "DATETIME", "entity.RANDtime()")
+}
+
+// Nullable allows NULL in the parameter's column. The Go type becomes a
+// pointer, nil being NULL, except for byte slices which are nil already.
+func Nullable(p Parameter) Parameter {
+	p.Nullable = true
+	if p.Type.Kind() == reflect.Slice {
+		return p
 	}
+	p.Type = reflect.PtrTo(p.Type)
+	if p.TypeName != "" {
+		p.TypeName = "*" + p.TypeName
+	}
+	p.Random = fmt.Sprintf("func() %s { v := %s; return &v }()", p.GoType(), p.Random)
+	return p
+}
+
+// Audit columns, maintained by the generated write paths
+const (
+	CreatedAt = "CreatedAt"
+	UpdatedAt = "UpdatedAt"
+	DeletedAt = "DeletedAt"
+	CreatedBy = "CreatedBy"
+	UpdatedBy = "UpdatedBy"
+)
+
+// AuditColumns lists the audit columns in the order they're added after
+// an object's parameters
+var AuditColumns = []string{CreatedAt, UpdatedAt, DeletedAt, CreatedBy, UpdatedBy}
+
+// Audit returns the parameter of an audit column. A DeletedAt column makes
+// Delete() a soft delete.
+func Audit(name string) Parameter {
+	var p Parameter
+	switch name {
+	case CreatedAt, UpdatedAt:
+		p = Datetime(name)
+		p.ConstructorOverride = "entity.Now()"
+	case DeletedAt:
+		p = Nullable(Datetime(name))
+		p.ConstructorOverride = "nil"
+	default:
+		p = String(name)
+		p.ConstructorOverride = `""`
+	}
+	p.Audit = name
+	return p
+}
+
+// VersionColumn is the column of Version
+const VersionColumn = "Version"
+
+var minVersion = 1.0
+
+// Version returns the optimistic concurrency counter. It starts at 1 and
+// every Update increments it, failing with domain.ErrStaleObject if the
+// row's version no longer matches.
+func Version() Parameter {
+	p := Int64(VersionColumn)
+	p.ConstructorOverride = "1"
+	p.Min = &minVersion
+	p.Version = true
+	return p
 }
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dialect is a SQL flavour the generated code runs on, named like its
+// database/sql driver and the domain.Dialect constant it's generated as
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite3"
+)
+
+// Dialects lists every dialect statements are generated for, MySQL first
+// since migrations are written for it
+var Dialects = []Dialect{MySQL, Postgres, SQLite}
+
+// Const returns the domain package constant naming the dialect
+func (d Dialect) Const() string {
+	switch d {
+	case Postgres:
+		return "domain.Postgres"
+	case SQLite:
+		return "domain.SQLite"
+	}
+	return "domain.MySQL"
+}
+
+// DialectSQL is a statement written for one dialect
+type DialectSQL struct {
+	Dialect Dialect
+	SQL     string
+}
+
+// forDialects returns the statement sql builds for each dialect
+func forDialects(sql func(d Dialect) string) []DialectSQL {
+	stmts := []DialectSQL{}
+	for _, d := range Dialects {
+		stmts = append(stmts, DialectSQL{Dialect: d, SQL: sql(d)})
+	}
+	return stmts
+}
+
+var sqlTypeRegexp = regexp.MustCompile(`^([A-Z]+(?: UNSIGNED)?)(\(.*\))?$`)
+
+// ColumnType returns the column type of the parameter in the dialect. The
+// MySQL SQLType is translated to its closest equivalent, an ENUM becoming a
+// VARCHAR checked against its values. Unknown types are kept as is.
+func (d Dialect) ColumnType(p Parameter) string {
+	if d == MySQL {
+		return p.SQLType
+	}
+	if p.Enum != nil {
+		width := 0
+		values := []string{}
+		for _, v := range p.Enum.Values {
+			if len(v.Value) > width {
+				width = len(v.Value)
+			}
+			values = append(values, "'"+v.Value+"'")
+		}
+		return fmt.Sprintf("VARCHAR(%d) CHECK (%s IN (%s))", width, p.Name.UpperCamel, strings.Join(values, ", "))
+	}
+	m := sqlTypeRegexp.FindStringSubmatch(strings.ToUpper(p.SQLType))
+	if m == nil {
+		return p.SQLType
+	}
+	name, args := m[1], m[2]
+	pg := d == Postgres
+	switch name {
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		if pg {
+			return "BYTEA"
+		}
+		return "BLOB"
+	case "DATETIME", "TIMESTAMP":
+		if pg {
+			return "TIMESTAMP"
+		}
+		return "DATETIME"
+	case "TINYINT":
+		if args == "(1)" {
+			return "BOOLEAN"
+		}
+		if pg {
+			return "SMALLINT"
+		}
+		return "INTEGER"
+	case "SMALLINT", "MEDIUMINT", "INT", "INTEGER":
+		if pg && name == "SMALLINT" {
+			return "SMALLINT"
+		}
+		return "INTEGER"
+	case "BIGINT":
+		if pg {
+			return "BIGINT"
+		}
+		return "INTEGER"
+	case "BIGINT UNSIGNED":
+		// Postgres has no unsigned integers
+		if pg {
+			return "NUMERIC(20)"
+		}
+		return "INTEGER"
+	case "FLOAT":
+		return "REAL"
+	case "DOUBLE":
+		if pg {
+			return "DOUBLE PRECISION"
+		}
+		return "REAL"
+	case "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT":
+		return "TEXT"
+	case "JSON":
+		if pg {
+			return "JSONB"
+		}
+		return "TEXT"
+	case "DECIMAL", "NUMERIC":
+		// SQLite would round a NUMERIC to a float, so decimals are kept as text
+		if pg {
+			return "NUMERIC" + args
+		}
+		return "TEXT"
+	}
+	return p.SQLType
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnumsImport is the package the enum types of every object are generated in
+var EnumsImport = "git.ottoq.com/otto-backend/valet/domain/enums"
+
+var enumValueRegexp = regexp.MustCompile(`^[A-Za-z0-9]+(?:[_-][A-Za-z0-9]+)*$`)
+
+// EnumType is a named string type limited to a list of values
+type EnumType struct {
+	Name   string // Name of the Go type in the enums package, ex. DeskState
+	Values []EnumValue
+}
+
+// EnumValue is one value of an enum and the constant holding it
+type EnumValue struct {
+	Const string // Const is the name of the constant, ex. DeskStateOpen
+	Value string // Value is what's stored in the column, ex. "open"
+}
+
+// Enum returns a string parameter limited to values. Its Go type is
+// enums.<Name>, with a constant for each value, and its column a MySQL
+// ENUM, ex. Enum("State", "open", "closed").
+func Enum(name string, values ...string) Parameter {
+	return NamedEnum(name, name, values...)
+}
+
+// NamedEnum is Enum with the Go type named typeName, which keeps the same
+// parameter of different objects from sharing a type
+func NamedEnum(typeName, name string, values ...string) Parameter {
+	e := &EnumType{Name: typeName}
+	sqlValues, goValues := []string{}, []string{}
+	for _, v := range values {
+		e.Values = append(e.Values, EnumValue{Const: typeName + enumConst(v), Value: v})
+		sqlValues = append(sqlValues, "'"+v+"'")
+		goValues = append(goValues, strconv.Quote(v))
+	}
+	p := Scalar(name, reflect.TypeOf(""), "ENUM("+strings.Join(sqlValues, ",")+")",
+		fmt.Sprintf("enums.%s(entity.RANDchoice(%s))", typeName, strings.Join(goValues, ", ")))
+	p.TypeName = "enums." + typeName
+	p.TypeImport = EnumsImport
+	p.Enum = e
+	p.OneOf = values
+	return p
+}
+
+// enumConst turns a value into the suffix of its constant, ex. out_of_order
+// becomes OutOfOrder
+func enumConst(v string) string {
+	parts := strings.FieldsFunc(v, func(r rune) bool { return r == '_' || r == '-' })
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// validateEnum returns why values can't make an enum, if they can't
+func validateEnum(typeName string, values []string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("enum %s has no values", typeName)
+	}
+	seen := map[string]string{}
+	for _, v := range values {
+		if !enumValueRegexp.MatchString(v) {
+			return fmt.Errorf("enum value %q of %s must be letters and digits separated by _ or -", v, typeName)
+		}
+		c := enumConst(v)
+		if other, ok := seen[c]; ok {
+			return fmt.Errorf("enum values %q and %q of %s both make the constant %s%s", other, v, typeName, typeName, c)
+		}
+		seen[c] = v
+	}
+	return nil
+}
+
+// Enums returns the enum types of the objects' parameters sorted by name,
+// failing if two parameters name the same type with different values
+func Enums(objects []Object) ([]EnumType, error) {
+	byName := map[string]*EnumType{}
+	for _, o := range objects {
+		for _, p := range o.Parameters {
+			if p.Enum == nil {
+				continue
+			}
+			if other, ok := byName[p.Enum.Name]; ok {
+				if !reflect.DeepEqual(other, p.Enum) {
+					return nil, fmt.Errorf("enum %s of %s.%s has different values elsewhere", p.Enum.Name, o.Name.UpperCamel, p.Name.UpperCamel)
+				}
+				continue
+			}
+			byName[p.Enum.Name] = p.Enum
+		}
+	}
+	enums := []EnumType{}
+	for _, e := range byName {
+		enums = append(enums, *e)
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	return enums, nil
+}
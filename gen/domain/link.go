@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"strings"
+
+	"git.ottoq.com/otto-backend/valet/gen/namecase"
+)
+
+// Link is a many-to-many relationship seen from one of its objects. The
+// rows of its join table pair the primary keys of both objects.
+type Link struct {
+	Table       *namecase.Name // Table is the join table
+	Self        *namecase.Name // Self is the object the link is seen from
+	Other       *namecase.Name // Other is the linked object
+	Key         Parameter      // Key is the primary key of Self
+	OtherKey    Parameter      // OtherKey is the primary key of Other
+	Column      Parameter      // Column of the join table referencing Self
+	OtherColumn Parameter      // OtherColumn of the join table referencing Other
+	OtherFrom   string         // OtherFrom is the FROM clause selecting Other
+}
+
+// NewJoin returns the join table of a relationship between left and
+// right, which must each have a single column primary key. Its composite
+// primary key is a foreign key to each, deleted along with either.
+func NewJoin(name *namecase.Name, description, typeID string, left, right Object) Object {
+	join := Object{
+		Name:        name,
+		Description: description,
+		TypeID:      typeID,
+		REST:        []string{},
+	}
+	for _, o := range []Object{left, right} {
+		key := o.PrimaryKeys()[0]
+		p := ForeignK(o.Name.UpperCamel+key.Name.UpperCamel, o.Name.UpperCamel, key.Name.UpperCamel)
+		p.PrimaryKey = true
+		p.Required = true
+		p.ForeignKey.Cascade = true
+		join.Parameters = append(join.Parameters, p)
+	}
+	// the primary key only serves lookups by its first column
+	second := join.Parameters[1].Name.UpperCamel
+	join.Indexes = []Index{{Name: IndexName(false, second), Columns: []IndexColumn{{Name: second}}}}
+	join.Join = []Link{
+		newLink(join, left, right, 0),
+		newLink(join, right, left, 1),
+	}
+	for _, pkg := range join.ValidateImports() {
+		join.Imports = append(join.Imports, pkg)
+	}
+	return join
+}
+
+// newLink returns the link of join seen from self, column being the
+// index of the join parameter referencing self
+func newLink(join, self, other Object, column int) Link {
+	return Link{
+		Table:       join.Name,
+		Self:        self.Name,
+		Other:       other.Name,
+		Key:         self.PrimaryKeys()[0],
+		OtherKey:    other.PrimaryKeys()[0],
+		Column:      join.Parameters[column],
+		OtherColumn: join.Parameters[1-column],
+		OtherFrom:   other.SQLFrom(),
+	}
+}
+
+// SQLAdds returns SQLAddFor each dialect
+func (l Link) SQLAdds() []DialectSQL {
+	return forDialects(l.SQLAddFor)
+}
+
+// SQLAddFor returns an INSERT of a link taking Column then OtherColumn.
+// An existing link is left as is.
+func (l Link) SQLAddFor(d Dialect) string {
+	s := "INSERT INTO " + l.Table.UpperCamel + " (" + l.Column.Name.UpperCamel + ", " + l.OtherColumn.Name.UpperCamel + ")\n" +
+		"VALUES (?, ?)\n"
+	if d != MySQL {
+		return s + "ON CONFLICT DO NOTHING"
+	}
+	return s + "ON DUPLICATE KEY UPDATE " + l.Column.Name.UpperCamel + " = " + l.Column.Name.UpperCamel
+}
+
+// SQLRemove returns a DELETE of a link taking Column then OtherColumn
+func (l Link) SQLRemove() string {
+	return "DELETE FROM " + l.Table.UpperCamel + "\n" +
+		"WHERE " + l.Column.Name.UpperCamel + " = ?" +
+		" AND " + l.OtherColumn.Name.UpperCamel + " = ?"
+}
+
+// SQLListKeys returns a SELECT of the keys linked to Column, leaving out
+// those of soft deleted objects
+func (l Link) SQLListKeys() string {
+	s := "SELECT " + l.OtherColumn.Name.UpperCamel + " FROM " + l.Table.UpperCamel + "\n" +
+		"WHERE " + l.Column.Name.UpperCamel + " = ?"
+	if l.OtherFrom != l.Other.UpperCamel {
+		s += " AND " + l.OtherColumn.Name.UpperCamel + " IN (SELECT " + l.OtherKey.Name.UpperCamel + " FROM " + l.OtherFrom + ")"
+	}
+	return s + "\nORDER BY " + l.OtherColumn.Name.UpperCamel
+}
+
+// SQLWhereLinked returns the clause selecting the Others linked to
+// Column, for appending to their SELECT
+func (l Link) SQLWhereLinked() string {
+	return "WHERE " + l.OtherKey.Name.UpperCamel + " IN (SELECT " + l.OtherColumn.Name.UpperCamel + " FROM " + l.Table.UpperCamel +
+		" WHERE " + l.Column.Name.UpperCamel + " = ?)"
+}
+
+// SQLJoinLinked returns the join of the join table to the rows of Self,
+// up to the list of Other keys it's limited to, Linked being Self's key
+// and LinkedTo Other's
+func (l Link) SQLJoinLinked() string {
+	return "JOIN (SELECT " + l.Column.Name.UpperCamel + " AS Linked, " + l.OtherColumn.Name.UpperCamel + " AS LinkedTo FROM " + l.Table.UpperCamel +
+		" WHERE " + l.OtherColumn.Name.UpperCamel
+}
+
+// Plural returns the name of several Others, ex. Attendants
+func (l Link) Plural() string {
+	if strings.HasSuffix(l.Other.UpperCamel, "s") {
+		return l.Other.UpperCamel + "es"
+	}
+	return l.Other.UpperCamel + "s"
+}
@@ -1,7 +1,13 @@
 package domain
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"git.ottoq.com/otto-backend/valet/gen/namecase"
@@ -13,8 +19,15 @@ type Object struct {
 	TypeID      string
 	Imports     []string
 	Parameters  []Parameter
+	Indexes     []Index
+	REST        []string // REST lists the generated endpoints, a subset of Ops
+	Links       []Link   // Links are the many-to-many relationships of the object
+	Join        []Link   // Join holds both sides of the relationship a join table stores
 }
 
+// Ops are the REST operations that can be generated for an object
+var Ops = []string{"create", "read", "update", "delete", "list"}
+
 type Parameter struct {
 	Name                *namecase.Name
 	Type                reflect.Type
@@ -23,79 +36,470 @@ type Parameter struct {
 	PrimaryKey          bool
 	ForeignKey          *ForeignKey
 	ConstructorOverride string
+	Random              string // Random is the expression used by Random(), ex. entity.RANDstring()
+	Nullable            bool
+	TypeName            string    // TypeName overrides Type's name in generated code
+	TypeImport          string    // TypeImport is the package TypeName needs
+	Audit               string    // Audit names the audit column the parameter is, if any
+	Version             bool      // Version marks the optimistic concurrency counter
+	Enum                *EnumType // Enum is the type of an enum parameter, see Enum()
+
+	// constraints checked by the generated Validate()
+	Required  bool
+	MaxLength int      // MaxLength in characters, defaults to the VARCHAR length
+	Min       *float64 // Min is the smallest value allowed for a number
+	Max       *float64 // Max is the largest value allowed for a number
+	Pattern   string   // Pattern is a regexp a string must match
+	OneOf     []string // OneOf lists the only values a string may have
+}
+
+// GoType returns the parameter's type as written in generated code
+func (p Parameter) GoType() string {
+	if p.TypeName != "" {
+		return p.TypeName
+	}
+	return p.Type.String()
+}
+
+// Import returns the package the parameter's type needs, if any
+func (p Parameter) Import() string {
+	if p.TypeImport != "" {
+		return p.TypeImport
+	}
+	t := p.Type
+	for t.Name() == "" && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	return t.PkgPath()
 }
 
 type ForeignKey struct {
-	Table  string
-	Column string
+	Table   string
+	Column  string
+	Cascade bool // Cascade deletes the row along with the one it references
+}
+
+// Index is a secondary index over one or more columns
+type Index struct {
+	Name    string
+	Unique  bool
+	Columns []IndexColumn
+}
+
+// IndexColumn is a column of an index. A non zero Length only indexes
+// that many leading characters of the column.
+type IndexColumn struct {
+	Name   string
+	Length int
+}
+
+// Hex reports whether the parameter is a hex string stored as raw bytes
+func (p Parameter) Hex() bool {
+	return p.SQLType == "BINARY(16)"
 }
 
+// SQLArg returns the argument writing expr, a value of the parameter. A
+// hex ID is converted to its bytes and a JSON document to text, which
+// every driver stores the same way.
+func (p Parameter) SQLArg(expr string) string {
+	switch {
+	case p.Hex():
+		return "domain.Hex(" + expr + ")"
+	case p.IsJSON():
+		return "domain.JSON(" + expr + ")"
+	}
+	return expr
+}
+
+// SQLScan returns the destination reading the parameter into expr, a
+// pointer to its value. A hex ID is read from its bytes and a JSON
+// document from whatever the driver returns.
+func (p Parameter) SQLScan(expr string) string {
+	switch {
+	case p.Hex():
+		return "domain.ScanHex(" + expr + ")"
+	case p.IsJSON():
+		return "domain.ScanJSON(" + expr + ")"
+	}
+	return expr
+}
+
+// PrimaryKeys returns the parameters making up the primary key
+func (o Object) PrimaryKeys() []Parameter {
+	params := []Parameter{}
+	for _, p := range o.Parameters {
+		if p.PrimaryKey {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// NonPrimaryKeys returns every parameter outside the primary key
+func (o Object) NonPrimaryKeys() []Parameter {
+	params := []Parameter{}
+	for _, p := range o.Parameters {
+		if !p.PrimaryKey {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// SQLSelect returns a SELECT of every column, in NewFromRow scan order,
+// from SQLFrom
+func (o Object) SQLSelect() string {
+	return o.sqlSelect(o.SQLFrom())
+}
+
+// SQLSelectWithDeleted returns SQLSelect including soft deleted rows
+func (o Object) SQLSelectWithDeleted() string {
+	return o.sqlSelect(o.Name.UpperCamel)
+}
+
+func (o Object) sqlSelect(from string) string {
+	columns := []string{}
+	for _, p := range o.Parameters {
+		columns = append(columns, p.Name.UpperCamel)
+	}
+	return "SELECT " + strings.Join(columns, ", ") + " FROM " + from
+}
+
+// SQLFrom returns what queries select from. Soft deleted rows are left
+// out by a derived table named like the table, so clauses appended to a
+// SELECT work either way and MySQL merges it into the outer query.
+func (o Object) SQLFrom() string {
+	if o.SoftDelete() {
+		return "(SELECT * FROM " + o.Name.UpperCamel + " WHERE " + DeletedAt + " IS NULL) AS " + o.Name.UpperCamel
+	}
+	return o.Name.UpperCamel
+}
+
+// SQLWherePrimary returns a WHERE clause matching the primary key
+func (o Object) SQLWherePrimary() string {
+	conds := []string{}
+	for _, p := range o.PrimaryKeys() {
+		conds = append(conds, p.Name.UpperCamel+" = ?")
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
+
+// SQLOrderPrimary returns an ORDER BY clause on the primary key
+func (o Object) SQLOrderPrimary() string {
+	columns := []string{}
+	for _, p := range o.PrimaryKeys() {
+		columns = append(columns, p.Name.UpperCamel)
+	}
+	return "ORDER BY " + strings.Join(columns, ", ")
+}
+
+// SQLInsert returns a parameterized INSERT taking every column in order
 func (o Object) SQLInsert() string {
+	columns := []string{}
 	params := []string{}
-	updates := []string{}
 	for _, p := range o.Parameters {
-		if strings.Contains(p.Name.UpperCamel, "ID") {
-			params = append(params, `UNHEX( '%s' )`)
-		} else {
-			switch p.Type.String() {
-			case "float64":
-				params = append(params, `%f`)
-			case "int":
-				params = append(params, `%d`)
-			case "string":
-				params = append(params, `'%s'`)
-			case "time.Time":
-				params = append(params, `'%s'`)
-			}
+		columns = append(columns, p.Name.UpperCamel)
+		params = append(params, "?")
+	}
+	return "INSERT INTO " + o.Name.UpperCamel + " (" + strings.Join(columns, ", ") + ")\n" +
+		"VALUES (" + strings.Join(params, ", ") + ")"
+}
+
+// SQLUpserts returns SQLUpsertFor each dialect
+func (o Object) SQLUpserts() []DialectSQL {
+	return forDialects(o.SQLUpsertFor)
+}
+
+// SQLUpsertFor returns SQLInsert, updating the UpdateParameters if the
+// row exists. A versioned row is only updated if its version matches, a
+// soft deleted one only if it isn't deleted.
+// MySQL assigns the version last since it applies assignments in order,
+// Postgres and SQLite return the version written and no row if the
+// version didn't match. Without UpdateParameters an existing row is left
+// as is.
+func (o Object) SQLUpsertFor(d Dialect) string {
+	if d != MySQL {
+		return o.sqlOnConflict()
+	}
+	if len(o.UpdateParameters()) == 0 {
+		key := o.PrimaryKeys()[0].Name.UpperCamel
+		return o.SQLInsert() + "\n" +
+			"ON DUPLICATE KEY UPDATE " + key + " = " + key
+	}
+	updates := []string{}
+	v := o.VersionParameter()
+	conds := []string{}
+	if v != nil {
+		conds = append(conds, v.Name.UpperCamel+" = VALUES("+v.Name.UpperCamel+")")
+	}
+	if o.SoftDelete() {
+		conds = append(conds, DeletedAt+" IS NULL")
+	}
+	cond := strings.Join(conds, " AND ")
+	for _, p := range o.UpdateParameters() {
+		value := "VALUES(" + p.Name.UpperCamel + ")"
+		if cond != "" {
+			value = "IF(" + cond + ", " + value + ", " + p.Name.UpperCamel + ")"
 		}
-		if p.PrimaryKey {
+		updates = append(updates, p.Name.UpperCamel+" = "+value)
+	}
+	if v != nil {
+		updates = append(updates, v.Name.UpperCamel+" = IF("+cond+", "+v.Name.UpperCamel+" + 1, "+v.Name.UpperCamel+")")
+	}
+	return o.SQLInsert() + "\n" +
+		"ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
+
+// sqlOnConflict returns the upsert of Postgres and SQLite
+func (o Object) sqlOnConflict() string {
+	if len(o.UpdateParameters()) == 0 {
+		return o.SQLInsert() + "\n" +
+			"ON CONFLICT DO NOTHING"
+	}
+	keys := []string{}
+	for _, p := range o.PrimaryKeys() {
+		keys = append(keys, p.Name.UpperCamel)
+	}
+	updates := []string{}
+	for _, p := range o.UpdateParameters() {
+		updates = append(updates, p.Name.UpperCamel+" = excluded."+p.Name.UpperCamel)
+	}
+	conds := []string{}
+	if v := o.VersionParameter(); v != nil {
+		version := o.Name.UpperCamel + "." + v.Name.UpperCamel
+		updates = append(updates, v.Name.UpperCamel+" = "+version+" + 1")
+		conds = append(conds, version+" = excluded."+v.Name.UpperCamel)
+	}
+	if o.SoftDelete() {
+		conds = append(conds, o.Name.UpperCamel+"."+DeletedAt+" IS NULL")
+	}
+	s := o.SQLInsert() + "\n" +
+		"ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+	if len(conds) > 0 {
+		s += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	if v := o.VersionParameter(); v != nil {
+		s += "\nRETURNING " + v.Name.UpperCamel
+	}
+	return s
+}
+
+// SQLUpdate returns a parameterized UPDATE taking the UpdateParameters
+// followed by the primary key. A versioned object increments its version
+// and takes the version it was read at last.
+func (o Object) SQLUpdate() string {
+	updates := []string{}
+	for _, p := range o.UpdateParameters() {
+		updates = append(updates, p.Name.UpperCamel+" = ?")
+	}
+	if v := o.VersionParameter(); v != nil {
+		updates = append(updates, v.Name.UpperCamel+" = "+v.Name.UpperCamel+" + 1")
+	}
+	return "UPDATE " + o.Name.UpperCamel + " SET " + strings.Join(updates, ", ") + "\n" +
+		o.SQLUpdateWhere()
+}
+
+// SQLUpdateWhere returns the WHERE of an UPDATE, matching the primary key
+// and the version if there is one. Soft deleted rows aren't matched.
+func (o Object) SQLUpdateWhere() string {
+	where := o.SQLWherePrimary()
+	if v := o.VersionParameter(); v != nil {
+		where += " AND " + v.Name.UpperCamel + " = ?"
+	}
+	if o.SoftDelete() {
+		where += " AND " + DeletedAt + " IS NULL"
+	}
+	return where
+}
+
+// PatchKeys returns the parameters a patch may name but not change, the
+// primary key and the version
+func (o Object) PatchKeys() []Parameter {
+	keys := o.PrimaryKeys()
+	if v := o.VersionParameter(); v != nil {
+		keys = append(keys, *v)
+	}
+	return keys
+}
+
+// SQLDelete returns a parameterized DELETE taking the primary key. A soft
+// deleted object gets an UPDATE of a row that isn't deleted instead,
+// taking the DeleteParameters, the primary key and the version if there
+// is one, which it increments.
+func (o Object) SQLDelete() string {
+	if !o.SoftDelete() {
+		return "DELETE FROM " + o.Name.UpperCamel + " " + o.SQLWherePrimary()
+	}
+	return o.sqlSetDeleted(DeletedAt + " IS NULL")
+}
+
+// SQLRestore returns the UPDATE undoing a soft delete, taking the same
+// arguments as SQLDelete
+func (o Object) SQLRestore() string {
+	return o.sqlSetDeleted(DeletedAt + " IS NOT NULL")
+}
+
+// sqlSetDeleted returns an UPDATE of the DeleteParameters of a row in the
+// state cond matches
+func (o Object) sqlSetDeleted(cond string) string {
+	updates := []string{}
+	for _, p := range o.DeleteParameters() {
+		updates = append(updates, p.Name.UpperCamel+" = ?")
+	}
+	where := o.SQLWherePrimary()
+	if v := o.VersionParameter(); v != nil {
+		updates = append(updates, v.Name.UpperCamel+" = "+v.Name.UpperCamel+" + 1")
+		where += " AND " + v.Name.UpperCamel + " = ?"
+	}
+	return "UPDATE " + o.Name.UpperCamel + " SET " + strings.Join(updates, ", ") + "\n" +
+		where + " AND " + cond
+}
+
+// UpdateParameters returns the columns an Update writes, every non key
+// column but those only set on insert or delete and the version, which
+// the database increments
+func (o Object) UpdateParameters() []Parameter {
+	params := []Parameter{}
+	for _, p := range o.NonPrimaryKeys() {
+		if p.Version {
 			continue
 		}
-		if strings.Contains(p.Name.UpperCamel, "ID") {
-			updates = append(updates, p.Name.UpperCamel+`=UNHEX( '%s' )`)
-		} else {
-			switch p.Type.String() {
-			case "float64":
-				updates = append(updates, p.Name.UpperCamel+`=%f`)
-			case "int":
-				updates = append(updates, p.Name.UpperCamel+`=%d`)
-			case "string":
-				updates = append(updates, p.Name.UpperCamel+`='%s'`)
-			case "time.Time":
-				updates = append(updates, p.Name.UpperCamel+`='%s'`)
-			}
+		switch p.Audit {
+		case CreatedAt, CreatedBy, DeletedAt:
+			continue
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// CreatedParameters returns the audit columns set when a row is created
+func (o Object) CreatedParameters() []Parameter {
+	params := []Parameter{}
+	for _, p := range o.Parameters {
+		switch p.Audit {
+		case CreatedAt, CreatedBy:
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// SQLSelectCreated returns a SELECT of the CreatedParameters of the row
+// with the given primary key, deleted or not
+func (o Object) SQLSelectCreated() string {
+	columns := []string{}
+	for _, p := range o.CreatedParameters() {
+		columns = append(columns, p.Name.UpperCamel)
+	}
+	return "SELECT " + strings.Join(columns, ", ") + " FROM " + o.Name.UpperCamel + " " + o.SQLWherePrimary()
+}
+
+// DeleteParameters returns the columns a soft delete or restore writes
+func (o Object) DeleteParameters() []Parameter {
+	params := []Parameter{}
+	for _, p := range o.Parameters {
+		switch p.Audit {
+		case DeletedAt, UpdatedAt, UpdatedBy:
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// HasAudit reports whether the object has the named audit column
+func (o Object) HasAudit(name string) bool {
+	for _, p := range o.Parameters {
+		if p.Audit == name {
+			return true
 		}
 	}
-	paramstr := strings.Join(params, ",\n")
-	updatestr := strings.Join(updates, ",\n")
-	insertstr := "INSERT INTO " + o.Name.UpperCamel + " VALUES(\n" +
-		paramstr + "\n)\n" +
-		"ON DUPLICATE KEY UPDATE\n" +
-		updatestr + "\n;"
-	return insertstr
+	return false
+}
+
+// SoftDelete reports whether deleting only marks rows deleted
+func (o Object) SoftDelete() bool {
+	return o.HasAudit(DeletedAt)
+}
+
+// VersionParameter returns the optimistic concurrency counter, nil if the
+// object doesn't have one
+func (o Object) VersionParameter() *Parameter {
+	for i := range o.Parameters {
+		if o.Parameters[i].Version {
+			return &o.Parameters[i]
+		}
+	}
+	return nil
+}
+
+// Versioned reports whether updates check and increment a version
+func (o Object) Versioned() bool {
+	return o.VersionParameter() != nil
+}
+
+// UpdateInputParameters returns what a client sends to update an object,
+// its primary key and MutableParameters followed by the version it read
+func (o Object) UpdateInputParameters() []Parameter {
+	params := append(o.PrimaryKeys(), o.MutableParameters()...)
+	if v := o.VersionParameter(); v != nil {
+		params = append(params, *v)
+	}
+	return params
+}
+
+// Stamped reports whether writes set any audit column besides DeletedAt
+func (o Object) Stamped() bool {
+	return o.HasAudit(CreatedAt) || o.HasAudit(UpdatedAt) || o.HasAudit(CreatedBy) || o.HasAudit(UpdatedBy)
 }
 
+// SQLSchema returns the CREATE TABLE of the object in MySQL, the dialect
+// migrations are written in
 func (o Object) SQLSchema() string {
+	return o.SQLSchemaFor(MySQL)
+}
+
+// SQLSchemas returns SQLSchemaFor each dialect
+func (o Object) SQLSchemas() []DialectSQL {
+	return forDialects(o.SQLSchemaFor)
+}
+
+// SQLSchemaFor returns the statements creating the object's table in the
+// dialect. Postgres and SQLite can't declare indexes in CREATE TABLE, so
+// a CREATE INDEX follows for each, named after the table since their
+// index names are shared by every table.
+func (o Object) SQLSchemaFor(d Dialect) string {
 	columns := []string{}
 	primary := []string{}
 	secondary := []string{}
 	for _, p := range o.Parameters {
-		columns = append(columns, p.Name.UpperCamel+" "+p.SQLType)
+		columns = append(columns, p.Name.UpperCamel+" "+d.ColumnType(p)+p.SQLDefault())
 		if p.PrimaryKey {
-			primary = append(primary,
-				PrimaryString(p.Name.UpperCamel))
+			primary = append(primary, p.Name.UpperCamel)
 		}
 		if p.ForeignKey != nil {
-			secondary = append(secondary,
-				ForeignString(p.Name.UpperCamel, p.ForeignKey.Table, p.ForeignKey.Column))
+			secondary = append(secondary, p.SQLForeign())
+		}
+	}
+	if len(primary) > 0 {
+		columns = append(columns, PrimaryString(primary...))
+	}
+	indexes := []string{}
+	for _, i := range o.AllIndexes() {
+		if d == MySQL {
+			columns = append(columns, i.SQL())
+		} else {
+			indexes = append(indexes, i.SQLCreate(o.Name.UpperCamel))
 		}
 	}
-	columns = append(columns, primary...)
 	columns = append(columns, secondary...)
 
 	colstr := strings.Join(columns, ",\n")
 	tablstr := "CREATE TABLE " + o.Name.UpperCamel + " (\n" +
 		colstr + "\n);"
+	for _, i := range indexes {
+		tablstr += "\n" + i
+	}
 
 	return tablstr
 }
@@ -109,3 +513,470 @@ func ForeignString(localCol, table, foreigncol string) string {
 	forstr := "FOREIGN KEY (" + localCol + ")" + " REFERENCES " + table + "(" + foreigncol + ")"
 	return forstr
 }
+
+// SQLDefault returns what follows the column type of p in every dialect,
+// NOT NULL DEFAULT its first value for an enum that isn't nullable so rows
+// written before the column was added hold a valid one
+func (p Parameter) SQLDefault() string {
+	if p.Enum == nil || p.Nullable {
+		return ""
+	}
+	return " NOT NULL DEFAULT '" + p.Enum.Values[0].Value + "'"
+}
+
+// SQLForeign returns the FOREIGN KEY clause of a foreign key parameter
+func (p Parameter) SQLForeign() string {
+	s := ForeignString(p.Name.UpperCamel, p.ForeignKey.Table, p.ForeignKey.Column)
+	if p.ForeignKey.Cascade {
+		s += " ON DELETE CASCADE"
+	}
+	return s
+}
+
+// Parameter returns the named parameter or nil if the object doesn't have it
+func (o Object) Parameter(name string) *Parameter {
+	for i := range o.Parameters {
+		if strings.EqualFold(o.Parameters[i].Name.UpperCamel, name) {
+			return &o.Parameters[i]
+		}
+	}
+	return nil
+}
+
+// Relation is a foreign key parameter seen from the object holding it
+type Relation struct {
+	Name      string     // Name of the loader, the column without its ID suffix
+	Column    Parameter  // Column is the foreign key parameter
+	Table     string     // Table is the referenced object
+	Package   string     // Package of the referenced object, empty if it's this object
+	Reference *Parameter // Reference is the referenced parameter
+}
+
+// Qualifier returns the prefix for identifiers of the referenced package
+func (r Relation) Qualifier() string {
+	if r.Package == "" {
+		return ""
+	}
+	return r.Package + "."
+}
+
+// Relations returns a Relation for each foreign key of the object. The
+// referenced objects are looked up in List.
+func (o Object) Relations() []Relation {
+	relations := []Relation{}
+	for _, p := range o.Parameters {
+		if p.ForeignKey == nil {
+			continue
+		}
+		ref := Lookup(p.ForeignKey.Table)
+		if ref == nil {
+			continue
+		}
+		name := strings.TrimSuffix(p.Name.UpperCamel, "ID")
+		if name == "" || name == p.Name.UpperCamel {
+			name = ref.Name.UpperCamel
+		}
+		r := Relation{
+			Name:      name,
+			Column:    p,
+			Table:     ref.Name.UpperCamel,
+			Reference: ref.Parameter(p.ForeignKey.Column),
+		}
+		if ref.Name.UpperCamel != o.Name.UpperCamel {
+			r.Package = ref.Name.Lower
+		}
+		if r.Reference == nil {
+			continue
+		}
+		relations = append(relations, r)
+	}
+	return relations
+}
+
+// KeyType returns the Go type of the referenced values, which a nullable
+// column holds a pointer to
+func (r Relation) KeyType() string {
+	return r.Reference.GoType()
+}
+
+// Key returns the referenced value held by the column expr, which must
+// not be nil if the column is nullable
+func (r Relation) Key(expr string) string {
+	if r.Column.Nullable {
+		return "*" + expr
+	}
+	return expr
+}
+
+// Referrer is a foreign key of another object seen from the object it
+// references
+type Referrer struct {
+	Object   Object
+	Relation Relation
+}
+
+// Method returns the name of the referenced object's method listing the
+// referrers, ex. Desks, or DesksByBackupNode for a foreign key not named
+// after the object it references
+func (b Referrer) Method() string {
+	if b.Relation.Name == b.Relation.Table {
+		return b.Object.Name.UpperCamel + "s"
+	}
+	return b.Object.Name.UpperCamel + "sBy" + b.Relation.Name
+}
+
+// Method returns the name of the referenced object's method listing the
+// objects holding the relation, see Referrer.Method
+func (r Relation) Method(o Object) string {
+	return Referrer{Object: o, Relation: r}.Method()
+}
+
+// ReferencedBy returns the foreign keys of the objects in List that
+// reference this one
+func (o Object) ReferencedBy() []Referrer {
+	referrers := []Referrer{}
+	for _, other := range List {
+		for _, r := range other.Relations() {
+			if r.Table == o.Name.UpperCamel {
+				referrers = append(referrers, Referrer{Object: other, Relation: r})
+			}
+		}
+	}
+	return referrers
+}
+
+// RelationImports returns the packages of every object this one references
+func (o Object) RelationImports() []string {
+	seen := map[string]bool{}
+	imports := []string{}
+	for _, r := range o.Relations() {
+		if r.Package != "" && !seen[r.Package] {
+			seen[r.Package] = true
+			imports = append(imports, r.Package)
+		}
+	}
+	return imports
+}
+
+// Lookup returns the object in List with the given name or nil
+func Lookup(name string) *Object {
+	for i := range List {
+		if List[i].Name.UpperCamel == name {
+			return &List[i]
+		}
+	}
+	return nil
+}
+
+// SQL returns the index definition as used in CREATE TABLE
+func (i Index) SQL() string {
+	columns := []string{}
+	for _, c := range i.Columns {
+		if c.Length > 0 {
+			columns = append(columns, fmt.Sprintf("%s(%d)", c.Name, c.Length))
+		} else {
+			columns = append(columns, c.Name)
+		}
+	}
+	kind := "INDEX "
+	if i.Unique {
+		kind = "UNIQUE INDEX "
+	}
+	return kind + i.Name + " (" + strings.Join(columns, ", ") + ")"
+}
+
+// SQLCreate returns a CREATE INDEX of the index on table, named
+// table_name. Lengths are dropped, they're only understood by MySQL.
+func (i Index) SQLCreate(table string) string {
+	columns := []string{}
+	for _, c := range i.Columns {
+		columns = append(columns, c.Name)
+	}
+	kind := "CREATE INDEX "
+	if i.Unique {
+		kind = "CREATE UNIQUE INDEX "
+	}
+	return kind + table + "_" + i.Name + " ON " + table + " (" + strings.Join(columns, ", ") + ");"
+}
+
+// IndexName returns the default name of an index over columns
+func IndexName(unique bool, columns ...string) string {
+	if unique {
+		return "uniq_" + strings.Join(columns, "_")
+	}
+	return "idx_" + strings.Join(columns, "_")
+}
+
+// AllIndexes returns the declared indexes followed by a single column
+// index for each non key parameter flagged with Index
+func (o Object) AllIndexes() []Index {
+	indexes := append([]Index{}, o.Indexes...)
+	for _, p := range o.Parameters {
+		if !p.Index || p.PrimaryKey {
+			continue
+		}
+		covered := false
+		for _, i := range o.Indexes {
+			if i.Columns[0].Name == p.Name.UpperCamel && i.Columns[0].Length == 0 {
+				covered = true
+			}
+		}
+		if !covered {
+			indexes = append(indexes, Index{
+				Name:    IndexName(false, p.Name.UpperCamel),
+				Columns: []IndexColumn{{Name: p.Name.UpperCamel}},
+			})
+		}
+	}
+	return indexes
+}
+
+// UniqueLookup is a GetBy function generated for a unique index
+type UniqueLookup struct {
+	Name   string      // Name of the function, ex. GetByName
+	Params []Parameter // Params are the indexed columns, in index order
+}
+
+// SQLWhere returns a WHERE clause matching every indexed column
+func (u UniqueLookup) SQLWhere() string {
+	conds := []string{}
+	for _, p := range u.Params {
+		conds = append(conds, p.Name.UpperCamel+" = ?")
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
+
+// UniqueLookups returns a lookup for each unique index
+func (o Object) UniqueLookups() []UniqueLookup {
+	lookups := []UniqueLookup{}
+	for _, i := range o.AllIndexes() {
+		if !i.Unique {
+			continue
+		}
+		u := UniqueLookup{Name: "GetBy"}
+		for _, c := range i.Columns {
+			p := o.Parameter(c.Name)
+			u.Name += p.Name.UpperCamel
+			u.Params = append(u.Params, *p)
+		}
+		lookups = append(lookups, u)
+	}
+	return lookups
+}
+
+// Check is a condition the generated Validate() reports as a field error
+type Check struct {
+	Cond   string // Cond is a Go expression that's true when the check fails
+	Reason string
+}
+
+var (
+	charLengthRegexp = regexp.MustCompile(`^(?i)(?:VAR)?CHAR\((\d+)\)$`)
+	textBytes        = map[string]int{"TINYTEXT": 255, "TEXT": 65535, "MEDIUMTEXT": 16777215}
+)
+
+// Kind returns the kind of the parameter's value, looking through nullable pointers
+func (p Parameter) Kind() reflect.Kind {
+	if p.Type.Kind() == reflect.Ptr {
+		return p.Type.Elem().Kind()
+	}
+	return p.Type.Kind()
+}
+
+// MaxChars returns the maximum length of a string parameter in characters
+func (p Parameter) MaxChars() int {
+	if p.MaxLength > 0 {
+		return p.MaxLength
+	}
+	if m := charLengthRegexp.FindStringSubmatch(p.SQLType); m != nil && p.Kind() == reflect.String && !p.Hex() {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// PatternVar names the package variable holding the compiled Pattern
+func (p Parameter) PatternVar() string {
+	return p.Name.LowerCamel + "Pattern"
+}
+
+// Checks returns the conditions Validate() tests for the parameter
+func (p Parameter) Checks() []Check {
+	field := "o." + p.Name.UpperCamel
+	value := field
+	if p.Type.Kind() == reflect.Ptr {
+		value = "*" + field
+	}
+	checks := []Check{}
+	if p.Required {
+		switch {
+		case p.Type.Kind() == reflect.Ptr:
+			checks = append(checks, Check{field + " == nil", "is required"})
+		case p.Kind() == reflect.String:
+			checks = append(checks, Check{value + ` == ""`, "is required"})
+		case p.Kind() == reflect.Slice:
+			checks = append(checks, Check{"len(" + value + ") == 0", "is required"})
+		case p.Kind() == reflect.Struct:
+			checks = append(checks, Check{value + ".IsZero()", "is required"})
+		}
+	}
+	values := []Check{}
+	if p.Hex() {
+		values = append(values, Check{value + ` != "" && !domain.IsHexID(` + value + ")", "must be 32 hexadecimal characters"})
+	}
+	if n := p.MaxChars(); n > 0 {
+		values = append(values, Check{fmt.Sprintf("utf8.RuneCountInString(%s) > %d", value, n),
+			fmt.Sprintf("must be at most %d characters", n)})
+	}
+	if n, ok := textBytes[strings.ToUpper(p.SQLType)]; ok && p.MaxLength == 0 {
+		values = append(values, Check{fmt.Sprintf("len(%s) > %d", value, n), fmt.Sprintf("must be at most %d bytes", n)})
+	}
+	if p.Min != nil {
+		values = append(values, Check{fmt.Sprintf("float64(%s) < %v", value, *p.Min), fmt.Sprintf("must be at least %v", *p.Min)})
+	}
+	if p.Max != nil {
+		values = append(values, Check{fmt.Sprintf("float64(%s) > %v", value, *p.Max), fmt.Sprintf("must be at most %v", *p.Max)})
+	}
+	if p.Pattern != "" {
+		values = append(values, Check{"!" + p.PatternVar() + ".MatchString(" + value + ")", "must match " + p.Pattern})
+	}
+	if p.Enum != nil {
+		values = append(values, Check{"!" + field + ".Valid()", "must be one of " + strings.Join(p.OneOf, ", ")})
+	} else if len(p.OneOf) > 0 {
+		conds := []string{}
+		for _, v := range p.OneOf {
+			conds = append(conds, fmt.Sprintf("%s != %q", value, v))
+		}
+		values = append(values, Check{strings.Join(conds, " && "), "must be one of " + strings.Join(p.OneOf, ", ")})
+	}
+	for _, c := range values {
+		if p.Type.Kind() == reflect.Ptr {
+			c.Cond = field + " != nil && (" + c.Cond + ")"
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// ValidateImports returns the packages the generated Validate() needs
+func (o Object) ValidateImports() []string {
+	imports := map[string]bool{}
+	for _, p := range o.Parameters {
+		if p.MaxChars() > 0 {
+			imports["unicode/utf8"] = true
+		}
+		if p.Pattern != "" {
+			imports["regexp"] = true
+		}
+	}
+	list := []string{}
+	for i := range imports {
+		list = append(list, i)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// ValueType returns the parameter's type without the nullable pointer,
+// as taken by the generated query builder
+func (p Parameter) ValueType() string {
+	return strings.TrimPrefix(p.GoType(), "*")
+}
+
+// IsNumeric reports whether the parameter is a number
+func (p Parameter) IsNumeric() bool {
+	switch p.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// IsTime reports whether the parameter is a time.Time
+func (p Parameter) IsTime() bool {
+	return p.Kind() == reflect.Struct && strings.HasSuffix(p.GoType(), "time.Time")
+}
+
+// IsText reports whether the parameter holds text that can be matched
+// with LIKE, which an enum's few values don't need
+func (p Parameter) IsText() bool {
+	return p.Kind() == reflect.String && !p.Hex() && p.Enum == nil
+}
+
+// IsJSON reports whether the parameter holds a JSON document
+func (p Parameter) IsJSON() bool {
+	return p.ValueType() == "json.RawMessage"
+}
+
+// Comparable reports whether the column can be compared and sorted
+func (p Parameter) Comparable() bool {
+	return p.Kind() != reflect.Slice
+}
+
+// HasOp reports whether the REST operation is generated for the object
+func (o Object) HasOp(op string) bool {
+	for _, r := range o.REST {
+		if r == op {
+			return true
+		}
+	}
+	return false
+}
+
+// OpTypeID returns the TypeID of a REST operation's input, derived from
+// the object's TypeID so it's stable between runs
+func (o Object) OpTypeID(op string) string {
+	sum := md5.Sum([]byte(o.TypeID + ":" + op))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// InputParameters returns the parameters a client provides, the
+// arguments of New
+func (o Object) InputParameters() []Parameter {
+	params := []Parameter{}
+	for _, p := range o.Parameters {
+		if p.ConstructorOverride == "" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// MutableParameters returns the input parameters outside the primary key
+func (o Object) MutableParameters() []Parameter {
+	params := []Parameter{}
+	for _, p := range o.InputParameters() {
+		if !p.PrimaryKey {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// NullableParameters returns the MutableParameters that may be written
+// as NULL, nullable and not required
+func (o Object) NullableParameters() []Parameter {
+	params := []Parameter{}
+	for _, p := range o.MutableParameters() {
+		if p.Nullable && !p.Required {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// TypeImports returns the packages needed by the types of the parameters
+func (o Object) TypeImports() []string {
+	imports := map[string]bool{}
+	for _, p := range o.InputParameters() {
+		if pkg := p.Import(); pkg != "" {
+			imports[pkg] = true
+		}
+	}
+	list := []string{}
+	for i := range imports {
+		list = append(list, i)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,91 @@
+package domain
+
+import "strings"
+
+// CycleError is returned by SortByReferences when foreign keys reference
+// each other in a loop, so no table can be created first
+type CycleError struct {
+	Names []string // Names of the objects in the loop, the first repeated last
+}
+
+// Error returns the error string
+func (err *CycleError) Error() string {
+	return "foreign keys form a cycle: " + strings.Join(err.Names, " -> ")
+}
+
+// SortByReferences orders objects so each follows every object its
+// foreign keys reference, keeping the given order otherwise. A foreign key
+// to the object itself doesn't count, references to objects that aren't
+// in the list are ignored.
+func SortByReferences(objects []Object) ([]Object, error) {
+	deps := map[string][]string{}
+	known := map[string]bool{}
+	for _, o := range objects {
+		known[o.Name.UpperCamel] = true
+	}
+	for _, o := range objects {
+		name := o.Name.UpperCamel
+		for _, p := range o.Parameters {
+			if p.ForeignKey == nil || p.ForeignKey.Table == name || !known[p.ForeignKey.Table] {
+				continue
+			}
+			deps[name] = append(deps[name], p.ForeignKey.Table)
+		}
+	}
+
+	sorted := make([]Object, 0, len(objects))
+	placed := map[string]bool{}
+	for len(sorted) < len(objects) {
+		progress := false
+		for _, o := range objects {
+			name := o.Name.UpperCamel
+			if placed[name] || !all(deps[name], placed) {
+				continue
+			}
+			sorted = append(sorted, o)
+			placed[name] = true
+			progress = true
+			break
+		}
+		if !progress {
+			return nil, &CycleError{Names: cycle(objects, deps, placed)}
+		}
+	}
+	return sorted, nil
+}
+
+func all(names []string, set map[string]bool) bool {
+	for _, n := range names {
+		if !set[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// cycle follows unplaced dependencies from the first unplaced object
+// until one repeats. Every unplaced object has one, or it'd be placed.
+func cycle(objects []Object, deps map[string][]string, placed map[string]bool) []string {
+	name := ""
+	for _, o := range objects {
+		if !placed[o.Name.UpperCamel] {
+			name = o.Name.UpperCamel
+			break
+		}
+	}
+	path := []string{}
+	seen := map[string]int{}
+	for {
+		if i, ok := seen[name]; ok {
+			return append(path[i:], name)
+		}
+		seen[name] = len(path)
+		path = append(path, name)
+		for _, d := range deps[name] {
+			if !placed[d] {
+				name = d
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/gen/namecase"
+)
+
+// objects returns an object for each "Name" or "Name:Ref,Ref", with a
+// foreign key to each Ref
+func objects(specs ...string) []Object {
+	list := []Object{}
+	for _, spec := range specs {
+		name, refs, _ := strings.Cut(spec, ":")
+		o := Object{Name: namecase.New(name)}
+		for _, ref := range strings.Split(refs, ",") {
+			if ref != "" {
+				o.Parameters = append(o.Parameters, Parameter{
+					Name:       namecase.New(ref + "ID"),
+					ForeignKey: &ForeignKey{Table: ref, Column: "ID"},
+				})
+			}
+		}
+		list = append(list, o)
+	}
+	return list
+}
+
+func TestSortByReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects []Object
+		want    string // the names in order, or the error
+	}{
+		{"none", objects(), ""},
+		{"unrelated", objects("B", "A"), "B A"},
+		{"chain", objects("Desk:Node", "Attendant:Desk", "Node"), "Node Desk Attendant"},
+		{"diamond", objects("D:B,C", "C:A", "B:A", "A"), "A C B D"},
+		{"self-reference", objects("Node:Node", "Desk:Node"), "Node Desk"},
+		{"unknown reference", objects("Desk:Node"), "Desk"},
+		{"cycle", objects("Node:Desk", "Desk:Node"), "foreign keys form a cycle: Node -> Desk -> Node"},
+		{"cycle past a chain", objects("A:B", "B:C", "C:D", "D:B"), "foreign keys form a cycle: B -> C -> D -> B"},
+	}
+	for _, test := range tests {
+		sorted, err := SortByReferences(test.objects)
+		got := ""
+		if err != nil {
+			if _, ok := err.(*CycleError); !ok {
+				t.Errorf("%s: got %T, want *CycleError", test.name, err)
+			}
+			got = err.Error()
+		} else {
+			names := []string{}
+			for _, o := range sorted {
+				names = append(names, o.Name.UpperCamel)
+			}
+			got = strings.Join(names, " ")
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	// the objects keep their parameters
+	sorted, err := SortByReferences(objects("Desk:Node", "Node"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sorted[1], objects("Desk:Node")[0]) {
+		t.Fatalf("got %+v", sorted[1])
+	}
+}
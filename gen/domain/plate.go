@@ -10,26 +10,41 @@ var Plate = map[string]string{
 package {{ .Name.Lower }}
 
 import (
+	"context"
+	{{- if or .Versioned .SoftDelete .UpdateParameters }}
+	"database/sql"
+	{{- end }}
 	"fmt"
 	"encoding/json"
-	{{ range $k, $v := .Imports -}}
+	"strings"
+	{{- range $k, $v := .Imports }}{{ if stdlib $v }}
+	"{{ $v }}"
+	{{- end }}{{ end }}
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	{{- range $k, $v := .Imports }}{{ if not (stdlib $v) }}
 	"{{ $v }}"
+	{{- end }}{{ end }}
+	{{- range $k, $v := .RelationImports }}
+	"git.ottoq.com/otto-backend/valet/domain/{{ $v }}"
 	{{- end }}
-	
 	"git.ottoq.com/otto-backend/valet/entity"
 )
-
 type {{ .Name.UpperCamel }} struct {
 	{{- range $p := .Parameters }}
-	{{ $p.Name.UpperCamel }} {{ $p.Type }}
+	{{ $p.Name.UpperCamel }} {{ $p.GoType }}
 	{{- end }}
+
+	// changed holds the value of each field when o was last read or
+	// written, or before its first setter call if it wasn't
+	changed map[string]interface{}
 }
 
 func New(
   {{ range $i, $param := .Parameters -}}
   {{ if ne .ConstructorOverride "" }}
   {{- else -}}
-  {{ $param.Name.LowerCamel }} {{ $param.Type }},
+  {{ $param.Name.LowerCamel }} {{ $param.GoType }},
   {{ end }} 
   {{- end -}}
 ) (*{{ .Name.UpperCamel }}, error) {
@@ -43,9 +58,129 @@ func New(
 	  {{- end }}
 	  {{ end }}
 	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
 	return d, nil
 }
 
+{{ range $p := .Parameters }}{{ if $p.Pattern -}}
+var {{ $p.PatternVar }} = regexp.MustCompile({{ printf "%q" $p.Pattern }})
+{{ end }}{{ end }}
+// Validate checks every field against its constraints, returning a
+// domain.ValidationError listing each field that failed
+func (o *{{ .Name.UpperCamel }}) Validate() error {
+	errs := domain.ValidationError{}
+	{{- range $p := .Parameters }}{{ range $c := $p.Checks }}
+	if {{ $c.Cond }} {
+		errs = append(errs, &domain.FieldError{Field: "{{ $p.Name.UpperCamel }}", Reason: {{ printf "%q" $c.Reason }}})
+	}
+	{{- end }}{{ end }}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+///////////////////
+// CHANGES
+///////////////////
+{{ range $p := .MutableParameters }}
+// Set{{ $p.Name.UpperCamel }} sets {{ $p.Name.UpperCamel }}, recording the change for Changes() and Update
+func (o *{{ $.Name.UpperCamel }}) Set{{ $p.Name.UpperCamel }}(v {{ $p.GoType }}) {
+	o.track("{{ $p.Name.UpperCamel }}", o.{{ $p.Name.UpperCamel }})
+	o.{{ $p.Name.UpperCamel }} = v
+}
+{{ end }}
+// track records the value a field had before it was first set
+func (o *{{ .Name.UpperCamel }}) track(field string, old interface{}) {
+	if o.changed == nil {
+		o.changed = map[string]interface{}{}
+	}
+	if _, ok := o.changed[field]; !ok {
+		o.changed[field] = domain.Copy(old)
+	}
+}
+
+// saved records the value of every field as the row holds it
+func (o *{{ .Name.UpperCamel }}) saved() {
+	o.changed = map[string]interface{}{
+		{{- range $p := .MutableParameters }}
+		"{{ $p.Name.UpperCamel }}": domain.Copy(o.{{ $p.Name.UpperCamel }}),
+		{{- end }}
+	}
+}
+
+// Changes lists the fields holding a new value since o was read or last
+// written, in column order, however they were assigned. Of an o that
+// wasn't, only the fields set through a setter are tracked.
+func (o *{{ .Name.UpperCamel }}) Changes() []domain.Change {
+	changes := []domain.Change{}
+	{{- range $p := .MutableParameters }}
+	if old, ok := o.changed["{{ $p.Name.UpperCamel }}"]; ok && domain.Changed(old, o.{{ $p.Name.UpperCamel }}) {
+		changes = append(changes, domain.Change{Field: "{{ $p.Name.UpperCamel }}", Old: old, New: o.{{ $p.Name.UpperCamel }}})
+	}
+	{{- end }}
+	return changes
+}
+
+// Patch applies a JSON merge patch (RFC 7386) to o through its setters and
+// validates the result, returning a domain.ValidationError and leaving o
+// unchanged if either fails. The primary key{{ if .Versioned }} and version{{ end }} may be named but
+// not changed, other fields can't be patched.
+func (o *{{ .Name.UpperCamel }}) Patch(patch []byte) error {
+	fields, err := domain.ParsePatch(patch)
+	if err != nil {
+		return err
+	}
+	patched := *o
+	patched.changed = nil
+	for field, old := range o.changed {
+		patched.track(field, old)
+	}
+	errs := domain.ValidationError{}
+	for _, f := range fields {
+		switch f.Name {
+		{{- range $p := .MutableParameters }}
+		case "{{ $p.Name.UpperCamel }}":
+			{{- if $p.IsJSON }}
+			if v, err := f.MergeJSON(o.{{ $p.Name.UpperCamel }}, {{ $p.Nullable }}); err != nil {
+				errs = append(errs, err)
+			} else {
+				patched.Set{{ $p.Name.UpperCamel }}(v)
+			}
+			{{- else }}
+			var v {{ $p.GoType }}
+			if err := f.Decode(&v, {{ $p.Nullable }}); err != nil {
+				errs = append(errs, err)
+			} else {
+				patched.Set{{ $p.Name.UpperCamel }}(v)
+			}
+			{{- end }}
+		{{- end }}
+		{{- range $p := .PatchKeys }}
+		case "{{ $p.Name.UpperCamel }}":
+			var v {{ $p.GoType }}
+			if err := f.Decode(&v, false); err != nil {
+				errs = append(errs, err)
+			} else if v != o.{{ $p.Name.UpperCamel }} {
+				errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be changed"})
+			}
+		{{- end }}
+		default:
+			errs = append(errs, &domain.FieldError{Field: f.Name, Reason: "can't be patched"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if err := patched.Validate(); err != nil {
+		return err
+	}
+	*o = patched
+	return nil
+}
+
 type Scannable interface {
 	Scan(dest ...interface{}) error
 }
@@ -54,23 +189,35 @@ func NewFromRow(row Scannable) (*{{ .Name.UpperCamel }}, error) {
 	d := {{ .Name.UpperCamel }}{}
 	err := row.Scan(
 	  {{ range $i, $param := .Parameters -}}
-	  &d.{{ $param.Name.UpperCamel }},
+	  {{ $param.SQLScan (print "&d." $param.Name.UpperCamel) }},
 	  {{ end }}
 	)
 	if err != nil {
 		return nil, err
 	}
+	d.saved()
 	return &d, nil
 }
 
-func Schema() string {
-	return ` + "`" + `{{ .SQLSchema }} ` + "`" + `
+// schema creates the table in each dialect
+var schema = domain.Statements{
+	{{- range .SQLSchemas }}
+	{{ .Dialect.Const }}: {{ literal .SQL }},
+	{{- end }}
+}
+
+// Schema returns the statements creating the table in each dialect
+func Schema() domain.Statements {
+	return schema
 }
 
 func TableName() string {
 	return "{{ .Name.UpperCamel }}"
 }
 
+// TypeID identifies {{ .Name.UpperCamel }} objects
+const TypeID = "{{ .TypeID }}"
+
 func Random() *{{ .Name.UpperCamel }} {
 	d := &{{ .Name.UpperCamel }} {
 	  {{ range $i, $param := .Parameters -}}
@@ -78,35 +225,841 @@ func Random() *{{ .Name.UpperCamel }} {
 	  {{- if ne .ConstructorOverride "" -}}
 	  {{ .ConstructorOverride }},
 	  {{- else -}}
-	  entity.RAND{{ $param.Type }}(),
+	  {{ $param.Random }},
 	  {{- end }}
 	  {{ end }}
 	}
 	return d
 }
 
-func (o *{{ .Name.UpperCamel }}) InsertString() string {
-	istr := fmt.Sprintf(` + "`" + `{{ .SQLInsert }}` + "`" + `,
-	  {{ range $i, $param := .Parameters -}}
-	  {{ if contains $param.Type.String "time" -}}
-	  o.{{ $param.Name.UpperCamel }}.Format("2006-01-02 15:04:05"),
-	  {{- else -}}
-	  o.{{ $param.Name.UpperCamel }},
-	  {{- end }}
+///////////////////
+// REPOSITORY
+///////////////////
+
+const (
+	sqlSelect = ` + "`" + `{{ .SQLSelect }}` + "`" + `
+	{{- if .SoftDelete }}
+	sqlSelectWithDeleted = ` + "`" + `{{ .SQLSelectWithDeleted }}` + "`" + `
+	{{- end }}
+	sqlInsert = ` + "`" + `{{ .SQLInsert }}` + "`" + `
+	{{- if .UpdateParameters }}
+	sqlUpdate = ` + "`" + `{{ .SQLUpdate }}` + "`" + `
+	{{- end }}
+	sqlDelete = ` + "`" + `{{ .SQLDelete }}` + "`" + `
+	{{- if .SoftDelete }}
+	sqlRestore = ` + "`" + `{{ .SQLRestore }}` + "`" + `
+	{{- end }}
+	{{- if .CreatedParameters }}
+	sqlSelectCreated = ` + "`" + `{{ .SQLSelectCreated }}` + "`" + `
+	{{- end }}
+)
+
+// sqlUpsert differs in each dialect
+var sqlUpsert = domain.Statements{
+	{{- range .SQLUpserts }}
+	{{ .Dialect.Const }}: {{ literal .SQL }},
+	{{- end }}
+}
+
+var _ domain.Domain = (*{{ .Name.UpperCamel }})(nil)
+
+// SQLSelect returns the SELECT of every column, in NewFromRow scan order
+func SQLSelect() string {
+	return sqlSelect
+}
+
+// GetByID returns the {{ .Name.UpperCamel }} with the given primary key
+func GetByID(ctx context.Context, db domain.DB
+	{{- range $i, $param := .PrimaryKeys }}, {{ $param.Name.LowerCamel }} {{ $param.GoType }}{{ end }}) (*{{ .Name.UpperCamel }}, error) {
+	row := db.QueryRowContext(ctx, sqlSelect+" {{ .SQLWherePrimary }}"
+		{{- range $i, $param := .PrimaryKeys }}, {{ $param.SQLArg $param.Name.LowerCamel }}{{ end }})
+	return NewFromRow(row)
+}
+
+{{ range $u := .UniqueLookups -}}
+// {{ $u.Name }} returns the {{ $.Name.UpperCamel }} with the given
+{{- range $i, $p := $u.Params }}{{ if $i }},{{ end }} {{ $p.Name.UpperCamel }}{{ end }}
+func {{ $u.Name }}(ctx context.Context, db domain.DB
+	{{- range $i, $p := $u.Params }}, {{ $p.Name.LowerCamel }} {{ $p.GoType }}{{ end }}) (*{{ $.Name.UpperCamel }}, error) {
+	row := db.QueryRowContext(ctx, sqlSelect+" {{ $u.SQLWhere }}"
+		{{- range $i, $p := $u.Params }}, {{ $p.SQLArg $p.Name.LowerCamel }}{{ end }})
+	return NewFromRow(row)
+}
+
+{{ end -}}
+// List returns up to limit {{ .Name.UpperCamel }}s ordered by primary key, skipping the first offset
+func List(ctx context.Context, db domain.DB, limit, offset int) ([]*{{ .Name.UpperCamel }}, error) {
+	return Select(ctx, db, "{{ .SQLOrderPrimary }} LIMIT ? OFFSET ?", limit, offset)
+}
+
+// Select returns every {{ .Name.UpperCamel }} matched by clause, which is appended to
+// the SELECT statement, ex. "WHERE Name = ? LIMIT 10"
+func Select(ctx context.Context, db domain.DB, clause string, args ...interface{}) ([]*{{ .Name.UpperCamel }}, error) {
+	return query(ctx, db, sqlSelect+" "+clause, args...)
+}
+
+func query(ctx context.Context, db domain.DB, stmt string, args ...interface{}) ([]*{{ .Name.UpperCamel }}, error) {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []*{{ .Name.UpperCamel }}{}
+	for rows.Next() {
+		o, err := NewFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// Insert writes o as a new row
+func (o *{{ .Name.UpperCamel }}) Insert(ctx context.Context, db domain.DB) error {
+	{{- if .Stamped }}
+	o.stamp(ctx, true)
+	{{- end }}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, sqlInsert, o.values()...); err != nil {
+		return err
+	}
+	o.saved()
+	return nil
+}
+
+{{ if .Versioned -}}
+// Upsert writes o as a new row, or overwrites the row that shares its
+// primary key if it's still at o's version, returning a
+// *domain.ErrStaleObject if it isn't{{ if .SoftDelete }} or it's deleted{{ end }}
+func (o *{{ .Name.UpperCamel }}) Upsert(ctx context.Context, db domain.DB) error {
+	{{- if .Stamped }}
+	o.stamp(ctx, true)
+	{{- end }}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if domain.DialectOf(db) != domain.MySQL {
+		// the version written is returned, or no row if o is stale
+		err := db.QueryRowContext(ctx, sqlUpsert.For(db), o.values()...).Scan(&o.Version)
+		if err == sql.ErrNoRows {
+			return &domain.ErrStaleObject{Table: TableName(), Version: o.Version}
+		}
+		if err != nil {
+			return err
+		}
+		{{- if .CreatedParameters }}
+		// the row may have existed
+		if err := o.readCreated(ctx, db); err != nil {
+			return err
+		}
+		{{- end }}
+		o.saved()
+		return nil
+	}
+	res, err := db.ExecContext(ctx, sqlUpsert.For(db), o.values()...)
+	if err != nil {
+		return err
+	}
+	// MySQL counts an inserted row once, an updated row twice and an
+	// unchanged one not at all
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	switch n {
+	case 0:
+		return &domain.ErrStaleObject{Table: TableName(), Version: o.Version}
+	case 2:
+		o.Version++
+		{{- if .CreatedParameters }}
+		if err := o.readCreated(ctx, db); err != nil {
+			return err
+		}
+		{{- end }}
+	}
+	o.saved()
+	return nil
+}
+
+// Update overwrites the row that shares o's primary key and version,
+// incrementing the version. It returns a *domain.ErrStaleObject if the
+// row changed since o was read{{ if .SoftDelete }} or it's deleted{{ end }}.
+// Only the changed columns are written, and nothing if there are none.
+// Every column is written for an o that wasn't read or written, unless a
+// setter was used.
+func (o *{{ .Name.UpperCamel }}) Update(ctx context.Context, db domain.DB) error {
+	changes := o.Changes()
+	if o.changed != nil && len(changes) == 0 {
+		return o.Validate()
+	}
+	{{- if .Stamped }}
+	o.stamp(ctx, false)
+	{{- end }}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	stmt, args := sqlUpdate, []interface{}{
+	  {{ range $i, $param := .UpdateParameters -}}
+	  {{ $param.SQLArg (print "o." $param.Name.UpperCamel) }},
 	  {{ end }}
+	  {{- range $i, $param := .PrimaryKeys -}}
+	  {{ $param.SQLArg (print "o." $param.Name.UpperCamel) }},
+	  {{ end -}}
+	  o.Version,
+	}
+	if o.changed != nil {
+		stmt, args = o.sqlUpdateChanges(changes)
+	}
+	res, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &domain.ErrStaleObject{Table: TableName(), Version: o.Version}
+	}
+	o.Version++
+	o.saved()
+	return nil
+}
+{{- else -}}
+// Upsert writes o as a new row, or overwrites the row that shares its primary key
+{{- if .SoftDelete }}
+// unless it's deleted, returning a *domain.ErrDeleted if it is{{ end }}
+func (o *{{ .Name.UpperCamel }}) Upsert(ctx context.Context, db domain.DB) error {
+	{{- if .Stamped }}
+	o.stamp(ctx, true)
+	{{- end }}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	{{- if or .SoftDelete .CreatedParameters }}
+	res, err := db.ExecContext(ctx, sqlUpsert.For(db), o.values()...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	{{- if .SoftDelete }}
+	// a deleted row is left as it is, which isn't counted, nor is a row
+	// MySQL leaves unchanged
+	if n == 0 {
+		found, err := o.exists(ctx, db)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return &domain.ErrDeleted{Table: TableName()}
+		}
+	}
+	{{- end }}
+	{{- if .CreatedParameters }}
+	// MySQL counts an inserted row once, other databases can't tell an
+	// insert from an update
+	if n != 1 || domain.DialectOf(db) != domain.MySQL {
+		if err := o.readCreated(ctx, db); err != nil {
+			return err
+		}
+	}
+	{{- end }}
+	{{- else }}
+	if _, err := db.ExecContext(ctx, sqlUpsert.For(db), o.values()...); err != nil {
+		return err
+	}
+	{{- end }}
+	o.saved()
+	return nil
+}
+
+{{ if not .UpdateParameters -}}
+// Update only validates o, every column being part of its primary key
+func (o *{{ .Name.UpperCamel }}) Update(ctx context.Context, db domain.DB) error {
+	return o.Validate()
+}
+{{- else -}}
+// Update overwrites the row that shares o's primary key, returning
+// sql.ErrNoRows if there's none{{ if .SoftDelete }} or it's deleted{{ end }}.
+// Only the changed columns are written, and nothing if there are none.
+// Every column is written for an o that wasn't read or written, unless a
+// setter was used.
+func (o *{{ .Name.UpperCamel }}) Update(ctx context.Context, db domain.DB) error {
+	changes := o.Changes()
+	if o.changed != nil && len(changes) == 0 {
+		return o.Validate()
+	}
+	{{- if .Stamped }}
+	o.stamp(ctx, false)
+	{{- end }}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	stmt, args := sqlUpdate, []interface{}{
+	  {{ range $i, $param := .UpdateParameters -}}
+	  {{ $param.SQLArg (print "o." $param.Name.UpperCamel) }},
+	  {{ end }}
+	  {{- range $i, $param := .PrimaryKeys -}}
+	  {{ $param.SQLArg (print "o." $param.Name.UpperCamel) }},
+	  {{ end -}}
+	}
+	if o.changed != nil {
+		stmt, args = o.sqlUpdateChanges(changes)
+	}
+	res, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	// MySQL doesn't count a row it leaves unchanged
+	if n == 0 {
+		found, err := o.exists(ctx, db)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return sql.ErrNoRows
+		}
+	}
+	o.saved()
+	return nil
+}
+{{- end }}
+{{- end }}
+{{ if .UpdateParameters }}
+// updateColumn returns the SET assignment of a column a setter changes
+// and its argument
+func (o *{{ .Name.UpperCamel }}) updateColumn(field string) (string, interface{}) {
+	switch field {
+	{{- range $p := .MutableParameters }}
+	case "{{ $p.Name.UpperCamel }}":
+		return "{{ $p.Name.UpperCamel }} = ?", {{ $p.SQLArg (print "o." $p.Name.UpperCamel) }}
+	{{- end }}
+	}
+	panic("no column " + field)
+}
+
+// sqlUpdateChanges returns an UPDATE of the changed columns, along with
+// those every Update sets, and its arguments
+func (o *{{ .Name.UpperCamel }}) sqlUpdateChanges(changes []domain.Change) (string, []interface{}) {
+	sets := []string{}
+	args := []interface{}{}
+	for _, c := range changes {
+		set, arg := o.updateColumn(c.Field)
+		sets = append(sets, set)
+		args = append(args, arg)
+	}
+	{{- range $p := .UpdateParameters }}{{ if $p.Audit }}
+	sets = append(sets, "{{ $p.Name.UpperCamel }} = ?")
+	args = append(args, {{ $p.SQLArg (print "o." $p.Name.UpperCamel) }})
+	{{- end }}{{ end }}
+	{{- with .VersionParameter }}
+	sets = append(sets, "{{ .Name.UpperCamel }} = {{ .Name.UpperCamel }} + 1")
+	{{- end }}
+	args = append(args
+		{{- range $p := .PrimaryKeys }}, {{ $p.SQLArg (print "o." $p.Name.UpperCamel) }}{{ end }}
+		{{- with .VersionParameter }}, o.{{ .Name.UpperCamel }}{{ end }})
+	return "UPDATE {{ .Name.UpperCamel }} SET " + strings.Join(sets, ", ") + " {{ .SQLUpdateWhere }}", args
+}
+{{ end }}
+
+{{ if .SoftDelete -}}
+// Delete marks the row that shares o's primary key deleted. It's left out
+// of every query but QueryBuilder.WithDeleted until it's restored.
+{{- if .Versioned }} It
+// returns a *domain.ErrStaleObject if the row changed since o was read
+// or it's already deleted.
+{{- else }} It
+// returns sql.ErrNoRows if there's no such row or it's already deleted.
+{{- end }}
+func (o *{{ .Name.UpperCamel }}) Delete(ctx context.Context, db domain.DB) error {
+	now := entity.Now().Truncate(time.Second)
+	return o.setDeleted(ctx, db, sqlDelete, &now)
+}
+
+// Restore undoes Delete, returning {{ if .Versioned }}a *domain.ErrStaleObject if the row
+// changed since o was read{{ else }}sql.ErrNoRows if there's no such
+// row{{ end }} or it isn't deleted
+func (o *{{ .Name.UpperCamel }}) Restore(ctx context.Context, db domain.DB) error {
+	return o.setDeleted(ctx, db, sqlRestore, nil)
+}
+
+// setDeleted sets DeletedAt with stmt, sqlDelete or sqlRestore
+func (o *{{ .Name.UpperCamel }}) setDeleted(ctx context.Context, db domain.DB, stmt string, deletedAt *time.Time) error {
+	{{- if .Stamped }}
+	o.stamp(ctx, false)
+	{{- end }}
+	res, err := db.ExecContext(ctx, stmt
+	  {{- range $i, $param := .DeleteParameters }}, {{ if eq $param.Audit "DeletedAt" }}deletedAt{{ else }}{{ $param.SQLArg (print "o." $param.Name.UpperCamel) }}{{ end }}{{ end }}
+	  {{- range $i, $param := .PrimaryKeys }}, {{ $param.SQLArg (print "o." $param.Name.UpperCamel) }}{{ end }}
+	  {{- with .VersionParameter }}, o.{{ .Name.UpperCamel }}{{ end }})
+	if err != nil {
+		return err
+	}
+	// the row always changes, so MySQL counts it
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		{{- if .Versioned }}
+		return &domain.ErrStaleObject{Table: TableName(), Version: o.Version}
+		{{- else }}
+		return sql.ErrNoRows
+		{{- end }}
+	}
+	o.DeletedAt = deletedAt
+	{{- with .VersionParameter }}
+	o.{{ .Name.UpperCamel }}++
+	{{- end }}
+	return nil
+}
+{{- else -}}
+// Delete removes the row that shares o's primary key
+func (o *{{ .Name.UpperCamel }}) Delete(ctx context.Context, db domain.DB) error {
+	_, err := db.ExecContext(ctx, sqlDelete
+	  {{- range $i, $param := .PrimaryKeys }}, {{ $param.SQLArg (print "o." $param.Name.UpperCamel) }}{{ end }})
+	return err
+}
+{{- end }}
+{{ if .Stamped }}
+// stamp sets the audit columns of a write, created being true for a new
+// row. Upsert reads back the created columns of a row that already
+// existed, see readCreated.
+func (o *{{ .Name.UpperCamel }}) stamp(ctx context.Context, created bool) {
+	{{- if or (.HasAudit "CreatedAt") (.HasAudit "UpdatedAt") }}
+	now := entity.Now().Truncate(time.Second)
+	{{- end }}
+	{{- if or (.HasAudit "CreatedBy") (.HasAudit "UpdatedBy") }}
+	actor := domain.Actor(ctx)
+	{{- end }}
+	{{- if or (.HasAudit "CreatedAt") (.HasAudit "CreatedBy") }}
+	if created {
+		{{- if .HasAudit "CreatedAt" }}
+		o.CreatedAt = now
+		{{- end }}
+		{{- if .HasAudit "CreatedBy" }}
+		o.CreatedBy = actor
+		{{- end }}
+	}
+	{{- end }}
+	{{- if .HasAudit "UpdatedAt" }}
+	o.UpdatedAt = now
+	{{- end }}
+	{{- if .HasAudit "UpdatedBy" }}
+	o.UpdatedBy = actor
+	{{- end }}
+}
+{{ end }}
+{{- if .CreatedParameters }}
+// readCreated reads the audit columns set when o's row was created, which
+// Upsert leaves as they were when the row exists
+func (o *{{ .Name.UpperCamel }}) readCreated(ctx context.Context, db domain.DB) error {
+	return db.QueryRowContext(ctx, sqlSelectCreated
+		{{- range $p := .PrimaryKeys }}, {{ $p.SQLArg (print "o." $p.Name.UpperCamel) }}{{ end }}).Scan(
+		{{- range $i, $p := .CreatedParameters }}{{ if $i }}, {{ end }}{{ $p.SQLScan (print "&o." $p.Name.UpperCamel) }}{{ end }})
+}
+{{ end }}
+{{- if and (not .Versioned) (or .SoftDelete .UpdateParameters) }}
+// exists reports whether the row that shares o's primary key is there{{ if .SoftDelete }}
+// and not deleted{{ end }}, which a write counting no row can't tell
+func (o *{{ .Name.UpperCamel }}) exists(ctx context.Context, db domain.DB) (bool, error) {
+	n, err := Query()
+		{{- range $p := .PrimaryKeys }}.Where{{ $p.Name.UpperCamel }}(o.{{ $p.Name.UpperCamel }}){{ end }}.Count(ctx, db)
+	return n > 0, err
+}
+{{ end }}
+// values returns the argument writing each column in table order
+func (o *{{ .Name.UpperCamel }}) values() []interface{} {
+	return []interface{}{
 	  {{ range $i, $param := .Parameters -}}
-	  {{ if not $param.PrimaryKey -}}
-	  {{ if contains $param.Type.String "time" -}}
-	  o.{{ $param.Name.UpperCamel }}.Format("2006-01-02 15:04:05"),
-	  {{- else -}}
-	  o.{{ $param.Name.UpperCamel }},
-	  {{- end }}
-	  {{- end }}
+	  {{ $param.SQLArg (print "o." $param.Name.UpperCamel) }},
 	  {{ end }}
-	)
-	return istr	
+	}
+}
+
+///////////////////
+// QUERY BUILDER
+///////////////////
+
+// QueryBuilder builds a SELECT of {{ .Name.UpperCamel }}s, ex.
+// Query().Where{{ (index .Parameters 0).Name.UpperCamel }}(v).Limit(10).All(ctx, db)
+type QueryBuilder struct {
+	q domain.Query
+	{{- if .SoftDelete }}
+	withDeleted bool
+	{{- end }}
 }
 
+// Query starts a query matching every {{ .Name.UpperCamel }}
+func Query() *QueryBuilder {
+	return &QueryBuilder{}
+}
+{{ range $p := .Parameters }}{{ if $p.Comparable }}
+// Where{{ $p.Name.UpperCamel }} matches {{ $p.Name.UpperCamel }} equal to v
+func (b *QueryBuilder) Where{{ $p.Name.UpperCamel }}(v {{ $p.ValueType }}) *QueryBuilder {
+	b.q.Cond("{{ $p.Name.UpperCamel }} = ?", {{ $p.SQLArg "v" }})
+	return b
+}
+
+// {{ $p.Name.UpperCamel }}In matches {{ $p.Name.UpperCamel }} equal to any of vs
+func (b *QueryBuilder) {{ $p.Name.UpperCamel }}In(vs ...{{ $p.ValueType }}) *QueryBuilder {
+	args := make([]interface{}, len(vs))
+	for i, v := range vs {
+		args[i] = {{ $p.SQLArg "v" }}
+	}
+	b.q.In("{{ $p.Name.UpperCamel }}", "?", args)
+	return b
+}
+
+// OrderBy{{ $p.Name.UpperCamel }} sorts by {{ $p.Name.UpperCamel }}, after any order added before it
+func (b *QueryBuilder) OrderBy{{ $p.Name.UpperCamel }}(o domain.Order) *QueryBuilder {
+	b.q.Order("{{ $p.Name.UpperCamel }}", o)
+	return b
+}
+{{ end }}{{ if $p.IsText }}
+// {{ $p.Name.UpperCamel }}Like matches {{ $p.Name.UpperCamel }} against a LIKE pattern, ex. "T%"
+func (b *QueryBuilder) {{ $p.Name.UpperCamel }}Like(pattern string) *QueryBuilder {
+	b.q.Cond("{{ $p.Name.UpperCamel }} LIKE ?", pattern)
+	return b
+}
+{{ end }}{{ if $p.IsNumeric }}
+// {{ $p.Name.UpperCamel }}GreaterThan matches {{ $p.Name.UpperCamel }} greater than v
+func (b *QueryBuilder) {{ $p.Name.UpperCamel }}GreaterThan(v {{ $p.ValueType }}) *QueryBuilder {
+	b.q.Cond("{{ $p.Name.UpperCamel }} > ?", v)
+	return b
+}
+
+// {{ $p.Name.UpperCamel }}LessThan matches {{ $p.Name.UpperCamel }} less than v
+func (b *QueryBuilder) {{ $p.Name.UpperCamel }}LessThan(v {{ $p.ValueType }}) *QueryBuilder {
+	b.q.Cond("{{ $p.Name.UpperCamel }} < ?", v)
+	return b
+}
+{{ end }}{{ if $p.IsTime }}
+// {{ $p.Name.UpperCamel }}After matches {{ $p.Name.UpperCamel }} later than t
+func (b *QueryBuilder) {{ $p.Name.UpperCamel }}After(t time.Time) *QueryBuilder {
+	b.q.Cond("{{ $p.Name.UpperCamel }} > ?", t)
+	return b
+}
+
+// {{ $p.Name.UpperCamel }}Before matches {{ $p.Name.UpperCamel }} earlier than t
+func (b *QueryBuilder) {{ $p.Name.UpperCamel }}Before(t time.Time) *QueryBuilder {
+	b.q.Cond("{{ $p.Name.UpperCamel }} < ?", t)
+	return b
+}
+{{ end }}{{ if $p.Nullable }}
+// {{ $p.Name.UpperCamel }}IsNull matches rows without a {{ $p.Name.UpperCamel }}
+func (b *QueryBuilder) {{ $p.Name.UpperCamel }}IsNull() *QueryBuilder {
+	b.q.Cond("{{ $p.Name.UpperCamel }} IS NULL")
+	return b
+}
+
+// {{ $p.Name.UpperCamel }}IsNotNull matches rows with a {{ $p.Name.UpperCamel }}
+func (b *QueryBuilder) {{ $p.Name.UpperCamel }}IsNotNull() *QueryBuilder {
+	b.q.Cond("{{ $p.Name.UpperCamel }} IS NOT NULL")
+	return b
+}
+{{ end }}{{ if $p.ForeignKey }}
+// PartitionBy{{ $p.Name.UpperCamel }} pages the {{ $.Name.UpperCamel }}s of each {{ $p.ForeignKey.Table }} apart, see
+// domain.Query.Partition. Each row of SQL then ends with the
+// {{ $p.Name.UpperCamel }}, so All can't read them.
+func (b *QueryBuilder) PartitionBy{{ $p.Name.UpperCamel }}() *QueryBuilder {
+	b.q.Partition("{{ $p.Name.UpperCamel }}")
+	return b
+}
+{{ end }}{{ end }}
+// Limit returns at most n {{ .Name.UpperCamel }}s
+func (b *QueryBuilder) Limit(n int) *QueryBuilder {
+	b.q.Limit(n)
+	return b
+}
+
+// Offset skips the first n {{ .Name.UpperCamel }}s
+func (b *QueryBuilder) Offset(n int) *QueryBuilder {
+	b.q.Offset(n)
+	return b
+}
+
+{{ range $l := .Links }}
+// LinkedTo{{ $l.Plural }} matches the {{ $.Name.UpperCamel }}s linked to any of the {{ $l.Plural }} with
+// {{ $l.OtherColumn.Name.LowerCamel }}s, partitioned by the {{ $l.Other.UpperCamel }}
+func (b *QueryBuilder) LinkedTo{{ $l.Plural }}({{ $l.OtherColumn.Name.LowerCamel }}s ...{{ $l.OtherColumn.ValueType }}) *QueryBuilder {
+	args := make([]interface{}, len({{ $l.OtherColumn.Name.LowerCamel }}s))
+	for i, v := range {{ $l.OtherColumn.Name.LowerCamel }}s {
+		args[i] = {{ $l.OtherColumn.SQLArg "v" }}
+	}
+	b.q.Join("{{ $l.SQLJoinLinked }} IN ("+domain.Placeholders("?", len(args))+")) AS Link ON Link.Linked = {{ $.Name.UpperCamel }}.{{ $l.Key.Name.UpperCamel }}", args...)
+	b.q.Partition("Link.LinkedTo")
+	return b
+}
+{{ end }}
+{{ if .SoftDelete -}}
+// WithDeleted includes soft deleted {{ .Name.UpperCamel }}s
+func (b *QueryBuilder) WithDeleted() *QueryBuilder {
+	b.withDeleted = true
+	return b
+}
+
+// SQL returns the parameterized statement and its arguments
+func (b *QueryBuilder) SQL() (string, []interface{}) {
+	if b.withDeleted {
+		return b.q.Select(sqlSelectWithDeleted)
+	}
+	return b.q.Select(sqlSelect)
+}
+
+// CountSQL returns the statement counting the matching {{ .Name.UpperCamel }}s, ignoring
+// Limit and Offset, and its arguments. Partitioned, it selects each
+// partition and its count.
+func (b *QueryBuilder) CountSQL() (string, []interface{}) {
+	if b.withDeleted {
+		return b.q.Count("{{ .Name.UpperCamel }}")
+	}
+	return b.q.Count("{{ .SQLFrom }}")
+}
+{{- else -}}
+// SQL returns the parameterized statement and its arguments
+func (b *QueryBuilder) SQL() (string, []interface{}) {
+	return b.q.Select(sqlSelect)
+}
+
+// CountSQL returns the statement counting the matching {{ .Name.UpperCamel }}s, ignoring
+// Limit and Offset, and its arguments. Partitioned, it selects each
+// partition and its count.
+func (b *QueryBuilder) CountSQL() (string, []interface{}) {
+	return b.q.Count("{{ .SQLFrom }}")
+}
+{{- end }}
+
+// All returns every matching {{ .Name.UpperCamel }}
+func (b *QueryBuilder) All(ctx context.Context, db domain.DB) ([]*{{ .Name.UpperCamel }}, error) {
+	stmt, args := b.SQL()
+	return query(ctx, db, stmt, args...)
+}
+
+// First returns the first matching {{ .Name.UpperCamel }}, or sql.ErrNoRows
+func (b *QueryBuilder) First(ctx context.Context, db domain.DB) (*{{ .Name.UpperCamel }}, error) {
+	b.q.Limit(1)
+	query, args := b.SQL()
+	return NewFromRow(db.QueryRowContext(ctx, query, args...))
+}
+
+// Count returns the number of matching {{ .Name.UpperCamel }}s, ignoring Limit and Offset
+func (b *QueryBuilder) Count(ctx context.Context, db domain.DB) (int, error) {
+	stmt, args := b.CountSQL()
+	var n int
+	err := db.QueryRowContext(ctx, stmt, args...).Scan(&n)
+	return n, err
+}
+
+{{ range $r := .Relations }}
+///////////////////
+// {{ $r.Name | upper }} RELATION
+///////////////////
+
+// {{ $r.Name }} returns the {{ $r.Table }} referenced by {{ $r.Column.Name.UpperCamel }}
+{{- if $r.Column.Nullable }}, sql.ErrNoRows if it's nil{{ end }}
+func (o *{{ $.Name.UpperCamel }}) {{ $r.Name }}(ctx context.Context, db domain.DB) (*{{ $r.Qualifier }}{{ $r.Table }}, error) {
+	row := db.QueryRowContext(ctx, {{ if $r.Package }}{{ $r.Package }}.SQLSelect(){{ else }}sqlSelect{{ end }}+" WHERE {{ $r.Reference.Name.UpperCamel }} = ?", {{ $r.Column.SQLArg (print "o." $r.Column.Name.UpperCamel) }})
+	return {{ $r.Qualifier }}NewFromRow(row)
+}
+
+// Load{{ $r.Name }}s fetches the {{ $r.Table }} of every {{ $.Name.UpperCamel }} in list with a single
+// query, keyed by {{ $r.Column.Name.UpperCamel }}
+{{- if $r.Reference.Hex }} as written, whatever the case of its hex digits{{ end }}
+{{- if $r.Column.Nullable }}.
+// {{ $.Name.UpperCamel }}s without one are left out.{{ end }}
+func Load{{ $r.Name }}s(ctx context.Context, db domain.DB, list []*{{ $.Name.UpperCamel }}) (map[{{ $r.KeyType }}]*{{ $r.Qualifier }}{{ $r.Table }}, error) {
+	keys := map[{{ $r.KeyType }}]*{{ $r.Qualifier }}{{ $r.Table }}{}
+	args := []interface{}{}
+	for _, o := range list {
+		{{- if $r.Column.Nullable }}
+		if o.{{ $r.Column.Name.UpperCamel }} == nil {
+			continue
+		}
+		{{- end }}
+		if _, ok := keys[{{ $r.Key (print "o." $r.Column.Name.UpperCamel) }}]; !ok {
+			keys[{{ $r.Key (print "o." $r.Column.Name.UpperCamel) }}] = nil
+			args = append(args, {{ $r.Column.SQLArg (print "o." $r.Column.Name.UpperCamel) }})
+		}
+	}
+	if len(args) == 0 {
+		return keys, nil
+	}
+	found, err := {{ $r.Qualifier }}Select(ctx, db, "WHERE {{ $r.Reference.Name.UpperCamel }} IN ("+domain.Placeholders("?", len(args))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	{{- if $r.Reference.Hex }}
+	// scanned IDs are upper case
+	byID := map[{{ $r.KeyType }}]*{{ $r.Qualifier }}{{ $r.Table }}{}
+	for _, f := range found {
+		byID[f.{{ $r.Reference.Name.UpperCamel }}] = f
+	}
+	for k := range keys {
+		keys[k] = byID[strings.ToUpper(k)]
+	}
+	{{- else }}
+	for _, f := range found {
+		keys[f.{{ $r.Reference.Name.UpperCamel }}] = f
+	}
+	{{- end }}
+	return keys, nil
+}
+
+// ListBy{{ $r.Column.Name.UpperCamel }} returns every {{ $.Name.UpperCamel }} referencing the given {{ $r.Table }}.
+{{- if $r.Package }}
+// It lives here rather than on {{ $r.Table }} to avoid an import cycle.
+{{- end }}
+func ListBy{{ $r.Column.Name.UpperCamel }}(ctx context.Context, db domain.DB, {{ $r.Column.Name.LowerCamel }} {{ $r.KeyType }}) ([]*{{ $.Name.UpperCamel }}, error) {
+	return Select(ctx, db, "WHERE {{ $r.Column.Name.UpperCamel }} = ? {{ $.SQLOrderPrimary }}", {{ $r.Reference.SQLArg $r.Column.Name.LowerCamel }})
+}
+
+// ListBy{{ $r.Column.Name.UpperCamel }}s fetches the {{ $.Name.UpperCamel }}s of every given {{ $r.Table }} with a
+// single query, grouped by {{ $r.Column.Name.UpperCamel }}
+{{- if $r.Reference.Hex }} as given, whatever the case of its
+// hex digits{{ end }}
+func ListBy{{ $r.Column.Name.UpperCamel }}s(ctx context.Context, db domain.DB, {{ $r.Column.Name.LowerCamel }}s ...{{ $r.KeyType }}) (map[{{ $r.KeyType }}][]*{{ $.Name.UpperCamel }}, error) {
+	groups := map[{{ $r.KeyType }}][]*{{ $.Name.UpperCamel }}{}
+	if len({{ $r.Column.Name.LowerCamel }}s) == 0 {
+		return groups, nil
+	}
+	args := make([]interface{}, len({{ $r.Column.Name.LowerCamel }}s))
+	for i, k := range {{ $r.Column.Name.LowerCamel }}s {
+		args[i] = {{ $r.Reference.SQLArg "k" }}
+	}
+	found, err := Select(ctx, db, "WHERE {{ $r.Column.Name.UpperCamel }} IN ("+domain.Placeholders("?", len(args))+") {{ $.SQLOrderPrimary }}", args...)
+	if err != nil {
+		return nil, err
+	}
+	{{- if $r.Reference.Hex }}
+	// scanned IDs are upper case
+	byID := map[{{ $r.KeyType }}][]*{{ $.Name.UpperCamel }}{}
+	for _, f := range found {
+		byID[{{ $r.Key (print "f." $r.Column.Name.UpperCamel) }}] = append(byID[{{ $r.Key (print "f." $r.Column.Name.UpperCamel) }}], f)
+	}
+	for _, k := range {{ $r.Column.Name.LowerCamel }}s {
+		if g := byID[strings.ToUpper(k)]; g != nil {
+			groups[k] = g
+		}
+	}
+	{{- else }}
+	for _, f := range found {
+		groups[{{ $r.Key (print "f." $r.Column.Name.UpperCamel) }}] = append(groups[{{ $r.Key (print "f." $r.Column.Name.UpperCamel) }}], f)
+	}
+	{{- end }}
+	return groups, nil
+}
+{{- if not $r.Package }}
+
+// {{ $r.Method $ }} returns every {{ $.Name.UpperCamel }} whose {{ $r.Column.Name.UpperCamel }} references o
+func (o *{{ $.Name.UpperCamel }}) {{ $r.Method $ }}(ctx context.Context, db domain.DB) ([]*{{ $.Name.UpperCamel }}, error) {
+	return ListBy{{ $r.Column.Name.UpperCamel }}(ctx, db, o.{{ $r.Reference.Name.UpperCamel }})
+}
+{{- else }}
+
+// the {{ $r.Table }}s list the {{ $.Name.UpperCamel }}s referencing them with this package's
+// loader, as it imports theirs
+func init() {
+	{{ $r.Package }}.{{ $r.Method $ }}Loader = func(ctx context.Context, db domain.DB, {{ $r.Column.Name.LowerCamel }} {{ $r.KeyType }}) ([]domain.Domain, error) {
+		list, err := ListBy{{ $r.Column.Name.UpperCamel }}(ctx, db, {{ $r.Column.Name.LowerCamel }})
+		if err != nil {
+			return nil, err
+		}
+		found := make([]domain.Domain, len(list))
+		for i, o := range list {
+			found[i] = o
+		}
+		return found, nil
+	}
+}
+{{- end }}
+{{ end }}
+{{- range $b := .ReferencedBy }}{{ if $b.Relation.Package }}
+///////////////////
+// {{ $b.Method | upper }}
+///////////////////
+
+// {{ $b.Method }}Loader lists the {{ $b.Object.Name.UpperCamel }}s whose {{ $b.Relation.Column.Name.UpperCamel }} is {{ $b.Relation.Column.Name.LowerCamel }}.
+// The {{ $b.Object.Name.Lower }} package sets it, as it imports this one.
+var {{ $b.Method }}Loader func(ctx context.Context, db domain.DB, {{ $b.Relation.Column.Name.LowerCamel }} {{ $b.Relation.KeyType }}) ([]domain.Domain, error)
+
+// {{ $b.Method }} returns every {{ $b.Object.Name.UpperCamel }} whose {{ $b.Relation.Column.Name.UpperCamel }} references o, each a
+// *{{ $b.Object.Name.Lower }}.{{ $b.Object.Name.UpperCamel }}. They're loaded by the {{ $b.Object.Name.Lower }} package, which
+// imports this one, so it must be imported too.
+func (o *{{ $.Name.UpperCamel }}) {{ $b.Method }}(ctx context.Context, db domain.DB) ([]domain.Domain, error) {
+	if {{ $b.Method }}Loader == nil {
+		return nil, fmt.Errorf("{{ $.Name.Lower }}.{{ $b.Method }} needs the {{ $b.Object.Name.Lower }} package imported")
+	}
+	return {{ $b.Method }}Loader(ctx, db, o.{{ $b.Relation.Reference.Name.UpperCamel }})
+}
+{{ end }}{{ end }}
+{{- range $l := .Links }}
+///////////////////
+// {{ $l.Other.UpperCamel | upper }} LINKS
+///////////////////
+
+// sqlAdd{{ $l.Other.UpperCamel }} differs in each dialect
+var sqlAdd{{ $l.Other.UpperCamel }} = domain.Statements{
+	{{- range $l.SQLAdds }}
+	{{ .Dialect.Const }}: {{ literal .SQL }},
+	{{- end }}
+}
+
+// Add{{ $l.Other.UpperCamel }} links the {{ $l.Other.UpperCamel }} with {{ $l.OtherColumn.Name.LowerCamel }} to o through
+// {{ $l.Table.UpperCamel }}. Adding a link that exists does nothing.
+func (o *{{ $.Name.UpperCamel }}) Add{{ $l.Other.UpperCamel }}(ctx context.Context, db domain.DB, {{ $l.OtherColumn.Name.LowerCamel }} {{ $l.OtherColumn.GoType }}) error {
+	_, err := db.ExecContext(ctx, sqlAdd{{ $l.Other.UpperCamel }}.For(db), {{ $l.Key.SQLArg (print "o." $l.Key.Name.UpperCamel) }}, {{ $l.OtherColumn.SQLArg $l.OtherColumn.Name.LowerCamel }})
+	return err
+}
+
+// Remove{{ $l.Other.UpperCamel }} unlinks the {{ $l.Other.UpperCamel }} with {{ $l.OtherColumn.Name.LowerCamel }} from o
+func (o *{{ $.Name.UpperCamel }}) Remove{{ $l.Other.UpperCamel }}(ctx context.Context, db domain.DB, {{ $l.OtherColumn.Name.LowerCamel }} {{ $l.OtherColumn.GoType }}) error {
+	_, err := db.ExecContext(ctx, {{ literal $l.SQLRemove }}, {{ $l.Key.SQLArg (print "o." $l.Key.Name.UpperCamel) }}, {{ $l.OtherColumn.SQLArg $l.OtherColumn.Name.LowerCamel }})
+	return err
+}
+
+// {{ $l.Other.UpperCamel }}{{ $l.OtherKey.Name.UpperCamel }}s returns the keys of the {{ $l.Plural }} linked to o.
+// {{ $l.Table.Lower }}.{{ $l.Plural }}Of{{ $.Name.UpperCamel }} returns them whole, it lives there to
+// avoid an import cycle.
+func (o *{{ $.Name.UpperCamel }}) {{ $l.Other.UpperCamel }}{{ $l.OtherKey.Name.UpperCamel }}s(ctx context.Context, db domain.DB) ([]{{ $l.OtherColumn.GoType }}, error) {
+	rows, err := db.QueryContext(ctx, {{ literal $l.SQLListKeys }}, {{ $l.Key.SQLArg (print "o." $l.Key.Name.UpperCamel) }})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := []{{ $l.OtherColumn.GoType }}{}
+	for rows.Next() {
+		var k {{ $l.OtherColumn.GoType }}
+		if err := rows.Scan({{ $l.OtherColumn.SQLScan "&k" }}); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+{{ end }}
+{{- if .Join }}
+///////////////////
+// LINKED OBJECTS
+///////////////////
+{{ end }}
+{{- range $l := .Join }}
+// {{ $l.Plural }}Of{{ $l.Self.UpperCamel }} returns the {{ $l.Plural }} linked to the {{ $l.Self.UpperCamel }} with {{ $l.Column.Name.LowerCamel }}
+func {{ $l.Plural }}Of{{ $l.Self.UpperCamel }}(ctx context.Context, db domain.DB, {{ $l.Column.Name.LowerCamel }} {{ $l.Column.GoType }}) ([]*{{ $l.Other.Lower }}.{{ $l.Other.UpperCamel }}, error) {
+	return {{ $l.Other.Lower }}.Select(ctx, db, "{{ $l.SQLWhereLinked }} ORDER BY {{ $l.OtherKey.Name.UpperCamel }}", {{ $l.Column.SQLArg $l.Column.Name.LowerCamel }})
+}
+{{ end }}
+
 func (o *{{ .Name.UpperCamel }}) String() string {
 	b, _ := json.MarshalIndent(o, "", "    ")
 	return string(b)	
@@ -116,4 +1069,277 @@ func (o *{{ .Name.UpperCamel }}) PPrint() {
 	fmt.Println(o.String())
 }
 `,
+
+	"DomainTest": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+{{- $pkg := .Name.Lower }}
+{{- $name := .Name.UpperCamel }}
+
+package {{ $pkg }}_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/domaintest"
+	"git.ottoq.com/otto-backend/valet/domain/{{ $pkg }}"
+)
+
+// object describes {{ $name }} to the tests every object shares
+var object = domaintest.Object{
+	Name:   "{{ $name }}",
+	Table:  {{ $pkg }}.TableName(),
+	Schema: {{ $pkg }}.Schema(),
+	Random: func() domaintest.Record {
+		return {{ $pkg }}.Random()
+	},
+	Get: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*{{ $pkg }}.{{ $name }})
+		return {{ $pkg }}.GetByID(ctx, db
+			{{- range $p := .PrimaryKeys }}, o.{{ $p.Name.UpperCamel }}{{ end }})
+	},
+	{{- if .SoftDelete }}
+	GetDeleted: func(ctx context.Context, db domain.DB, r domaintest.Record) (domaintest.Record, error) {
+		o := r.(*{{ $pkg }}.{{ $name }})
+		return {{ $pkg }}.Query().WithDeleted()
+			{{- range $p := .PrimaryKeys }}.Where{{ $p.Name.UpperCamel }}(o.{{ $p.Name.UpperCamel }}){{ end }}.First(ctx, db)
+	},
+	{{- end }}
+	Keys: []string{
+		{{- range $p := .PrimaryKeys }}"{{ $p.Name.UpperCamel }}", {{ end -}} },
+	Created: []string{
+		{{- if .HasAudit "CreatedAt" }}"CreatedAt", {{ end }}
+		{{- if .HasAudit "CreatedBy" }}"CreatedBy", {{ end -}} },
+	Mutable: []string{
+		{{- range $p := .MutableParameters }}{{ if not $p.IsJSON }}"{{ $p.Name.UpperCamel }}", {{ end }}{{ end -}} },
+	Versioned: {{ .Versioned }},
+}
+
+func Test{{ $name }}(t *testing.T) {
+	object.Test(t)
+}
+
+// Test{{ $name }}Keys reads a {{ $name }} back by its primary key, and nothing by a key
+// differing in one column
+func Test{{ $name }}Keys(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	want := {{ $pkg }}.Random()
+	if err := want.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := {{ $pkg }}.GetByID(ctx, db
+		{{- range $p := .PrimaryKeys }}, want.{{ $p.Name.UpperCamel }}{{ end }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := domaintest.Diff(want, got); d != "" {
+		t.Fatal(d)
+	}
+
+	other := {{ $pkg }}.Random()
+	{{- range $p := .PrimaryKeys }}
+	if _, err := {{ $pkg }}.GetByID(ctx, db
+		{{- range $q := $.PrimaryKeys }}, {{ if eq $q.Name.UpperCamel $p.Name.UpperCamel }}other{{ else }}want{{ end }}.{{ $q.Name.UpperCamel }}{{ end }}); err != sql.ErrNoRows {
+		t.Errorf("GetByID of another {{ $p.Name.UpperCamel }} got %v, want sql.ErrNoRows", err)
+	}
+	{{- end }}
+}
+{{- if .MutableParameters }}
+
+// Test{{ $name }}Columns updates each column of a {{ $name }} on its own and reads it
+// back
+func Test{{ $name }}Columns(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	o := {{ $pkg }}.Random()
+	if err := o.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	columns := []struct {
+		name string
+		set  func(o, values *{{ $pkg }}.{{ $name }})
+		get  func(o *{{ $pkg }}.{{ $name }}) interface{}
+	}{
+		{{- range $p := .MutableParameters }}
+		{
+			"{{ $p.Name.UpperCamel }}",
+			func(o, values *{{ $pkg }}.{{ $name }}) { o.Set{{ $p.Name.UpperCamel }}(values.{{ $p.Name.UpperCamel }}) },
+			func(o *{{ $pkg }}.{{ $name }}) interface{} { return o.{{ $p.Name.UpperCamel }} },
+		},
+		{{- end }}
+	}
+	for _, c := range columns {
+		got, err := {{ $pkg }}.GetByID(ctx, db
+			{{- range $q := $.PrimaryKeys }}, o.{{ $q.Name.UpperCamel }}{{ end }})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.set(got, {{ $pkg }}.Random())
+		if err := got.Update(ctx, db); err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		saved, err := {{ $pkg }}.GetByID(ctx, db
+			{{- range $q := $.PrimaryKeys }}, o.{{ $q.Name.UpperCamel }}{{ end }})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := domaintest.Diff(c.get(got), c.get(saved)); d != "" {
+			t.Errorf("%s %s", c.name, d)
+		}
+	}
+}
+{{- end }}
+{{- with .NullableParameters }}
+
+// Test{{ $name }}NullColumns writes a {{ $name }} with its nullable columns NULL, then
+// updates them to values and back to NULL
+func Test{{ $name }}NullColumns(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+
+	o := {{ $pkg }}.Random()
+	{{- range $p := . }}
+	o.{{ $p.Name.UpperCamel }} = nil
+	{{- end }}
+	if err := o.Insert(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	for _, values := range []*{{ $pkg }}.{{ $name }}{o, {{ $pkg }}.Random(), o} {
+		got, err := {{ $pkg }}.GetByID(ctx, db
+			{{- range $q := $.PrimaryKeys }}, o.{{ $q.Name.UpperCamel }}{{ end }})
+		if err != nil {
+			t.Fatal(err)
+		}
+		{{- range $p := . }}
+		got.Set{{ $p.Name.UpperCamel }}(values.{{ $p.Name.UpperCamel }})
+		{{- end }}
+		if err := got.Update(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+		saved, err := {{ $pkg }}.GetByID(ctx, db
+			{{- range $q := $.PrimaryKeys }}, o.{{ $q.Name.UpperCamel }}{{ end }})
+		if err != nil {
+			t.Fatal(err)
+		}
+		{{- range $p := . }}
+		if d := domaintest.Diff(values.{{ $p.Name.UpperCamel }}, saved.{{ $p.Name.UpperCamel }}); d != "" {
+			t.Fatalf("{{ $p.Name.UpperCamel }} %s", d)
+		}
+		{{- end }}
+	}
+}
+{{- end }}
+`,
+
+	"Enums": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+// Package enums holds the types of the enum parameters of every domain
+// object. Values outside an enum are rejected when parsed, unmarshalled
+// from JSON, scanned from or written to the database.
+package enums
+{{ if . }}
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+{{ end }}
+{{- range $e := . }}
+// {{ $e.Name }} is one of {{ range $i, $v := $e.Values }}{{ if $i }}, {{ end }}{{ $v.Value }}{{ end }}
+type {{ $e.Name }} string
+
+const (
+	{{- range $v := $e.Values }}
+	{{ $v.Const }} {{ $e.Name }} = "{{ $v.Value }}"
+	{{- end }}
+)
+
+// {{ $e.Name }}Values lists every {{ $e.Name }} in declaration order
+var {{ $e.Name }}Values = []{{ $e.Name }}{
+	{{- range $v := $e.Values }}
+	{{ $v.Const }},
+	{{- end }}
+}
+
+// Parse{{ $e.Name }} returns the {{ $e.Name }} of s or an error if s isn't one
+func Parse{{ $e.Name }}(s string) ({{ $e.Name }}, error) {
+	v := {{ $e.Name }}(s)
+	if !v.Valid() {
+		return "", fmt.Errorf("invalid {{ $e.Name }} %q", s)
+	}
+	return v, nil
+}
+
+// Valid reports whether v is one of {{ $e.Name }}Values
+func (v {{ $e.Name }}) Valid() bool {
+	switch v {
+	case {{ range $i, $c := $e.Values }}{{ if $i }}, {{ end }}{{ $c.Const }}{{ end }}:
+		return true
+	}
+	return false
+}
+
+func (v {{ $e.Name }}) String() string {
+	return string(v)
+}
+
+// MarshalJSON implements json.Marshaler
+func (v {{ $e.Name }}) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(v))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, rejecting unknown values.
+// null leaves v unchanged, as it does other types.
+func (v *{{ $e.Name }}) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := Parse{{ $e.Name }}(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting NULL and unknown values
+func (v *{{ $e.Name }}) Scan(src interface{}) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return fmt.Errorf("can't scan %T into {{ $e.Name }}", src)
+	}
+	parsed, err := Parse{{ $e.Name }}(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, rejecting unknown values
+func (v {{ $e.Name }}) Value() (driver.Value, error) {
+	if !v.Valid() {
+		return nil, fmt.Errorf("invalid {{ $e.Name }} %q", string(v))
+	}
+	return string(v), nil
+}
+{{ end }}`,
 }
@@ -0,0 +1,950 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"git.ottoq.com/otto-backend/valet/gen/namecase"
+)
+
+////////////////////////////////////////////////////////////
+
+// SchemaError is a problem found at a specific line of a schema file
+type SchemaError struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+// Error returns the error string
+func (err *SchemaError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", err.File, err.Line, err.Reason)
+}
+
+// SchemaErrors is every problem found while validating a schema file
+type SchemaErrors []*SchemaError
+
+// Error returns the error string, one problem per line
+func (errs SchemaErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+////////////////////////////////////////////////////////////
+
+// schemaFile is the on disk representation of the domain model
+type schemaFile struct {
+	Objects       []objectSpec       `json:"objects"`
+	Relationships []relationshipSpec `json:"relationships"`
+}
+
+// relationshipSpec declares a many-to-many relationship, stored in a join
+// table named Name
+type relationshipSpec struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	TypeID      string   `json:"typeID"`
+	Between     []string `json:"between"`
+}
+
+type objectSpec struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	TypeID      string      `json:"typeID"`
+	Parameters  []paramSpec `json:"parameters"`
+	Indexes     []indexSpec `json:"indexes"`
+	REST        *[]string   `json:"rest"`
+	Audit       []string    `json:"audit"`
+}
+
+type paramSpec struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	SQLType    string   `json:"sqlType"`
+	Index      bool     `json:"index"`
+	Unique     bool     `json:"unique"`
+	Nullable   bool     `json:"nullable"`
+	References string   `json:"references"`
+	Precision  int      `json:"precision"`
+	Scale      int      `json:"scale"`
+	Values     []string `json:"values"` // Values of an enum
+
+	// constraints, see Parameter
+	Required  bool     `json:"required"`
+	MaxLength int      `json:"maxLength"`
+	Min       *float64 `json:"min"`
+	Max       *float64 `json:"max"`
+	Pattern   string   `json:"pattern"`
+	OneOf     []string `json:"oneOf"`
+	Example   string   `json:"example"` // Example is used by Random() for a pattern it can't satisfy
+
+	// Generator names a realistic value Random() draws, see Generators
+	Generator string `json:"generator"`
+}
+
+// indexSpec columns are column names, optionally with a prefix length
+// ex. "Name(20)"
+type indexSpec struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique"`
+	Columns []string `json:"columns"`
+}
+
+var (
+	identRegexp       = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	typeIDRegexp      = regexp.MustCompile(`^[0-9A-F]{32}$`)
+	indexColumnRegexp = regexp.MustCompile(`^([A-Za-z0-9]+)(?:\((\d+)\))?$`)
+	indexNameRegexp   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	sqlLengthRegexp   = regexp.MustCompile(`^(?i)(?:VAR)?(?:CHAR|BINARY)\((\d+)\)$|^(?i)(?:TINY|MEDIUM|LONG)?(?:TEXT|BLOB)$`)
+)
+
+// paramTypes builds a Parameter for each type allowed in a schema file.
+// Types with a fixed column name (id, typeid, timestamp, version) ignore
+// spec.Name.
+var paramTypes = map[string]func(o objectSpec, p paramSpec) Parameter{
+	"id": func(o objectSpec, p paramSpec) Parameter {
+		return ID()
+	},
+	"typeid": func(o objectSpec, p paramSpec) Parameter {
+		return TypeID(o.TypeID)
+	},
+	"timestamp": func(o objectSpec, p paramSpec) Parameter {
+		return Timestamp()
+	},
+	"version": func(o objectSpec, p paramSpec) Parameter {
+		return Version()
+	},
+	"primary": func(o objectSpec, p paramSpec) Parameter {
+		return PrimaryK(p.Name)
+	},
+	"foreign": func(o objectSpec, p paramSpec) Parameter {
+		ref := strings.SplitN(p.References, ".", 2)
+		return ForeignK(p.Name, ref[0], ref[1])
+	},
+	"string": func(o objectSpec, p paramSpec) Parameter {
+		return String(p.Name)
+	},
+	"float": func(o objectSpec, p paramSpec) Parameter {
+		return Float(p.Name)
+	},
+	"datetime": func(o objectSpec, p paramSpec) Parameter {
+		return Datetime(p.Name)
+	},
+	"text": func(o objectSpec, p paramSpec) Parameter {
+		return Text(p.Name)
+	},
+	"mediumtext": func(o objectSpec, p paramSpec) Parameter {
+		return MediumText(p.Name)
+	},
+	"bool": func(o objectSpec, p paramSpec) Parameter {
+		return Bool(p.Name)
+	},
+	"int": func(o objectSpec, p paramSpec) Parameter {
+		return Int(p.Name)
+	},
+	"int32": func(o objectSpec, p paramSpec) Parameter {
+		return Int32(p.Name)
+	},
+	"int64": func(o objectSpec, p paramSpec) Parameter {
+		return Int64(p.Name)
+	},
+	"uint": func(o objectSpec, p paramSpec) Parameter {
+		return Uint(p.Name)
+	},
+	"decimal": func(o objectSpec, p paramSpec) Parameter {
+		if p.Precision == 0 {
+			return Decimal(p.Name, defaultPrecision, defaultScale)
+		}
+		return Decimal(p.Name, p.Precision, p.Scale)
+	},
+	"json": func(o objectSpec, p paramSpec) Parameter {
+		return JSON(p.Name)
+	},
+	"bytes": func(o objectSpec, p paramSpec) Parameter {
+		return Bytes(p.Name)
+	},
+	"enum": func(o objectSpec, p paramSpec) Parameter {
+		return NamedEnum(o.Name+p.Name, p.Name, p.Values...)
+	},
+}
+
+// Generator is a realistic value Random() can draw instead of random
+// letters or numbers, which makes seeded data readable
+type Generator struct {
+	Kind     reflect.Kind // Kind of the parameters it applies to
+	Random   string       // Random is the expression drawing a value
+	Min, Max float64      // Min and Max bound the values of a number
+}
+
+// Generators are the generators a schema parameter may name
+var Generators = map[string]Generator{
+	"firstName": {Kind: reflect.String, Random: "entity.RANDfirstName()"},
+	"lastName":  {Kind: reflect.String, Random: "entity.RANDlastName()"},
+	"name":      {Kind: reflect.String, Random: "entity.RANDname()"},
+	"company":   {Kind: reflect.String, Random: "entity.RANDcompany()"},
+	"city":      {Kind: reflect.String, Random: "entity.RANDcity()"},
+	"street":    {Kind: reflect.String, Random: "entity.RANDstreet()"},
+	"email":     {Kind: reflect.String, Random: "entity.RANDemail()"},
+	"phone":     {Kind: reflect.String, Random: "entity.RANDphone()"},
+	"latitude":  {Kind: reflect.Float64, Random: "entity.RANDlatitude()", Min: -90, Max: 90},
+	"longitude": {Kind: reflect.Float64, Random: "entity.RANDlongitude()", Min: -180, Max: 180},
+}
+
+// decimals default to money, up to 9,999,999,999.99
+const (
+	defaultPrecision = 12
+	defaultScale     = 2
+)
+
+// notNullable are the parameter types that can't be declared nullable
+var notNullable = map[string]bool{
+	"id":        true,
+	"typeid":    true,
+	"timestamp": true,
+	"version":   true,
+	"primary":   true,
+}
+
+// plateImports are always imported by the domain plate
+var plateImports = map[string]bool{
+	"context":       true,
+	"encoding/json": true,
+	"fmt":           true,
+	"strings":       true,
+}
+
+// fixedNames are the parameter types that always produce the same column
+var fixedNames = map[string]string{
+	"id":        "ID",
+	"typeid":    "TypeID",
+	"timestamp": "Timestamp",
+	"version":   VersionColumn,
+}
+
+////////////////////////////////////////////////////////////
+
+// LoadFile reads and validates the schema file at path
+func LoadFile(path string) ([]Object, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Load(path, b)
+}
+
+// Load parses and validates a schema document, YAML if name ends in
+// .yaml or .yml and JSON otherwise. The name is only used when reporting
+// errors.
+func Load(name string, data []byte) ([]Object, error) {
+	if isYAML(name) {
+		return loadYAML(name, data)
+	}
+	return loadJSON(name, data)
+}
+
+// loadJSON parses and validates a JSON schema document
+func loadJSON(name string, data []byte) ([]Object, error) {
+	var sf schemaFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sf); err != nil {
+		return nil, decodeError(name, data, err)
+	}
+	lines, err := lineIndex(data)
+	if err != nil {
+		return nil, decodeError(name, data, err)
+	}
+
+	errs := SchemaErrors{}
+	fail := func(path, format string, args ...interface{}) {
+		errs = append(errs, &SchemaError{
+			File:   name,
+			Line:   lineOf(lines, path),
+			Reason: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if len(sf.Objects) == 0 {
+		fail("", "no objects defined")
+	}
+	names := map[string]bool{}
+	typeIDs := map[string]string{}
+	for i, o := range sf.Objects {
+		op := fmt.Sprintf("objects[%d]", i)
+		if !identRegexp.MatchString(o.Name) {
+			fail(op+".name", "object name %q must be an UpperCamel identifier", o.Name)
+		} else if names[o.Name] {
+			fail(op+".name", "duplicate object %q", o.Name)
+		}
+		names[o.Name] = true
+		if !typeIDRegexp.MatchString(o.TypeID) {
+			fail(op+".typeID", "typeID of %s must be 32 uppercase hex characters", o.Name)
+		} else if other, ok := typeIDs[o.TypeID]; ok {
+			fail(op+".typeID", "typeID of %s is already used by %s", o.Name, other)
+		}
+		typeIDs[o.TypeID] = o.Name
+		if len(o.Parameters) == 0 {
+			fail(op, "object %s has no parameters", o.Name)
+		}
+
+		params := map[string]bool{}
+		primary := 0
+		for j, p := range o.Parameters {
+			pp := fmt.Sprintf("%s.parameters[%d]", op, j)
+			if _, ok := paramTypes[p.Type]; !ok {
+				fail(pp+".type", "unknown parameter type %q (want one of %s)", p.Type, strings.Join(knownTypes(), ", "))
+				continue
+			}
+			pname := p.Name
+			if fixed, ok := fixedNames[p.Type]; ok {
+				if pname != "" && pname != fixed {
+					fail(pp+".name", "%s parameters are always named %s", p.Type, fixed)
+				}
+				pname = fixed
+			} else if !identRegexp.MatchString(pname) {
+				fail(pp+".name", "parameter name %q must be an UpperCamel identifier", pname)
+			}
+			if params[pname] {
+				fail(pp+".name", "duplicate parameter %s.%s", o.Name, pname)
+			}
+			params[pname] = true
+			if p.Type == "id" || p.Type == "primary" {
+				primary++
+				if p.Unique {
+					fail(pp+".unique", "primary key %s.%s is already unique", o.Name, pname)
+				}
+			}
+			if p.Nullable && notNullable[p.Type] {
+				fail(pp+".nullable", "%s parameters can't be nullable", p.Type)
+			}
+			if p.Type == "decimal" {
+				if p.Precision < 0 || p.Precision > 65 || p.Scale < 0 || p.Scale > 30 || p.Scale > p.Precision {
+					fail(pp, "decimal %s.%s needs 0 < precision <= 65 and 0 <= scale <= min(30, precision)", o.Name, pname)
+				}
+			} else if p.Precision != 0 || p.Scale != 0 {
+				fail(pp, "only decimal parameters may set precision and scale")
+			}
+			if p.Type == "enum" {
+				if err := validateEnum(o.Name+pname, p.Values); err != nil {
+					fail(pp+".values", "%s", err)
+				}
+			} else if p.Values != nil {
+				fail(pp+".values", "only enum parameters may set values")
+			}
+			validateConstraints(fail, pp, o, p, pname)
+			if p.Type == "foreign" {
+				if len(strings.Split(p.References, ".")) != 2 {
+					fail(pp+".references", "foreign key %s.%s must reference \"Table.Column\"", o.Name, pname)
+				}
+			} else if p.References != "" {
+				fail(pp+".references", "only foreign parameters may set references")
+			}
+		}
+		if primary == 0 {
+			fail(op, "object %s has no primary key", o.Name)
+		}
+
+		for j, a := range o.Audit {
+			ap := fmt.Sprintf("%s.audit[%d]", op, j)
+			switch {
+			case !knownAudit(a):
+				fail(ap, "unknown audit column %q (want one of %s)", a, strings.Join(AuditColumns, ", "))
+			case params[a]:
+				fail(ap, "audit column %s of %s is already a parameter or listed twice", a, o.Name)
+			}
+			params[a] = true
+		}
+
+		if o.REST != nil {
+			for j, rest := range *o.REST {
+				if !knownOp(rest) {
+					fail(fmt.Sprintf("%s.rest[%d]", op, j), "unknown REST operation %q (want one of %s)", rest, strings.Join(Ops, ", "))
+				}
+			}
+		}
+
+		indexNames := map[string]bool{}
+		for j, ix := range o.Indexes {
+			ip := fmt.Sprintf("%s.indexes[%d]", op, j)
+			if len(ix.Columns) == 0 {
+				fail(ip, "index of %s has no columns", o.Name)
+				continue
+			}
+			cols := []string{}
+			for k, c := range ix.Columns {
+				cp := fmt.Sprintf("%s.columns[%d]", ip, k)
+				m := indexColumnRegexp.FindStringSubmatch(c)
+				if m == nil {
+					fail(cp, "index column %q must be a column name, optionally with a prefix length ex. Name(20)", c)
+					continue
+				}
+				cols = append(cols, m[1])
+				p := findParam(o, m[1])
+				if p == nil {
+					fail(cp, "index column %s.%s does not exist", o.Name, m[1])
+					continue
+				}
+				if m[2] == "" {
+					continue
+				}
+				length := sqlLengthRegexp.FindStringSubmatch(sqlTypeOf(o, *p))
+				if length == nil {
+					fail(cp, "prefix length on %s.%s requires a character column", o.Name, m[1])
+				} else if n, _ := strconv.Atoi(m[2]); n == 0 || (length[1] != "" && n > atoi(length[1])) {
+					fail(cp, "prefix length %s on %s.%s must be between 1 and the column length", m[2], o.Name, m[1])
+				}
+			}
+			name := ix.Name
+			if name == "" {
+				name = IndexName(ix.Unique, cols...)
+			} else if !indexNameRegexp.MatchString(name) {
+				fail(ip+".name", "index name %q must be an identifier", name)
+			}
+			if indexNames[name] {
+				fail(ip, "duplicate index %s on %s", name, o.Name)
+			}
+			indexNames[name] = true
+		}
+	}
+	validateReferences(fail, sf.Objects)
+	validateRelationships(fail, sf, names, typeIDs)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	objects := make([]Object, len(sf.Objects))
+	for i, o := range sf.Objects {
+		objects[i] = newObject(o)
+	}
+	objects = addJoins(objects, sf.Relationships)
+	sorted, err := SortByReferences(objects)
+	if cycle, ok := err.(*CycleError); ok {
+		for i, o := range sf.Objects {
+			if o.Name == cycle.Names[0] {
+				fail(fmt.Sprintf("objects[%d]", i), "%s", cycle)
+			}
+		}
+		return nil, errs
+	}
+	return sorted, err
+}
+
+// validateReferences checks every foreign key references a column of a
+// known object that MySQL can point a constraint at, an indexed column of
+// the same type
+func validateReferences(fail func(path, format string, args ...interface{}), specs []objectSpec) {
+	byName := map[string]objectSpec{}
+	for _, o := range specs {
+		byName[o.Name] = o
+	}
+	for i, o := range specs {
+		for j, p := range o.Parameters {
+			ref := strings.Split(p.References, ".")
+			if p.Type != "foreign" || len(ref) != 2 {
+				continue
+			}
+			pp := fmt.Sprintf("objects[%d].parameters[%d].references", i, j)
+			target, ok := byName[ref[0]]
+			if !ok {
+				fail(pp, "foreign key %s.%s references unknown object %s", o.Name, p.Name, ref[0])
+				continue
+			}
+			col := findParam(target, ref[1])
+			if col == nil || col.canonical() != ref[1] {
+				fail(pp, "foreign key %s.%s references unknown column %s.%s", o.Name, p.Name, ref[0], ref[1])
+				continue
+			}
+			if !indexed(target, *col) {
+				fail(pp, "foreign key %s.%s references %s.%s, which isn't the primary key or indexed", o.Name, p.Name, ref[0], ref[1])
+			}
+			if want, got := sqlTypeOf(target, *col), sqlTypeOf(o, p); !strings.EqualFold(want, got) {
+				fail(pp, "foreign key %s.%s is %s but %s.%s is %s", o.Name, p.Name, got, ref[0], ref[1], want)
+			}
+		}
+	}
+}
+
+// validateRelationships checks each relationship links two different
+// objects with single column primary keys and doesn't clash with an
+// object or another relationship
+func validateRelationships(fail func(path, format string, args ...interface{}), sf schemaFile, names map[string]bool, typeIDs map[string]string) {
+	byName := map[string]objectSpec{}
+	for _, o := range sf.Objects {
+		byName[o.Name] = o
+	}
+	pairs := map[string]string{}
+	for i, r := range sf.Relationships {
+		rp := fmt.Sprintf("relationships[%d]", i)
+		if !identRegexp.MatchString(r.Name) {
+			fail(rp+".name", "relationship name %q must be an UpperCamel identifier", r.Name)
+		} else if names[r.Name] {
+			fail(rp+".name", "relationship %s is already the name of an object or relationship", r.Name)
+		}
+		names[r.Name] = true
+		if !typeIDRegexp.MatchString(r.TypeID) {
+			fail(rp+".typeID", "typeID of %s must be 32 uppercase hex characters", r.Name)
+		} else if other, ok := typeIDs[r.TypeID]; ok {
+			fail(rp+".typeID", "typeID of %s is already used by %s", r.Name, other)
+		}
+		typeIDs[r.TypeID] = r.Name
+		if len(r.Between) != 2 {
+			fail(rp+".between", "relationship %s must be between two objects", r.Name)
+			continue
+		}
+		if r.Between[0] == r.Between[1] {
+			fail(rp+".between", "relationship %s must be between two different objects", r.Name)
+			continue
+		}
+		for j, name := range r.Between {
+			o, ok := byName[name]
+			if !ok {
+				fail(fmt.Sprintf("%s.between[%d]", rp, j), "relationship %s links unknown object %s", r.Name, name)
+			} else if keys := primaryKeys(o); len(keys) != 1 {
+				fail(fmt.Sprintf("%s.between[%d]", rp, j), "relationship %s links %s, which needs a single column primary key", r.Name, name)
+			}
+		}
+		pair := r.Between[0] + "." + r.Between[1]
+		if r.Between[0] > r.Between[1] {
+			pair = r.Between[1] + "." + r.Between[0]
+		}
+		if other, ok := pairs[pair]; ok {
+			fail(rp+".between", "relationship %s links the same objects as %s", r.Name, other)
+		}
+		pairs[pair] = r.Name
+	}
+}
+
+// primaryKeys returns the primary key parameters of an object spec
+func primaryKeys(o objectSpec) []paramSpec {
+	keys := []paramSpec{}
+	for _, p := range o.Parameters {
+		if p.Type == "id" || p.Type == "primary" {
+			keys = append(keys, p)
+		}
+	}
+	return keys
+}
+
+// addJoins appends the join table of each validated relationship and
+// links both of its objects to it
+func addJoins(objects []Object, relationships []relationshipSpec) []Object {
+	index := map[string]int{}
+	for i, o := range objects {
+		index[o.Name.UpperCamel] = i
+	}
+	for _, r := range relationships {
+		left, right := index[r.Between[0]], index[r.Between[1]]
+		join := NewJoin(namecase.New(r.Name), r.Description, r.TypeID, objects[left], objects[right])
+		objects[left].Links = append(objects[left].Links, join.Join[0])
+		objects[right].Links = append(objects[right].Links, join.Join[1])
+		objects = append(objects, join)
+	}
+	return objects
+}
+
+// indexed reports whether a column is the primary key or starts an index
+func indexed(o objectSpec, p paramSpec) bool {
+	switch {
+	case p.Type == "id" || p.Type == "primary" || p.Unique || p.Index:
+		return true
+	}
+	for _, ix := range o.Indexes {
+		if len(ix.Columns) == 0 {
+			continue
+		}
+		if m := indexColumnRegexp.FindStringSubmatch(ix.Columns[0]); m != nil && strings.EqualFold(m[1], p.canonical()) {
+			return true
+		}
+	}
+	return false
+}
+
+// newObject converts a validated spec into the generator model
+func newObject(o objectSpec) Object {
+	obj := Object{
+		Name:        namecase.New(o.Name),
+		Description: o.Description,
+		TypeID:      o.TypeID,
+		REST:        Ops,
+	}
+	if o.REST != nil {
+		obj.REST = *o.REST
+	}
+	imports := map[string]bool{}
+	for _, ix := range o.Indexes {
+		idx := Index{Name: ix.Name, Unique: ix.Unique}
+		cols := []string{}
+		for _, c := range ix.Columns {
+			m := indexColumnRegexp.FindStringSubmatch(c)
+			col := IndexColumn{Name: findParam(o, m[1]).canonical(), Length: atoi(m[2])}
+			idx.Columns = append(idx.Columns, col)
+			cols = append(cols, col.Name)
+		}
+		if idx.Name == "" {
+			idx.Name = IndexName(idx.Unique, cols...)
+		}
+		obj.Indexes = append(obj.Indexes, idx)
+	}
+	for _, ps := range o.Parameters {
+		p := paramTypes[ps.Type](o, ps)
+		if ps.Unique {
+			obj.Indexes = append(obj.Indexes, Index{
+				Name:    IndexName(true, p.Name.UpperCamel),
+				Unique:  true,
+				Columns: []IndexColumn{{Name: p.Name.UpperCamel}},
+			})
+		}
+		if ps.SQLType != "" {
+			p.SQLType = ps.SQLType
+		}
+		if ps.Index {
+			p.Index = true
+		}
+		p = applyConstraints(p, ps)
+		if ps.Nullable {
+			p = Nullable(p)
+		}
+		if pkg := p.Import(); pkg != "" && !plateImports[pkg] {
+			imports[pkg] = true
+		}
+		obj.Parameters = append(obj.Parameters, p)
+	}
+	for _, a := range AuditColumns {
+		for _, name := range o.Audit {
+			if name != a {
+				continue
+			}
+			p := Audit(a)
+			if pkg := p.Import(); pkg != "" && !plateImports[pkg] {
+				imports[pkg] = true
+			}
+			obj.Parameters = append(obj.Parameters, p)
+		}
+	}
+	for _, pkg := range obj.ValidateImports() {
+		imports[pkg] = true
+	}
+	for pkg := range imports {
+		obj.Imports = append(obj.Imports, pkg)
+	}
+	sort.Strings(obj.Imports)
+	return obj
+}
+
+// validateConstraints checks the constraints make sense for the parameter's type
+func validateConstraints(fail func(path, format string, args ...interface{}), pp string, o objectSpec, ps paramSpec, name string) {
+	if ps.Type == "foreign" && len(strings.Split(ps.References, ".")) != 2 {
+		return
+	}
+	p := paramTypes[ps.Type](o, ps)
+	if ps.SQLType != "" {
+		p.SQLType = ps.SQLType
+	}
+	isString := p.Kind() == reflect.String && !p.Hex() && p.Enum == nil
+	if p.Enum != nil && ps.SQLType != "" {
+		fail(pp+".sqlType", "enum columns are always ENUM of their values")
+	}
+	numeric := false
+	switch p.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Float64:
+		numeric = true
+	}
+	if ps.Required && !ps.Nullable && (numeric || p.Kind() == reflect.Bool) {
+		fail(pp+".required", "%s.%s always has a value, only nullable numbers and bools can be required", o.Name, name)
+	}
+	if ps.MaxLength != 0 {
+		if !isString {
+			fail(pp+".maxLength", "maxLength only applies to string parameters")
+		} else if max := p.MaxChars(); ps.MaxLength < 0 || (max > 0 && ps.MaxLength > max) {
+			fail(pp+".maxLength", "maxLength of %s.%s must be between 1 and the column length %d", o.Name, name, max)
+		}
+	}
+	if (ps.Min != nil || ps.Max != nil) && ps.Type == "version" {
+		fail(pp, "version parameters always start at 1 and can't set min or max")
+	} else if (ps.Min != nil || ps.Max != nil) && !numeric {
+		fail(pp, "min and max only apply to numeric parameters")
+	}
+	if ps.Min != nil && ps.Max != nil && *ps.Min > *ps.Max {
+		fail(pp+".min", "min of %s.%s is greater than its max", o.Name, name)
+	}
+	if ps.Pattern != "" {
+		if !isString {
+			fail(pp+".pattern", "pattern only applies to string parameters")
+		} else if re, err := regexp.Compile(ps.Pattern); err != nil {
+			fail(pp+".pattern", "pattern of %s.%s: %s", o.Name, name, err)
+		} else if ps.Example == "" && len(ps.OneOf) == 0 {
+			fail(pp+".pattern", "%s.%s needs an example matching its pattern for Random()", o.Name, name)
+		} else if ps.Example != "" && !re.MatchString(ps.Example) {
+			fail(pp+".example", "example of %s.%s doesn't match its pattern", o.Name, name)
+		}
+	}
+	if ps.Example != "" && !isString {
+		fail(pp+".example", "example only applies to string parameters")
+	} else if ps.MaxLength > 0 && utf8.RuneCountInString(ps.Example) > ps.MaxLength {
+		fail(pp+".example", "example of %s.%s is longer than its maxLength", o.Name, name)
+	}
+	if len(ps.OneOf) > 0 && !isString {
+		fail(pp+".oneOf", "oneOf only applies to string parameters")
+	}
+	if ps.Generator != "" {
+		g, ok := Generators[ps.Generator]
+		switch {
+		case !ok:
+			fail(pp+".generator", "unknown generator %q (want one of %s)", ps.Generator, strings.Join(knownGenerators(), ", "))
+		case g.Kind != p.Kind() || (g.Kind == reflect.String && !isString):
+			fail(pp+".generator", "generator %s only applies to %s parameters", ps.Generator, g.Kind)
+		case ps.Pattern != "" || ps.Example != "" || len(ps.OneOf) > 0:
+			fail(pp+".generator", "generator of %s.%s can't be combined with a pattern, example or oneOf", o.Name, name)
+		case g.Kind != reflect.String && ((ps.Min != nil && *ps.Min > g.Min) || (ps.Max != nil && *ps.Max < g.Max)):
+			fail(pp+".generator", "generator %s draws values from %v to %v, outside the min or max of %s.%s", ps.Generator, g.Min, g.Max, o.Name, name)
+		}
+	}
+}
+
+// applyConstraints copies the spec's constraints onto p and keeps Random()
+// within them
+func applyConstraints(p Parameter, ps paramSpec) Parameter {
+	p.Required = p.Required || ps.Required
+	if ps.MaxLength > 0 {
+		p.MaxLength = ps.MaxLength
+		if ps.MaxLength < 10 {
+			p.Random = fmt.Sprintf("%s[:%d]", p.Random, ps.MaxLength)
+		}
+	}
+	if ps.Pattern != "" {
+		p.Pattern = ps.Pattern
+	}
+	if ps.Example != "" {
+		p.Random = strconv.Quote(ps.Example)
+	}
+	if ps.Min != nil || ps.Max != nil {
+		p.Min, p.Max = ps.Min, ps.Max
+		min, max := 0.0, 1.0
+		switch {
+		case ps.Min != nil && ps.Max != nil:
+			min, max = *ps.Min, *ps.Max
+		case ps.Min != nil:
+			min, max = *ps.Min, *ps.Min+100
+		default:
+			min, max = *ps.Max-100, *ps.Max
+		}
+		p.Random = fmt.Sprintf("%s(entity.RANDrange(%v, %v))", p.GoType(), min, max)
+	}
+	if len(ps.OneOf) > 0 {
+		p.OneOf = ps.OneOf
+		quoted := []string{}
+		for _, v := range ps.OneOf {
+			quoted = append(quoted, strconv.Quote(v))
+		}
+		p.Random = "entity.RANDchoice(" + strings.Join(quoted, ", ") + ")"
+	}
+	if g, ok := Generators[ps.Generator]; ok {
+		p.Random = g.Random
+		if n := p.MaxChars(); g.Kind == reflect.String && n > 0 && n < generatedChars {
+			p.Random = fmt.Sprintf("entity.Truncate(%s, %d)", g.Random, n)
+		}
+	}
+	return p
+}
+
+// generatedChars is the length of the longest value a string generator draws
+const generatedChars = 40
+
+func knownAudit(name string) bool {
+	for _, a := range AuditColumns {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+func knownOp(op string) bool {
+	for _, o := range Ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// findParam returns the parameter spec for a column name or nil
+func findParam(o objectSpec, name string) *paramSpec {
+	for i, p := range o.Parameters {
+		pname := p.Name
+		if fixed, ok := fixedNames[p.Type]; ok {
+			pname = fixed
+		}
+		if strings.EqualFold(pname, name) {
+			return &o.Parameters[i]
+		}
+	}
+	return nil
+}
+
+// canonical returns the column name of the parameter spec
+func (p *paramSpec) canonical() string {
+	if fixed, ok := fixedNames[p.Type]; ok {
+		return fixed
+	}
+	return p.Name
+}
+
+// sqlTypeOf returns the column type the parameter spec generates
+func sqlTypeOf(o objectSpec, p paramSpec) string {
+	if p.SQLType != "" {
+		return p.SQLType
+	}
+	build, ok := paramTypes[p.Type]
+	if !ok || (p.Type == "foreign" && len(strings.Split(p.References, ".")) != 2) {
+		return ""
+	}
+	return build(o, p).SQLType
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func knownGenerators() []string {
+	names := []string{}
+	for g := range Generators {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func knownTypes() []string {
+	types := []string{}
+	for t := range paramTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+////////////////////////////////////////////////////////////
+
+// decodeError attaches a line number to json decoding errors
+func decodeError(name string, data []byte, err error) error {
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return &SchemaError{File: name, Line: lineAt(data, e.Offset), Reason: e.Error()}
+	case *json.UnmarshalTypeError:
+		return &SchemaError{File: name, Line: lineAt(data, e.Offset),
+			Reason: fmt.Sprintf("%s must be %s, not %s", e.Field, e.Type, e.Value)}
+	}
+	line := 1
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		line = unknownFieldLine(data, field)
+	}
+	return &SchemaError{File: name, Line: line, Reason: err.Error()}
+}
+
+// unknownFieldLine returns the first line a member named field, quoted,
+// is found on, or 1
+func unknownFieldLine(data []byte, field string) int {
+	name, err := strconv.Unquote(field)
+	if err != nil {
+		return 1
+	}
+	lines, err := lineIndex(data)
+	if err != nil {
+		return 1
+	}
+	first := 0
+	for path, line := range lines {
+		if (path == name || strings.HasSuffix(path, "."+name)) && (first == 0 || line < first) {
+			first = line
+		}
+	}
+	if first == 0 {
+		return 1
+	}
+	return first
+}
+
+// lineIndex maps the path of every value in a json document
+// (ex. "objects[1].parameters[0].type") to the line it starts on
+func lineIndex(data []byte) (map[string]int, error) {
+	lines := map[string]int{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var walk func(path string) error
+	walk = func(path string) error {
+		lines[path] = lineAt(data, valueStart(data, dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child := fmt.Sprint(key)
+				if path != "" {
+					child = path + "." + child
+				}
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+	return lines, walk("")
+}
+
+// lineOf returns the line of path, or of its closest parent when path
+// is missing from the document (ex. an omitted field)
+func lineOf(lines map[string]int, path string) int {
+	for {
+		if line, ok := lines[path]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			return lines[""]
+		}
+		path = path[:i]
+	}
+}
+
+// valueStart skips separators following offset to find where a value begins
+func valueStart(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// lineAt returns the 1 based line number of offset
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
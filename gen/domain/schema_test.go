@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// matches reports whether err is at the file and line of want, ex.
+// "bad.json:8: ", and has the rest of want in its reason
+func matches(err error, want string) bool {
+	at := strings.Index(want, ": ") + len(": ")
+	return strings.HasPrefix(err.Error(), want[:at]) && strings.Contains(err.Error(), want[at:])
+}
+
+// badSchema has the parameter of each test on line 8
+const badSchema = `{
+  "objects": [
+    {
+      "name": "Node",
+      "typeID": "0C74DFC158C646C280BCB0DAF9E015D1",
+      "parameters": [
+        { "type": "id" },
+        %s
+      ]
+    },
+    {
+      "name": "Desk",
+      "typeID": "E1874C161CDB492FB95EF210E653B886",
+      "parameters": [{ "type": "id" }, { "name": "Name", "type": "string" }]
+    }
+  ]
+}`
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		param string
+		want  string
+	}{
+		{`{ "name": "Name", "type": "strnig" }`, `bad.json:8: unknown parameter type "strnig"`},
+		{`{ "name": "name", "type": "string" }`, `bad.json:8: parameter name "name" must be an UpperCamel identifier`},
+		{`{ "name": "ID", "type": "string" }`, `bad.json:8: duplicate parameter Node.ID`},
+		{`{ "name": "Other", "type": "version" }`, `bad.json:8: version parameters are always named Version`},
+		{`{ "name": "Key", "type": "primary", "nullable": true }`, `bad.json:8: primary parameters can't be nullable`},
+		{`{ "name": "Cost", "type": "decimal", "precision": 70 }`, `bad.json:8: decimal Node.Cost needs 0 < precision <= 65`},
+		{`{ "name": "Name", "type": "string", "scale": 2 }`, `bad.json:8: only decimal parameters may set precision and scale`},
+		{`{ "name": "State", "type": "enum" }`, `bad.json:8: enum NodeState has no values`},
+		{`{ "name": "Name", "type": "string", "values": ["a"] }`, `bad.json:8: only enum parameters may set values`},
+		{`{ "name": "Name", "type": "string", "maxLength": 1000 }`, `bad.json:8: maxLength of Node.Name must be between 1 and the column length`},
+		{`{ "name": "Count", "type": "int", "min": 2, "max": 1 }`, `bad.json:8: min of Node.Count is greater than its max`},
+		{`{ "name": "Name", "type": "string", "pattern": "(" }`, `bad.json:8: pattern of Node.Name`},
+		{`{ "name": "Name", "type": "string", "generator": "planet" }`, `bad.json:8: unknown generator "planet"`},
+		{`{ "name": "Count", "type": "int", "generator": "city" }`, `bad.json:8: generator city only applies to string parameters`},
+		{`{ "name": "DeskID", "type": "foreign", "references": "Desk" }`, `bad.json:8: foreign key Node.DeskID must reference "Table.Column"`},
+		{`{ "name": "DeskID", "type": "foreign", "references": "Room.ID" }`, `bad.json:8: foreign key Node.DeskID references unknown object Room`},
+		{`{ "name": "DeskID", "type": "foreign", "references": "Desk.Floor" }`, `bad.json:8: foreign key Node.DeskID references unknown column Desk.Floor`},
+		{`{ "name": "DeskName", "type": "foreign", "references": "Desk.Name" }`, `bad.json:8: foreign key Node.DeskName references Desk.Name, which isn't the primary key or indexed`},
+		{`{ "name": "Name", "type": "string", "references": "Desk.ID" }`, `bad.json:8: only foreign parameters may set references`},
+		{`{ "name": "Name", "type": "string", "colour": "red" }`, `bad.json:8: json: unknown field "colour"`},
+		{`{ "name": "Name", "type": "string", "nullable": "yes" }`, `bad.json:8: nullable must be bool, not string`},
+		{`{ "name": "Name", "type": "string" `, `bad.json:9: invalid character ']'`},
+	}
+	for _, test := range tests {
+		_, err := Load("bad.json", []byte(fmt.Sprintf(badSchema, test.param)))
+		if err == nil {
+			t.Errorf("%s loaded", test.param)
+		} else if !matches(err, test.want) {
+			t.Errorf("%s got\n%s\nwant %s", test.param, err, test.want)
+		}
+	}
+}
+
+func TestLoadObjectErrors(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{`{"objects": []}`, `bad.json:1: no objects defined`},
+		{`{
+  "objects": [
+    { "name": "Node", "typeID": "0C74DFC158C646C280BCB0DAF9E015D1", "parameters": [{ "type": "id" }] },
+    { "name": "Node", "typeID": "E1874C161CDB492FB95EF210E653B886", "parameters": [{ "type": "id" }] }
+  ]
+}`, `bad.json:4: duplicate object "Node"`},
+		{`{
+  "objects": [
+    { "name": "Node", "typeID": "0c74", "parameters": [{ "type": "id" }] }
+  ]
+}`, `bad.json:3: typeID of Node must be 32 uppercase hex characters`},
+		{`{
+  "objects": [
+    {
+      "name": "Node",
+      "typeID": "0C74DFC158C646C280BCB0DAF9E015D1",
+      "parameters": [{ "name": "Name", "type": "string" }]
+    }
+  ]
+}`, `bad.json:3: object Node has no primary key`},
+		{`{
+  "objects": [
+    {
+      "name": "Node",
+      "typeID": "0C74DFC158C646C280BCB0DAF9E015D1",
+      "parameters": [{ "type": "id" }],
+      "indexes": [{ "columns": ["Name"] }]
+    }
+  ]
+}`, `bad.json:7: index column Node.Name does not exist`},
+		{`{
+  "objects": [
+    {
+      "name": "Node",
+      "typeID": "0C74DFC158C646C280BCB0DAF9E015D1",
+      "parameters": [{ "type": "id" }, { "name": "DeskID", "type": "foreign", "references": "Desk.ID" }]
+    },
+    {
+      "name": "Desk",
+      "typeID": "E1874C161CDB492FB95EF210E653B886",
+      "parameters": [{ "type": "id" }, { "name": "NodeID", "type": "foreign", "references": "Node.ID" }]
+    }
+  ]
+}`, `bad.json:3: foreign keys form a cycle: Node -> Desk -> Node`},
+		{`{
+  "objects": [
+    { "name": "Node", "typeID": "0C74DFC158C646C280BCB0DAF9E015D1", "parameters": [{ "type": "id" }] }
+  ],
+  "relationships": [
+    { "name": "NodeNode", "typeID": "E1874C161CDB492FB95EF210E653B886", "between": ["Node", "Node"] }
+  ]
+}`, `bad.json:6: relationship NodeNode must be between two different objects`},
+	}
+	for _, test := range tests {
+		_, err := Load("bad.json", []byte(test.schema))
+		if err == nil {
+			t.Errorf("%s loaded", test.schema)
+		} else if !matches(err, test.want) {
+			t.Errorf("got\n%s\nwant %s", err, test.want)
+		}
+	}
+}
+
+const yamlSchema = `objects:
+  - name: Node
+    typeID: 0C74DFC158C646C280BCB0DAF9E015D1
+    description: Node of the network
+    parameters:
+      - type: id
+      - name: Name
+        type: string
+        maxLength: 40
+  - name: Desk
+    typeID: E1874C161CDB492FB95EF210E653B886
+    parameters:
+      - type: id
+      - name: NodeID
+        type: foreign
+        references: Node.ID
+    indexes:
+      - columns: [NodeID]
+`
+
+func TestLoadYAML(t *testing.T) {
+	objects, err := Load("schema.yaml", []byte(yamlSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, err := Load("schema.json", []byte(`{
+  "objects": [
+    {
+      "name": "Node",
+      "typeID": "0C74DFC158C646C280BCB0DAF9E015D1",
+      "description": "Node of the network",
+      "parameters": [{ "type": "id" }, { "name": "Name", "type": "string", "maxLength": 40 }]
+    },
+    {
+      "name": "Desk",
+      "typeID": "E1874C161CDB492FB95EF210E653B886",
+      "parameters": [{ "type": "id" }, { "name": "NodeID", "type": "foreign", "references": "Node.ID" }],
+      "indexes": [{ "columns": ["NodeID"] }]
+    }
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != len(same) {
+		t.Fatalf("YAML has %d objects, JSON %d", len(objects), len(same))
+	}
+	for i := range objects {
+		if got, want := objects[i].SQLSchema(), same[i].SQLSchema(); got != want {
+			t.Errorf("YAML made\n%s\nJSON made\n%s", got, want)
+		}
+	}
+
+	tests := []struct {
+		old, new string
+		want     string
+	}{
+		{"type: string", "type: strnig", `bad.yaml:8: unknown parameter type "strnig"`},
+		{"maxLength: 40", "maxLength: 4000", `bad.yaml:9: maxLength of Node.Name must be between 1`},
+		{"maxLength: 40", "maxLength: forty", `bad.yaml:9: maxLength must be int, not string`},
+		{"maxLength: 40", "colour: red", `bad.yaml:9: json: unknown field "colour"`},
+		{"references: Node.ID", "references: Room.ID", `bad.yaml:16: foreign key Desk.NodeID references unknown object Room`},
+		{"columns: [NodeID]", "columns: [Floor]", `bad.yaml:18: index column Desk.Floor does not exist`},
+		{"maxLength: 40", "maxLength: 40: 4", `bad.yaml:9: mapping values are not allowed in this context`},
+		{"type: string", "type: @string", `bad.yaml:8: found character that cannot start any token`},
+	}
+	for _, test := range tests {
+		if !strings.Contains(yamlSchema, test.old) {
+			t.Fatalf("the schema has no %q", test.old)
+		}
+		_, err := Load("bad.yaml", []byte(strings.Replace(yamlSchema, test.old, test.new, 1)))
+		if err == nil {
+			t.Errorf("%s loaded", test.new)
+		} else if !matches(err, test.want) {
+			t.Errorf("%s got\n%s\nwant %s", test.new, err, test.want)
+		}
+	}
+}
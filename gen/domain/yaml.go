@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlLineRegexp finds the line of a yaml.v3 parse error, ex.
+// "yaml: line 3: mapping values are not allowed in this context"
+var yamlLineRegexp = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// isYAML reports whether a schema file is YAML rather than JSON
+func isYAML(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// loadYAML converts a YAML schema to JSON and loads that, reporting
+// errors at the lines of the YAML document
+func loadYAML(name string, data []byte) ([]Object, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if m := yamlLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+			return nil, &SchemaError{File: name, Line: atoi(m[1]), Reason: m[2]}
+		}
+		return nil, &SchemaError{File: name, Line: 1, Reason: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	w := &jsonWriter{name: name}
+	if len(doc.Content) > 0 {
+		if err := w.value(doc.Content[0], "", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	objects, err := loadJSON(name, w.buf.Bytes())
+	switch e := err.(type) {
+	case *SchemaError:
+		e.Line = w.yamlLine(e.Line)
+	case SchemaErrors:
+		for _, se := range e {
+			se.Line = w.yamlLine(se.Line)
+		}
+	}
+	return objects, err
+}
+
+// jsonWriter writes a YAML document as JSON, one value per line,
+// recording the YAML line each JSON line came from
+type jsonWriter struct {
+	name  string // name of the schema file
+	buf   bytes.Buffer
+	lines []int // lines[i] is the YAML line of JSON line i+1
+}
+
+// line writes s as the next JSON line
+func (w *jsonWriter) line(yamlLine int, s string) {
+	w.buf.WriteString(s)
+	w.buf.WriteByte('\n')
+	w.lines = append(w.lines, yamlLine)
+}
+
+// yamlLine returns the YAML line a JSON line came from
+func (w *jsonWriter) yamlLine(jsonLine int) int {
+	if jsonLine < 1 || jsonLine > len(w.lines) {
+		return 1
+	}
+	return w.lines[jsonLine-1]
+}
+
+// value writes n as JSON, prefix being the member name it's the value of
+// and suffix the comma following it
+func (w *jsonWriter) value(n *yaml.Node, prefix, suffix string) error {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return w.value(n.Alias, prefix, suffix)
+	case yaml.MappingNode:
+		w.line(n.Line, prefix+"{")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return &SchemaError{File: w.name, Line: key.Line, Reason: "keys must be strings"}
+			}
+			comma := ","
+			if i+2 == len(n.Content) {
+				comma = ""
+			}
+			if err := w.value(value, strconv.Quote(key.Value)+": ", comma); err != nil {
+				return err
+			}
+		}
+		w.line(n.Line, "}"+suffix)
+	case yaml.SequenceNode:
+		w.line(n.Line, prefix+"[")
+		for i, item := range n.Content {
+			comma := ","
+			if i+1 == len(n.Content) {
+				comma = ""
+			}
+			if err := w.value(item, "", comma); err != nil {
+				return err
+			}
+		}
+		w.line(n.Line, "]"+suffix)
+	case yaml.ScalarNode:
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return &SchemaError{File: w.name, Line: n.Line, Reason: err.Error()}
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return &SchemaError{File: w.name, Line: n.Line, Reason: fmt.Sprintf("%s can't be written as JSON", n.Value)}
+		}
+		w.line(n.Line, prefix+string(b)+suffix)
+	default:
+		return &SchemaError{File: w.name, Line: n.Line, Reason: "unsupported YAML node"}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+package dto
+
+// BasePath is where the input DTO packages are generated, relative to the
+// repository root
+var BasePath = "dto/input"
@@ -0,0 +1,363 @@
+package dto
+
+var Plate = map[string]string{
+	"Input": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+// Package input{{ .Name.Lower }}
+// Input DTOs for the {{ .Name.UpperCamel }} REST endpoints
+package input{{ .Name.Lower }}
+
+import (
+	{{- if .HasOp "update" }}
+	"encoding/json"
+	{{- end }}
+	"net/http"
+	{{- if .HasOp "list" }}
+	"strconv"
+	{{- end }}
+	{{- if or (.HasOp "create") (.HasOp "update") }}
+	{{- range $k, $v := .TypeImports }}{{ if stdlib $v }}
+	"{{ $v }}"
+	{{- end }}{{ end }}
+	{{- end }}
+
+	"github.com/wardn/uuid"
+
+	"git.ottoq.com/otto-backend/valet/dto/input"
+	{{- if or (.HasOp "create") (.HasOp "update") }}
+	{{- range $k, $v := .TypeImports }}{{ if not (stdlib $v) }}
+	"{{ $v }}"
+	{{- end }}{{ end }}
+	{{- end }}
+	"git.ottoq.com/otto-backend/valet/server"
+	"git.ottoq.com/otto-backend/valet/server/securecookie"
+)
+
+// Route is the path every {{ .Name.UpperCamel }} endpoint is served on
+const Route = "/{{ .Name.Lower }}"
+
+const (
+	{{- if .HasOp "create" }}
+	CreateTypeID = "{{ .OpTypeID "create" }}"
+	{{- end }}
+	{{- if .HasOp "read" }}
+	ReadTypeID   = "{{ .OpTypeID "read" }}"
+	{{- end }}
+	{{- if .HasOp "update" }}
+	UpdateTypeID = "{{ .OpTypeID "update" }}"
+	{{- end }}
+	{{- if .HasOp "delete" }}
+	DeleteTypeID = "{{ .OpTypeID "delete" }}"
+	{{- end }}
+	{{- if .HasOp "list" }}
+	ListTypeID   = "{{ .OpTypeID "list" }}"
+	{{- end }}
+)
+{{ if .HasOp "list" }}
+const (
+	// DefaultLimit is the page size of a list without a limit
+	DefaultLimit = 50
+	// MaxLimit is the largest page a list returns
+	MaxLimit = 500
+)
+{{ end }}
+// Converters returns the converters of every {{ .Name.UpperCamel }} endpoint, for
+// registering on Route
+func Converters() server.HTTPConverterMap {
+	return server.HTTPConverterMap{
+		{{- if .HasOp "create" }}
+		"POST": FromCreateRequest,
+		{{- end }}
+		{{- if and (.HasOp "read") (.HasOp "list") }}
+		"GET": fromGetRequest,
+		{{- else if .HasOp "read" }}
+		"GET": FromReadRequest,
+		{{- else if .HasOp "list" }}
+		"GET": FromListRequest,
+		{{- end }}
+		{{- if .HasOp "update" }}
+		"PUT":   FromUpdateRequest,
+		"PATCH": FromPatchRequest,
+		{{- end }}
+		{{- if .HasOp "delete" }}
+		"DELETE": FromDeleteRequest,
+		{{- end }}
+	}
+}
+{{ if and (.HasOp "read") (.HasOp "list") }}
+// fromGetRequest reads a single {{ .Name.UpperCamel }} when the request names
+// one, otherwise lists them
+func fromGetRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+	{{ with index .PrimaryKeys 0 -}}
+	if r.URL.Query().Get("{{ .Name.LowerCamel }}") != "" {
+	{{- end }}
+		return FromReadRequest(w, r, sc, sessionCookieName)
+	}
+	return FromListRequest(w, r, sc, sessionCookieName)
+}
+{{ end }}
+// base holds what every payload shares
+type base struct {
+	id   string
+	w    http.ResponseWriter
+	r    *http.Request
+	sesh string
+}
+
+func newBase(w http.ResponseWriter, r *http.Request, sesh string) base {
+	return base{id: uuid.NewNoDash(), w: w, r: r, sesh: sesh}
+}
+
+func (p *base) Writer() http.ResponseWriter {
+	return p.w
+}
+func (p *base) Request() *http.Request {
+	return p.r
+}
+func (p *base) SessionID() string {
+	return p.sesh
+}
+func (p *base) ID() string {
+	return p.id
+}
+{{ if or (.HasOp "read") (.HasOp "delete") }}
+// Key identifies a single {{ .Name.UpperCamel }}, read from the query string
+type Key struct {
+	{{- range $p := .PrimaryKeys }}
+	{{ $p.Name.UpperCamel }} {{ $p.GoType }}
+	{{- end }}
+}
+
+func keyFromQuery(r *http.Request) (Key, error) {
+	q := r.URL.Query()
+	k := Key{
+		{{- range $p := .PrimaryKeys }}
+		{{ $p.Name.UpperCamel }}: q.Get("{{ $p.Name.LowerCamel }}"),
+		{{- end }}
+	}
+	{{- range $p := .PrimaryKeys }}
+	if k.{{ $p.Name.UpperCamel }} == "" {
+		return k, &server.Error{Status: http.StatusBadRequest, Message: "missing {{ $p.Name.LowerCamel }}"}
+	}
+	{{- end }}
+	return k, nil
+}
+{{ end }}
+{{- if .HasOp "create" }}
+////////////////////////////////////////////////////////////
+// CREATE
+////////////////////////////////////////////////////////////
+
+// CreateContents are the arguments of {{ .Name.Lower }}.New
+type CreateContents struct {
+	{{- range $p := .InputParameters }}
+	{{ $p.Name.UpperCamel }} {{ $p.GoType }}
+	{{- end }}
+}
+
+type CreatePayload struct {
+	base
+	Contents CreateContents
+}
+
+func (p *CreatePayload) TypeID() string {
+	return CreateTypeID
+}
+
+// FromCreateRequest converts a POST with CreateContents as its body
+func FromCreateRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var c CreateContents
+	seshID, err := input.ParseRW(&c, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatePayload{base: newBase(w, r, seshID), Contents: c}, nil
+}
+{{ end }}
+{{- if .HasOp "read" }}
+////////////////////////////////////////////////////////////
+// READ
+////////////////////////////////////////////////////////////
+
+type ReadPayload struct {
+	base
+	Key Key
+}
+
+func (p *ReadPayload) TypeID() string {
+	return ReadTypeID
+}
+
+// FromReadRequest converts a GET naming the {{ .Name.UpperCamel }} in its query string
+func FromReadRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	k, err := keyFromQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	return &ReadPayload{base: newBase(w, r, seshID), Key: k}, nil
+}
+{{ end }}
+{{- if .HasOp "update" }}
+////////////////////////////////////////////////////////////
+// UPDATE
+////////////////////////////////////////////////////////////
+
+// UpdateContents names the {{ .Name.UpperCamel }} and holds its new values
+{{- if .Versioned }}, along
+// with the version they're based on. A PUT must name the version.
+{{- end }}
+type UpdateContents struct {
+	{{- range $p := .UpdateInputParameters }}
+	{{ $p.Name.UpperCamel }} {{ $p.GoType }}
+	{{- end }}
+}
+
+// UpdatePayload of a PATCH holds the merge patch as well, Contents then
+// only having the members the patch names
+type UpdatePayload struct {
+	base
+	Contents UpdateContents
+	Patch    json.RawMessage // Patch is the body of a PATCH, nil for a PUT
+}
+
+func (p *UpdatePayload) TypeID() string {
+	return UpdateTypeID
+}
+
+// FromUpdateRequest converts a PUT with UpdateContents as its body
+func FromUpdateRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var c UpdateContents
+	seshID, err := input.ParseRW(&c, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	{{- range $p := .PrimaryKeys }}
+	if c.{{ $p.Name.UpperCamel }} == "" {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "missing {{ $p.Name.UpperCamel }}"}
+	}
+	{{- end }}
+	{{- with .VersionParameter }}
+	if c.{{ .Name.UpperCamel }} == 0 {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "missing {{ .Name.UpperCamel }}"}
+	}
+	{{- end }}
+	return &UpdatePayload{base: newBase(w, r, seshID), Contents: c}, nil
+}
+
+// FromPatchRequest converts a PATCH with a JSON merge patch (RFC 7386) of
+// the {{ .Name.UpperCamel }} as its body. The patch must name the {{ .Name.UpperCamel }}
+{{- if .Versioned }} and may
+// name the version it's based on{{ end }}.
+func FromPatchRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var patch json.RawMessage
+	seshID, err := input.ParseRW(&patch, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	var c UpdateContents
+	if err := json.Unmarshal(patch, &c); err != nil {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: err.Error()}
+	}
+	{{- range $p := .PrimaryKeys }}
+	if c.{{ $p.Name.UpperCamel }} == "" {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "missing {{ $p.Name.UpperCamel }}"}
+	}
+	{{- end }}
+	return &UpdatePayload{base: newBase(w, r, seshID), Contents: c, Patch: patch}, nil
+}
+{{ end }}
+{{- if .HasOp "delete" }}
+////////////////////////////////////////////////////////////
+// DELETE
+////////////////////////////////////////////////////////////
+
+type DeletePayload struct {
+	base
+	Key Key
+}
+
+func (p *DeletePayload) TypeID() string {
+	return DeleteTypeID
+}
+
+// FromDeleteRequest converts a DELETE naming the {{ .Name.UpperCamel }} in its query string
+func FromDeleteRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	k, err := keyFromQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	return &DeletePayload{base: newBase(w, r, seshID), Key: k}, nil
+}
+{{ end }}
+{{- if .HasOp "list" }}
+////////////////////////////////////////////////////////////
+// LIST
+////////////////////////////////////////////////////////////
+
+type ListPayload struct {
+	base
+	Limit  int
+	Offset int
+}
+
+func (p *ListPayload) TypeID() string {
+	return ListTypeID
+}
+
+// FromListRequest converts a GET with optional limit and offset query
+// parameters. The limit defaults to DefaultLimit and is capped at MaxLimit.
+func FromListRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	seshID, err := input.ParseRW(nil, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	p := &ListPayload{base: newBase(w, r, seshID), Limit: DefaultLimit}
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		if p.Limit, err = strconv.Atoi(v); err != nil || p.Limit < 1 {
+			return nil, &server.Error{Status: http.StatusBadRequest, Message: "limit must be a positive number"}
+		}
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+	if v := q.Get("offset"); v != "" {
+		if p.Offset, err = strconv.Atoi(v); err != nil || p.Offset < 0 {
+			return nil, &server.Error{Status: http.StatusBadRequest, Message: "offset must be a non negative number"}
+		}
+	}
+	return p, nil
+}
+{{ end }}
+`,
+}
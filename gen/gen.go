@@ -1,88 +1,458 @@
+// go run gen/gen.go [flags]
+//
+// Generates the domain, database, input DTO, handler and TypeID registry
+// packages, the tables of the seed command, the GraphQL schema and its
+// resolvers, the OpenAPI document, the TypeScript client, and the ER
+// diagram and data dictionary under docs/ from the schema file.
+// Run it from the repository root, or point -out at it.
+//
+//	-dry-run    print a diff of what would change, write nothing
+//	-check      exit non zero if any generated file is stale or hand edited
+//	-templates  directory of templates adding files or overriding the
+//	            built-in ones, see gen/output.LoadDir; may be repeated
+//
+// Templates written in Go are registered by the gen/plugins package.
 package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"go/format"
+	"io/ioutil"
 	"log"
 	"os"
-	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
-	"git.ottoq.com/otto-backend/valet/gen/database"
+	"git.ottoq.com/otto-backend/valet/gen/diff"
 	"git.ottoq.com/otto-backend/valet/gen/domain"
+	"git.ottoq.com/otto-backend/valet/gen/migration"
+	"git.ottoq.com/otto-backend/valet/gen/openapi"
+	"git.ottoq.com/otto-backend/valet/gen/output"
+	_ "git.ottoq.com/otto-backend/valet/gen/plugins"
+	"git.ottoq.com/otto-backend/valet/gen/typescript"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+const (
+	// generatedSuffix marks every file the generator owns
+	generatedSuffix = "_gen.go"
+	// generatedTestSuffix marks every test file the generator owns
+	generatedTestSuffix = "_gen_test.go"
+	// checksumPrefix starts the header line holding a generated file's checksum
+	checksumPrefix = "// checksum "
+	// headerLine is the header line the checksum is placed after
+	headerLine = "// VERY GENERATED PLZ NO MODIFY\n"
 )
 
 var (
-	rundir  = path.Join(os.Getenv("GOPATH"), "/src/git.ottoq.com/otto-backend/valet")
+	schemaPath    = flag.String("schema", domain.SchemaPath, "JSON or YAML (.yaml, .yml) schema file describing the domain objects, relative to -out unless absolute")
+	outDir        = flag.String("out", ".", "repository root the generated packages are written under")
+	dryRun        = flag.Bool("dry-run", false, "print a diff of the changes instead of writing them")
+	check         = flag.Bool("check", false, "exit 1 if any generated file is stale or was hand edited")
+	migrationName = flag.String("migration", "", "write a migration with this name for the schema changes since -dsn")
+	migrationDSN  = flag.String("dsn", "", "MySQL database to diff against when writing a migration, ex. user:pass@tcp(127.0.0.1:3306)/valet (default: an empty database)")
+	migrationDir  = flag.String("migrations", "database/migrations", "directory holding migration files, relative to -out unless absolute")
+	templateDirs  dirList
+
 	funcMap = template.FuncMap{ //added func to compare strings
 		"contains": func(a, b string) bool {
 			return strings.Contains(a, b)
 		},
+		"upper": strings.ToUpper,
+		// literal quotes a string as a Go raw string when it can
+		"literal": func(s string) string {
+			if strings.Contains(s, "`") {
+				return strconv.Quote(s)
+			}
+			return "`" + s + "`"
+		},
+		// stdlib reports whether an import path is in the standard library,
+		// whose first element has no dot, to group it as gofmt would
+		"stdlib": func(pkg string) bool {
+			return !strings.Contains(strings.SplitN(pkg, "/", 2)[0], ".")
+		},
+		"tstype":   typescript.Type,
+		"optional": typescript.Optional,
 	}
 )
 
+func init() {
+	flag.Var(&templateDirs, "templates", "directory of templates to add or override, relative to -out unless absolute; may be repeated")
+}
+
+// dirList is a flag that may be repeated
+type dirList []string
+
+func (d *dirList) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *dirList) Set(dir string) error {
+	*d = append(*d, dir)
+	return nil
+}
+
+// File is a generated file, Path being relative to the output root
+type File struct {
+	Path string
+	Code []byte
+}
+
 func main() {
-	verifyRunDir()
-	Domain()
-	Database()
+	flag.Parse()
+	objects, err := domain.LoadFile(underOut(*schemaPath))
+	if err != nil {
+		log.Fatal(err)
+	}
+	domain.List = objects
+	for _, dir := range templateDirs {
+		if err := output.LoadDir(underOut(dir)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	files := []File{}
+	for _, generate := range []func() ([]File, error){Templates, OpenAPI} {
+		f, err := generate()
+		if err != nil {
+			log.Fatal(err)
+		}
+		files = append(files, f...)
+	}
+
+	switch {
+	case *check:
+		problems, err := Check(*outDir, files)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			fmt.Println("run: go run gen/gen.go")
+			os.Exit(1)
+		}
+	case *dryRun:
+		if err := Diff(os.Stdout, *outDir, files); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		if err := Write(*outDir, files); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *migrationName != "" && !*check {
+		if err := Migration(*migrationName, *migrationDSN, underOut(*migrationDir), *dryRun); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// underOut returns a path given on the command line relative to -out,
+// or as is when it's absolute
+func underOut(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(*outDir, path)
+}
+
+// Templates renders every registered template, the built-in ones and
+// those added or overridden by plugins and template directories
+func Templates() ([]File, error) {
+	files := []File{}
+	for _, t := range output.Templates() {
+		f, err := Render(t, domain.List)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %s", t.Name, err)
+		}
+		files = append(files, f...)
+	}
+	return files, nil
+}
+
+// Render renders a template for each object it applies to, or once for
+// all of them
+func Render(t output.Template, objects []domain.Object) ([]File, error) {
+	if t.Filter != nil {
+		kept := []domain.Object{}
+		for _, o := range objects {
+			if t.Filter(o) {
+				kept = append(kept, o)
+			}
+		}
+		objects = kept
+	}
+	if t.Scope == output.Model {
+		var data interface{} = objects
+		if t.Data != nil {
+			var err error
+			if data, err = t.Data(objects); err != nil {
+				return nil, err
+			}
+		}
+		f, err := render(t, data)
+		if err != nil {
+			return nil, err
+		}
+		return []File{f}, nil
+	}
+	files := []File{}
+	for _, o := range objects {
+		f, err := render(t, o)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", o.Name.UpperCamel, err)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func render(t output.Template, data interface{}) (File, error) {
+	pt, err := template.New(t.Name + " path").Funcs(funcMap).Parse(t.Path)
+	if err != nil {
+		return File{}, err
+	}
+	p := new(bytes.Buffer)
+	if err := pt.Execute(p, data); err != nil {
+		return File{}, err
+	}
+	generate := GenerateText
+	if strings.HasSuffix(p.String(), ".go") {
+		generate = GenerateCode
+	}
+	code, err := generate(t.Name, t.Text, data)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Path: filepath.Clean(p.String()), Code: code}, nil
 }
 
-func Domain() error {
-	basepath := domain.BasePath
-	for _, d := range domain.List {
-		MakePackage(path.Join(basepath, d.Name.Lower), d.Name.Lower+"_gen.go", "Domain", domain.Plate["Domain"], d)
+// OpenAPI writes the OpenAPI document as openapi.json, next to the
+// constant the OpenAPI template serves it from
+func OpenAPI() ([]File, error) {
+	doc, err := openapi.Document(domain.List)
+	if err != nil {
+		return nil, err
 	}
+	return []File{{Path: filepath.Join(openapi.BasePath, "openapi.json"), Code: doc}}, nil
+}
+
+// Migration writes the statements taking the database at dsn to the
+// current domain model. An empty dsn diffs against an empty database.
+// A dry run prints the statements instead.
+func Migration(name, dsn, dir string, dryRun bool) error {
+	current := map[string]*migration.Table{}
+	if dsn != "" {
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if current, err = migration.Current(db); err != nil {
+			return err
+		}
+	}
+	up, down, unknown := migration.Diff(domain.List, current)
+	for _, t := range unknown {
+		log.Printf("table %s is not in the domain model, leaving it alone\n", t)
+	}
+	if dryRun {
+		fmt.Printf("-- migration %s up\n%s\n\n-- migration %s down\n%s\n",
+			name, strings.Join(up, "\n\n"), name, strings.Join(down, "\n\n"))
+		return nil
+	}
+	file, err := migration.Write(dir, name, up, down)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		log.Println("database is up to date, no migration written")
+		return nil
+	}
+	log.Printf("wrote %s\n", file)
 	return nil
 }
 
-func Database() error {
-	//Database is also generated from domain objects
-	basepath := database.BasePath
-	MakePackage(basepath, "database_gen.go", "Database", database.Plate["Database"], domain.List)
+// Write saves every file that differs from what's on disk
+func Write(root string, files []File) error {
+	for _, f := range files {
+		p := filepath.Join(root, f.Path)
+		if old, err := ioutil.ReadFile(p); err == nil && bytes.Equal(old, f.Code) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0744); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(p, f.Code, 0644); err != nil {
+			return err
+		}
+		log.Printf("wrote %s\n", f.Path)
+	}
 	return nil
 }
 
-func MakePackage(basedir, filename, tmplname, tmpl string, obj interface{}) error {
-	code := GenerateCode(tmplname, tmpl, obj)
-	if err := os.MkdirAll(basedir, 0744); err != nil {
-		return err
+// Diff prints a unified diff between what's on disk and files, including
+// generated files that would be left behind
+func Diff(w *os.File, root string, files []File) error {
+	for _, f := range files {
+		old, err := ioutil.ReadFile(filepath.Join(root, f.Path))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		fmt.Fprint(w, diff.Unified("a/"+f.Path, "b/"+f.Path, string(old), string(f.Code)))
 	}
-	f, err := os.Create(path.Join(basedir, filename))
+	orphans, err := Orphans(root, files)
 	if err != nil {
 		return err
 	}
-	if _, err := f.Write([]byte(code)); err != nil {
-		return err
+	for _, o := range orphans {
+		fmt.Fprintf(w, "# %s is no longer generated and should be removed\n", o)
 	}
 	return nil
 }
 
-func GenerateCode(tmplname, tmpl string, obj interface{}) string {
+// Check compares files against what's on disk and describes every
+// missing, stale, hand edited or orphaned generated file
+func Check(root string, files []File) ([]string, error) {
+	problems := []string{}
+	for _, f := range files {
+		old, err := ioutil.ReadFile(filepath.Join(root, f.Path))
+		switch {
+		case os.IsNotExist(err):
+			problems = append(problems, f.Path+": missing")
+		case err != nil:
+			return nil, err
+		case Verify(f.Code) && !Verify(old):
+			problems = append(problems, f.Path+": hand edited, changes will be lost when it's regenerated")
+		case !bytes.Equal(old, f.Code):
+			problems = append(problems, f.Path+": stale, the schema or templates have changed")
+		}
+	}
+	orphans, err := Orphans(root, files)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range orphans {
+		problems = append(problems, o+": no longer generated")
+	}
+	return problems, nil
+}
+
+// Orphans returns the generated files under the output packages that
+// aren't in files, ex. those of a deleted object
+func Orphans(root string, files []File) ([]string, error) {
+	wanted := map[string]bool{}
+	for _, f := range files {
+		wanted[filepath.Clean(f.Path)] = true
+	}
+	orphans := []string{}
+	dirs := map[string]bool{}
+	for _, t := range output.Templates() {
+		// a template rendered anywhere can't be told apart from the rest
+		// of the repository
+		if dir := t.Dir(); dir != "." {
+			dirs[dir] = true
+		}
+	}
+	for dir := range dirs {
+		err := filepath.Walk(filepath.Join(root, dir), func(p string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			if err != nil || info.IsDir() || !(strings.HasSuffix(p, generatedSuffix) || strings.HasSuffix(p, generatedTestSuffix)) {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			if !wanted[rel] {
+				orphans = append(orphans, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+func GenerateCode(tmplname, tmpl string, obj interface{}) ([]byte, error) {
 	t, err := template.New(tmplname).Funcs(funcMap).Parse(tmpl)
 	if err != nil {
-		log.Fatal(err.Error())
+		return nil, err
 	}
 	b := new(bytes.Buffer)
 	if err := t.Execute(b, obj); err != nil {
-		log.Fatal(err.Error())
+		return nil, err
 	}
 	formatted, err := format.Source(b.Bytes())
 	if err != nil {
 		fmt.Printf("%s\n", b.Bytes())
-		log.Fatal(err.Error())
+		return nil, err
 	}
-
-	return string(formatted)
+	return Stamp(formatted), nil
 }
 
-func verifyRunDir() {
-	cwd, err := os.Getwd()
+// GenerateText is GenerateCode for files that aren't Go
+func GenerateText(tmplname, tmpl string, obj interface{}) ([]byte, error) {
+	t, err := template.New(tmplname).Funcs(funcMap).Parse(tmpl)
 	if err != nil {
-		log.Fatal("failed to get current working directory")
+		return nil, err
+	}
+	b := new(bytes.Buffer)
+	if err := t.Execute(b, obj); err != nil {
+		return nil, err
+	}
+	return Stamp(b.Bytes()), nil
+}
+
+////////////////////////////////////////////////////////////
+
+// Stamp adds a checksum of the code to its header so hand edits can be
+// told apart from a stale template or schema
+func Stamp(code []byte) []byte {
+	i := bytes.Index(code, []byte(headerLine))
+	if i < 0 {
+		return code
 	}
-	if cwd != rundir {
-		log.Fatalf("must be run from directory: %s\n", rundir)
+	i += len(headerLine)
+	line := checksumPrefix + checksum(code) + "\n"
+	return append(append(append([]byte{}, code[:i]...), line...), code[i:]...)
+}
+
+// Verify reports whether code still matches the checksum in its header
+func Verify(code []byte) bool {
+	start := bytes.Index(code, []byte("\n"+checksumPrefix))
+	if start < 0 {
+		return false
+	}
+	start++
+	end := bytes.IndexByte(code[start:], '\n')
+	if end < 0 {
+		return false
 	}
+	end += start
+	sum := string(code[start+len(checksumPrefix) : end])
+	unstamped := append(append([]byte{}, code[:start]...), code[end+1:]...)
+	return sum == checksum(unstamped)
+}
+
+func checksum(code []byte) string {
+	sum := sha256.Sum256(code)
+	return hex.EncodeToString(sum[:8])
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+	"git.ottoq.com/otto-backend/valet/gen/output"
+)
+
+const selfReferenceSchema = `{
+  "objects": [
+    {
+      "name": "Node",
+      "typeID": "0C74DFC158C646C280BCB0DAF9E015D1",
+      "parameters": [
+        { "type": "id" },
+        { "name": "Name", "type": "string" },
+        { "name": "ParentID", "type": "foreign", "references": "Node.ID", "nullable": true }
+      ]
+    },
+    {
+      "name": "Desk",
+      "typeID": "E1874C161CDB492FB95EF210E653B886",
+      "parameters": [
+        { "type": "id" },
+        { "name": "NodeID", "type": "foreign", "references": "Node.ID" }
+      ]
+    }
+  ]
+}`
+
+// renderTemplate renders the template name for each object in schema, keyed by
+// path
+func renderTemplate(t *testing.T, schema, name string) map[string]string {
+	objects, err := domain.Load("test.json", []byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := domain.List
+	domain.List = objects
+	defer func() { domain.List = list }()
+
+	for _, tmpl := range output.Templates() {
+		if tmpl.Name != name {
+			continue
+		}
+		files, err := Render(tmpl, objects)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code := map[string]string{}
+		for _, f := range files {
+			code[filepath.ToSlash(f.Path)] = string(f.Code)
+		}
+		return code
+	}
+	t.Fatalf("no %s template", name)
+	return nil
+}
+
+// renderDomain renders the domain package of each object in schema,
+// keyed by path
+func renderDomain(t *testing.T, schema string) map[string]*ast.File {
+	parsed := map[string]*ast.File{}
+	for path, code := range renderTemplate(t, schema, "Domain") {
+		f, err := parser.ParseFile(token.NewFileSet(), path, code, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		parsed[path] = f
+	}
+	return parsed
+}
+
+// funcs returns the declared functions of f as "Name" or "Recv.Name",
+// each with its signature
+func funcs(f *ast.File) map[string]string {
+	declared := map[string]string{}
+	for _, d := range f.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		name := fn.Name.Name
+		if fn.Recv != nil {
+			name = types.ExprString(fn.Recv.List[0].Type) + "." + name
+		}
+		params := []string{}
+		for _, p := range fn.Type.Params.List {
+			for range p.Names {
+				params = append(params, types.ExprString(p.Type))
+			}
+		}
+		results := []string{}
+		if fn.Type.Results != nil {
+			for _, r := range fn.Type.Results.List {
+				results = append(results, types.ExprString(r.Type))
+			}
+		}
+		declared[name] = "(" + strings.Join(params, ", ") + ") (" + strings.Join(results, ", ") + ")"
+	}
+	return declared
+}
+
+func TestSelfReference(t *testing.T) {
+	files := renderDomain(t, selfReferenceSchema)
+	node := funcs(files["domain/node/node_gen.go"])
+	want := map[string]string{
+		"*Node.Parent":        "(context.Context, domain.DB) (*Node, error)",
+		"*Node.NodesByParent": "(context.Context, domain.DB) ([]*Node, error)",
+		"LoadParents":         "(context.Context, domain.DB, []*Node) (map[string]*Node, error)",
+		"ListByParentID":      "(context.Context, domain.DB, string) ([]*Node, error)",
+		"ListByParentIDs":     "(context.Context, domain.DB, ...string) (map[string][]*Node, error)",
+	}
+	for name, sig := range want {
+		if got, ok := node[name]; !ok {
+			t.Errorf("node has no %s", name)
+		} else if got != sig {
+			t.Errorf("node %s is %s, want %s", name, got, sig)
+		}
+	}
+
+	// a reference across packages is listed by the referencing package,
+	// which sets node's loader as node can't import it
+	desk := funcs(files["domain/desk/desk_gen.go"])
+	for _, name := range []string{"*Desk.Node", "LoadNodes", "ListByNodeID", "ListByNodeIDs", "init"} {
+		if _, ok := desk[name]; !ok {
+			t.Errorf("desk has no %s", name)
+		}
+	}
+	if got, want := node["*Node.Desks"], "(context.Context, domain.DB) ([]domain.Domain, error)"; got != want {
+		t.Errorf("node Desks is %q, want %s", got, want)
+	}
+	for _, imp := range files["domain/node/node_gen.go"].Imports {
+		if strings.HasSuffix(imp.Path.Value, "/domain/desk\"") {
+			t.Error("node imports desk")
+		}
+	}
+}
+
+func TestEnumParameter(t *testing.T) {
+	schema := `{
+  "objects": [
+    {
+      "name": "Desk",
+      "typeID": "E1874C161CDB492FB95EF210E653B886",
+      "parameters": [
+        { "type": "id" },
+        { "name": "Name", "type": "string" },
+        { "name": "State", "type": "enum", "values": ["open", "closed"] }
+      ]
+    }
+  ]
+}`
+	declared := funcs(renderDomain(t, schema)["domain/desk/desk_gen.go"])
+	if _, ok := declared["*QueryBuilder.NameLike"]; !ok {
+		t.Error("desk has no NameLike")
+	}
+	if _, ok := declared["*QueryBuilder.StateLike"]; ok {
+		t.Error("desk has a StateLike for an enum")
+	}
+
+	// the enums package is grouped with the repository's, after the
+	// standard library's
+	for template, path := range map[string]string{
+		"Domain": "domain/desk/desk_gen.go",
+		"Input":  "dto/input/desk/desk_gen.go",
+	} {
+		code := renderTemplate(t, schema, template)[path]
+		imports := code[strings.Index(code, "import (")+len("import ("):]
+		imports = imports[:strings.Index(imports, ")")]
+		groups := strings.Split(imports, "\n\n")
+		if !strings.Contains(groups[len(groups)-1], `"git.ottoq.com/otto-backend/valet/domain/enums"`) {
+			t.Errorf("%s doesn't import enums last:%s", path, imports)
+		}
+		if strings.Contains(groups[0], "enums") {
+			t.Errorf("%s imports enums with the standard library:%s", path, imports)
+		}
+	}
+}
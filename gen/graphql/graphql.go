@@ -0,0 +1,536 @@
+// Package graphql generates the GraphQL schema of the domain objects and
+// the resolvers serving it from the domain packages. Objects with a read
+// or list REST endpoint get a type, with their foreign keys and links to
+// other such objects as fields, and lists that filter and paginate.
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+	"git.ottoq.com/otto-backend/valet/gen/namecase"
+)
+
+// BasePath is where the graphql package is generated, relative to the
+// repository root
+var BasePath = "graphql"
+
+// Schema is the GraphQL schema of the served objects
+type Schema struct {
+	Types []Type
+	Enums []Enum // Enums are the enum parameters' types, sorted by name
+	SDL   string // SDL is the schema in the GraphQL schema language
+}
+
+// Imports returns the packages the generated resolvers need besides the
+// domain packages
+func (s *Schema) Imports() []string {
+	imports := map[string]bool{}
+	for _, t := range s.Types {
+		if t.Get() {
+			imports["database/sql"] = true
+		}
+		for _, f := range t.Sorts {
+			if pkg := f.Import(); pkg != "" {
+				imports[pkg] = true
+			}
+		}
+	}
+	list := []string{}
+	for i := range imports {
+		list = append(list, i)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Enum is an enum parameter's type whose values are all GraphQL names
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+// Var names the generated variable holding the enum
+func (e Enum) Var() string {
+	return "enum" + e.Name
+}
+
+// Type is an object served by GraphQL
+type Type struct {
+	domain.Object
+	Fields   []Field  // Fields are the columns
+	Filters  []Filter // Filters are the fields of the list filter argument
+	Sorts    []Field  // Sorts are the columns lists can be ordered by
+	Parents  []Parent
+	Children []Child
+	Links    []Link
+	Listed   bool // Listed is set when some field lists the objects
+}
+
+// Get reports whether the query root reads the object by its key
+func (t Type) Get() bool {
+	return t.HasOp("read") && len(t.PrimaryKeys()) == 1
+}
+
+// List reports whether the query root lists the objects
+func (t Type) List() bool {
+	return t.HasOp("list")
+}
+
+// Key returns the field of the single column primary key
+func (t Type) Key() Field {
+	for _, f := range t.Fields {
+		if f.PrimaryKey {
+			return f
+		}
+	}
+	return Field{}
+}
+
+// linked reports whether the type has a link to the named object
+func (t Type) linked(name string) bool {
+	for _, l := range t.Links {
+		if l.Other.Name.UpperCamel == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Plural names the root field listing the objects, ex. desks
+func (t Type) Plural() string {
+	return plural(t.Name.LowerCamel)
+}
+
+// Field is a column of a served object
+type Field struct {
+	domain.Parameter
+	Name string // Name is the GraphQL field, the lower camel case column
+	Type string // Type is the Go expression of the field's graphql.Type
+	SDL  string // SDL is the type in the schema language
+}
+
+// Convert returns the Go expression of the column's value type parsed
+// from expr, an argument of the field's type
+func (f Field) Convert(expr string) string {
+	switch {
+	case f.IsTime():
+		return expr + ".(time.Time)"
+	case f.Kind() == reflect.String:
+		return conversion(f.ValueType(), "string", expr)
+	case f.Kind() == reflect.Bool:
+		return conversion(f.ValueType(), "bool", expr)
+	case f.Kind() == reflect.Float64 || f.Kind() == reflect.Float32:
+		return conversion(f.ValueType(), "float64", expr)
+	}
+	return conversion(f.ValueType(), "int64", expr)
+}
+
+// Leaf returns Type without its non null wrapper
+func (f Field) Leaf() string {
+	return strings.TrimSuffix(strings.TrimPrefix(f.Type, "&NonNull{Of: "), "}")
+}
+
+func conversion(to, from, expr string) string {
+	if to == from {
+		return expr + ".(" + from + ")"
+	}
+	return to + "(" + expr + ".(" + from + "))"
+}
+
+// Filter is a field of the filter argument of a list
+type Filter struct {
+	Name  string
+	Type  string // Type is the Go expression of the field's graphql.Type
+	SDL   string
+	Apply string // Apply is the Go statement applying the value v to the query builder b
+}
+
+// Parent is a foreign key, served as the object it references
+type Parent struct {
+	domain.Relation
+	Name   string         // Name is the GraphQL field, ex. node
+	Target *namecase.Name // Target is the referenced object
+}
+
+// Child is a foreign key of another object referencing this one, served
+// as the list of objects referencing it
+type Child struct {
+	domain.Relation
+	Name   string // Name is the GraphQL field, ex. desks
+	Source Type   // Source is the object holding the foreign key
+}
+
+// Link is a many-to-many relationship, served as the list of linked
+// objects
+type Link struct {
+	domain.Link
+	Name  string // Name is the GraphQL field, ex. attendants
+	Other Type
+}
+
+// Linked names the QueryBuilder method of Other matching those linked to
+// the objects, ex. LinkedToDesks
+func (l Link) Linked() string {
+	return "LinkedTo" + plural(l.Self.UpperCamel)
+}
+
+// Types returns the Schema of the objects that are listed or read by a
+// single column key over REST, failing if two of its types or fields
+// share a name
+func Types(objects []domain.Object) (*Schema, error) {
+	s := &Schema{}
+	byName := map[string]*Type{}
+	enums := map[string]Enum{}
+	for _, o := range objects {
+		t := Type{Object: o}
+		if !t.Get() && !t.List() {
+			continue
+		}
+		for _, p := range o.Parameters {
+			f := field(p, enums)
+			t.Fields = append(t.Fields, f)
+			if p.Comparable() {
+				t.Sorts = append(t.Sorts, f)
+				t.Filters = append(t.Filters, filters(f)...)
+			}
+		}
+		s.Types = append(s.Types, t)
+	}
+	for i := range s.Types {
+		byName[s.Types[i].Name.UpperCamel] = &s.Types[i]
+	}
+	for i := range s.Types {
+		t := &s.Types[i]
+		for _, l := range t.Object.Links {
+			other, ok := byName[l.Other.UpperCamel]
+			if !ok {
+				continue
+			}
+			t.Links = append(t.Links, Link{Link: l, Name: namecase.New(l.Plural()).LowerCamel, Other: *other})
+		}
+	}
+	for i := range s.Types {
+		t := &s.Types[i]
+		for _, r := range t.Relations() {
+			target, ok := byName[r.Table]
+			if !ok {
+				continue
+			}
+			t.Parents = append(t.Parents, Parent{
+				Relation: r,
+				Name:     namecase.New(r.Name).LowerCamel,
+				Target:   target.Name,
+			})
+			if r.Reference.Nullable {
+				continue
+			}
+			// the field is named after the foreign key when it isn't
+			// the only way to the objects
+			name := plural(t.Name.LowerCamel)
+			if r.Name != r.Table || target.linked(t.Name.UpperCamel) {
+				name += "By" + r.Name
+			}
+			target.Children = append(target.Children, Child{Relation: r, Name: name, Source: *t})
+		}
+	}
+	for i := range s.Types {
+		t := &s.Types[i]
+		t.Listed = t.Listed || t.List()
+		for _, c := range t.Children {
+			byName[c.Source.Name.UpperCamel].Listed = true
+		}
+		for _, l := range t.Links {
+			byName[l.Other.Name.UpperCamel].Listed = true
+		}
+	}
+	for _, e := range enums {
+		s.Enums = append(s.Enums, e)
+	}
+	sort.Slice(s.Enums, func(i, j int) bool { return s.Enums[i].Name < s.Enums[j].Name })
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	s.SDL = s.sdl()
+	return s, nil
+}
+
+// graphqlName matches the names GraphQL allows
+var graphqlName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// field returns the Field of a column, adding the GraphQL enum of an
+// enum parameter to enums
+func field(p domain.Parameter, enums map[string]Enum) Field {
+	f := Field{Parameter: p, Name: p.Name.LowerCamel}
+	f.Type, f.SDL = scalar(p, enums)
+	// an empty JSON document is served as null
+	if !p.Nullable && !p.IsJSON() {
+		f.Type, f.SDL = "&NonNull{Of: "+f.Type+"}", f.SDL+"!"
+	}
+	return f
+}
+
+// scalar returns the Go expression and name of a column's leaf type
+func scalar(p domain.Parameter, enums map[string]Enum) (string, string) {
+	switch {
+	case p.Hex():
+		return "ID", "ID"
+	case p.IsTime():
+		return "Time", "Time"
+	case p.IsJSON():
+		return "JSON", "JSON"
+	case p.Enum != nil && enumNames(p.Enum):
+		e := Enum{Name: p.Enum.Name}
+		for _, v := range p.Enum.Values {
+			e.Values = append(e.Values, v.Value)
+		}
+		enums[e.Name] = e
+		return e.Var(), e.Name
+	}
+	switch p.Kind() {
+	case reflect.String, reflect.Slice:
+		return "String", "String"
+	case reflect.Bool:
+		return "Boolean", "Boolean"
+	case reflect.Float32, reflect.Float64:
+		return "Float", "Float"
+	}
+	return "Int", "Int"
+}
+
+// enumNames reports whether every value of an enum is a GraphQL name,
+// or it's served as a String
+func enumNames(e *domain.EnumType) bool {
+	for _, v := range e.Values {
+		switch {
+		case !graphqlName.MatchString(v.Value):
+			return false
+		case v.Value == "true" || v.Value == "false" || v.Value == "null":
+			return false
+		}
+	}
+	return true
+}
+
+// filters returns the filter fields of a comparable column
+func filters(f Field) []Filter {
+	name := f.Name
+	method := "b." + f.Parameter.Name.UpperCamel
+	leaf := f.Leaf()
+	sdl := strings.TrimSuffix(f.SDL, "!")
+	list := []Filter{
+		{Name: name, Type: leaf, SDL: sdl, Apply: "b.Where" + f.Parameter.Name.UpperCamel + "(" + f.Convert("v") + ")"},
+		{
+			Name: name + "In",
+			Type: "&List{Of: &NonNull{Of: " + leaf + "}}",
+			SDL:  "[" + sdl + "!]",
+			Apply: "vs := []" + f.ValueType() + "{}\n" +
+				"for _, v := range v.([]interface{}) {\n" +
+				"vs = append(vs, " + f.Convert("v") + ")\n" +
+				"}\n" +
+				method + "In(vs...)",
+		},
+	}
+	switch {
+	case f.IsText():
+		list = append(list, Filter{Name: name + "Like", Type: "String", SDL: "String", Apply: method + "Like(v.(string))"})
+	case f.IsNumeric():
+		list = append(list,
+			Filter{Name: name + "GreaterThan", Type: leaf, SDL: sdl, Apply: method + "GreaterThan(" + f.Convert("v") + ")"},
+			Filter{Name: name + "LessThan", Type: leaf, SDL: sdl, Apply: method + "LessThan(" + f.Convert("v") + ")"})
+	case f.IsTime():
+		list = append(list,
+			Filter{Name: name + "After", Type: "Time", SDL: "Time", Apply: method + "After(v.(time.Time))"},
+			Filter{Name: name + "Before", Type: "Time", SDL: "Time", Apply: method + "Before(v.(time.Time))"})
+	}
+	if f.Nullable {
+		list = append(list, Filter{
+			Name:  name + "IsNull",
+			Type:  "Boolean",
+			SDL:   "Boolean",
+			Apply: "if v.(bool) {\n" + method + "IsNull()\n} else {\n" + method + "IsNotNull()\n}",
+		})
+	}
+	return list
+}
+
+// plural returns the name of several of name, ex. desks
+func plural(name string) string {
+	if strings.HasSuffix(name, "s") {
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// check fails if two types or two fields of a type share a name
+func (s *Schema) check() error {
+	types := map[string]string{}
+	claim := func(name, owner string) error {
+		if other, ok := types[name]; ok {
+			return fmt.Errorf("graphql type %s of %s is also the type of %s", name, owner, other)
+		}
+		types[name] = owner
+		return nil
+	}
+	for _, name := range []string{"Query", "Order", "ID", "String", "Int", "Float", "Boolean", "Time", "JSON"} {
+		types[name] = "the graphql package"
+	}
+	for _, e := range s.Enums {
+		if err := claim(e.Name, "an enum parameter"); err != nil {
+			return err
+		}
+	}
+	root := map[string]string{}
+	for _, t := range s.Types {
+		name := t.Name.UpperCamel
+		for _, suffix := range []string{"", "Page", "Filter", "Order", "Field"} {
+			if err := claim(name+suffix, name); err != nil {
+				return err
+			}
+		}
+		fields := map[string]string{"__typename": "GraphQL"}
+		add := func(field, what string) error {
+			if other, ok := fields[field]; ok {
+				return fmt.Errorf("graphql field %s.%s of %s is also the field of %s", name, field, what, other)
+			}
+			fields[field] = what
+			return nil
+		}
+		for _, f := range t.Fields {
+			if err := add(f.Name, "column "+f.Parameter.Name.UpperCamel); err != nil {
+				return err
+			}
+		}
+		for _, p := range t.Parents {
+			if err := add(p.Name, "foreign key "+p.Column.Name.UpperCamel); err != nil {
+				return err
+			}
+		}
+		for _, c := range t.Children {
+			if err := add(c.Name, "foreign key "+c.Source.Name.UpperCamel+"."+c.Column.Name.UpperCamel); err != nil {
+				return err
+			}
+		}
+		for _, l := range t.Links {
+			if err := add(l.Name, "relationship "+l.Table.UpperCamel); err != nil {
+				return err
+			}
+		}
+		filters := map[string]bool{}
+		for _, f := range t.Filters {
+			if filters[f.Name] {
+				return fmt.Errorf("graphql filter %sFilter.%s is declared twice", name, f.Name)
+			}
+			filters[f.Name] = true
+		}
+		roots := []string{}
+		if t.Get() {
+			roots = append(roots, t.Name.LowerCamel)
+		}
+		if t.List() {
+			roots = append(roots, t.Plural())
+		}
+		for _, r := range roots {
+			if other, ok := root[r]; ok {
+				return fmt.Errorf("graphql field Query.%s of %s is also a field of %s", r, name, other)
+			}
+			root[r] = name
+		}
+	}
+	return nil
+}
+
+// listArgs are the arguments of every list of t
+func listArgs(t Type) string {
+	name := t.Name.UpperCamel
+	return "(filter: " + name + "Filter, orderBy: [" + name + "Order!], limit: Int = 50, offset: Int = 0)"
+}
+
+// description returns a description in the schema language, indented by
+// indent
+func description(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	return indent + `"""` + strings.Replace(text, `"""`, `\"""`, -1) + `"""` + "\n"
+}
+
+// sdl returns the schema in the GraphQL schema language
+func (s *Schema) sdl() string {
+	b := &strings.Builder{}
+	b.WriteString(`"""An RFC 3339 date and time, ex. 2017-06-01T12:00:00Z"""
+scalar Time
+
+"""Any JSON value"""
+scalar JSON
+
+"""The direction of a sort"""
+enum Order {
+  ASC
+  DESC
+}
+`)
+	for _, e := range s.Enums {
+		fmt.Fprintf(b, "\nenum %s {\n", e.Name)
+		for _, v := range e.Values {
+			fmt.Fprintf(b, "  %s\n", v)
+		}
+		b.WriteString("}\n")
+	}
+	b.WriteString("\ntype Query {\n")
+	for _, t := range s.Types {
+		name := t.Name.UpperCamel
+		if t.Get() {
+			k := t.Key()
+			b.WriteString(description("Reads the "+name+" with the given key, null if there's none", "  "))
+			fmt.Fprintf(b, "  %s(%s: %s!): %s\n", t.Name.LowerCamel, k.Name, strings.TrimSuffix(k.SDL, "!"), name)
+		}
+		if t.List() {
+			b.WriteString(description("Lists "+name+"s", "  "))
+			fmt.Fprintf(b, "  %s%s: %sPage\n", t.Plural(), listArgs(t), name)
+		}
+	}
+	b.WriteString("}\n")
+	for _, t := range s.Types {
+		name := t.Name.UpperCamel
+		b.WriteString("\n" + description(t.Description, ""))
+		fmt.Fprintf(b, "type %s {\n", name)
+		for _, f := range t.Fields {
+			fmt.Fprintf(b, "  %s: %s\n", f.Name, f.SDL)
+		}
+		for _, p := range t.Parents {
+			b.WriteString(description("The "+p.Table+" referenced by "+p.Column.Name.UpperCamel, "  "))
+			fmt.Fprintf(b, "  %s: %s\n", p.Name, p.Table)
+		}
+		for _, c := range t.Children {
+			source := c.Source.Name.UpperCamel
+			b.WriteString(description("The "+source+"s whose "+c.Column.Name.UpperCamel+" references this "+name, "  "))
+			fmt.Fprintf(b, "  %s%s: %sPage\n", c.Name, listArgs(c.Source), source)
+		}
+		for _, l := range t.Links {
+			other := l.Other.Name.UpperCamel
+			b.WriteString(description("The "+l.Plural()+" linked through "+l.Table.UpperCamel, "  "))
+			fmt.Fprintf(b, "  %s%s: %sPage\n", l.Name, listArgs(l.Other), other)
+		}
+		b.WriteString("}\n")
+		fmt.Fprintf(b, "\n%s", description("A page of a list of "+name+"s and the length of the whole list", ""))
+		fmt.Fprintf(b, "type %sPage {\n  items: [%s!]!\n  total: Int!\n}\n", name, name)
+		fmt.Fprintf(b, "\n%s", description("Matches the "+name+"s passing every filter given", ""))
+		fmt.Fprintf(b, "input %sFilter {\n", name)
+		for _, f := range t.Filters {
+			fmt.Fprintf(b, "  %s: %s\n", f.Name, f.SDL)
+		}
+		b.WriteString("}\n")
+		fmt.Fprintf(b, "\ninput %sOrder {\n  field: %sField!\n  direction: Order = ASC\n}\n", name, name)
+		fmt.Fprintf(b, "\nenum %sField {\n", name)
+		for _, f := range t.Sorts {
+			fmt.Fprintf(b, "  %s\n", f.Parameter.Name.UpperCamel)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,291 @@
+package graphql
+
+var Plate = map[string]string{
+	"GraphQL": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+package graphql
+
+import (
+	"context"
+	{{- range .Imports }}{{ if stdlib . }}
+	"{{ . }}"
+	{{- end }}{{ end }}
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	{{- range .Imports }}{{ if not (stdlib .) }}
+	"{{ . }}"
+	{{- end }}{{ end }}
+	{{- range .Types }}
+	"git.ottoq.com/otto-backend/valet/domain/{{ .Name.Lower }}"
+	{{- end }}
+	"git.ottoq.com/otto-backend/valet/handler"
+	"git.ottoq.com/otto-backend/valet/server"
+)
+
+// SDL is the schema NewSchema returns, in the GraphQL schema language
+const SDL = {{ literal .SDL }}
+{{ range .Enums }}
+var {{ .Var }} = &Enum{Name: "{{ .Name }}", Values: []string{ {{- range $i, $v := .Values }}{{ if $i }}, {{ end }}"{{ $v }}"{{ end -}} }}
+{{ end }}
+// Register serves NewSchema on Path, see Serve
+func Register(s *server.Server, db domain.DB, hooks handler.Hooks) error {
+	return Serve(s, NewSchema(db, hooks))
+}
+
+// NewSchema returns the schema of the objects listed or read by key over
+// REST, resolved from db. Every field calls the Authorize hook of the
+// objects it returns with the op of the REST endpoint it stands for,
+// "read" for a single object and "list" for a list. A field the hook
+// refuses is null, as is every field of an object without the hook.
+func NewSchema(db domain.DB, hooks handler.Hooks) *Schema {
+	{{- range $t := .Types }}
+	{{ $t.Name.LowerCamel }}Type := &Object{Name: "{{ $t.Name.UpperCamel }}", Fields: map[string]*Field{
+		{{- range $t.Fields }}
+		"{{ .Name }}": {Type: {{ .Type }}, Resolve: column("{{ .Parameter.Name.UpperCamel }}")},
+		{{- end }}
+	}}
+	{{- end }}
+	{{- range $t := .Types }}{{ if $t.Listed }}
+	{{ $t.Name.LowerCamel }}Page := newPage({{ $t.Name.LowerCamel }}Type)
+	{{ $t.Name.LowerCamel }}Args := listArgs(
+		&Input{Name: "{{ $t.Name.UpperCamel }}Filter", Fields: map[string]*Argument{
+			{{- range $t.Filters }}
+			"{{ .Name }}": {Type: {{ .Type }}},
+			{{- end }}
+		}},
+		newOrder("{{ $t.Name.UpperCamel }}Order", &Enum{Name: "{{ $t.Name.UpperCamel }}Field", Values: []string{
+			{{- range $t.Sorts }}
+			"{{ .Parameter.Name.UpperCamel }}",
+			{{- end }}
+		}}),
+	)
+	{{- end }}{{ end }}
+	query := &Object{Name: "Query", Fields: map[string]*Field{}}
+{{ range $t := .Types }}
+	///////////////////
+	// {{ $t.Name.UpperCamel | upper }}
+	///////////////////
+{{ if $t.Get }}{{ $k := $t.Key }}
+	query.Fields["{{ $t.Name.LowerCamel }}"] = &Field{
+		Type: {{ $t.Name.LowerCamel }}Type,
+		Args: map[string]*Argument{"{{ $k.Name }}": {Type: &NonNull{Of: {{ $k.Leaf }}}}},
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := authorize(ctx, hooks.{{ $t.Name.UpperCamel }}.Authorize, "read"); err != nil {
+				return nil, err
+			}
+			o, err := {{ $t.Name.Lower }}.GetByID(ctx, db, {{ $k.Convert (printf "args[%q]" $k.Name) }})
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+			return o, err
+		}),
+	}
+{{ end }}{{ if $t.List }}
+	query.Fields["{{ $t.Plural }}"] = &Field{
+		Type: {{ $t.Name.LowerCamel }}Page,
+		Args: {{ $t.Name.LowerCamel }}Args,
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := authorize(ctx, hooks.{{ $t.Name.UpperCamel }}.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			b := query{{ $t.Name.UpperCamel }}({{ $t.Name.Lower }}.Query(), args)
+			items := []*{{ $t.Name.Lower }}.{{ $t.Name.UpperCamel }}{}
+			if limit > 0 {
+				if items, err = b.Limit(limit).Offset(offset).All(ctx, db); err != nil {
+					return nil, err
+				}
+			}
+			return &Page{Items: items, Count: func(ctx context.Context) (int, error) {
+				return b.Count(ctx, db)
+			}}, nil
+		}),
+	}
+{{ end }}{{ range $p := $t.Parents }}
+	{{ $t.Name.LowerCamel }}Type.Fields["{{ $p.Name }}"] = &Field{
+		Type: {{ $p.Target.LowerCamel }}Type,
+		Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			if err := authorize(ctx, hooks.{{ $p.Table }}.Authorize, "read"); err != nil {
+				return nil, err
+			}
+			list := make([]*{{ $t.Name.Lower }}.{{ $t.Name.UpperCamel }}, len(sources))
+			for i, s := range sources {
+				list[i] = s.(*{{ $t.Name.Lower }}.{{ $t.Name.UpperCamel }})
+			}
+			parents, err := {{ $t.Name.Lower }}.Load{{ $p.Relation.Name }}s(ctx, db, list)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]interface{}, len(list))
+			for i, o := range list {
+				{{- if $p.Column.Nullable }}
+				if o.{{ $p.Column.Name.UpperCamel }} == nil {
+					continue
+				}
+				{{- end }}
+				values[i] = parents[{{ $p.Key (print "o." $p.Column.Name.UpperCamel) }}]
+			}
+			return values, nil
+		},
+	}
+{{ end }}{{ range $c := $t.Children }}{{ $s := $c.Source }}
+	{{ $t.Name.LowerCamel }}Type.Fields["{{ $c.Name }}"] = &Field{
+		Type: {{ $s.Name.LowerCamel }}Page,
+		Args: {{ $s.Name.LowerCamel }}Args,
+		Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			if err := authorize(ctx, hooks.{{ $s.Name.UpperCamel }}.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			keys := make([]{{ $c.Column.ValueType }}, len(sources))
+			for i, s := range sources {
+				keys[i] = s.(*{{ $t.Name.Lower }}.{{ $t.Name.UpperCamel }}).{{ $c.Reference.Name.UpperCamel }}
+			}
+			b := query{{ $s.Name.UpperCamel }}({{ $s.Name.Lower }}.Query().{{ $c.Column.Name.UpperCamel }}In(keys...).PartitionBy{{ $c.Column.Name.UpperCamel }}(), args)
+			groups := map[{{ $c.Column.ValueType }}][]*{{ $s.Name.Lower }}.{{ $s.Name.UpperCamel }}{}
+			if limit > 0 {
+				stmt, stmtArgs := b.Limit(limit).Offset(offset).SQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				for rows.Next() {
+					var k {{ $c.Column.ValueType }}
+					o, err := {{ $s.Name.Lower }}.NewFromRow(partitioned{rows, {{ $c.Column.SQLScan "&k" }}})
+					if err != nil {
+						return nil, err
+					}
+					groups[k] = append(groups[k], o)
+				}
+				if err := rows.Err(); err != nil {
+					return nil, err
+				}
+			}
+			totals := &partitionCounts{count: func(ctx context.Context) (map[interface{}]int, error) {
+				stmt, stmtArgs := b.CountSQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				counts := map[interface{}]int{}
+				for rows.Next() {
+					var k {{ $c.Column.ValueType }}
+					var n int
+					if err := rows.Scan({{ $c.Column.SQLScan "&k" }}, &n); err != nil {
+						return nil, err
+					}
+					counts[k] = n
+				}
+				return counts, rows.Err()
+			}}
+			values := make([]interface{}, len(keys))
+			for i, k := range keys {
+				values[i] = &Page{Items: groups[k], Count: totals.of(k)}
+			}
+			return values, nil
+		},
+	}
+{{ end }}{{ range $l := $t.Links }}{{ $o := $l.Other }}
+	{{ $t.Name.LowerCamel }}Type.Fields["{{ $l.Name }}"] = &Field{
+		Type: {{ $o.Name.LowerCamel }}Page,
+		Args: {{ $o.Name.LowerCamel }}Args,
+		Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			if err := authorize(ctx, hooks.{{ $o.Name.UpperCamel }}.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			keys := make([]{{ $l.Key.ValueType }}, len(sources))
+			for i, s := range sources {
+				keys[i] = s.(*{{ $t.Name.Lower }}.{{ $t.Name.UpperCamel }}).{{ $l.Key.Name.UpperCamel }}
+			}
+			b := query{{ $o.Name.UpperCamel }}({{ $o.Name.Lower }}.Query().{{ $l.Linked }}(keys...), args)
+			groups := map[{{ $l.Key.ValueType }}][]*{{ $o.Name.Lower }}.{{ $o.Name.UpperCamel }}{}
+			if limit > 0 {
+				stmt, stmtArgs := b.Limit(limit).Offset(offset).SQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				for rows.Next() {
+					var k {{ $l.Key.ValueType }}
+					o, err := {{ $o.Name.Lower }}.NewFromRow(partitioned{rows, {{ $l.Key.SQLScan "&k" }}})
+					if err != nil {
+						return nil, err
+					}
+					groups[k] = append(groups[k], o)
+				}
+				if err := rows.Err(); err != nil {
+					return nil, err
+				}
+			}
+			totals := &partitionCounts{count: func(ctx context.Context) (map[interface{}]int, error) {
+				stmt, stmtArgs := b.CountSQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				counts := map[interface{}]int{}
+				for rows.Next() {
+					var k {{ $l.Key.ValueType }}
+					var n int
+					if err := rows.Scan({{ $l.Key.SQLScan "&k" }}, &n); err != nil {
+						return nil, err
+					}
+					counts[k] = n
+				}
+				return counts, rows.Err()
+			}}
+			values := make([]interface{}, len(keys))
+			for i, k := range keys {
+				values[i] = &Page{Items: groups[k], Count: totals.of(k)}
+			}
+			return values, nil
+		},
+	}
+{{ end }}{{ end }}
+	return New(query, SDL)
+}
+{{ range $t := .Types }}{{ if $t.Listed }}
+// query{{ $t.Name.UpperCamel }} adds the filter and orderBy arguments of a list of
+// {{ $t.Name.UpperCamel }}s to b
+func query{{ $t.Name.UpperCamel }}(b *{{ $t.Name.Lower }}.QueryBuilder, args map[string]interface{}) *{{ $t.Name.Lower }}.QueryBuilder {
+	filter, _ := args["filter"].(map[string]interface{})
+	{{- range $t.Filters }}
+	if v := filter["{{ .Name }}"]; v != nil {
+		{{ .Apply }}
+	}
+	{{- end }}
+	orders, _ := args["orderBy"].([]interface{})
+	for _, o := range orders {
+		o := o.(map[string]interface{})
+		direction, _ := o["direction"].(string)
+		switch o["field"] {
+		{{- range $t.Sorts }}
+		case "{{ .Parameter.Name.UpperCamel }}":
+			b.OrderBy{{ .Parameter.Name.UpperCamel }}(domain.Order(direction))
+		{{- end }}
+		}
+	}
+	// the primary key breaks ties, so pages don't overlap
+	{{- range $t.PrimaryKeys }}
+	b.OrderBy{{ .Name.UpperCamel }}(domain.Asc)
+	{{- end }}
+	return b
+}
+{{ end }}{{ end }}`,
+}
@@ -0,0 +1,5 @@
+package handler
+
+// BasePath is where the handler packages are generated, relative to the
+// repository root
+var BasePath = "handler"
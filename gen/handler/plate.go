@@ -0,0 +1,348 @@
+package handler
+
+var Plate = map[string]string{
+	"Handler": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+// Package {{ .Name.Lower }}
+// Default handlers for the {{ .Name.UpperCamel }} REST endpoints
+package {{ .Name.Lower }}
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/{{ .Name.Lower }}"
+	"git.ottoq.com/otto-backend/valet/dto/input/{{ .Name.Lower }}"
+	"git.ottoq.com/otto-backend/valet/entity"
+	"git.ottoq.com/otto-backend/valet/server"
+)
+
+// Hooks add business logic to the default handlers. Every hook but
+// Authorize is optional and an error from any of them fails the request,
+// a *server.Error choosing the status.
+type Hooks struct {
+	// Authorize runs first for every operation, op being one of
+	// "create", "read", "update", "delete" or "list". Without it every
+	// request is refused, as GraphQL queries are.
+	Authorize func(ctx context.Context, op string, in server.InputDTO) error
+	// Before hooks run once the input is applied, before the write
+	BeforeCreate func(ctx context.Context, o *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) error
+	BeforeUpdate func(ctx context.Context, o *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) error
+	BeforeDelete func(ctx context.Context, o *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) error
+	// After hooks run once the write succeeded, before the response
+	AfterCreate func(ctx context.Context, o *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) error
+	AfterUpdate func(ctx context.Context, o *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) error
+	AfterDelete func(ctx context.Context, o *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) error
+}
+
+func (h Hooks) authorize(ctx context.Context, op string, in server.InputDTO) error {
+	if h.Authorize == nil {
+		return &server.Error{Status: http.StatusForbidden}
+	}
+	return h.Authorize(ctx, op, in)
+}
+
+func run(ctx context.Context, hook func(context.Context, *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) error, o *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) error {
+	if hook == nil {
+		return nil
+	}
+	return hook(ctx, o)
+}
+
+// Register serves every generated {{ .Name.UpperCamel }} endpoint on input{{ .Name.Lower }}.Route
+func Register(s *server.Server, db domain.DB, hooks Hooks) error {
+	s.RegisterHTTPRoute(input{{ .Name.Lower }}.Route, input{{ .Name.Lower }}.Converters())
+	{{- if .HasOp "create" }}
+	if err := s.RegisterHandler(&CreateHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	{{- end }}
+	{{- if .HasOp "read" }}
+	if err := s.RegisterHandler(&ReadHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	{{- end }}
+	{{- if .HasOp "update" }}
+	if err := s.RegisterHandler(&UpdateHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	{{- end }}
+	{{- if .HasOp "delete" }}
+	if err := s.RegisterHandler(&DeleteHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	{{- end }}
+	{{- if .HasOp "list" }}
+	if err := s.RegisterHandler(&ListHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	{{- end }}
+	return nil
+}
+
+// httpError maps the errors of the domain package to a response status
+func httpError(err error) error {
+	switch e := err.(type) {
+	case *server.Error:
+		return e
+	case domain.ValidationError:
+		return &server.Error{Status: http.StatusBadRequest, Message: e.Error()}
+	case *domain.ErrStaleObject:
+		return &server.Error{Status: http.StatusConflict, Message: e.Error()}
+	case *domain.ErrDeleted:
+		return &server.Error{Status: http.StatusConflict, Message: e.Error()}
+	}
+	if err == sql.ErrNoRows {
+		return &server.Error{Status: http.StatusNotFound}
+	}
+	return err
+}
+
+func respond(responses chan entity.Identifier, o *{{ .Name.Lower }}.{{ .Name.UpperCamel }}) {
+	responses <- server.NewResponse(o.{{ (index .PrimaryKeys 0).Name.UpperCamel }}, {{ .Name.Lower }}.TypeID, o)
+}
+{{ if .HasOp "create" }}
+// CreateHandler inserts a new {{ .Name.UpperCamel }}
+type CreateHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *CreateHandler) InputTypeID() string {
+	return input{{ .Name.Lower }}.CreateTypeID
+}
+
+func (h *CreateHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*input{{ .Name.Lower }}.CreatePayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "create", in); err != nil {
+		return httpError(err)
+	}
+	o, err := {{ .Name.Lower }}.New(
+		{{- range $p := .InputParameters }}
+		p.Contents.{{ $p.Name.UpperCamel }},
+		{{- end }}
+	)
+	if err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.BeforeCreate, o); err != nil {
+		return httpError(err)
+	}
+	if err := o.Insert(ctx, h.DB); err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.AfterCreate, o); err != nil {
+		return httpError(err)
+	}
+	respond(responses, o)
+	return nil
+}
+{{ end }}
+{{- if .HasOp "read" }}
+// ReadHandler responds with a single {{ .Name.UpperCamel }}
+type ReadHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *ReadHandler) InputTypeID() string {
+	return input{{ .Name.Lower }}.ReadTypeID
+}
+
+func (h *ReadHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*input{{ .Name.Lower }}.ReadPayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "read", in); err != nil {
+		return httpError(err)
+	}
+	o, err := {{ .Name.Lower }}.GetByID(ctx, h.DB
+		{{- range $p := .PrimaryKeys }}, p.Key.{{ $p.Name.UpperCamel }}{{ end }})
+	if err != nil {
+		return httpError(err)
+	}
+	respond(responses, o)
+	return nil
+}
+{{ end }}
+{{- if .HasOp "update" }}
+// UpdateHandler overwrites an existing {{ .Name.UpperCamel }} with the contents of a
+// PUT or applies a PATCH to it
+type UpdateHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *UpdateHandler) InputTypeID() string {
+	return input{{ .Name.Lower }}.UpdateTypeID
+}
+
+func (h *UpdateHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*input{{ .Name.Lower }}.UpdatePayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "update", in); err != nil {
+		return httpError(err)
+	}
+	o, err := {{ .Name.Lower }}.GetByID(ctx, h.DB
+		{{- range $p := .PrimaryKeys }}, p.Contents.{{ $p.Name.UpperCamel }}{{ end }})
+	if err != nil {
+		return httpError(err)
+	}
+	{{- with .VersionParameter }}
+	// the version the client read, so Update fails if it's stale. A PUT
+	// always has one, a patch without one applies to the version just read.
+	if p.Patch == nil || p.Contents.{{ .Name.UpperCamel }} != 0 {
+		o.{{ .Name.UpperCamel }} = p.Contents.{{ .Name.UpperCamel }}
+	}
+	{{- end }}
+	if p.Patch != nil {
+		if err := o.Patch(p.Patch); err != nil {
+			return httpError(err)
+		}
+	}
+	{{- if .MutableParameters }} else {
+		// setters, so only the columns that differ are written
+		{{- range $p := .MutableParameters }}
+		o.Set{{ $p.Name.UpperCamel }}(p.Contents.{{ $p.Name.UpperCamel }})
+		{{- end }}
+	}
+	{{- end }}
+	if err := run(ctx, h.Hooks.BeforeUpdate, o); err != nil {
+		return httpError(err)
+	}
+	if err := o.Update(ctx, h.DB); err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.AfterUpdate, o); err != nil {
+		return httpError(err)
+	}
+	respond(responses, o)
+	return nil
+}
+{{ end }}
+{{- if .HasOp "delete" }}
+// DeleteHandler removes a {{ .Name.UpperCamel }}, responding with no body
+type DeleteHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *DeleteHandler) InputTypeID() string {
+	return input{{ .Name.Lower }}.DeleteTypeID
+}
+
+func (h *DeleteHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*input{{ .Name.Lower }}.DeletePayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "delete", in); err != nil {
+		return httpError(err)
+	}
+	o, err := {{ .Name.Lower }}.GetByID(ctx, h.DB
+		{{- range $p := .PrimaryKeys }}, p.Key.{{ $p.Name.UpperCamel }}{{ end }})
+	if err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.BeforeDelete, o); err != nil {
+		return httpError(err)
+	}
+	if err := o.Delete(ctx, h.DB); err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.AfterDelete, o); err != nil {
+		return httpError(err)
+	}
+	return nil
+}
+{{ end }}
+{{- if .HasOp "list" }}
+// ListHandler responds with a page of {{ .Name.UpperCamel }}s
+type ListHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *ListHandler) InputTypeID() string {
+	return input{{ .Name.Lower }}.ListTypeID
+}
+
+func (h *ListHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*input{{ .Name.Lower }}.ListPayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "list", in); err != nil {
+		return httpError(err)
+	}
+	list, err := {{ .Name.Lower }}.List(ctx, h.DB, p.Limit, p.Offset)
+	if err != nil {
+		return httpError(err)
+	}
+	responses <- server.NewResponse("", input{{ .Name.Lower }}.ListTypeID, list)
+	return nil
+}
+{{ end }}
+`,
+
+	"Handlers": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+// Package handler
+// Registers the default handlers of every domain object with REST endpoints
+package handler
+
+import (
+	"context"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/server"
+	{{- range $o := . }}
+	{{ $o.Name.Lower }}handler "git.ottoq.com/otto-backend/valet/handler/{{ $o.Name.Lower }}"
+	{{- end }}
+)
+
+// Hooks holds the hooks of each object's handlers
+type Hooks struct {
+	{{- range $o := . }}
+	{{ $o.Name.UpperCamel }} {{ $o.Name.Lower }}handler.Hooks
+	{{- end }}
+}
+
+// Authorized returns the Hooks authorizing every operation on every
+// object with authorize
+func Authorized(authorize func(ctx context.Context, op string, in server.InputDTO) error) Hooks {
+	return Hooks{
+		{{- range $o := . }}
+		{{ $o.Name.UpperCamel }}: {{ $o.Name.Lower }}handler.Hooks{Authorize: authorize},
+		{{- end }}
+	}
+}
+
+// RegisterAll serves the generated endpoints of every domain object
+func RegisterAll(s *server.Server, db domain.DB, hooks Hooks) error {
+	{{- range $o := . }}
+	if err := {{ $o.Name.Lower }}handler.Register(s, db, hooks.{{ $o.Name.UpperCamel }}); err != nil {
+		return err
+	}
+	{{- end }}
+	return nil
+}
+`,
+}
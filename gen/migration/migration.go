@@ -0,0 +1,413 @@
+// Package migration diffs the domain model against a live database and
+// writes the versioned migration files applied by database.Migrate.
+// Migrations are MySQL only: the database is read from MySQL's
+// information_schema and the statements are MySQL DDL. Postgres and
+// SQLite databases are created by database.CreateTables.
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+)
+
+// Table is the current state of a table in the database
+type Table struct {
+	Name    string
+	Columns []Column
+	Indexes []domain.Index
+}
+
+// Column is the current state of a column in the database
+type Column struct {
+	Name       string
+	Type       string
+	ForeignKey *ForeignKey // ForeignKey is the constraint on the column, if any
+}
+
+// ForeignKey is a foreign key constraint in the database
+type ForeignKey struct {
+	Name    string
+	Table   string
+	Column  string
+	Cascade bool
+}
+
+// SQL returns the clause adding the constraint back to a column
+func (fk ForeignKey) SQL(column string) string {
+	s := "ADD CONSTRAINT " + fk.Name + " " + domain.ForeignString(column, fk.Table, fk.Column)
+	if fk.Cascade {
+		s += " ON DELETE CASCADE"
+	}
+	return s
+}
+
+// Column returns the named column or nil if the table doesn't have it
+func (t *Table) Column(name string) *Column {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+var (
+	fileRegexp    = regexp.MustCompile(`^(\d+)_\w+\.(up|down)\.sql$`)
+	intSizeRegexp = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|bigint)\(\d+\)`)
+)
+
+// Current reads every table in the MySQL database the connection is using
+func Current(db *sql.DB) (map[string]*Table, error) {
+	rows, err := db.Query(`SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = DATABASE()
+ORDER BY TABLE_NAME, ORDINAL_POSITION`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tables := map[string]*Table{}
+	for rows.Next() {
+		var table string
+		var c Column
+		if err := rows.Scan(&table, &c.Name, &c.Type); err != nil {
+			return nil, err
+		}
+		t, ok := tables[table]
+		if !ok {
+			t = &Table{Name: table}
+			tables[table] = t
+		}
+		t.Columns = append(t.Columns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := readIndexes(db, tables); err != nil {
+		return nil, err
+	}
+	return tables, readForeignKeys(db, tables)
+}
+
+// readIndexes adds the secondary indexes of each table
+func readIndexes(db *sql.DB, tables map[string]*Table) error {
+	rows, err := db.Query(`SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, COLUMN_NAME, COALESCE(SUB_PART, 0)
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = DATABASE() AND INDEX_NAME <> 'PRIMARY'
+ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name string
+		var nonUnique bool
+		var c domain.IndexColumn
+		if err := rows.Scan(&table, &name, &nonUnique, &c.Name, &c.Length); err != nil {
+			return err
+		}
+		t, ok := tables[table]
+		if !ok {
+			continue
+		}
+		if n := len(t.Indexes); n == 0 || t.Indexes[n-1].Name != name {
+			t.Indexes = append(t.Indexes, domain.Index{Name: name, Unique: !nonUnique})
+		}
+		i := &t.Indexes[len(t.Indexes)-1]
+		i.Columns = append(i.Columns, c)
+	}
+	return rows.Err()
+}
+
+// readForeignKeys adds the foreign key constraints of each table's columns
+func readForeignKeys(db *sql.DB, tables map[string]*Table) error {
+	rows, err := db.Query(`SELECT k.TABLE_NAME, k.COLUMN_NAME, k.CONSTRAINT_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, r.DELETE_RULE
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.TABLE_SCHEMA = DATABASE() AND k.REFERENCED_TABLE_NAME IS NOT NULL`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, column, rule string
+		var fk ForeignKey
+		if err := rows.Scan(&table, &column, &fk.Name, &fk.Table, &fk.Column, &rule); err != nil {
+			return err
+		}
+		fk.Cascade = rule == "CASCADE"
+		t, ok := tables[table]
+		if !ok {
+			continue
+		}
+		if c := t.Column(column); c != nil {
+			c.ForeignKey = &fk
+		}
+	}
+	return rows.Err()
+}
+
+// Diff returns the statements migrating the current tables to the domain
+// model (up) and the statements reverting them (down). Tables that aren't
+// part of the domain model are never dropped, they're listed in unknown.
+func Diff(objects []domain.Object, current map[string]*Table) (up, down, unknown []string) {
+	known := map[string]bool{}
+	for _, o := range objects {
+		name := o.Name.UpperCamel
+		known[strings.ToLower(name)] = true
+		t := lookup(current, name)
+		if t == nil {
+			up = append(up, o.SQLSchema())
+			down = append([]string{"DROP TABLE " + name + ";"}, down...)
+			continue
+		}
+		alter, revert := diffTable(o, t)
+		if len(alter) > 0 {
+			up = append(up, "ALTER TABLE "+name+"\n"+strings.Join(alter, ",\n")+";")
+			down = append([]string{"ALTER TABLE " + name + "\n" + strings.Join(revert, ",\n") + ";"}, down...)
+		}
+	}
+	for _, t := range current {
+		if !known[strings.ToLower(t.Name)] && t.Name != "schema_migrations" {
+			unknown = append(unknown, t.Name)
+		}
+	}
+	sort.Strings(unknown)
+	return up, down, unknown
+}
+
+// diffTable returns the ALTER TABLE clauses for a single table
+func diffTable(o domain.Object, t *Table) (alter, revert []string) {
+	prev := ""
+	for _, p := range o.Parameters {
+		name := p.Name.UpperCamel
+		c := t.Column(name)
+		position := " FIRST"
+		if prev != "" {
+			position = " AFTER " + prev
+		}
+		prev = name
+		switch {
+		case c == nil:
+			alter = append(alter, "ADD COLUMN "+name+" "+p.SQLType+p.SQLDefault()+position)
+			revert = append([]string{"DROP COLUMN " + name}, revert...)
+			if p.ForeignKey != nil {
+				fk := ForeignKeyName(o.Name.UpperCamel, name)
+				alter = append(alter, "ADD CONSTRAINT "+fk+" "+p.SQLForeign())
+				revert = append([]string{"DROP FOREIGN KEY " + fk}, revert...)
+			}
+		case normalize(c.Type) != normalize(p.SQLType):
+			alter = append(alter, "MODIFY COLUMN "+name+" "+p.SQLType+p.SQLDefault())
+			revert = append([]string{"MODIFY COLUMN " + name + " " + strings.ToUpper(c.Type)}, revert...)
+		}
+	}
+	for _, c := range t.Columns {
+		if o.Parameter(c.Name) != nil {
+			continue
+		}
+		// MySQL refuses to drop a column a foreign key is on
+		if c.ForeignKey != nil {
+			alter = append(alter, "DROP FOREIGN KEY "+c.ForeignKey.Name)
+			revert = append([]string{c.ForeignKey.SQL(c.Name)}, revert...)
+		}
+		alter = append(alter, "DROP COLUMN "+c.Name)
+		revert = append([]string{"ADD COLUMN " + c.Name + " " + strings.ToUpper(c.Type)}, revert...)
+	}
+
+	wanted := o.AllIndexes()
+	dropped, added := []domain.Index{}, []domain.Index{}
+	for _, i := range wanted {
+		existing := t.Index(i.Name)
+		if existing != nil && existing.SQL() == i.SQL() {
+			continue
+		}
+		if existing != nil {
+			dropped = append(dropped, *existing)
+		}
+		added = append(added, i)
+	}
+	// only indexes named by the generator are dropped, MySQL creates
+	// indexes of its own for foreign keys
+	for _, i := range t.Indexes {
+		if !generated(i.Name) {
+			continue
+		}
+		keep := false
+		for _, w := range wanted {
+			keep = keep || w.Name == i.Name
+		}
+		if !keep {
+			dropped = append(dropped, i)
+		}
+	}
+
+	// the indexes left by the up statements are those wanted and those
+	// not named by the generator, and by the down statements those
+	// dropped and those kept, but for the ones MySQL made for foreign
+	// keys: it drops them itself once another index leads with the column
+	left := append([]domain.Index{}, wanted...)
+	restored := append([]domain.Index{}, dropped...)
+	for _, i := range t.Indexes {
+		if !generated(i.Name) {
+			left = append(left, i)
+		}
+		if !t.implicit(i) && !has(dropped, i.Name) && !has(added, i.Name) {
+			restored = append(restored, i)
+		}
+	}
+	for _, i := range t.foreignKeyIndexes(o, dropped, left) {
+		alter = append(alter, "ADD "+i.SQL())
+		revert = append([]string{"DROP INDEX " + i.Name}, revert...)
+	}
+	for _, i := range dropped {
+		alter = append(alter, "DROP INDEX "+i.Name)
+		revert = append([]string{"ADD " + i.SQL()}, revert...)
+	}
+	for _, i := range added {
+		alter = append(alter, "ADD "+i.SQL())
+		revert = append([]string{"DROP INDEX " + i.Name}, revert...)
+	}
+	for _, i := range t.foreignKeyIndexes(o, added, restored) {
+		revert = append([]string{"ADD " + i.SQL()}, revert...)
+	}
+	return alter, revert
+}
+
+// foreignKeyIndexes returns an index for each foreign key column the
+// dropped indexes lead with and none of the remaining ones does. MySQL
+// refuses to drop the last index a foreign key can use (error 1553), so
+// the column is indexed on its own first, the index named after it like
+// the one MySQL makes for a key.
+func (t *Table) foreignKeyIndexes(o domain.Object, dropped, remaining []domain.Index) []domain.Index {
+	indexes := []domain.Index{}
+	for _, c := range t.Columns {
+		if c.ForeignKey == nil || o.Parameter(c.Name) == nil {
+			continue
+		}
+		if leads(dropped, c.Name) && !leads(remaining, c.Name) {
+			indexes = append(indexes, domain.Index{Name: c.Name, Columns: []domain.IndexColumn{{Name: c.Name}}})
+		}
+	}
+	return indexes
+}
+
+// implicit reports whether MySQL made the index for a foreign key: it's
+// named after the column or the constraint
+func (t *Table) implicit(i domain.Index) bool {
+	if generated(i.Name) || len(i.Columns) != 1 {
+		return false
+	}
+	c := t.Column(i.Columns[0].Name)
+	return c != nil && c.ForeignKey != nil && (i.Name == c.Name || i.Name == c.ForeignKey.Name)
+}
+
+// leads reports whether one of the indexes starts with the whole column
+func leads(indexes []domain.Index, column string) bool {
+	for _, i := range indexes {
+		if strings.EqualFold(i.Columns[0].Name, column) && i.Columns[0].Length == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func has(indexes []domain.Index, name string) bool {
+	for _, i := range indexes {
+		if i.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Index returns the named index or nil if the table doesn't have it
+func (t *Table) Index(name string) *domain.Index {
+	for i := range t.Indexes {
+		if t.Indexes[i].Name == name {
+			return &t.Indexes[i]
+		}
+	}
+	return nil
+}
+
+func generated(index string) bool {
+	return strings.HasPrefix(index, domain.IndexName(false)) || strings.HasPrefix(index, domain.IndexName(true))
+}
+
+// ForeignKeyName names the constraints added by ALTER TABLE so they can
+// be dropped again
+func ForeignKeyName(table, column string) string {
+	return "fk_" + table + "_" + column
+}
+
+func lookup(tables map[string]*Table, name string) *Table {
+	for _, t := range tables {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// normalize makes a column type comparable with the type information_schema reports
+func normalize(sqlType string) string {
+	t := strings.ToLower(strings.TrimSpace(sqlType))
+	return intSizeRegexp.ReplaceAllString(t, "$1")
+}
+
+////////////////////////////////////////////////////////////
+
+// Write saves the statements as the next version in dir and returns the
+// path of the up file. Nothing is written when up is empty.
+func Write(dir, name string, up, down []string) (string, error) {
+	if len(up) == 0 {
+		return "", nil
+	}
+	version, err := nextVersion(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0744); err != nil {
+		return "", err
+	}
+	base := path.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	if err := ioutil.WriteFile(base+".up.sql", []byte(strings.Join(up, "\n\n")+"\n"), 0644); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(base+".down.sql", []byte(strings.Join(down, "\n\n")+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return base + ".up.sql", nil
+}
+
+func nextVersion(dir string) (int, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	last := 0
+	for _, f := range files {
+		m := fileRegexp.FindStringSubmatch(f.Name())
+		if m == nil {
+			continue
+		}
+		v, _ := strconv.Atoi(m[1])
+		if v > last {
+			last = v
+		}
+	}
+	return last + 1, nil
+}
@@ -0,0 +1,180 @@
+package migration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+)
+
+const testSchema = `{
+  "objects": [
+    {
+      "name": "Node",
+      "typeID": "0C74DFC158C646C280BCB0DAF9E015D1",
+      "parameters": [{ "type": "id" }, { "name": "Name", "type": "string" }]
+    },
+    {
+      "name": "Desk",
+      "typeID": "E1874C161CDB492FB95EF210E653B886",
+      "parameters": [{ "type": "id" }, { "name": "Name", "type": "string" }]
+    }
+  ]
+}`
+
+// current returns the tables of objects as the database would hold them
+func current(objects []domain.Object) map[string]*Table {
+	tables := map[string]*Table{}
+	for _, o := range objects {
+		t := &Table{Name: o.Name.UpperCamel, Indexes: o.AllIndexes()}
+		for _, p := range o.Parameters {
+			t.Columns = append(t.Columns, Column{Name: p.Name.UpperCamel, Type: p.SQLType})
+		}
+		tables[t.Name] = t
+	}
+	return tables
+}
+
+func TestDiff(t *testing.T) {
+	objects, err := domain.Load("test.json", []byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	up, down, unknown := Diff(objects, current(objects))
+	if len(up) != 0 || len(down) != 0 || len(unknown) != 0 {
+		t.Fatalf("Diff of the same tables got %q, %q, %q", up, down, unknown)
+	}
+
+	up, down, _ = Diff(objects, map[string]*Table{})
+	if want := []string{objects[0].SQLSchema(), objects[1].SQLSchema()}; !reflect.DeepEqual(up, want) {
+		t.Fatalf("Diff of an empty database got up %q, want %q", up, want)
+	}
+	if want := []string{"DROP TABLE Desk;", "DROP TABLE Node;"}; !reflect.DeepEqual(down, want) {
+		t.Fatalf("Diff of an empty database got down %q, want %q", down, want)
+	}
+}
+
+func TestDiffDropsForeignKeyFirst(t *testing.T) {
+	objects, err := domain.Load("test.json", []byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := current(objects)
+	tables["Desk"].Columns = append(tables["Desk"].Columns, Column{
+		Name:       "NodeID",
+		Type:       "binary(16)",
+		ForeignKey: &ForeignKey{Name: "Desk_ibfk_1", Table: "Node", Column: "ID", Cascade: true},
+	})
+
+	up, down, _ := Diff(objects, tables)
+	want := []string{"ALTER TABLE Desk\nDROP FOREIGN KEY Desk_ibfk_1,\nDROP COLUMN NodeID;"}
+	if !reflect.DeepEqual(up, want) {
+		t.Fatalf("got up %q, want %q", up, want)
+	}
+	want = []string{"ALTER TABLE Desk\nADD COLUMN NodeID BINARY(16),\nADD CONSTRAINT Desk_ibfk_1 FOREIGN KEY (NodeID) REFERENCES Node(ID) ON DELETE CASCADE;"}
+	if !reflect.DeepEqual(down, want) {
+		t.Fatalf("got down %q, want %q", down, want)
+	}
+}
+
+// TestDiffAddsEnum adds an enum column holding its first value in the
+// rows already there, as the table is created with it, and a nullable one
+// holding NULL
+func TestDiffAddsEnum(t *testing.T) {
+	objects, err := domain.Load("test.json", []byte(`{
+  "objects": [
+    {
+      "name": "Desk",
+      "typeID": "E1874C161CDB492FB95EF210E653B886",
+      "parameters": [
+        { "type": "id" },
+        { "name": "State", "type": "enum", "values": ["open", "closed"] },
+        { "name": "Mood", "type": "enum", "values": ["happy", "sad"], "nullable": true }
+      ]
+    }
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := current(objects)
+	tables["Desk"].Columns = tables["Desk"].Columns[:1]
+
+	up, _, _ := Diff(objects, tables)
+	want := []string{"ALTER TABLE Desk\nADD COLUMN State ENUM('open','closed') NOT NULL DEFAULT 'open' AFTER ID,\nADD COLUMN Mood ENUM('happy','sad') AFTER State;"}
+	if !reflect.DeepEqual(up, want) {
+		t.Fatalf("got up %q, want %q", up, want)
+	}
+	if schema := objects[0].SQLSchema(); !strings.Contains(schema, "\nState ENUM('open','closed') NOT NULL DEFAULT 'open',") {
+		t.Fatalf("the table is created as\n%s", schema)
+	}
+}
+
+func TestDiffKeepsForeignKeyIndexed(t *testing.T) {
+	objects, err := domain.Load("test.json", []byte(`{
+  "objects": [
+    {
+      "name": "Node",
+      "typeID": "0C74DFC158C646C280BCB0DAF9E015D1",
+      "parameters": [{ "type": "id" }]
+    },
+    {
+      "name": "Desk",
+      "typeID": "E1874C161CDB492FB95EF210E653B886",
+      "parameters": [
+        { "type": "id" },
+        { "name": "Name", "type": "string" },
+        { "name": "NodeID", "type": "foreign", "references": "Node.ID" }
+      ],
+      "indexes": [{ "columns": ["NodeID", "Name"], "unique": true }]
+    }
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fk := &ForeignKey{Name: "Desk_ibfk_1", Table: "Node", Column: "ID"}
+	withUnique := current(objects)
+	withUnique["Desk"].Columns[2].ForeignKey = fk
+	// before the unique index, the key used the index MySQL made for it
+	withoutUnique := current(objects)
+	withoutUnique["Desk"].Columns[2].ForeignKey = fk
+	withoutUnique["Desk"].Indexes = []domain.Index{{Name: "NodeID", Columns: []domain.IndexColumn{{Name: "NodeID"}}}}
+
+	up, down, _ := Diff(objects, withoutUnique)
+	want := []string{"ALTER TABLE Desk\nADD UNIQUE INDEX uniq_NodeID_Name (NodeID, Name);"}
+	if !reflect.DeepEqual(up, want) {
+		t.Fatalf("adding the index got up %q, want %q", up, want)
+	}
+	want = []string{"ALTER TABLE Desk\nADD INDEX NodeID (NodeID),\nDROP INDEX uniq_NodeID_Name;"}
+	if !reflect.DeepEqual(down, want) {
+		t.Fatalf("adding the index got down %q, want %q", down, want)
+	}
+
+	// an index on other columns replaces the unique one
+	objects[1].Indexes[0].Columns = []domain.IndexColumn{{Name: "Name"}}
+	objects[1].Indexes[0].Name = domain.IndexName(true, "Name")
+	up, down, _ = Diff(objects, withUnique)
+	want = []string{"ALTER TABLE Desk\nDROP INDEX uniq_NodeID_Name,\nADD UNIQUE INDEX uniq_Name (Name),\nADD INDEX idx_NodeID (NodeID);"}
+	if !reflect.DeepEqual(up, want) {
+		t.Fatalf("replacing the index got up %q, want %q", up, want)
+	}
+	want = []string{"ALTER TABLE Desk\nDROP INDEX idx_NodeID,\nDROP INDEX uniq_Name,\nADD UNIQUE INDEX uniq_NodeID_Name (NodeID, Name);"}
+	if !reflect.DeepEqual(down, want) {
+		t.Fatalf("replacing the index got down %q, want %q", down, want)
+	}
+
+	// without an index of its own the key keeps the one it has
+	objects[1].Parameters[2].Index = false
+	up, down, _ = Diff(objects, withUnique)
+	want = []string{"ALTER TABLE Desk\nADD INDEX NodeID (NodeID),\nDROP INDEX uniq_NodeID_Name,\nADD UNIQUE INDEX uniq_Name (Name);"}
+	if !reflect.DeepEqual(up, want) {
+		t.Fatalf("dropping the index got up %q, want %q", up, want)
+	}
+	want = []string{"ALTER TABLE Desk\nDROP INDEX uniq_Name,\nADD UNIQUE INDEX uniq_NodeID_Name (NodeID, Name),\nDROP INDEX NodeID;"}
+	if !reflect.DeepEqual(down, want) {
+		t.Fatalf("dropping the index got down %q, want %q", down, want)
+	}
+}
@@ -33,5 +33,4 @@ func New(n string) *Name {
 		LowerCamel: lowerCamel,
 		UpperCamel: upperCamel,
 	}
-	return nil
 }
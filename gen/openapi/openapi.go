@@ -0,0 +1,303 @@
+// Package openapi builds the OpenAPI 3 document describing the domain
+// objects and the REST endpoints generated for them
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+)
+
+// BasePath is where the openapi package is generated, relative to the
+// repository root
+var BasePath = "openapi"
+
+// Version is the OpenAPI version of the document
+const Version = "3.0.3"
+
+type object = map[string]interface{}
+
+// Document returns the OpenAPI document as indented JSON. Every object
+// gets a schema, and a path for its Route when it has REST endpoints.
+func Document(objects []domain.Object) ([]byte, error) {
+	paths := object{}
+	schemas := object{}
+	for _, o := range objects {
+		name := o.Name.UpperCamel
+		schemas[name] = Schema(o.Description, o.Parameters)
+		if o.HasOp("create") {
+			schemas[name+"Create"] = Schema("The arguments creating a "+name, o.InputParameters())
+		}
+		if o.HasOp("update") {
+			schemas[name+"Update"] = Schema("The "+name+" to update and its new values",
+				o.UpdateInputParameters())
+			schemas[name+"Patch"] = PatchSchema(o)
+		}
+		if ops := Operations(o); len(ops) > 0 {
+			paths["/"+o.Name.Lower] = ops
+		}
+	}
+	doc := object{
+		"openapi": Version,
+		"info": object{
+			"title":   "valet",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": schemas,
+		},
+	}
+	b := new(bytes.Buffer)
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Operations returns the path item of an object's Route, keyed by the
+// lower case http method
+func Operations(o domain.Object) object {
+	name := o.Name.UpperCamel
+	item := ref(name)
+	ops := object{}
+	if o.HasOp("create") {
+		ops["post"] = object{
+			"operationId": "create" + name,
+			"summary":     "Create a " + name,
+			"parameters":  []interface{}{csrfParameter()},
+			"requestBody": body(ref(name + "Create")),
+			"responses":   responses(item, "400", "403", "415"),
+		}
+	}
+	switch {
+	case o.HasOp("read") && o.HasOp("list"):
+		ops["get"] = object{
+			"operationId": "get" + name,
+			"summary":     "Read a " + name + " by its key, or list them without one",
+			"parameters":  append(keyParameters(o, false), pageParameters()...),
+			"responses": responses(object{
+				"oneOf": []interface{}{item, array(item)},
+			}, "400", "404"),
+		}
+	case o.HasOp("read"):
+		ops["get"] = object{
+			"operationId": "read" + name,
+			"summary":     "Read a " + name + " by its key",
+			"parameters":  keyParameters(o, true),
+			"responses":   responses(item, "400", "404"),
+		}
+	case o.HasOp("list"):
+		ops["get"] = object{
+			"operationId": "list" + name + "s",
+			"summary":     "List " + name + "s",
+			"parameters":  pageParameters(),
+			"responses":   responses(array(item), "400"),
+		}
+	}
+	if o.HasOp("update") {
+		statuses := []string{"400", "403", "404", "415"}
+		if o.Versioned() {
+			statuses = append(statuses, "409")
+		}
+		ops["put"] = object{
+			"operationId": "update" + name,
+			"summary":     "Update a " + name,
+			"parameters":  []interface{}{csrfParameter()},
+			"requestBody": body(ref(name + "Update")),
+			"responses":   responses(item, statuses...),
+		}
+		ops["patch"] = object{
+			"operationId": "patch" + name,
+			"summary":     "Apply a JSON merge patch to a " + name,
+			"parameters":  []interface{}{csrfParameter()},
+			"requestBody": object{
+				"required": true,
+				"content":  object{"application/merge-patch+json": object{"schema": ref(name + "Patch")}},
+			},
+			"responses": responses(item, statuses...),
+		}
+	}
+	if o.HasOp("delete") {
+		ops["delete"] = object{
+			"operationId": "delete" + name,
+			"summary":     "Delete a " + name,
+			"parameters":  append(keyParameters(o, true), csrfParameter()),
+			"responses":   responses(nil, "400", "403", "404"),
+		}
+	}
+	return ops
+}
+
+// Schema returns the schema of an object holding params
+func Schema(description string, params []domain.Parameter) object {
+	properties := object{}
+	required := []string{}
+	for _, p := range params {
+		properties[p.Name.UpperCamel] = ParameterSchema(p)
+		if p.Required {
+			required = append(required, p.Name.UpperCamel)
+		}
+	}
+	s := object{
+		"type":       "object",
+		"properties": properties,
+	}
+	if description != "" {
+		s["description"] = description
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// PatchSchema returns the schema of a merge patch of an object, which
+// must name the object and may hold any of its update parameters
+func PatchSchema(o domain.Object) object {
+	s := Schema("A JSON merge patch of the "+o.Name.UpperCamel+" it names", o.UpdateInputParameters())
+	required := []string{}
+	for _, p := range o.PrimaryKeys() {
+		required = append(required, p.Name.UpperCamel)
+	}
+	s["required"] = required
+	return s
+}
+
+// ParameterSchema returns the schema of a parameter's JSON value,
+// including the constraints Validate() checks
+func ParameterSchema(p domain.Parameter) object {
+	s := object{}
+	switch {
+	case p.Hex():
+		s["type"] = "string"
+		s["pattern"] = "^[0-9A-Fa-f]{32}$"
+	case p.IsTime():
+		s["type"] = "string"
+		s["format"] = "date-time"
+	case p.ValueType() == "json.RawMessage":
+		// any JSON value
+	case p.Kind() == reflect.Slice:
+		s["type"] = "string"
+		s["format"] = "byte"
+	case p.Kind() == reflect.String:
+		s["type"] = "string"
+	case p.Kind() == reflect.Bool:
+		s["type"] = "boolean"
+	case p.Kind() == reflect.Float64:
+		s["type"] = "number"
+		s["format"] = "double"
+	case p.Kind() == reflect.Int32:
+		s["type"] = "integer"
+		s["format"] = "int32"
+	case p.Kind() == reflect.Uint:
+		s["type"] = "integer"
+		s["minimum"] = 0
+	default:
+		s["type"] = "integer"
+		s["format"] = "int64"
+	}
+	if n := p.MaxChars(); n > 0 {
+		s["maxLength"] = n
+	}
+	if p.Min != nil {
+		s["minimum"] = *p.Min
+	}
+	if p.Max != nil {
+		s["maximum"] = *p.Max
+	}
+	if p.Pattern != "" {
+		s["pattern"] = p.Pattern
+	}
+	if len(p.OneOf) > 0 {
+		s["enum"] = p.OneOf
+	}
+	if p.Nullable {
+		s["nullable"] = true
+	}
+	return s
+}
+
+func ref(name string) object {
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+func array(items object) object {
+	return object{"type": "array", "items": items}
+}
+
+func body(schema object) object {
+	return object{
+		"required": true,
+		"content":  object{"application/json": object{"schema": schema}},
+	}
+}
+
+// responses returns a 200 with schema as its JSON body, no body when
+// schema is nil, and a plain text error for each of the statuses
+func responses(schema object, statuses ...string) object {
+	ok := object{"description": "OK"}
+	if schema != nil {
+		ok["content"] = object{"application/json": object{"schema": schema}}
+	}
+	r := object{"200": ok}
+	for _, s := range append(statuses, "500") {
+		r[s] = object{"description": statusText[s]}
+	}
+	return r
+}
+
+var statusText = map[string]string{
+	"400": "Bad Request",
+	"403": "Forbidden",
+	"404": "Not Found",
+	"409": "Conflict",
+	"415": "Unsupported Media Type",
+	"500": "Internal Server Error",
+}
+
+// csrfParameter returns the header a write sends the CSRF token of its
+// session in, which every response sets, see input.CSRFHeader
+func csrfParameter() object {
+	return object{
+		"name":        "X-CSRF-Token",
+		"in":          "header",
+		"required":    true,
+		"description": "The X-CSRF-Token header of an earlier response",
+		"schema":      object{"type": "string"},
+	}
+}
+
+func keyParameters(o domain.Object, required bool) []interface{} {
+	params := []interface{}{}
+	for _, p := range o.PrimaryKeys() {
+		params = append(params, object{
+			"name":     p.Name.LowerCamel,
+			"in":       "query",
+			"required": required,
+			"schema":   ParameterSchema(p),
+		})
+	}
+	return params
+}
+
+func pageParameters() []interface{} {
+	return []interface{}{
+		object{
+			"name":        "limit",
+			"in":          "query",
+			"description": "Page size, 50 by default and at most 500",
+			"schema":      object{"type": "integer", "minimum": 1},
+		},
+		object{
+			"name":   "offset",
+			"in":     "query",
+			"schema": object{"type": "integer", "minimum": 0},
+		},
+	}
+}
@@ -0,0 +1,14 @@
+package openapi
+
+var Plate = map[string]string{
+	"OpenAPI": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+package openapi
+
+// Spec is the OpenAPI document of every domain object and its generated
+// REST endpoints, also written to openapi.json
+const Spec = {{ literal . }}
+`,
+}
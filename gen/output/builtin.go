@@ -0,0 +1,135 @@
+package output
+
+import (
+	"path"
+
+	"git.ottoq.com/otto-backend/valet/gen/database"
+	"git.ottoq.com/otto-backend/valet/gen/docs"
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+	"git.ottoq.com/otto-backend/valet/gen/dto"
+	"git.ottoq.com/otto-backend/valet/gen/graphql"
+	"git.ottoq.com/otto-backend/valet/gen/handler"
+	"git.ottoq.com/otto-backend/valet/gen/openapi"
+	"git.ottoq.com/otto-backend/valet/gen/registry"
+	"git.ottoq.com/otto-backend/valet/gen/seed"
+	"git.ottoq.com/otto-backend/valet/gen/typescript"
+)
+
+// perObject is the path of a package generated for each object
+const perObject = "{{ .Name.Lower }}/{{ .Name.Lower }}"
+
+// hasREST keeps the objects with REST endpoints
+func hasREST(o domain.Object) bool {
+	return len(o.REST) > 0
+}
+
+// builtin returns the templates generated unless overridden
+func builtin() []Template {
+	return []Template{
+		{
+			Name: "Domain",
+			Text: domain.Plate["Domain"],
+			Path: path.Join(domain.BasePath, perObject+"_gen.go"),
+		},
+		{
+			Name: "DomainTest",
+			Text: domain.Plate["DomainTest"],
+			Path: path.Join(domain.BasePath, perObject+"_gen_test.go"),
+		},
+		{
+			Name:  "Enums",
+			Text:  domain.Plate["Enums"],
+			Path:  path.Join(domain.BasePath, "enums", "enums_gen.go"),
+			Scope: Model,
+			Data: func(objects []domain.Object) (interface{}, error) {
+				return domain.Enums(objects)
+			},
+		},
+		{
+			Name:  "Database",
+			Text:  database.Plate["Database"],
+			Path:  path.Join(database.BasePath, "database_gen.go"),
+			Scope: Model,
+		},
+		{
+			Name:   "Input",
+			Text:   dto.Plate["Input"],
+			Path:   path.Join(dto.BasePath, perObject+"_gen.go"),
+			Filter: hasREST,
+		},
+		{
+			Name:   "Handler",
+			Text:   handler.Plate["Handler"],
+			Path:   path.Join(handler.BasePath, perObject+"_gen.go"),
+			Filter: hasREST,
+		},
+		{
+			Name:   "Handlers",
+			Text:   handler.Plate["Handlers"],
+			Path:   path.Join(handler.BasePath, "handler_gen.go"),
+			Scope:  Model,
+			Filter: hasREST,
+		},
+		{
+			Name:  "Registry",
+			Text:  registry.Plate["Registry"],
+			Path:  path.Join(registry.BasePath, "registry_gen.go"),
+			Scope: Model,
+			Data: func(objects []domain.Object) (interface{}, error) {
+				return registry.Entries(objects)
+			},
+		},
+		{
+			Name:  "Seed",
+			Text:  seed.Plate["Seed"],
+			Path:  path.Join(seed.BasePath, "seed_gen.go"),
+			Scope: Model,
+			Data: func(objects []domain.Object) (interface{}, error) {
+				return seed.Tables(objects), nil
+			},
+		},
+		{
+			Name:  "OpenAPI",
+			Text:  openapi.Plate["OpenAPI"],
+			Path:  path.Join(openapi.BasePath, "openapi_gen.go"),
+			Scope: Model,
+			Data: func(objects []domain.Object) (interface{}, error) {
+				doc, err := openapi.Document(objects)
+				return string(doc), err
+			},
+		},
+		{
+			Name:  "GraphQL",
+			Text:  graphql.Plate["GraphQL"],
+			Path:  path.Join(graphql.BasePath, "graphql_gen.go"),
+			Scope: Model,
+			Data: func(objects []domain.Object) (interface{}, error) {
+				return graphql.Types(objects)
+			},
+		},
+		{
+			Name:  "Dictionary",
+			Text:  docs.Plate["Dictionary"],
+			Path:  path.Join(docs.BasePath, "schema_gen.md"),
+			Scope: Model,
+			Data: func(objects []domain.Object) (interface{}, error) {
+				return docs.Tables(objects), nil
+			},
+		},
+		{
+			Name:  "Graphviz",
+			Text:  docs.Plate["Graphviz"],
+			Path:  path.Join(docs.BasePath, "schema_gen.dot"),
+			Scope: Model,
+			Data: func(objects []domain.Object) (interface{}, error) {
+				return docs.Tables(objects), nil
+			},
+		},
+		{
+			Name:  "Client",
+			Text:  typescript.Plate["Client"],
+			Path:  path.Join(typescript.BasePath, typescript.FileName),
+			Scope: Model,
+		},
+	}
+}
@@ -0,0 +1,190 @@
+// Package output holds the templates the generator renders. The built-in
+// templates are registered here, plugins add their own or override any of
+// them by name, and template directories do the same without any Go.
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+)
+
+// Scope is what a template is rendered for
+type Scope int
+
+const (
+	// PerObject templates render a file for each domain.Object
+	PerObject Scope = iota
+	// Model templates render a single file from every domain.Object
+	Model
+)
+
+// Template renders generated files. Files whose path ends in .go are
+// gofmt'd, and a checksum follows the "// VERY GENERATED PLZ NO MODIFY"
+// header line of any file that has one.
+type Template struct {
+	Name string
+	Text string
+	// Path is the output file relative to the repository root, itself a
+	// template rendered with the same data as Text, ex.
+	// "report/{{ .Name.Lower }}_gen.go"
+	Path  string
+	Scope Scope
+	// Filter, when set, leaves out the objects it returns false for
+	Filter func(o domain.Object) bool
+	// Data, when set, replaces the objects a Model template is rendered with
+	Data func(objects []domain.Object) (interface{}, error)
+}
+
+var (
+	mu        sync.Mutex
+	templates = builtin()
+)
+
+// Register adds a template, replacing the one of the same name
+func Register(t Template) error {
+	if t.Name == "" || t.Path == "" {
+		return fmt.Errorf("output: template %q needs a name and a path", t.Name)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range templates {
+		if templates[i].Name == t.Name {
+			templates[i] = t
+			return nil
+		}
+	}
+	templates = append(templates, t)
+	return nil
+}
+
+// Override replaces the text of a registered template, keeping where and
+// for what it's rendered
+func Override(name, text string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range templates {
+		if templates[i].Name == name {
+			templates[i].Text = text
+			return nil
+		}
+	}
+	return fmt.Errorf("output: no template %q to override", name)
+}
+
+// Templates returns every registered template in registration order
+func Templates() []Template {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Template{}, templates...)
+}
+
+// LoadDir registers every NAME.tmpl file in dir. A file starting with a
+// comment setting its path, and optionally its scope, is registered as
+// template NAME:
+//
+//	{{/*
+//	path: report/{{ .Name.Lower }}_gen.go
+//	scope: object
+//	*/}}
+//
+// Without one it overrides the text of the registered template NAME, ex.
+// Domain.tmpl replaces the domain package template.
+func LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.Base(p), ".tmpl")
+		t, ok, err := parse(name, string(b))
+		if err != nil {
+			return fmt.Errorf("%s: %s", p, err)
+		}
+		if !ok {
+			err = Override(name, string(b))
+		} else {
+			err = Register(t)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", p, err)
+		}
+	}
+	return nil
+}
+
+const (
+	headerStart = "{{/*"
+	headerEnd   = "*/}}"
+)
+
+// parse reads the header of a template file, reporting false when it
+// doesn't have one
+func parse(name, text string) (Template, bool, error) {
+	t := Template{Name: name, Scope: PerObject}
+	trimmed := strings.TrimLeft(text, " \t\r\n")
+	if !strings.HasPrefix(trimmed, headerStart) {
+		return t, false, nil
+	}
+	end := strings.Index(trimmed, headerEnd)
+	if end < 0 {
+		return t, false, fmt.Errorf("header comment isn't closed")
+	}
+	header := trimmed[len(headerStart):end]
+	s := bufio.NewScanner(strings.NewReader(header))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			return t, false, fmt.Errorf("header line %q must be key: value", line)
+		}
+		value := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case "path":
+			t.Path = value
+		case "scope":
+			switch value {
+			case "object":
+				t.Scope = PerObject
+			case "model":
+				t.Scope = Model
+			default:
+				return t, false, fmt.Errorf("scope %q must be object or model", value)
+			}
+		default:
+			return t, false, fmt.Errorf("unknown header key %q (want path or scope)", kv[0])
+		}
+	}
+	if t.Path == "" {
+		// a plain comment, not a header
+		return t, false, nil
+	}
+	t.Text = strings.TrimLeft(trimmed[end+len(headerEnd):], "\r\n")
+	return t, true, nil
+}
+
+// Dir returns the directory every file of a template is written under,
+// the part of its Path before anything rendered
+func (t Template) Dir() string {
+	static := t.Path
+	if i := strings.Index(static, "{{"); i >= 0 {
+		static = static[:i]
+		if j := strings.LastIndex(static, "/"); j >= 0 {
+			return filepath.Clean(static[:j])
+		}
+		return "."
+	}
+	return filepath.Dir(static)
+}
@@ -0,0 +1,19 @@
+// Package plugins registers templates written in Go with the generator,
+// which imports it for its side effects. Add a file here registering a
+// template set from init, ex.
+//
+//	func init() {
+//		for _, t := range []output.Template{{
+//			Name:  "Report",
+//			Text:  report.Plate,
+//			Path:  "report/{{ .Name.Lower }}_gen.go",
+//			Scope: output.PerObject,
+//		}} {
+//			if err := output.Register(t); err != nil {
+//				panic(err)
+//			}
+//		}
+//	}
+//
+// output.Override replaces the text of a built-in template instead.
+package plugins
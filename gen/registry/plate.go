@@ -0,0 +1,60 @@
+package registry
+
+var Plate = map[string]string{
+	"Registry": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+package registry
+
+import (
+	"context"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	{{- range $e := . }}
+	{{- with $e.Object }}
+	"git.ottoq.com/otto-backend/valet/domain/{{ .Name.Lower }}"
+	{{- end }}
+	{{- end }}
+)
+
+// init registers every domain object and the input of each of their
+// REST endpoints
+func init() {
+	for _, e := range []Entry{
+		{{- range $e := . }}
+		{{- with $o := $e.Object }}
+		{
+			TypeID: {{ $o.Name.Lower }}.TypeID,
+			Name:   "{{ $e.Name }}",
+			Table:  {{ $o.Name.Lower }}.TableName(),
+			New: func() domain.Domain {
+				return &{{ $o.Name.Lower }}.{{ $o.Name.UpperCamel }}{}
+			},
+			Scan: func(row Scannable) (domain.Domain, error) {
+				o, err := {{ $o.Name.Lower }}.NewFromRow(row)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+			{{- if $e.Loadable }}
+			Get: func(ctx context.Context, db domain.DB, id string) (domain.Domain, error) {
+				o, err := {{ $o.Name.Lower }}.GetByID(ctx, db, id)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+			{{- end }}
+		},
+		{{- else }}
+		{TypeID: "{{ $e.TypeID }}", Name: "{{ $e.Name }}"},
+		{{- end }}
+		{{- end }}
+	} {
+		MustRegister(e)
+	}
+}
+`,
+}
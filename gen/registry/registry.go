@@ -0,0 +1,58 @@
+// Package registry collects every generated TypeID, those of the domain
+// objects and of the inputs of their REST endpoints, for the registry
+// package the server checks handlers against
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+)
+
+// BasePath is where the registry package is generated, relative to the
+// repository root
+var BasePath = "registry"
+
+var typeIDRegexp = regexp.MustCompile(`^[0-9A-F]{32}$`)
+
+// Entry is a TypeID and what it identifies
+type Entry struct {
+	TypeID string
+	Name   string         // Name of the object, or of an input ex. DeskCreate
+	Object *domain.Object // Object is nil for an input
+}
+
+// Loadable reports whether the entry's object can be read by a single id
+func (e Entry) Loadable() bool {
+	if e.Object == nil {
+		return false
+	}
+	keys := e.Object.PrimaryKeys()
+	return len(keys) == 1 && keys[0].GoType() == "string"
+}
+
+// Entries returns an entry for each object followed by one for each of
+// its REST operations, failing if a TypeID is malformed or used twice
+func Entries(objects []domain.Object) ([]Entry, error) {
+	entries := []Entry{}
+	for i := range objects {
+		o := &objects[i]
+		entries = append(entries, Entry{TypeID: o.TypeID, Name: o.Name.UpperCamel, Object: o})
+		for _, op := range o.REST {
+			entries = append(entries, Entry{TypeID: o.OpTypeID(op), Name: o.Name.UpperCamel + strings.ToUpper(op[:1]) + op[1:]})
+		}
+	}
+	seen := map[string]string{}
+	for _, e := range entries {
+		if !typeIDRegexp.MatchString(e.TypeID) {
+			return nil, fmt.Errorf("TypeID %q of %s must be 32 uppercase hex characters", e.TypeID, e.Name)
+		}
+		if other, ok := seen[e.TypeID]; ok {
+			return nil, fmt.Errorf("TypeID %s of %s is already used by %s", e.TypeID, e.Name, other)
+		}
+		seen[e.TypeID] = e.Name
+	}
+	return entries, nil
+}
@@ -0,0 +1,88 @@
+package seed
+
+var Plate = map[string]string{
+	"Seed": `
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+package main
+
+import (
+	"context"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	{{- range . }}
+	"git.ottoq.com/otto-backend/valet/domain/{{ .Name.Lower }}"
+	{{- end }}
+)
+
+// seeded holds the objects inserted so far, for the foreign keys of the
+// objects inserted after them
+type seeded struct {
+	{{- range . }}
+	{{ .Name.UpperCamel }} []*{{ .Name.Lower }}.{{ .Name.UpperCamel }}
+	{{- end }}
+
+	keys map[string]bool // keys are the claimed primary and unique keys, see claim
+}
+
+// tables seeds each table, referenced tables first
+var tables = []struct {
+	Name  string
+	Seed  func(ctx context.Context, db domain.DB, s *seeded, n int) error
+	Count func(s *seeded) int
+}{
+	{{- range . }}
+	{
+		Name:  {{ .Name.Lower }}.TableName(),
+		Seed:  seed{{ .Name.UpperCamel }},
+		Count: func(s *seeded) int { return len(s.{{ .Name.UpperCamel }}) },
+	},
+	{{- end }}
+}
+{{ range $t := . }}
+// seed{{ $t.Name.UpperCamel }} inserts n random {{ $t.Name.UpperCamel }}s, fewer if it runs out of
+// distinct keys
+func seed{{ $t.Name.UpperCamel }}(ctx context.Context, db domain.DB, s *seeded, n int) error {
+	for i := 0; i < n; i++ {
+		var o *{{ $t.Name.Lower }}.{{ $t.Name.UpperCamel }}
+		for try := 0; ; try++ {
+			if try == maxTries {
+				return nil
+			}
+			o = {{ $t.Name.Lower }}.Random()
+			{{- range $t.IDs }}
+			o.{{ .Name.UpperCamel }} = newID()
+			{{- end }}
+			{{- range $t.Parents }}
+			if len(s.{{ .Table }}) > 0 {
+				parent := s.{{ .Table }}[pick(len(s.{{ .Table }}))]
+				{{ .Assign "parent" }}
+			} else {
+				{{- if .Column.Nullable }}
+				o.{{ .Column.Name.UpperCamel }} = nil
+				{{- else if .Self }}
+				{{ .Assign "o" }}
+				{{- else }}
+				return noParent("{{ $t.Name.UpperCamel }}.{{ .Column.Name.UpperCamel }}", "{{ .Table }}")
+				{{- end }}
+			}
+			{{- end }}
+			if s.claim(
+				{{- range $i, $k := $t.Keys }}
+				key("{{ $t.Name.UpperCamel }}.{{ $i }}"{{ range $k }}, o.{{ .Name.UpperCamel }}{{ end }}),
+				{{- end }}
+			) {
+				break
+			}
+		}
+		if err := o.Insert(ctx, db); err != nil {
+			return err
+		}
+		s.{{ $t.Name.UpperCamel }} = append(s.{{ $t.Name.UpperCamel }}, o)
+	}
+	return nil
+}
+{{ end }}
+`,
+}
@@ -0,0 +1,73 @@
+// Package seed generates the tables of the seed command, which fills a
+// database with random objects whose foreign keys reference rows it
+// inserted before them
+package seed
+
+import (
+	"reflect"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+)
+
+// BasePath is where the seed command is generated, relative to the
+// repository root
+var BasePath = "seed"
+
+// Table is an object the seed command inserts rows of
+type Table struct {
+	domain.Object
+	IDs     []domain.Parameter   // IDs are drawn from the seeded source instead of entity.UUID()
+	Parents []Parent             // Parents are the foreign keys, wired to inserted rows
+	Keys    [][]domain.Parameter // Keys are the primary key and unique indexes, distinct in every row
+}
+
+// Parent is a foreign key the seed command points at a row it inserted
+type Parent struct {
+	domain.Relation
+	Self bool // Self is set when the object references itself
+}
+
+// Assign returns the statement setting the foreign key of o to the
+// referenced column of parent
+func (p Parent) Assign(parent string) string {
+	column := "o." + p.Column.Name.UpperCamel
+	ref := parent + "." + p.Reference.Name.UpperCamel
+	columnPtr := p.Column.Type.Kind() == reflect.Ptr
+	refPtr := p.Reference.Type.Kind() == reflect.Ptr
+	switch {
+	case columnPtr == refPtr:
+		return column + " = " + ref
+	case columnPtr:
+		return "v := " + ref + "\n" + column + " = &v"
+	}
+	return "if " + ref + " != nil {\n" + column + " = *" + ref + "\n}"
+}
+
+// Tables returns a Table for each object, in the order given, which must
+// put referenced objects first
+func Tables(objects []domain.Object) []Table {
+	tables := []Table{}
+	for _, o := range objects {
+		t := Table{Object: o}
+		for _, p := range o.Parameters {
+			drawn := p.ConstructorOverride
+			if drawn == "" {
+				drawn = p.Random
+			}
+			if drawn == "entity.UUID()" && p.ForeignKey == nil {
+				t.IDs = append(t.IDs, p)
+			}
+		}
+		for _, r := range o.Relations() {
+			t.Parents = append(t.Parents, Parent{Relation: r, Self: r.Package == ""})
+		}
+		if keys := o.PrimaryKeys(); len(keys) > 0 {
+			t.Keys = append(t.Keys, keys)
+		}
+		for _, u := range o.UniqueLookups() {
+			t.Keys = append(t.Keys, u.Params)
+		}
+		tables = append(tables, t)
+	}
+	return tables
+}
@@ -0,0 +1,185 @@
+package typescript
+
+var Plate = map[string]string{
+	"Client": `// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+
+/** A 16 byte ID written as 32 hexadecimal characters */
+export type HexID = string;
+/** An RFC 3339 date and time, ex. 2017-06-01T12:00:00Z */
+export type Timestamp = string;
+/** A fixed point number, kept as a string so it doesn't lose precision */
+export type Decimal = string;
+/** Bytes written as standard base64 */
+export type Base64 = string;
+{{- range $o := . }}
+
+/** {{ $o.Description }} */
+export interface {{ $o.Name.UpperCamel }} {
+{{- range $p := $o.Parameters }}
+  {{ $p.Name.UpperCamel }}: {{ tstype $p }};
+{{- end }}
+}
+{{- if $o.HasOp "create" }}
+
+/** The arguments creating a {{ $o.Name.UpperCamel }} */
+export interface {{ $o.Name.UpperCamel }}Create {
+{{- range $p := $o.InputParameters }}
+  {{ $p.Name.UpperCamel }}{{ if optional $p }}?{{ end }}: {{ tstype $p }};
+{{- end }}
+}
+{{- end }}
+{{- if $o.HasOp "update" }}
+
+/** The {{ $o.Name.UpperCamel }} to update and every one of its new values */
+export interface {{ $o.Name.UpperCamel }}Update {
+{{- range $p := $o.UpdateInputParameters }}
+  {{ $p.Name.UpperCamel }}: {{ tstype $p }};
+{{- end }}
+}
+
+/** A JSON merge patch of the {{ $o.Name.UpperCamel }} it names, null clearing a nullable field */
+export type {{ $o.Name.UpperCamel }}Patch = Pick<{{ $o.Name.UpperCamel }}Update, {{ range $i, $p := $o.PrimaryKeys }}{{ if $i }} | {{ end }}"{{ $p.Name.UpperCamel }}"{{ end }}> & Partial<{{ $o.Name.UpperCamel }}Update>;
+{{- end }}
+{{- end }}
+
+/** A page of a list, the server defaulting to 50 rows and allowing 500 */
+export interface Page {
+  limit?: number;
+  offset?: number;
+}
+
+export interface ClientOptions {
+  /** Prefixes every route, ex. https://valet.ottoq.com */
+  baseURL?: string;
+  /**
+   * Whether the browser sends the session cookie, "include" by default so
+   * it's sent to an API on another origin too
+   */
+  credentials?: RequestCredentials;
+  /** Added to every request */
+  headers?: Record<string, string>;
+  /** Replaces the global fetch, ex. in tests */
+  fetch?: typeof fetch;
+}
+
+/** A response with an error status, message being the body the server wrote */
+export class APIError extends Error {
+  constructor(public readonly status: number, message: string) {
+    super(message);
+    this.name = "APIError";
+  }
+}
+
+type Query = Record<string, string | number | undefined>;
+
+/**
+ * Client calls the generated REST endpoints.
+ *
+ * The server keeps the session in an encrypted HttpOnly cookie that scripts
+ * can't read, so the client leaves it to the browser by sending
+ * credentials. Every response carries the CSRF token of the session in the
+ * X-CSRF-Token header, which the client sends back with each write as the
+ * server refuses writes without it. A write made before any response
+ * brought the token is refused, bringing it, and sent again once. Bodies
+ * are sent as JSON, the only ones the server accepts.
+ */
+export class Client {
+  private readonly baseURL: string;
+  private readonly credentials: RequestCredentials;
+  private readonly headers: Record<string, string>;
+  private readonly fetcher: typeof fetch;
+  private csrfToken = "";
+
+  constructor(options: ClientOptions = {}) {
+    this.baseURL = (options.baseURL ?? "").replace(/\/+$/, "");
+    this.credentials = options.credentials ?? "include";
+    this.headers = options.headers ?? {};
+    this.fetcher = options.fetch ?? fetch.bind(globalThis);
+  }
+
+  private async request<T>(method: string, route: string, query: Query = {}, body?: unknown): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined) {
+        params.set(key, String(value));
+      }
+    }
+    const search = params.toString();
+    const send = async (): Promise<Response> => {
+      const headers: Record<string, string> = { Accept: "application/json", ...this.headers };
+      if (body !== undefined) {
+        headers["Content-Type"] = method === "PATCH" ? "application/merge-patch+json" : "application/json";
+      }
+      if (method !== "GET" && this.csrfToken) {
+        headers["X-CSRF-Token"] = this.csrfToken;
+      }
+      const res = await this.fetcher(this.baseURL + route + (search ? "?" + search : ""), {
+        method,
+        headers,
+        credentials: this.credentials,
+        body: body === undefined ? undefined : JSON.stringify(body),
+      });
+      this.csrfToken = res.headers.get("X-CSRF-Token") ?? this.csrfToken;
+      return res;
+    };
+    const sent = this.csrfToken;
+    let res = await send();
+    if (res.status === 403 && method !== "GET" && this.csrfToken !== sent) {
+      // sent without the token of the session, which the response brought
+      res = await send();
+    }
+    const text = await res.text();
+    if (!res.ok) {
+      throw new APIError(res.status, text.trim() || res.statusText);
+    }
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+{{- range $o := . }}
+{{- $name := $o.Name.UpperCamel }}
+{{- $route := printf "\"/%s\"" $o.Name.Lower }}
+{{- if $o.HasOp "create" }}
+
+  /** Creates a {{ $name }} */
+  create{{ $name }}(input: {{ $name }}Create): Promise<{{ $name }}> {
+    return this.request("POST", {{ $route }}, {}, input);
+  }
+{{- end }}
+{{- if $o.HasOp "read" }}
+
+  /** Reads a {{ $name }}, failing with a 404 APIError if it doesn't exist */
+  read{{ $name }}({{ range $i, $p := $o.PrimaryKeys }}{{ if $i }}, {{ end }}{{ $p.Name.LowerCamel }}: {{ tstype $p }}{{ end }}): Promise<{{ $name }}> {
+    return this.request("GET", {{ $route }}, { {{ range $i, $p := $o.PrimaryKeys }}{{ if $i }}, {{ end }}{{ $p.Name.LowerCamel }}{{ end }} });
+  }
+{{- end }}
+{{- if $o.HasOp "update" }}
+
+  /** Updates a {{ $name }}, failing with a 404 APIError if it doesn't exist
+  {{- if $o.Versioned }} and a 409 if its Version is stale{{ end }} */
+  update{{ $name }}(input: {{ $name }}Update): Promise<{{ $name }}> {
+    return this.request("PUT", {{ $route }}, {}, input);
+  }
+
+  /** Applies a merge patch to a {{ $name }}, only changing the fields it holds */
+  patch{{ $name }}(input: {{ $name }}Patch): Promise<{{ $name }}> {
+    return this.request("PATCH", {{ $route }}, {}, input);
+  }
+{{- end }}
+{{- if $o.HasOp "delete" }}
+
+  /** Deletes a {{ $name }}, failing with a 404 APIError if it doesn't exist */
+  delete{{ $name }}({{ range $i, $p := $o.PrimaryKeys }}{{ if $i }}, {{ end }}{{ $p.Name.LowerCamel }}: {{ tstype $p }}{{ end }}): Promise<void> {
+    return this.request("DELETE", {{ $route }}, { {{ range $i, $p := $o.PrimaryKeys }}{{ if $i }}, {{ end }}{{ $p.Name.LowerCamel }}{{ end }} });
+  }
+{{- end }}
+{{- if $o.HasOp "list" }}
+
+  /** Lists a page of {{ $name }}s */
+  list{{ $name }}s(page: Page = {}): Promise<{{ $name }}[]> {
+    return this.request("GET", {{ $route }}, { limit: page.limit, offset: page.offset });
+  }
+{{- end }}
+{{- end }}
+}
+`,
+}
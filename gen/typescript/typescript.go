@@ -0,0 +1,58 @@
+// Package typescript maps the domain model to TypeScript for the
+// generated front end types and client
+package typescript
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"git.ottoq.com/otto-backend/valet/gen/domain"
+)
+
+// BasePath is where the TypeScript client is generated, relative to the
+// repository root
+var BasePath = "client"
+
+// FileName is the generated module holding the types and the client
+const FileName = "valet_gen.ts"
+
+// Type returns the TypeScript type of a parameter's JSON value
+func Type(p domain.Parameter) string {
+	t := "number"
+	switch {
+	case p.Hex():
+		t = "HexID"
+	case p.IsTime():
+		t = "Timestamp"
+	case len(p.OneOf) > 0:
+		values := make([]string, len(p.OneOf))
+		for i, v := range p.OneOf {
+			values[i] = strconv.Quote(v)
+		}
+		t = strings.Join(values, " | ")
+	case p.ValueType() == "json.RawMessage":
+		t = "unknown"
+	case p.Kind() == reflect.Slice:
+		t = "Base64"
+	case strings.HasPrefix(p.SQLType, "DECIMAL"):
+		t = "Decimal"
+	case p.Kind() == reflect.String:
+		t = "string"
+	case p.Kind() == reflect.Bool:
+		t = "boolean"
+	}
+	if p.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+// Optional reports whether a parameter may be left out of a request body,
+// its zero value passing validation
+func Optional(p domain.Parameter) bool {
+	if p.Required || p.PrimaryKey {
+		return false
+	}
+	return p.Nullable || (p.Pattern == "" && len(p.OneOf) == 0)
+}
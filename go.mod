@@ -0,0 +1,19 @@
+module git.ottoq.com/otto-backend/valet
+
+go 1.21
+
+// The MySQL driver is forked to scan ID columns as hex strings, and the
+// UUID package to generate IDs without dashes; both are vendored from
+// third_party.
+replace (
+	github.com/go-sql-driver/mysql => ./third_party/github.com/go-sql-driver/mysql
+	github.com/wardn/uuid => ./third_party/github.com/wardn/uuid
+)
+
+require (
+	github.com/go-sql-driver/mysql v1.3.0
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/wardn/uuid v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,861 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Execute runs the operation named operationName of query, which may be
+// left empty when the query holds a single operation. variables are
+// decoded JSON values.
+func (s *Schema) Execute(ctx context.Context, query, operationName string, variables map[string]interface{}) *Result {
+	doc, err := parse(query)
+	if err != nil {
+		e := err.(*SyntaxError)
+		return &Result{Errors: []*Error{{Message: "syntax error: " + e.Message, Locations: []Location{e.Location}}}}
+	}
+	op, qerr := doc.operation(operationName)
+	if qerr != nil {
+		return &Result{Errors: []*Error{qerr}}
+	}
+	if op.kind != "query" {
+		return &Result{Errors: []*Error{{
+			Message:   fmt.Sprintf("%s isn't supported, only queries are", op.kind),
+			Locations: []Location{op.loc},
+		}}}
+	}
+	e := &executor{schema: s, doc: doc}
+	if errs := e.coerceVariables(op, variables); len(errs) > 0 {
+		return &Result{Errors: errs}
+	}
+	if errs := e.validate(op); len(errs) > 0 {
+		return &Result{Errors: errs}
+	}
+	data, ok := e.selectFields(ctx, s.Query, []interface{}{nil}, op.set, []path{{}})
+	r := &Result{Errors: e.errs, executed: true}
+	if ok[0] {
+		r.Data = data[0]
+	}
+	return r
+}
+
+// operation returns the named operation, or the only one
+func (d *document) operation(name string) (*operation, *Error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, &Error{Message: "the query holds several operations, name the one to run"}
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+type executor struct {
+	schema   *Schema
+	doc      *document
+	vars     map[string]interface{} // vars are the parsed variables that were given or have a default
+	declared map[string]bool
+	errs     []*Error
+}
+
+// path is the location of a value in the response
+type path []interface{}
+
+func (p path) with(key interface{}) path {
+	q := make(path, len(p), len(p)+1)
+	copy(q, p)
+	return append(q, key)
+}
+
+////////////////////////////////////////////////////////////
+// VARIABLES AND ARGUMENTS
+////////////////////////////////////////////////////////////
+
+// enumLiteral is an enum value written in a query
+type enumLiteral string
+
+func (e *executor) coerceVariables(op *operation, given map[string]interface{}) []*Error {
+	e.vars = map[string]interface{}{}
+	e.declared = map[string]bool{}
+	errs := []*Error{}
+	fail := func(d *varDef, format string, args ...interface{}) {
+		errs = append(errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{d.loc}})
+	}
+	for _, d := range op.vars {
+		if e.declared[d.name] {
+			fail(d, "variable $%s is declared twice", d.name)
+			continue
+		}
+		e.declared[d.name] = true
+		t, err := e.inputType(d.typ)
+		if err != nil {
+			fail(d, "variable $%s: %s", d.name, err)
+			continue
+		}
+		raw, ok := given[d.name]
+		if !ok {
+			if d.def != nil {
+				v, _, err := e.literal(d.def)
+				if err == nil {
+					v, err = coerce(t, v)
+				}
+				if err != nil {
+					fail(d, "variable $%s has an invalid default: %s", d.name, err)
+					continue
+				}
+				e.vars[d.name] = v
+			} else if _, nonNull := t.(*NonNull); nonNull {
+				fail(d, "variable $%s of required type %s was not provided", d.name, t)
+			}
+			continue
+		}
+		v, err := coerce(t, raw)
+		if err != nil {
+			fail(d, "variable $%s got an invalid value: %s", d.name, err)
+			continue
+		}
+		e.vars[d.name] = v
+	}
+	return errs
+}
+
+// inputType returns the schema type of a variable
+func (e *executor) inputType(ref *typeRef) (Type, error) {
+	var t Type
+	if ref.list != nil {
+		of, err := e.inputType(ref.list)
+		if err != nil {
+			return nil, err
+		}
+		t = &List{Of: of}
+	} else {
+		named, ok := e.schema.Type(ref.name)
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s", ref.name)
+		}
+		if _, ok := named.(*Object); ok {
+			return nil, fmt.Errorf("type %s isn't an input type", ref.name)
+		}
+		t = named
+	}
+	if ref.nonNull {
+		t = &NonNull{Of: t}
+	}
+	return t, nil
+}
+
+// arguments parses the arguments of a field, filling in defaults
+func (e *executor) arguments(defs map[string]*Argument, args []*argument) (map[string]interface{}, *Error) {
+	values := map[string]interface{}{}
+	seen := map[string]bool{}
+	for _, a := range args {
+		d, ok := defs[a.name]
+		if !ok {
+			return nil, &Error{Message: fmt.Sprintf("unknown argument %q", a.name), Locations: []Location{a.loc}}
+		}
+		if seen[a.name] {
+			return nil, &Error{Message: fmt.Sprintf("argument %q is given twice", a.name), Locations: []Location{a.loc}}
+		}
+		seen[a.name] = true
+		v, present, err := e.literal(a.value)
+		if err == nil && present {
+			values[a.name], err = coerce(d.Type, v)
+		}
+		if err != nil {
+			return nil, &Error{Message: fmt.Sprintf("argument %q: %s", a.name, err), Locations: []Location{a.loc}}
+		}
+	}
+	names := []string{}
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := defs[name]
+		if _, ok := values[name]; ok {
+			continue
+		}
+		if d.Default != nil {
+			values[name] = d.Default
+		} else if _, nonNull := d.Type.(*NonNull); nonNull {
+			return nil, &Error{Message: fmt.Sprintf("argument %q of type %s is required", name, d.Type)}
+		}
+	}
+	return values, nil
+}
+
+// literal returns the value written in a query, variables replaced by
+// their value. A variable that wasn't given and has no default isn't
+// present, and is left out of lists and objects.
+func (e *executor) literal(v *value) (interface{}, bool, error) {
+	switch v.kind {
+	case variableValue:
+		if !e.declared[v.raw] {
+			return nil, false, fmt.Errorf("variable $%s isn't declared", v.raw)
+		}
+		value, ok := e.vars[v.raw]
+		return value, ok, nil
+	case intValue:
+		n, err := strconv.ParseInt(v.raw, 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("integer %s is out of range", v.raw)
+		}
+		return n, true, nil
+	case floatValue:
+		f, err := strconv.ParseFloat(v.raw, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("number %s is out of range", v.raw)
+		}
+		return f, true, nil
+	case stringValue:
+		return v.raw, true, nil
+	case booleanValue:
+		return v.raw == "true", true, nil
+	case nullValue:
+		return nil, true, nil
+	case enumValue:
+		return enumLiteral(v.raw), true, nil
+	case listValue:
+		list := []interface{}{}
+		for _, item := range v.list {
+			value, ok, err := e.literal(item)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				list = append(list, value)
+			} else {
+				list = append(list, nil)
+			}
+		}
+		return list, true, nil
+	}
+	fields := map[string]interface{}{}
+	for _, f := range v.fields {
+		if _, ok := fields[f.name]; ok {
+			return nil, false, fmt.Errorf("field %q is given twice", f.name)
+		}
+		value, ok, err := e.literal(f.value)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			fields[f.name] = value
+		}
+	}
+	return fields, true, nil
+}
+
+// coerce parses v as a value of t
+func coerce(t Type, v interface{}) (interface{}, error) {
+	if nn, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected %s, found null", t)
+		}
+		return coerce(nn.Of, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := v.([]interface{})
+		if !ok {
+			// a single value is a list of one
+			items = []interface{}{v}
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			c, err := coerce(t.Of, item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %s", i, err)
+			}
+			list[i] = c
+		}
+		return list, nil
+	case *Input:
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected %s, found %s", t, describe(v))
+		}
+		for name := range fields {
+			if _, ok := t.Fields[name]; !ok {
+				return nil, fmt.Errorf("unknown field %q of %s", name, t)
+			}
+		}
+		m := map[string]interface{}{}
+		for name, f := range t.Fields {
+			value, ok := fields[name]
+			if !ok {
+				if f.Default != nil {
+					m[name] = f.Default
+				} else if _, nonNull := f.Type.(*NonNull); nonNull {
+					return nil, fmt.Errorf("field %q of %s is required", name, t)
+				}
+				continue
+			}
+			c, err := coerce(f.Type, value)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %s", name, err)
+			}
+			m[name] = c
+		}
+		return m, nil
+	case *Enum:
+		var s string
+		switch v := v.(type) {
+		case enumLiteral:
+			s = string(v)
+		case string:
+			s = v
+		}
+		for _, value := range t.Values {
+			if s == value {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("expected one of %s, found %s", joinValues(t.Values), describe(v))
+	case *Scalar:
+		return t.Parse(v)
+	}
+	return nil, fmt.Errorf("type %s can't be an argument", t)
+}
+
+func joinValues(values []string) string {
+	s := ""
+	for i, v := range values {
+		if i > 0 {
+			s += ", "
+		}
+		s += v
+	}
+	return s
+}
+
+////////////////////////////////////////////////////////////
+// VALIDATION
+////////////////////////////////////////////////////////////
+
+// validate checks every field of the operation exists and is given
+// valid arguments and a selection when it's an object, that the fields
+// sharing a response key can be merged and that the operation is within
+// MaxFragments, MaxDepth and MaxComplexity, before anything is resolved
+func (e *executor) validate(op *operation) []*Error {
+	errs := []*Error{}
+	fail := func(loc Location, format string, args ...interface{}) {
+		errs = append(errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+	}
+	if len(e.doc.fragments) > MaxFragments {
+		fail(op.loc, "the query defines more than the %d fragments allowed", MaxFragments)
+		return errs
+	}
+	var selections func(t *Object, set []selection, spreading map[string]bool, depth int) int
+	condition := func(t *Object, on string, loc Location) bool {
+		if on == "" || on == t.Name {
+			return true
+		}
+		if _, ok := e.schema.Type(on); !ok {
+			fail(loc, "unknown type %s", on)
+		} else {
+			fail(loc, "a fragment on %s can't be spread in %s", on, t.Name)
+		}
+		return false
+	}
+	directives := func(dirs []*directive) {
+		if _, err := e.include(dirs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	deep := false
+	// costs holds the complexity of each fragment by the depth it's
+	// spread at, so one spread many times is checked once
+	costs := map[string]map[int]int{}
+	// selections checks set, selected on t at depth, and returns its
+	// complexity, stopping past MaxComplexity
+	selections = func(t *Object, set []selection, spreading map[string]bool, depth int) int {
+		complexity := 0
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *field:
+				directives(sel.dirs)
+				complexity++
+				if sel.name == "__typename" {
+					if len(sel.args) > 0 || len(sel.set) > 0 {
+						fail(sel.loc, "__typename takes no arguments or selection")
+					}
+					continue
+				}
+				fd, ok := t.Fields[sel.name]
+				if !ok {
+					fail(sel.loc, "%s has no field %q", t.Name, sel.name)
+					continue
+				}
+				args, err := e.arguments(fd.Args, sel.args)
+				if err != nil {
+					if len(err.Locations) == 0 {
+						err.Locations = []Location{sel.loc}
+					}
+					err.Message = fmt.Sprintf("%s.%s: %s", t.Name, sel.name, err.Message)
+					errs = append(errs, err)
+				}
+				if obj, ok := named(fd.Type).(*Object); ok {
+					if len(sel.set) == 0 {
+						fail(sel.loc, "%s.%s of type %s needs a selection of its fields", t.Name, sel.name, fd.Type)
+						continue
+					}
+					if depth == MaxDepth {
+						if !deep {
+							fail(sel.loc, "fields are nested more than %d deep", MaxDepth)
+							deep = true
+						}
+						continue
+					}
+					// a list selects its fields once per item, and at
+					// least once as they're checked all the same
+					items := 1
+					if _, ok := fd.Args["limit"]; ok && err == nil {
+						if items, _, _ = bounds(args); items < 1 {
+							items = 1
+						}
+					}
+					complexity += items * selections(obj, sel.set, spreading, depth+1)
+				} else if len(sel.set) > 0 {
+					fail(sel.loc, "%s.%s of type %s has no fields to select", t.Name, sel.name, fd.Type)
+				}
+			case *spread:
+				directives(sel.dirs)
+				f, ok := e.doc.fragments[sel.name]
+				if !ok {
+					fail(sel.loc, "unknown fragment %s", sel.name)
+					continue
+				}
+				if spreading[sel.name] {
+					fail(sel.loc, "fragment %s spreads itself", sel.name)
+					continue
+				}
+				if !condition(t, f.on, sel.loc) {
+					continue
+				}
+				cost, ok := costs[sel.name][depth]
+				if !ok {
+					spreading[sel.name] = true
+					cost = selections(t, f.set, spreading, depth)
+					delete(spreading, sel.name)
+					if costs[sel.name] == nil {
+						costs[sel.name] = map[int]int{}
+					}
+					costs[sel.name][depth] = cost
+				}
+				complexity += cost
+			case *inline:
+				directives(sel.dirs)
+				if condition(t, sel.on, sel.loc) {
+					complexity += selections(t, sel.set, spreading, depth)
+				}
+			}
+			// past MaxComplexity, how far doesn't matter
+			if complexity > MaxComplexity {
+				return MaxComplexity + 1
+			}
+		}
+		return complexity
+	}
+	complexity := selections(e.schema.Query, op.set, map[string]bool{}, 1)
+	if len(errs) > 0 {
+		return errs
+	}
+	if complexity > MaxComplexity {
+		fail(op.loc, "the query selects more than the %d fields allowed, counting those of a list once per item", MaxComplexity)
+		return errs
+	}
+	return e.merge(e.schema.Query, op.set)
+}
+
+// merge checks the fields of set, selected on t, that share a response
+// key are the same field given the same arguments, so their values can
+// be merged, and so on down their merged selections. A fragment spread
+// twice in set is walked once, its fields merging with themselves.
+func (e *executor) merge(t *Object, set []selection) []*Error {
+	errs := []*Error{}
+	keys := []string{}
+	byKey := map[string][]*field{}
+	walked := map[string]bool{}
+	var walk func(set []selection)
+	walk = func(set []selection) {
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *field:
+				if _, ok := byKey[sel.key()]; !ok {
+					keys = append(keys, sel.key())
+				}
+				byKey[sel.key()] = append(byKey[sel.key()], sel)
+			case *spread:
+				if f := e.doc.fragments[sel.name]; f.on == t.Name && !walked[sel.name] {
+					walked[sel.name] = true
+					walk(f.set)
+				}
+			case *inline:
+				if sel.on == "" || sel.on == t.Name {
+					walk(sel.set)
+				}
+			}
+		}
+	}
+	walk(set)
+	for _, key := range keys {
+		fields := byKey[key]
+		first, merged := fields[0], true
+		for _, f := range fields[1:] {
+			reason := ""
+			switch {
+			case f.name != first.name:
+				reason = fmt.Sprintf("%s and %s are different fields", first.name, f.name)
+			case !sameArguments(first.args, f.args):
+				reason = fmt.Sprintf("%s is given different arguments", f.name)
+			default:
+				continue
+			}
+			errs = append(errs, &Error{
+				Message:   fmt.Sprintf("%s can't be selected twice as %s, alias one of them", key, reason),
+				Locations: []Location{first.loc, f.loc},
+			})
+			merged = false
+		}
+		fd, ok := t.Fields[first.name]
+		if !merged || !ok {
+			continue
+		}
+		if obj, ok := named(fd.Type).(*Object); ok {
+			sub := []selection{}
+			for _, f := range fields {
+				sub = append(sub, f.set...)
+			}
+			errs = append(errs, e.merge(obj, sub)...)
+		}
+	}
+	return errs
+}
+
+// sameArguments reports whether a and b give the same values to the same
+// arguments
+func sameArguments(a, b []*argument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		same := false
+		for _, y := range b {
+			if x.name == y.name {
+				same = sameValue(x.value, y.value)
+				break
+			}
+		}
+		if !same {
+			return false
+		}
+	}
+	return true
+}
+
+// sameValue reports whether a and b are written alike
+func sameValue(a, b *value) bool {
+	if a.kind != b.kind || a.raw != b.raw || len(a.list) != len(b.list) || len(a.fields) != len(b.fields) {
+		return false
+	}
+	for i := range a.list {
+		if !sameValue(a.list[i], b.list[i]) {
+			return false
+		}
+	}
+	for i := range a.fields {
+		if a.fields[i].name != b.fields[i].name || !sameValue(a.fields[i].value, b.fields[i].value) {
+			return false
+		}
+	}
+	return true
+}
+
+// named returns the type a list or non null wraps
+func named(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.Of
+		case *NonNull:
+			t = w.Of
+		default:
+			return t
+		}
+	}
+}
+
+// include reports whether the @skip and @include directives keep a
+// selection
+func (e *executor) include(dirs []*directive) (bool, *Error) {
+	keep := true
+	for _, d := range dirs {
+		if d.name != "skip" && d.name != "include" {
+			return false, &Error{Message: fmt.Sprintf("unknown directive @%s", d.name), Locations: []Location{d.loc}}
+		}
+		args, err := e.arguments(map[string]*Argument{"if": {Type: &NonNull{Of: Boolean}}}, d.args)
+		if err != nil {
+			err.Message = "@" + d.name + ": " + err.Message
+			if len(err.Locations) == 0 {
+				err.Locations = []Location{d.loc}
+			}
+			return false, err
+		}
+		if args["if"].(bool) == (d.name == "skip") {
+			keep = false
+		}
+	}
+	return keep, nil
+}
+
+////////////////////////////////////////////////////////////
+// EXECUTION
+////////////////////////////////////////////////////////////
+
+// group is the fields selected under one response key
+type group struct {
+	key    string
+	fields []*field
+}
+
+// collect returns the fields of set selected on t, merging those
+// sharing a response key
+func (e *executor) collect(t *Object, set []selection) []*group {
+	groups := []*group{}
+	byKey := map[string]*group{}
+	var walk func(set []selection)
+	walk = func(set []selection) {
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *field:
+				if keep, _ := e.include(sel.dirs); !keep {
+					continue
+				}
+				g, ok := byKey[sel.key()]
+				if !ok {
+					g = &group{key: sel.key()}
+					byKey[g.key] = g
+					groups = append(groups, g)
+				}
+				g.fields = append(g.fields, sel)
+			case *spread:
+				if keep, _ := e.include(sel.dirs); keep {
+					walk(e.doc.fragments[sel.name].set)
+				}
+			case *inline:
+				if keep, _ := e.include(sel.dirs); keep && (sel.on == "" || sel.on == t.Name) {
+					walk(sel.set)
+				}
+			}
+		}
+	}
+	walk(set)
+	return groups
+}
+
+// subselection merges the selections of the fields of a group
+func (g *group) subselection() []selection {
+	set := []selection{}
+	for _, f := range g.fields {
+		set = append(set, f.set...)
+	}
+	return set
+}
+
+func (e *executor) fail(message string, loc Location, p path) {
+	e.errs = append(e.errs, &Error{Message: message, Locations: []Location{loc}, Path: p})
+}
+
+// selectFields resolves set on each of sources, objects of type t. A
+// source whose non null field couldn't be resolved is nil and not ok.
+func (e *executor) selectFields(ctx context.Context, t *Object, sources []interface{}, set []selection, paths []path) ([]interface{}, []bool) {
+	results := make([]*orderedMap, len(sources))
+	ok := make([]bool, len(sources))
+	for i := range sources {
+		results[i] = &orderedMap{values: map[string]interface{}{}}
+		ok[i] = true
+	}
+	for _, g := range e.collect(t, set) {
+		f := g.fields[0]
+		if f.name == "__typename" {
+			for i := range sources {
+				results[i].set(g.key, t.Name)
+			}
+			continue
+		}
+		fd := t.Fields[f.name]
+		fieldPaths := make([]path, len(sources))
+		for i := range paths {
+			fieldPaths[i] = paths[i].with(g.key)
+		}
+		failed := make([]bool, len(sources))
+		var values []interface{}
+		args, aerr := e.arguments(fd.Args, f.args)
+		var err error
+		if aerr != nil {
+			err = aerr
+		} else {
+			values, err = fd.Resolve(ctx, sources, args)
+			if err == nil && len(values) != len(sources) {
+				err = fmt.Errorf("graphql: %s.%s resolved %d values for %d sources", t.Name, f.name, len(values), len(sources))
+			}
+		}
+		if err != nil {
+			values = make([]interface{}, len(sources))
+			for i := range failed {
+				failed[i] = true
+				e.fail(message(err), f.loc, fieldPaths[i])
+			}
+		}
+		completed, cok := e.complete(ctx, fd.Type, values, failed, g, fieldPaths)
+		for i := range sources {
+			results[i].set(g.key, completed[i])
+			if !cok[i] {
+				ok[i] = false
+			}
+		}
+	}
+	out := make([]interface{}, len(sources))
+	for i := range results {
+		if ok[i] {
+			out[i] = results[i]
+		}
+	}
+	return out, ok
+}
+
+// complete turns the resolved values of a field into response values. A
+// value is not ok when it's null but t isn't nullable, making the value
+// holding it null in turn.
+func (e *executor) complete(ctx context.Context, t Type, values []interface{}, failed []bool, g *group, paths []path) ([]interface{}, []bool) {
+	nn, nonNull := t.(*NonNull)
+	if nonNull {
+		t = nn.Of
+	}
+	out, ok := e.completeNullable(ctx, t, values, failed, g, paths)
+	for i := range out {
+		switch {
+		case nonNull && ok[i] && out[i] == nil:
+			e.fail(fmt.Sprintf("%s can't be null", g.key), g.fields[0].loc, paths[i])
+			ok[i] = false
+		case !nonNull && !ok[i]:
+			out[i], ok[i] = nil, true
+		}
+	}
+	return out, ok
+}
+
+func (e *executor) completeNullable(ctx context.Context, t Type, values []interface{}, failed []bool, g *group, paths []path) ([]interface{}, []bool) {
+	out := make([]interface{}, len(values))
+	ok := make([]bool, len(values))
+	// present indexes the values that aren't null
+	present := []int{}
+	for i, v := range values {
+		switch {
+		case failed != nil && failed[i]:
+		case isNull(v):
+			ok[i] = true
+		default:
+			ok[i] = true
+			present = append(present, i)
+		}
+	}
+	switch t := t.(type) {
+	case *Scalar:
+		for _, i := range present {
+			v, err := t.Serialize(deref(values[i]))
+			if err != nil {
+				e.fail(err.Error(), g.fields[0].loc, paths[i])
+				ok[i] = false
+				continue
+			}
+			out[i] = v
+		}
+	case *Enum:
+		for _, i := range present {
+			v, err := serializeString(deref(values[i]))
+			if err != nil {
+				e.fail(err.Error(), g.fields[0].loc, paths[i])
+				ok[i] = false
+				continue
+			}
+			out[i] = v
+		}
+	case *Object:
+		sources := make([]interface{}, len(present))
+		sourcePaths := make([]path, len(present))
+		for j, i := range present {
+			sources[j], sourcePaths[j] = values[i], paths[i]
+		}
+		results, rok := e.selectFields(ctx, t, sources, g.subselection(), sourcePaths)
+		for j, i := range present {
+			out[i], ok[i] = results[j], rok[j]
+		}
+	case *List:
+		// the items of every list are completed together, so each of
+		// their fields is resolved once
+		items := []interface{}{}
+		itemPaths := []path{}
+		owner := []int{}
+		for _, i := range present {
+			rv := reflect.ValueOf(deref(values[i]))
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				e.fail(fmt.Sprintf("%s resolved %T, not a list", g.key, values[i]), g.fields[0].loc, paths[i])
+				ok[i] = false
+				continue
+			}
+			for j := 0; j < rv.Len(); j++ {
+				items = append(items, rv.Index(j).Interface())
+				itemPaths = append(itemPaths, paths[i].with(j))
+				owner = append(owner, i)
+			}
+			out[i] = []interface{}{}
+		}
+		completed, iok := e.complete(ctx, t.Of, items, nil, g, itemPaths)
+		for j, i := range owner {
+			if !ok[i] {
+				continue
+			}
+			if !iok[j] {
+				out[i], ok[i] = nil, false
+				continue
+			}
+			out[i] = append(out[i].([]interface{}), completed[j])
+		}
+	}
+	return out, ok
+}
+
+// isNull reports whether v is nil or a nil pointer
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// deref returns what a pointer to a leaf value points to
+func deref(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Interface()
+}
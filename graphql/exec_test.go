@@ -0,0 +1,450 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testNode is a source of the Node type of testSchema
+type testNode struct {
+	ID       string
+	Name     string
+	Parent   *testNode
+	Children []*testNode
+}
+
+// testSchema returns a schema of two nodes, the second a child of the
+// first, along with fields that fail and one echoing its arguments
+func testSchema() *Schema {
+	one := &testNode{ID: "1", Name: "one"}
+	two := &testNode{ID: "2", Name: "two", Parent: one}
+	one.Children = []*testNode{two}
+	nodes := []*testNode{one, two}
+
+	nodeType := &Object{Name: "Node", Fields: map[string]*Field{
+		"id":     {Type: &NonNull{Of: ID}, Resolve: column("ID")},
+		"name":   {Type: String, Resolve: column("Name")},
+		"parent": {Resolve: column("Parent")},
+	}}
+	nodeType.Fields["parent"].Type = nodeType
+	nodePage := newPage(nodeType)
+	nodeArgs := listArgs(
+		&Input{Name: "NodeFilter", Fields: map[string]*Argument{"name": {Type: String}}},
+		newOrder("NodeOrder", &Enum{Name: "NodeField", Values: []string{"ID", "Name"}}),
+	)
+	page := func(list []*testNode, args map[string]interface{}) (interface{}, error) {
+		limit, offset, err := bounds(args)
+		if err != nil {
+			return nil, err
+		}
+		filter, _ := args["filter"].(map[string]interface{})
+		items := []*testNode{}
+		for _, n := range list {
+			if name, ok := filter["name"]; !ok || name == n.Name {
+				items = append(items, n)
+			}
+		}
+		total := len(items)
+		if offset > len(items) {
+			offset = len(items)
+		}
+		items = items[offset:]
+		if limit < len(items) {
+			items = items[:limit]
+		}
+		return &Page{Items: items, Total: total}, nil
+	}
+	nodeType.Fields["children"] = &Field{
+		Type: nodePage,
+		Args: nodeArgs,
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			return page(source.(*testNode).Children, args)
+		}),
+	}
+
+	echoInput := &Input{Name: "EchoInput", Fields: map[string]*Argument{
+		"at":    {Type: &NonNull{Of: Time}},
+		"order": {Type: order, Default: "ASC"},
+	}}
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"node": {
+			Type: nodeType,
+			Args: map[string]*Argument{"id": {Type: &NonNull{Of: ID}}},
+			Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				for _, n := range nodes {
+					if n.ID == args["id"] {
+						return n, nil
+					}
+				}
+				return nil, nil
+			}),
+		},
+		"nodes": {
+			Type: nodePage,
+			Args: nodeArgs,
+			Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				return page(nodes, args)
+			}),
+		},
+		"echo": {
+			Type: String,
+			Args: map[string]*Argument{
+				"text":  {Type: String},
+				"n":     {Type: Int, Default: int64(3)},
+				"tags":  {Type: &List{Of: &NonNull{Of: String}}},
+				"input": {Type: echoInput},
+			},
+			Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				b, err := json.Marshal(args)
+				return string(b), err
+			}),
+		},
+		"fail": {
+			Type: String,
+			Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				return nil, Errorf("no luck")
+			}),
+		},
+		"crash": {
+			Type: String,
+			Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				return nil, errors.New("the database password is hunter2")
+			}),
+		},
+		"required": {
+			Type: &NonNull{Of: String},
+			Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				return nil, nil
+			}),
+		},
+	}}
+	return New(query, "")
+}
+
+// execute runs query on testSchema, returning the response as JSON
+func execute(t *testing.T, query, operationName string, variables map[string]interface{}) string {
+	b, err := json.Marshal(testSchema().Execute(context.Background(), query, operationName, variables))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]interface{}
+		want      string
+	}{
+		{
+			name:  "fields",
+			query: `{ node(id: "2") { id name parent { id } } }`,
+			want:  `{"data":{"node":{"id":"2","name":"two","parent":{"id":"1"}}}}`,
+		},
+		{
+			name:  "null object",
+			query: `{ node(id: "3") { id } one: node(id: "1") { parent { id } } }`,
+			want:  `{"data":{"node":null,"one":{"parent":null}}}`,
+		},
+		{
+			name:  "aliases",
+			query: `{ a: node(id: "1") { key: id } b: node(id: "2") { key: id name: id } }`,
+			want:  `{"data":{"a":{"key":"1"},"b":{"key":"2","name":"2"}}}`,
+		},
+		{
+			name:  "merged fields",
+			query: `{ node(id: "2") { id } node(id: "2") { name parent { id } parent { name } } }`,
+			want:  `{"data":{"node":{"id":"2","name":"two","parent":{"id":"1","name":"one"}}}}`,
+		},
+		{
+			name:  "typename",
+			query: `{ __typename node(id: "1") { __typename kind: __typename } }`,
+			want:  `{"data":{"__typename":"Query","node":{"__typename":"Node","kind":"Node"}}}`,
+		},
+		{
+			name:  "fragments",
+			query: `{ node(id: "2") { ...named parent { ...named } } } fragment named on Node { id ... on Node { name } }`,
+			want:  `{"data":{"node":{"id":"2","name":"two","parent":{"id":"1","name":"one"}}}}`,
+		},
+		{
+			name:  "inline fragment without a type",
+			query: `{ node(id: "1") { ... { id } } }`,
+			want:  `{"data":{"node":{"id":"1"}}}`,
+		},
+		{
+			name:  "lists",
+			query: `{ nodes(limit: 1, offset: 1) { total items { id children { total items { id } } } } }`,
+			want:  `{"data":{"nodes":{"total":2,"items":[{"id":"2","children":{"total":0,"items":[]}}]}}}`,
+		},
+		{
+			name:  "list filter",
+			query: `{ nodes(filter: {name: "one"}) { items { children { items { name } } } } }`,
+			want:  `{"data":{"nodes":{"items":[{"children":{"items":[{"name":"two"}]}}]}}}`,
+		},
+		{
+			name:      "directives",
+			query:     `query ($yes: Boolean!) { node(id: "1") { id @skip(if: $yes) name @include(if: $yes) parent @include(if: false) { id } } }`,
+			variables: map[string]interface{}{"yes": true},
+			want:      `{"data":{"node":{"name":"one"}}}`,
+		},
+		{
+			name:  "argument defaults",
+			query: `{ echo }`,
+			want:  `{"data":{"echo":"{\"n\":3}"}}`,
+		},
+		{
+			name:  "argument literals",
+			query: `{ echo(text: """block "quoted" text""", n: -2, tags: "one", input: {at: "2020-01-02T03:04:05Z", order: DESC}) }`,
+			want:  `{"data":{"echo":"{\"input\":{\"at\":\"2020-01-02T03:04:05Z\",\"order\":\"DESC\"},\"n\":-2,\"tags\":[\"one\"],\"text\":\"block \\\"quoted\\\" text\"}"}}`,
+		},
+		{
+			name:      "variables",
+			query:     `query Echo($text: String, $n: Int = 7, $tags: [String!], $missing: Int) { echo(text: $text, n: $n, tags: $tags) other: echo(n: $missing) }`,
+			variables: map[string]interface{}{"text": "hi", "tags": []interface{}{"a", "b"}},
+			want:      `{"data":{"echo":"{\"n\":7,\"tags\":[\"a\",\"b\"],\"text\":\"hi\"}","other":"{\"n\":3}"}}`,
+		},
+		{
+			name:      "variables inside lists and objects",
+			query:     `query ($tag: String!, $at: Time!) { echo(tags: ["a", $tag], input: {at: $at}) }`,
+			variables: map[string]interface{}{"tag": "b", "at": "2020-01-02T03:04:05Z"},
+			want:      `{"data":{"echo":"{\"input\":{\"at\":\"2020-01-02T03:04:05Z\",\"order\":\"ASC\"},\"n\":3,\"tags\":[\"a\",\"b\"]}"}}`,
+		},
+		{
+			name:      "operation name",
+			query:     `query A { node(id: "1") { id } } query B { node(id: "2") { id } }`,
+			operation: "B",
+			want:      `{"data":{"node":{"id":"2"}}}`,
+		},
+
+		// errors resolving a field
+		{
+			name:  "resolver error",
+			query: `{ fail node(id: "1") { id } }`,
+			want:  `{"data":{"fail":null,"node":{"id":"1"}},"errors":[{"message":"no luck","locations":[{"line":1,"column":3}],"path":["fail"]}]}`,
+		},
+		{
+			name:  "internal error",
+			query: `{ crash }`,
+			want:  `{"data":{"crash":null},"errors":[{"message":"Internal Server Error","locations":[{"line":1,"column":3}],"path":["crash"]}]}`,
+		},
+		{
+			name:  "null non null field",
+			query: `{ node(id: "1") { id } required }`,
+			want:  `{"data":null,"errors":[{"message":"required can't be null","locations":[{"line":1,"column":24}],"path":["required"]}]}`,
+		},
+		{
+			name:  "list argument error",
+			query: `{ nodes(limit: -1) { total } }`,
+			want:  `{"data":{"nodes":null},"errors":[{"message":"limit and offset can't be negative","locations":[{"line":1,"column":3}],"path":["nodes"]}]}`,
+		},
+
+		// queries that aren't run
+		{
+			name:  "syntax error",
+			query: `{ node(id: "1") { id }`,
+			want:  `{"errors":[{"message":"syntax error: expected a name, found end of document","locations":[{"line":1,"column":23}]}]}`,
+		},
+		{
+			name:  "mutation",
+			query: `mutation { node(id: "1") { id } }`,
+			want:  `{"errors":[{"message":"mutation isn't supported, only queries are","locations":[{"line":1,"column":1}]}]}`,
+		},
+		{
+			name:  "several operations",
+			query: `query A { __typename } query B { __typename }`,
+			want:  `{"errors":[{"message":"the query holds several operations, name the one to run"}]}`,
+		},
+		{
+			name:      "unknown operation",
+			query:     `query A { __typename }`,
+			operation: "B",
+			want:      `{"errors":[{"message":"unknown operation \"B\""}]}`,
+		},
+		{
+			name:  "missing variable",
+			query: `query ($id: ID!) { node(id: $id) { id } }`,
+			want:  `{"errors":[{"message":"variable $id of required type ID! was not provided","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:      "invalid variable",
+			query:     `query ($n: Int) { echo(n: $n) }`,
+			variables: map[string]interface{}{"n": "three"},
+			want:      `{"errors":[{"message":"variable $n got an invalid value: expected an integer, found \"three\"","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:  "variable of an object type",
+			query: `query ($n: Node) { echo }`,
+			want:  `{"errors":[{"message":"variable $n: type Node isn't an input type","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:  "undeclared variable",
+			query: `{ echo(n: $n) }`,
+			want:  `{"errors":[{"message":"Query.echo: argument \"n\": variable $n isn't declared","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:  "unknown field",
+			query: `{ node(id: "1") { id colour } }`,
+			want:  `{"errors":[{"message":"Node has no field \"colour\"","locations":[{"line":1,"column":22}]}]}`,
+		},
+		{
+			name:  "unknown argument",
+			query: `{ node(id: "1", name: "one") { id } }`,
+			want:  `{"errors":[{"message":"Query.node: unknown argument \"name\"","locations":[{"line":1,"column":17}]}]}`,
+		},
+		{
+			name:  "missing argument",
+			query: `{ node { id } }`,
+			want:  `{"errors":[{"message":"Query.node: argument \"id\" of type ID! is required","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:  "invalid argument",
+			query: `{ echo(n: "three") }`,
+			want:  `{"errors":[{"message":"Query.echo: argument \"n\": expected an integer, found \"three\"","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:  "invalid enum",
+			query: `{ nodes(orderBy: {field: Colour}) { total } }`,
+			want:  `{"errors":[{"message":"Query.nodes: argument \"orderBy\": item 0: field \"field\": expected one of ID, Name, found Colour","locations":[{"line":1,"column":9}]}]}`,
+		},
+		{
+			name:  "missing selection",
+			query: `{ node(id: "1") }`,
+			want:  `{"errors":[{"message":"Query.node of type Node needs a selection of its fields","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:  "selection of a leaf",
+			query: `{ node(id: "1") { id { name } } }`,
+			want:  `{"errors":[{"message":"Node.id of type ID! has no fields to select","locations":[{"line":1,"column":19}]}]}`,
+		},
+		{
+			name:  "unknown fragment",
+			query: `{ ...missing }`,
+			want:  `{"errors":[{"message":"unknown fragment missing","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:  "fragment cycle",
+			query: `{ node(id: "1") { ...a } } fragment a on Node { parent { ...a } }`,
+			want:  `{"errors":[{"message":"fragment a spreads itself","locations":[{"line":1,"column":58}]}]}`,
+		},
+		{
+			name:  "fragment on another type",
+			query: `{ ...named } fragment named on Node { id }`,
+			want:  `{"errors":[{"message":"a fragment on Node can't be spread in Query","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:  "unknown directive",
+			query: `{ echo @deprecated }`,
+			want:  `{"errors":[{"message":"unknown directive @deprecated","locations":[{"line":1,"column":8}]}]}`,
+		},
+
+		// fields sharing a response key must merge
+		{
+			name:  "different fields under one key",
+			query: `{ a: node(id: "1") { id } a: nodes { total } }`,
+			want:  `{"errors":[{"message":"a can't be selected twice as node and nodes are different fields, alias one of them","locations":[{"line":1,"column":3},{"line":1,"column":27}]}]}`,
+		},
+		{
+			name:  "different arguments under one key",
+			query: `{ node(id: "1") { id } node(id: "2") { id } }`,
+			want:  `{"errors":[{"message":"node can't be selected twice as node is given different arguments, alias one of them","locations":[{"line":1,"column":3},{"line":1,"column":24}]}]}`,
+		},
+		{
+			name:  "conflict inside fragments",
+			query: `{ node(id: "1") { ...a ...b } } fragment a on Node { key: id } fragment b on Node { key: name }`,
+			want:  `{"errors":[{"message":"key can't be selected twice as id and name are different fields, alias one of them","locations":[{"line":1,"column":54},{"line":1,"column":85}]}]}`,
+		},
+		{
+			name:  "conflict in merged selections",
+			query: `{ node(id: "2") { parent { x: id } } node(id: "2") { parent { x: name } } }`,
+			want:  `{"errors":[{"message":"x can't be selected twice as id and name are different fields, alias one of them","locations":[{"line":1,"column":28},{"line":1,"column":63}]}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := execute(t, test.query, test.operation, test.variables); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExecuteLimits(t *testing.T) {
+	// nested is a query whose fields nest n deep
+	nested := func(n int) string {
+		return `{ node(id: "2") ` + strings.Repeat(`{ parent `, n-2) + `{ id }` + strings.Repeat(` }`, n-2) + ` }`
+	}
+	if got := execute(t, nested(MaxDepth), "", nil); strings.Contains(got, "errors") {
+		t.Errorf("a query %d deep failed: %s", MaxDepth, got)
+	}
+	// the parent at MaxDepth can't select fields
+	q := nested(MaxDepth + 1)
+	want := fmt.Sprintf(`{"errors":[{"message":"fields are nested more than %d deep","locations":[{"line":1,"column":%d}]}]}`,
+		MaxDepth, strings.LastIndex(q, "parent")+1)
+	if got := execute(t, q, "", nil); got != want {
+		t.Errorf("a query %d deep got\n%s\nwant\n%s", MaxDepth+1, got, want)
+	}
+
+	// lists multiply the fields selected under them by their limit
+	tests := []struct {
+		query string
+		ok    bool
+	}{
+		{`{ nodes(limit: 500) { items { children(limit: 98) { items { id } } } } }`, true},
+		{`{ nodes(limit: 500) { items { children(limit: 500) { items { id } } } } }`, false},
+		{`{ nodes(limit: 9999) { items { children { items { children { items { id } } } } } } }`, false},
+		{`query ($n: Int) { nodes(limit: $n) { items { children(limit: 500) { items { id } } } } }`, true},
+		{`{ nodes(limit: 0) { total items { children(limit: 100) { items { children(limit: 500) { total } } } } } }`, true},
+		// a list without items is still checked, as if it had one
+		{`{ nodes(limit: 0) { total items { children(limit: 500) { items { children(limit: 500) { total } } } } } }`, false},
+	}
+	for _, test := range tests {
+		got := execute(t, test.query, "", map[string]interface{}{"n": 10})
+		if ok := !strings.Contains(got, "errors"); ok != test.ok {
+			t.Errorf("%s got %s", test.query, got)
+		} else if !ok && !strings.Contains(got, fmt.Sprintf("more than the %d fields allowed", MaxComplexity)) {
+			t.Errorf("%s got %s", test.query, got)
+		}
+	}
+}
+
+// bomb is a query whose fragments each spread the next twice, selecting
+// 2^n fields in n+1 fragments, under a list of no items if empty
+func bomb(n int, empty bool) string {
+	q := `{ node(id: "1") { ...F0 } }`
+	if empty {
+		q = `{ node(id: "1") { children(limit: 0) { items { ...F0 } } } }`
+	}
+	for i := 0; i < n; i++ {
+		q += fmt.Sprintf(" fragment F%d on Node { id ...F%d ...F%d }", i, i+1, i+1)
+	}
+	return q + fmt.Sprintf(" fragment F%d on Node { id }", n)
+}
+
+func TestExecuteFragmentBombs(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{bomb(10, false), `{"data":{"node":{"id":"1"}}}`},
+		{bomb(60, false), fmt.Sprintf("more than the %d fields allowed", MaxComplexity)},
+		{bomb(60, true), fmt.Sprintf("more than the %d fields allowed", MaxComplexity)},
+		{bomb(MaxFragments, false), fmt.Sprintf("more than the %d fragments allowed", MaxFragments)},
+	}
+	for i, test := range tests {
+		start := time.Now()
+		got := execute(t, test.query, "", nil)
+		if !strings.Contains(got, test.want) {
+			t.Errorf("bomb %d got %.200s", i, got)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("bomb %d took %s", i, d)
+		}
+	}
+}
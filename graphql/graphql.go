@@ -0,0 +1,552 @@
+// Package graphql serves read queries over the domain objects. The
+// generator builds the schema, with a type per object, its foreign keys
+// as fields and filtered, paginated lists, and resolvers backed by the
+// domain packages. This package parses, validates and executes queries
+// against it.
+//
+// Every field resolves the values of all its sources at once, so a list
+// of desks loads the node of each with a single query.
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////
+// TYPES
+////////////////////////////////////////////////////////////
+
+// Type is the type of a field or argument
+type Type interface {
+	// String returns the type as written in a schema, ex. [Desk!]!
+	String() string
+}
+
+// Scalar is a leaf value
+type Scalar struct {
+	Name string
+	// Serialize returns the JSON value of a resolved Go value
+	Serialize func(v interface{}) (interface{}, error)
+	// Parse returns the Go value of an argument, given as a literal or a
+	// variable, or one already parsed
+	Parse func(v interface{}) (interface{}, error)
+}
+
+func (t *Scalar) String() string {
+	return t.Name
+}
+
+// Enum is a leaf value that's one of a set of names, resolved and parsed
+// as a string
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+func (t *Enum) String() string {
+	return t.Name
+}
+
+// Object is a type with fields
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+func (t *Object) String() string {
+	return t.Name
+}
+
+// Input is an argument with fields, parsed as a map keyed by field name.
+// Fields left out of the argument are left out of the map.
+type Input struct {
+	Name   string
+	Fields map[string]*Argument
+}
+
+func (t *Input) String() string {
+	return t.Name
+}
+
+// List is a list of Of
+type List struct {
+	Of Type
+}
+
+func (t *List) String() string {
+	return "[" + t.Of.String() + "]"
+}
+
+// NonNull is Of without null
+type NonNull struct {
+	Of Type
+}
+
+func (t *NonNull) String() string {
+	return t.Of.String() + "!"
+}
+
+// Field is a field of an Object
+type Field struct {
+	Type    Type
+	Args    map[string]*Argument
+	Resolve Resolver
+}
+
+// Argument is an argument of a field or a field of an Input
+type Argument struct {
+	Type Type
+	// Default is the parsed value of a left out argument, if not nil
+	Default interface{}
+}
+
+// Resolver returns the value of a field for each of sources, in order.
+// The sources are the values resolved for the object holding the field,
+// nil for the fields of the query root. args are the parsed arguments.
+type Resolver func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error)
+
+// Each returns a Resolver calling resolve for one source at a time
+func Each(resolve func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error)) Resolver {
+	return func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		values := make([]interface{}, len(sources))
+		for i, s := range sources {
+			v, err := resolve(ctx, s, args)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+}
+
+// column resolves the named field of each source, a pointer to a struct
+func column(name string) Resolver {
+	return Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return reflect.ValueOf(source).Elem().FieldByName(name).Interface(), nil
+	})
+}
+
+////////////////////////////////////////////////////////////
+// SCALARS
+////////////////////////////////////////////////////////////
+
+var (
+	// String is text. A []byte resolves to its base64.
+	String = &Scalar{Name: "String", Serialize: serializeString, Parse: parseString}
+	// ID is a key, resolved and parsed as a string
+	ID = &Scalar{Name: "ID", Serialize: serializeString, Parse: parseString}
+	// Int is an integer, parsed as an int64. Unlike the specification's it
+	// isn't limited to 32 bits.
+	Int = &Scalar{Name: "Int", Serialize: serializeInt, Parse: parseInt}
+	// Float is a number, parsed as a float64
+	Float = &Scalar{Name: "Float", Serialize: serializeFloat, Parse: parseFloat}
+	// Boolean is true or false
+	Boolean = &Scalar{Name: "Boolean", Serialize: serializeBoolean, Parse: parseBoolean}
+	// Time is a time.Time written in RFC 3339
+	Time = &Scalar{Name: "Time", Serialize: serializeTime, Parse: parseTime}
+	// JSON is any JSON value, resolved from and parsed as a json.RawMessage
+	JSON = &Scalar{Name: "JSON", Serialize: serializeJSON, Parse: parseJSON}
+)
+
+func serializeString(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Kind() == reflect.String:
+		return rv.String(), nil
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		return base64.StdEncoding.EncodeToString(rv.Bytes()), nil
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+	return nil, fmt.Errorf("can't resolve %T as a String", v)
+}
+
+func parseString(v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("expected a string, found %s", describe(v))
+}
+
+func serializeInt(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	}
+	return nil, fmt.Errorf("can't resolve %T as an Int", v)
+}
+
+func parseInt(v interface{}) (interface{}, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<63 {
+			return int64(n), nil
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+	}
+	return nil, fmt.Errorf("expected an integer, found %s", describe(v))
+}
+
+func serializeFloat(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("can't resolve %v as a Float", f)
+		}
+		return f, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	}
+	return nil, fmt.Errorf("can't resolve %T as a Float", v)
+}
+
+func parseFloat(v interface{}) (interface{}, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a number, found %s", describe(v))
+}
+
+func serializeBoolean(v interface{}) (interface{}, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Bool {
+		return rv.Bool(), nil
+	}
+	return nil, fmt.Errorf("can't resolve %T as a Boolean", v)
+}
+
+func parseBoolean(v interface{}) (interface{}, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("expected a boolean, found %s", describe(v))
+}
+
+func serializeTime(v interface{}) (interface{}, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return nil, fmt.Errorf("can't resolve %T as a Time", v)
+}
+
+func parseTime(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC 3339 time, found %q", t)
+		}
+		return parsed, nil
+	}
+	return nil, fmt.Errorf("expected an RFC 3339 time, found %s", describe(v))
+}
+
+func serializeJSON(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		if rv.Len() == 0 {
+			return nil, nil
+		}
+		return json.RawMessage(rv.Bytes()), nil
+	}
+	return v, nil
+}
+
+func parseJSON(v interface{}) (interface{}, error) {
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(b), nil
+}
+
+// describe names the kind of a parsed value for an error
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case enumLiteral:
+		return string(v)
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprint(v)
+}
+
+////////////////////////////////////////////////////////////
+// LISTS
+////////////////////////////////////////////////////////////
+
+// order is the direction of a sort
+var order = &Enum{Name: "Order", Values: []string{"ASC", "DESC"}}
+
+// Page is a page of a list and the length of the whole list
+type Page struct {
+	Items interface{}
+	Total int
+	// Count, when set, returns Total. It's only called when total is
+	// selected.
+	Count func(ctx context.Context) (int, error)
+}
+
+// newPage returns the type of a Page of items
+func newPage(item *Object) *Object {
+	return &Object{Name: item.Name + "Page", Fields: map[string]*Field{
+		"items": {
+			Type: &NonNull{Of: &List{Of: &NonNull{Of: item}}},
+			Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				return source.(*Page).Items, nil
+			}),
+		},
+		"total": {
+			Type: &NonNull{Of: Int},
+			Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				p := source.(*Page)
+				if p.Count != nil {
+					return p.Count(ctx)
+				}
+				return p.Total, nil
+			}),
+		},
+	}}
+}
+
+// newOrder returns the input sorting a list by one of fields
+func newOrder(name string, fields *Enum) *Input {
+	return &Input{Name: name, Fields: map[string]*Argument{
+		"field":     {Type: &NonNull{Of: fields}},
+		"direction": {Type: order, Default: "ASC"},
+	}}
+}
+
+// listArgs returns the arguments of a list: the filter, the orders
+// sorting it and the page
+func listArgs(filter, order *Input) map[string]*Argument {
+	return map[string]*Argument{
+		"filter":  {Type: filter},
+		"orderBy": {Type: &List{Of: &NonNull{Of: order}}},
+		"limit":   {Type: Int, Default: int64(DefaultLimit)},
+		"offset":  {Type: Int, Default: int64(0)},
+	}
+}
+
+// bounds returns the limit and offset arguments of a list, the limit
+// being at most MaxLimit
+func bounds(args map[string]interface{}) (int, int, error) {
+	limit, ok := args["limit"].(int64)
+	if !ok {
+		limit = DefaultLimit
+	}
+	offset, _ := args["offset"].(int64)
+	if limit < 0 || offset < 0 {
+		return 0, 0, Errorf("limit and offset can't be negative")
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	return int(limit), int(offset), nil
+}
+
+// partitioned is a row of a partitioned SELECT, which ends with the
+// partition after the columns NewFromRow reads. Scan reads it with key.
+type partitioned struct {
+	rows *sql.Rows
+	key  interface{}
+}
+
+// Scan implements the Scannable of the domain packages
+func (p partitioned) Scan(dest ...interface{}) error {
+	return p.rows.Scan(append(dest, p.key)...)
+}
+
+// partitionCounts counts the objects of each partition of a list once,
+// when the first of its pages needs its total
+type partitionCounts struct {
+	once   sync.Once
+	counts map[interface{}]int
+	err    error
+	// count runs the count of each partition
+	count func(ctx context.Context) (map[interface{}]int, error)
+}
+
+// of returns the Count of the Page of the partition key
+func (c *partitionCounts) of(key interface{}) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		c.once.Do(func() {
+			c.counts, c.err = c.count(ctx)
+		})
+		return c.counts[key], c.err
+	}
+}
+
+////////////////////////////////////////////////////////////
+// SCHEMA
+////////////////////////////////////////////////////////////
+
+// Schema is the types reachable from the query root
+type Schema struct {
+	Query *Object
+	// SDL is the schema in the GraphQL schema language
+	SDL   string
+	types map[string]Type
+}
+
+// New returns the schema of query, described by sdl
+func New(query *Object, sdl string) *Schema {
+	s := &Schema{Query: query, SDL: sdl, types: map[string]Type{}}
+	s.add(query)
+	for _, t := range []Type{String, ID, Int, Float, Boolean} {
+		s.add(t)
+	}
+	return s
+}
+
+// add records t and every type its fields and arguments use
+func (s *Schema) add(t Type) {
+	switch t := t.(type) {
+	case *List:
+		s.add(t.Of)
+		return
+	case *NonNull:
+		s.add(t.Of)
+		return
+	}
+	if _, ok := s.types[t.String()]; ok {
+		return
+	}
+	s.types[t.String()] = t
+	switch t := t.(type) {
+	case *Object:
+		for _, f := range t.Fields {
+			s.add(f.Type)
+			for _, a := range f.Args {
+				s.add(a.Type)
+			}
+		}
+	case *Input:
+		for _, f := range t.Fields {
+			s.add(f.Type)
+		}
+	}
+}
+
+// Type returns the named type
+func (s *Schema) Type(name string) (Type, bool) {
+	t, ok := s.types[name]
+	return t, ok
+}
+
+////////////////////////////////////////////////////////////
+// RESULT
+////////////////////////////////////////////////////////////
+
+// Result is the response to a query. Data is left out when the query
+// wasn't executed because it's invalid.
+type Result struct {
+	Data     interface{}
+	Errors   []*Error
+	executed bool
+}
+
+// MarshalJSON encodes the result as a GraphQL response
+func (r *Result) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	if r.executed {
+		m["data"] = r.Data
+	}
+	if len(r.Errors) > 0 {
+		m["errors"] = r.Errors
+	}
+	return json.Marshal(m)
+}
+
+// Error is a problem with a query, or with the field at Path
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+// Error returns the error string
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// Errorf returns an *Error, which a resolver returns to report its
+// message as is
+func Errorf(format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+// orderedMap is an object of the response, its keys in selection order
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, v interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+// MarshalJSON encodes the map keeping its order
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	b := []byte{'{'}
+	for i, k := range m.keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(append(b, key...), ':'), v...)
+	}
+	return append(b, '}'), nil
+}
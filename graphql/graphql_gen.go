@@ -0,0 +1,1420 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 27054c68435f867e
+
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/attendant"
+	"git.ottoq.com/otto-backend/valet/domain/desk"
+	"git.ottoq.com/otto-backend/valet/domain/enums"
+	"git.ottoq.com/otto-backend/valet/domain/node"
+	"git.ottoq.com/otto-backend/valet/handler"
+	"git.ottoq.com/otto-backend/valet/server"
+)
+
+// SDL is the schema NewSchema returns, in the GraphQL schema language
+const SDL = `"""An RFC 3339 date and time, ex. 2017-06-01T12:00:00Z"""
+scalar Time
+
+"""Any JSON value"""
+scalar JSON
+
+"""The direction of a sort"""
+enum Order {
+  ASC
+  DESC
+}
+
+enum DeskState {
+  open
+  closed
+  out_of_service
+}
+
+type Query {
+  """Reads the Node with the given key, null if there's none"""
+  node(id: ID!): Node
+  """Lists Nodes"""
+  nodes(filter: NodeFilter, orderBy: [NodeOrder!], limit: Int = 50, offset: Int = 0): NodePage
+  """Reads the Desk with the given key, null if there's none"""
+  desk(id: ID!): Desk
+  """Lists Desks"""
+  desks(filter: DeskFilter, orderBy: [DeskOrder!], limit: Int = 50, offset: Int = 0): DeskPage
+  """Reads the Attendant with the given key, null if there's none"""
+  attendant(id: ID!): Attendant
+  """Lists Attendants"""
+  attendants(filter: AttendantFilter, orderBy: [AttendantOrder!], limit: Int = 50, offset: Int = 0): AttendantPage
+}
+
+"""Node represents a node in the organization permission heirarchy tree"""
+type Node {
+  id: ID!
+  typeID: ID!
+  timestamp: Time!
+  name: String!
+  createdAt: Time!
+  updatedAt: Time!
+  deletedAt: Time
+  createdBy: String!
+  updatedBy: String!
+  """The Desks whose NodeID references this Node"""
+  desks(filter: DeskFilter, orderBy: [DeskOrder!], limit: Int = 50, offset: Int = 0): DeskPage
+}
+
+"""A page of a list of Nodes and the length of the whole list"""
+type NodePage {
+  items: [Node!]!
+  total: Int!
+}
+
+"""Matches the Nodes passing every filter given"""
+input NodeFilter {
+  id: ID
+  idIn: [ID!]
+  typeID: ID
+  typeIDIn: [ID!]
+  timestamp: Time
+  timestampIn: [Time!]
+  timestampAfter: Time
+  timestampBefore: Time
+  name: String
+  nameIn: [String!]
+  nameLike: String
+  createdAt: Time
+  createdAtIn: [Time!]
+  createdAtAfter: Time
+  createdAtBefore: Time
+  updatedAt: Time
+  updatedAtIn: [Time!]
+  updatedAtAfter: Time
+  updatedAtBefore: Time
+  deletedAt: Time
+  deletedAtIn: [Time!]
+  deletedAtAfter: Time
+  deletedAtBefore: Time
+  deletedAtIsNull: Boolean
+  createdBy: String
+  createdByIn: [String!]
+  createdByLike: String
+  updatedBy: String
+  updatedByIn: [String!]
+  updatedByLike: String
+}
+
+input NodeOrder {
+  field: NodeField!
+  direction: Order = ASC
+}
+
+enum NodeField {
+  ID
+  TypeID
+  Timestamp
+  Name
+  CreatedAt
+  UpdatedAt
+  DeletedAt
+  CreatedBy
+  UpdatedBy
+}
+
+"""Desk where car keys can be stored"""
+type Desk {
+  id: ID!
+  typeID: ID!
+  timestamp: Time!
+  name: String!
+  lat: Float!
+  lng: Float!
+  nodeID: ID!
+  state: DeskState!
+  version: Int!
+  createdAt: Time!
+  updatedAt: Time!
+  deletedAt: Time
+  createdBy: String!
+  updatedBy: String!
+  """The Node referenced by NodeID"""
+  node: Node
+  """The Attendants linked through DeskAttendant"""
+  attendants(filter: AttendantFilter, orderBy: [AttendantOrder!], limit: Int = 50, offset: Int = 0): AttendantPage
+}
+
+"""A page of a list of Desks and the length of the whole list"""
+type DeskPage {
+  items: [Desk!]!
+  total: Int!
+}
+
+"""Matches the Desks passing every filter given"""
+input DeskFilter {
+  id: ID
+  idIn: [ID!]
+  typeID: ID
+  typeIDIn: [ID!]
+  timestamp: Time
+  timestampIn: [Time!]
+  timestampAfter: Time
+  timestampBefore: Time
+  name: String
+  nameIn: [String!]
+  nameLike: String
+  lat: Float
+  latIn: [Float!]
+  latGreaterThan: Float
+  latLessThan: Float
+  lng: Float
+  lngIn: [Float!]
+  lngGreaterThan: Float
+  lngLessThan: Float
+  nodeID: ID
+  nodeIDIn: [ID!]
+  state: DeskState
+  stateIn: [DeskState!]
+  version: Int
+  versionIn: [Int!]
+  versionGreaterThan: Int
+  versionLessThan: Int
+  createdAt: Time
+  createdAtIn: [Time!]
+  createdAtAfter: Time
+  createdAtBefore: Time
+  updatedAt: Time
+  updatedAtIn: [Time!]
+  updatedAtAfter: Time
+  updatedAtBefore: Time
+  deletedAt: Time
+  deletedAtIn: [Time!]
+  deletedAtAfter: Time
+  deletedAtBefore: Time
+  deletedAtIsNull: Boolean
+  createdBy: String
+  createdByIn: [String!]
+  createdByLike: String
+  updatedBy: String
+  updatedByIn: [String!]
+  updatedByLike: String
+}
+
+input DeskOrder {
+  field: DeskField!
+  direction: Order = ASC
+}
+
+enum DeskField {
+  ID
+  TypeID
+  Timestamp
+  Name
+  Lat
+  Lng
+  NodeID
+  State
+  Version
+  CreatedAt
+  UpdatedAt
+  DeletedAt
+  CreatedBy
+  UpdatedBy
+}
+
+"""Attendant parks cars and hands out their keys"""
+type Attendant {
+  id: ID!
+  typeID: ID!
+  timestamp: Time!
+  name: String!
+  createdAt: Time!
+  updatedAt: Time!
+  deletedAt: Time
+  createdBy: String!
+  updatedBy: String!
+  """The Desks linked through DeskAttendant"""
+  desks(filter: DeskFilter, orderBy: [DeskOrder!], limit: Int = 50, offset: Int = 0): DeskPage
+}
+
+"""A page of a list of Attendants and the length of the whole list"""
+type AttendantPage {
+  items: [Attendant!]!
+  total: Int!
+}
+
+"""Matches the Attendants passing every filter given"""
+input AttendantFilter {
+  id: ID
+  idIn: [ID!]
+  typeID: ID
+  typeIDIn: [ID!]
+  timestamp: Time
+  timestampIn: [Time!]
+  timestampAfter: Time
+  timestampBefore: Time
+  name: String
+  nameIn: [String!]
+  nameLike: String
+  createdAt: Time
+  createdAtIn: [Time!]
+  createdAtAfter: Time
+  createdAtBefore: Time
+  updatedAt: Time
+  updatedAtIn: [Time!]
+  updatedAtAfter: Time
+  updatedAtBefore: Time
+  deletedAt: Time
+  deletedAtIn: [Time!]
+  deletedAtAfter: Time
+  deletedAtBefore: Time
+  deletedAtIsNull: Boolean
+  createdBy: String
+  createdByIn: [String!]
+  createdByLike: String
+  updatedBy: String
+  updatedByIn: [String!]
+  updatedByLike: String
+}
+
+input AttendantOrder {
+  field: AttendantField!
+  direction: Order = ASC
+}
+
+enum AttendantField {
+  ID
+  TypeID
+  Timestamp
+  Name
+  CreatedAt
+  UpdatedAt
+  DeletedAt
+  CreatedBy
+  UpdatedBy
+}
+`
+
+var enumDeskState = &Enum{Name: "DeskState", Values: []string{"open", "closed", "out_of_service"}}
+
+// Register serves NewSchema on Path, see Serve
+func Register(s *server.Server, db domain.DB, hooks handler.Hooks) error {
+	return Serve(s, NewSchema(db, hooks))
+}
+
+// NewSchema returns the schema of the objects listed or read by key over
+// REST, resolved from db. Every field calls the Authorize hook of the
+// objects it returns with the op of the REST endpoint it stands for,
+// "read" for a single object and "list" for a list. A field the hook
+// refuses is null, as is every field of an object without the hook.
+func NewSchema(db domain.DB, hooks handler.Hooks) *Schema {
+	nodeType := &Object{Name: "Node", Fields: map[string]*Field{
+		"id":        {Type: &NonNull{Of: ID}, Resolve: column("ID")},
+		"typeID":    {Type: &NonNull{Of: ID}, Resolve: column("TypeID")},
+		"timestamp": {Type: &NonNull{Of: Time}, Resolve: column("Timestamp")},
+		"name":      {Type: &NonNull{Of: String}, Resolve: column("Name")},
+		"createdAt": {Type: &NonNull{Of: Time}, Resolve: column("CreatedAt")},
+		"updatedAt": {Type: &NonNull{Of: Time}, Resolve: column("UpdatedAt")},
+		"deletedAt": {Type: Time, Resolve: column("DeletedAt")},
+		"createdBy": {Type: &NonNull{Of: String}, Resolve: column("CreatedBy")},
+		"updatedBy": {Type: &NonNull{Of: String}, Resolve: column("UpdatedBy")},
+	}}
+	deskType := &Object{Name: "Desk", Fields: map[string]*Field{
+		"id":        {Type: &NonNull{Of: ID}, Resolve: column("ID")},
+		"typeID":    {Type: &NonNull{Of: ID}, Resolve: column("TypeID")},
+		"timestamp": {Type: &NonNull{Of: Time}, Resolve: column("Timestamp")},
+		"name":      {Type: &NonNull{Of: String}, Resolve: column("Name")},
+		"lat":       {Type: &NonNull{Of: Float}, Resolve: column("Lat")},
+		"lng":       {Type: &NonNull{Of: Float}, Resolve: column("Lng")},
+		"nodeID":    {Type: &NonNull{Of: ID}, Resolve: column("NodeID")},
+		"state":     {Type: &NonNull{Of: enumDeskState}, Resolve: column("State")},
+		"version":   {Type: &NonNull{Of: Int}, Resolve: column("Version")},
+		"createdAt": {Type: &NonNull{Of: Time}, Resolve: column("CreatedAt")},
+		"updatedAt": {Type: &NonNull{Of: Time}, Resolve: column("UpdatedAt")},
+		"deletedAt": {Type: Time, Resolve: column("DeletedAt")},
+		"createdBy": {Type: &NonNull{Of: String}, Resolve: column("CreatedBy")},
+		"updatedBy": {Type: &NonNull{Of: String}, Resolve: column("UpdatedBy")},
+	}}
+	attendantType := &Object{Name: "Attendant", Fields: map[string]*Field{
+		"id":        {Type: &NonNull{Of: ID}, Resolve: column("ID")},
+		"typeID":    {Type: &NonNull{Of: ID}, Resolve: column("TypeID")},
+		"timestamp": {Type: &NonNull{Of: Time}, Resolve: column("Timestamp")},
+		"name":      {Type: &NonNull{Of: String}, Resolve: column("Name")},
+		"createdAt": {Type: &NonNull{Of: Time}, Resolve: column("CreatedAt")},
+		"updatedAt": {Type: &NonNull{Of: Time}, Resolve: column("UpdatedAt")},
+		"deletedAt": {Type: Time, Resolve: column("DeletedAt")},
+		"createdBy": {Type: &NonNull{Of: String}, Resolve: column("CreatedBy")},
+		"updatedBy": {Type: &NonNull{Of: String}, Resolve: column("UpdatedBy")},
+	}}
+	nodePage := newPage(nodeType)
+	nodeArgs := listArgs(
+		&Input{Name: "NodeFilter", Fields: map[string]*Argument{
+			"id":              {Type: ID},
+			"idIn":            {Type: &List{Of: &NonNull{Of: ID}}},
+			"typeID":          {Type: ID},
+			"typeIDIn":        {Type: &List{Of: &NonNull{Of: ID}}},
+			"timestamp":       {Type: Time},
+			"timestampIn":     {Type: &List{Of: &NonNull{Of: Time}}},
+			"timestampAfter":  {Type: Time},
+			"timestampBefore": {Type: Time},
+			"name":            {Type: String},
+			"nameIn":          {Type: &List{Of: &NonNull{Of: String}}},
+			"nameLike":        {Type: String},
+			"createdAt":       {Type: Time},
+			"createdAtIn":     {Type: &List{Of: &NonNull{Of: Time}}},
+			"createdAtAfter":  {Type: Time},
+			"createdAtBefore": {Type: Time},
+			"updatedAt":       {Type: Time},
+			"updatedAtIn":     {Type: &List{Of: &NonNull{Of: Time}}},
+			"updatedAtAfter":  {Type: Time},
+			"updatedAtBefore": {Type: Time},
+			"deletedAt":       {Type: Time},
+			"deletedAtIn":     {Type: &List{Of: &NonNull{Of: Time}}},
+			"deletedAtAfter":  {Type: Time},
+			"deletedAtBefore": {Type: Time},
+			"deletedAtIsNull": {Type: Boolean},
+			"createdBy":       {Type: String},
+			"createdByIn":     {Type: &List{Of: &NonNull{Of: String}}},
+			"createdByLike":   {Type: String},
+			"updatedBy":       {Type: String},
+			"updatedByIn":     {Type: &List{Of: &NonNull{Of: String}}},
+			"updatedByLike":   {Type: String},
+		}},
+		newOrder("NodeOrder", &Enum{Name: "NodeField", Values: []string{
+			"ID",
+			"TypeID",
+			"Timestamp",
+			"Name",
+			"CreatedAt",
+			"UpdatedAt",
+			"DeletedAt",
+			"CreatedBy",
+			"UpdatedBy",
+		}}),
+	)
+	deskPage := newPage(deskType)
+	deskArgs := listArgs(
+		&Input{Name: "DeskFilter", Fields: map[string]*Argument{
+			"id":                 {Type: ID},
+			"idIn":               {Type: &List{Of: &NonNull{Of: ID}}},
+			"typeID":             {Type: ID},
+			"typeIDIn":           {Type: &List{Of: &NonNull{Of: ID}}},
+			"timestamp":          {Type: Time},
+			"timestampIn":        {Type: &List{Of: &NonNull{Of: Time}}},
+			"timestampAfter":     {Type: Time},
+			"timestampBefore":    {Type: Time},
+			"name":               {Type: String},
+			"nameIn":             {Type: &List{Of: &NonNull{Of: String}}},
+			"nameLike":           {Type: String},
+			"lat":                {Type: Float},
+			"latIn":              {Type: &List{Of: &NonNull{Of: Float}}},
+			"latGreaterThan":     {Type: Float},
+			"latLessThan":        {Type: Float},
+			"lng":                {Type: Float},
+			"lngIn":              {Type: &List{Of: &NonNull{Of: Float}}},
+			"lngGreaterThan":     {Type: Float},
+			"lngLessThan":        {Type: Float},
+			"nodeID":             {Type: ID},
+			"nodeIDIn":           {Type: &List{Of: &NonNull{Of: ID}}},
+			"state":              {Type: enumDeskState},
+			"stateIn":            {Type: &List{Of: &NonNull{Of: enumDeskState}}},
+			"version":            {Type: Int},
+			"versionIn":          {Type: &List{Of: &NonNull{Of: Int}}},
+			"versionGreaterThan": {Type: Int},
+			"versionLessThan":    {Type: Int},
+			"createdAt":          {Type: Time},
+			"createdAtIn":        {Type: &List{Of: &NonNull{Of: Time}}},
+			"createdAtAfter":     {Type: Time},
+			"createdAtBefore":    {Type: Time},
+			"updatedAt":          {Type: Time},
+			"updatedAtIn":        {Type: &List{Of: &NonNull{Of: Time}}},
+			"updatedAtAfter":     {Type: Time},
+			"updatedAtBefore":    {Type: Time},
+			"deletedAt":          {Type: Time},
+			"deletedAtIn":        {Type: &List{Of: &NonNull{Of: Time}}},
+			"deletedAtAfter":     {Type: Time},
+			"deletedAtBefore":    {Type: Time},
+			"deletedAtIsNull":    {Type: Boolean},
+			"createdBy":          {Type: String},
+			"createdByIn":        {Type: &List{Of: &NonNull{Of: String}}},
+			"createdByLike":      {Type: String},
+			"updatedBy":          {Type: String},
+			"updatedByIn":        {Type: &List{Of: &NonNull{Of: String}}},
+			"updatedByLike":      {Type: String},
+		}},
+		newOrder("DeskOrder", &Enum{Name: "DeskField", Values: []string{
+			"ID",
+			"TypeID",
+			"Timestamp",
+			"Name",
+			"Lat",
+			"Lng",
+			"NodeID",
+			"State",
+			"Version",
+			"CreatedAt",
+			"UpdatedAt",
+			"DeletedAt",
+			"CreatedBy",
+			"UpdatedBy",
+		}}),
+	)
+	attendantPage := newPage(attendantType)
+	attendantArgs := listArgs(
+		&Input{Name: "AttendantFilter", Fields: map[string]*Argument{
+			"id":              {Type: ID},
+			"idIn":            {Type: &List{Of: &NonNull{Of: ID}}},
+			"typeID":          {Type: ID},
+			"typeIDIn":        {Type: &List{Of: &NonNull{Of: ID}}},
+			"timestamp":       {Type: Time},
+			"timestampIn":     {Type: &List{Of: &NonNull{Of: Time}}},
+			"timestampAfter":  {Type: Time},
+			"timestampBefore": {Type: Time},
+			"name":            {Type: String},
+			"nameIn":          {Type: &List{Of: &NonNull{Of: String}}},
+			"nameLike":        {Type: String},
+			"createdAt":       {Type: Time},
+			"createdAtIn":     {Type: &List{Of: &NonNull{Of: Time}}},
+			"createdAtAfter":  {Type: Time},
+			"createdAtBefore": {Type: Time},
+			"updatedAt":       {Type: Time},
+			"updatedAtIn":     {Type: &List{Of: &NonNull{Of: Time}}},
+			"updatedAtAfter":  {Type: Time},
+			"updatedAtBefore": {Type: Time},
+			"deletedAt":       {Type: Time},
+			"deletedAtIn":     {Type: &List{Of: &NonNull{Of: Time}}},
+			"deletedAtAfter":  {Type: Time},
+			"deletedAtBefore": {Type: Time},
+			"deletedAtIsNull": {Type: Boolean},
+			"createdBy":       {Type: String},
+			"createdByIn":     {Type: &List{Of: &NonNull{Of: String}}},
+			"createdByLike":   {Type: String},
+			"updatedBy":       {Type: String},
+			"updatedByIn":     {Type: &List{Of: &NonNull{Of: String}}},
+			"updatedByLike":   {Type: String},
+		}},
+		newOrder("AttendantOrder", &Enum{Name: "AttendantField", Values: []string{
+			"ID",
+			"TypeID",
+			"Timestamp",
+			"Name",
+			"CreatedAt",
+			"UpdatedAt",
+			"DeletedAt",
+			"CreatedBy",
+			"UpdatedBy",
+		}}),
+	)
+	query := &Object{Name: "Query", Fields: map[string]*Field{}}
+
+	///////////////////
+	// NODE
+	///////////////////
+
+	query.Fields["node"] = &Field{
+		Type: nodeType,
+		Args: map[string]*Argument{"id": {Type: &NonNull{Of: ID}}},
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := authorize(ctx, hooks.Node.Authorize, "read"); err != nil {
+				return nil, err
+			}
+			o, err := node.GetByID(ctx, db, args["id"].(string))
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+			return o, err
+		}),
+	}
+
+	query.Fields["nodes"] = &Field{
+		Type: nodePage,
+		Args: nodeArgs,
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := authorize(ctx, hooks.Node.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			b := queryNode(node.Query(), args)
+			items := []*node.Node{}
+			if limit > 0 {
+				if items, err = b.Limit(limit).Offset(offset).All(ctx, db); err != nil {
+					return nil, err
+				}
+			}
+			return &Page{Items: items, Count: func(ctx context.Context) (int, error) {
+				return b.Count(ctx, db)
+			}}, nil
+		}),
+	}
+
+	nodeType.Fields["desks"] = &Field{
+		Type: deskPage,
+		Args: deskArgs,
+		Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			if err := authorize(ctx, hooks.Desk.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			keys := make([]string, len(sources))
+			for i, s := range sources {
+				keys[i] = s.(*node.Node).ID
+			}
+			b := queryDesk(desk.Query().NodeIDIn(keys...).PartitionByNodeID(), args)
+			groups := map[string][]*desk.Desk{}
+			if limit > 0 {
+				stmt, stmtArgs := b.Limit(limit).Offset(offset).SQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				for rows.Next() {
+					var k string
+					o, err := desk.NewFromRow(partitioned{rows, domain.ScanHex(&k)})
+					if err != nil {
+						return nil, err
+					}
+					groups[k] = append(groups[k], o)
+				}
+				if err := rows.Err(); err != nil {
+					return nil, err
+				}
+			}
+			totals := &partitionCounts{count: func(ctx context.Context) (map[interface{}]int, error) {
+				stmt, stmtArgs := b.CountSQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				counts := map[interface{}]int{}
+				for rows.Next() {
+					var k string
+					var n int
+					if err := rows.Scan(domain.ScanHex(&k), &n); err != nil {
+						return nil, err
+					}
+					counts[k] = n
+				}
+				return counts, rows.Err()
+			}}
+			values := make([]interface{}, len(keys))
+			for i, k := range keys {
+				values[i] = &Page{Items: groups[k], Count: totals.of(k)}
+			}
+			return values, nil
+		},
+	}
+
+	///////////////////
+	// DESK
+	///////////////////
+
+	query.Fields["desk"] = &Field{
+		Type: deskType,
+		Args: map[string]*Argument{"id": {Type: &NonNull{Of: ID}}},
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := authorize(ctx, hooks.Desk.Authorize, "read"); err != nil {
+				return nil, err
+			}
+			o, err := desk.GetByID(ctx, db, args["id"].(string))
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+			return o, err
+		}),
+	}
+
+	query.Fields["desks"] = &Field{
+		Type: deskPage,
+		Args: deskArgs,
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := authorize(ctx, hooks.Desk.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			b := queryDesk(desk.Query(), args)
+			items := []*desk.Desk{}
+			if limit > 0 {
+				if items, err = b.Limit(limit).Offset(offset).All(ctx, db); err != nil {
+					return nil, err
+				}
+			}
+			return &Page{Items: items, Count: func(ctx context.Context) (int, error) {
+				return b.Count(ctx, db)
+			}}, nil
+		}),
+	}
+
+	deskType.Fields["node"] = &Field{
+		Type: nodeType,
+		Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			if err := authorize(ctx, hooks.Node.Authorize, "read"); err != nil {
+				return nil, err
+			}
+			list := make([]*desk.Desk, len(sources))
+			for i, s := range sources {
+				list[i] = s.(*desk.Desk)
+			}
+			parents, err := desk.LoadNodes(ctx, db, list)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]interface{}, len(list))
+			for i, o := range list {
+				values[i] = parents[o.NodeID]
+			}
+			return values, nil
+		},
+	}
+
+	deskType.Fields["attendants"] = &Field{
+		Type: attendantPage,
+		Args: attendantArgs,
+		Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			if err := authorize(ctx, hooks.Attendant.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			keys := make([]string, len(sources))
+			for i, s := range sources {
+				keys[i] = s.(*desk.Desk).ID
+			}
+			b := queryAttendant(attendant.Query().LinkedToDesks(keys...), args)
+			groups := map[string][]*attendant.Attendant{}
+			if limit > 0 {
+				stmt, stmtArgs := b.Limit(limit).Offset(offset).SQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				for rows.Next() {
+					var k string
+					o, err := attendant.NewFromRow(partitioned{rows, domain.ScanHex(&k)})
+					if err != nil {
+						return nil, err
+					}
+					groups[k] = append(groups[k], o)
+				}
+				if err := rows.Err(); err != nil {
+					return nil, err
+				}
+			}
+			totals := &partitionCounts{count: func(ctx context.Context) (map[interface{}]int, error) {
+				stmt, stmtArgs := b.CountSQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				counts := map[interface{}]int{}
+				for rows.Next() {
+					var k string
+					var n int
+					if err := rows.Scan(domain.ScanHex(&k), &n); err != nil {
+						return nil, err
+					}
+					counts[k] = n
+				}
+				return counts, rows.Err()
+			}}
+			values := make([]interface{}, len(keys))
+			for i, k := range keys {
+				values[i] = &Page{Items: groups[k], Count: totals.of(k)}
+			}
+			return values, nil
+		},
+	}
+
+	///////////////////
+	// ATTENDANT
+	///////////////////
+
+	query.Fields["attendant"] = &Field{
+		Type: attendantType,
+		Args: map[string]*Argument{"id": {Type: &NonNull{Of: ID}}},
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := authorize(ctx, hooks.Attendant.Authorize, "read"); err != nil {
+				return nil, err
+			}
+			o, err := attendant.GetByID(ctx, db, args["id"].(string))
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+			return o, err
+		}),
+	}
+
+	query.Fields["attendants"] = &Field{
+		Type: attendantPage,
+		Args: attendantArgs,
+		Resolve: Each(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := authorize(ctx, hooks.Attendant.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			b := queryAttendant(attendant.Query(), args)
+			items := []*attendant.Attendant{}
+			if limit > 0 {
+				if items, err = b.Limit(limit).Offset(offset).All(ctx, db); err != nil {
+					return nil, err
+				}
+			}
+			return &Page{Items: items, Count: func(ctx context.Context) (int, error) {
+				return b.Count(ctx, db)
+			}}, nil
+		}),
+	}
+
+	attendantType.Fields["desks"] = &Field{
+		Type: deskPage,
+		Args: deskArgs,
+		Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			if err := authorize(ctx, hooks.Desk.Authorize, "list"); err != nil {
+				return nil, err
+			}
+			limit, offset, err := bounds(args)
+			if err != nil {
+				return nil, err
+			}
+			keys := make([]string, len(sources))
+			for i, s := range sources {
+				keys[i] = s.(*attendant.Attendant).ID
+			}
+			b := queryDesk(desk.Query().LinkedToAttendants(keys...), args)
+			groups := map[string][]*desk.Desk{}
+			if limit > 0 {
+				stmt, stmtArgs := b.Limit(limit).Offset(offset).SQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				for rows.Next() {
+					var k string
+					o, err := desk.NewFromRow(partitioned{rows, domain.ScanHex(&k)})
+					if err != nil {
+						return nil, err
+					}
+					groups[k] = append(groups[k], o)
+				}
+				if err := rows.Err(); err != nil {
+					return nil, err
+				}
+			}
+			totals := &partitionCounts{count: func(ctx context.Context) (map[interface{}]int, error) {
+				stmt, stmtArgs := b.CountSQL()
+				rows, err := db.QueryContext(ctx, stmt, stmtArgs...)
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+				counts := map[interface{}]int{}
+				for rows.Next() {
+					var k string
+					var n int
+					if err := rows.Scan(domain.ScanHex(&k), &n); err != nil {
+						return nil, err
+					}
+					counts[k] = n
+				}
+				return counts, rows.Err()
+			}}
+			values := make([]interface{}, len(keys))
+			for i, k := range keys {
+				values[i] = &Page{Items: groups[k], Count: totals.of(k)}
+			}
+			return values, nil
+		},
+	}
+
+	return New(query, SDL)
+}
+
+// queryNode adds the filter and orderBy arguments of a list of
+// Nodes to b
+func queryNode(b *node.QueryBuilder, args map[string]interface{}) *node.QueryBuilder {
+	filter, _ := args["filter"].(map[string]interface{})
+	if v := filter["id"]; v != nil {
+		b.WhereID(v.(string))
+	}
+	if v := filter["idIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.IDIn(vs...)
+	}
+	if v := filter["typeID"]; v != nil {
+		b.WhereTypeID(v.(string))
+	}
+	if v := filter["typeIDIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.TypeIDIn(vs...)
+	}
+	if v := filter["timestamp"]; v != nil {
+		b.WhereTimestamp(v.(time.Time))
+	}
+	if v := filter["timestampIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.TimestampIn(vs...)
+	}
+	if v := filter["timestampAfter"]; v != nil {
+		b.TimestampAfter(v.(time.Time))
+	}
+	if v := filter["timestampBefore"]; v != nil {
+		b.TimestampBefore(v.(time.Time))
+	}
+	if v := filter["name"]; v != nil {
+		b.WhereName(v.(string))
+	}
+	if v := filter["nameIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.NameIn(vs...)
+	}
+	if v := filter["nameLike"]; v != nil {
+		b.NameLike(v.(string))
+	}
+	if v := filter["createdAt"]; v != nil {
+		b.WhereCreatedAt(v.(time.Time))
+	}
+	if v := filter["createdAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.CreatedAtIn(vs...)
+	}
+	if v := filter["createdAtAfter"]; v != nil {
+		b.CreatedAtAfter(v.(time.Time))
+	}
+	if v := filter["createdAtBefore"]; v != nil {
+		b.CreatedAtBefore(v.(time.Time))
+	}
+	if v := filter["updatedAt"]; v != nil {
+		b.WhereUpdatedAt(v.(time.Time))
+	}
+	if v := filter["updatedAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.UpdatedAtIn(vs...)
+	}
+	if v := filter["updatedAtAfter"]; v != nil {
+		b.UpdatedAtAfter(v.(time.Time))
+	}
+	if v := filter["updatedAtBefore"]; v != nil {
+		b.UpdatedAtBefore(v.(time.Time))
+	}
+	if v := filter["deletedAt"]; v != nil {
+		b.WhereDeletedAt(v.(time.Time))
+	}
+	if v := filter["deletedAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.DeletedAtIn(vs...)
+	}
+	if v := filter["deletedAtAfter"]; v != nil {
+		b.DeletedAtAfter(v.(time.Time))
+	}
+	if v := filter["deletedAtBefore"]; v != nil {
+		b.DeletedAtBefore(v.(time.Time))
+	}
+	if v := filter["deletedAtIsNull"]; v != nil {
+		if v.(bool) {
+			b.DeletedAtIsNull()
+		} else {
+			b.DeletedAtIsNotNull()
+		}
+	}
+	if v := filter["createdBy"]; v != nil {
+		b.WhereCreatedBy(v.(string))
+	}
+	if v := filter["createdByIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.CreatedByIn(vs...)
+	}
+	if v := filter["createdByLike"]; v != nil {
+		b.CreatedByLike(v.(string))
+	}
+	if v := filter["updatedBy"]; v != nil {
+		b.WhereUpdatedBy(v.(string))
+	}
+	if v := filter["updatedByIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.UpdatedByIn(vs...)
+	}
+	if v := filter["updatedByLike"]; v != nil {
+		b.UpdatedByLike(v.(string))
+	}
+	orders, _ := args["orderBy"].([]interface{})
+	for _, o := range orders {
+		o := o.(map[string]interface{})
+		direction, _ := o["direction"].(string)
+		switch o["field"] {
+		case "ID":
+			b.OrderByID(domain.Order(direction))
+		case "TypeID":
+			b.OrderByTypeID(domain.Order(direction))
+		case "Timestamp":
+			b.OrderByTimestamp(domain.Order(direction))
+		case "Name":
+			b.OrderByName(domain.Order(direction))
+		case "CreatedAt":
+			b.OrderByCreatedAt(domain.Order(direction))
+		case "UpdatedAt":
+			b.OrderByUpdatedAt(domain.Order(direction))
+		case "DeletedAt":
+			b.OrderByDeletedAt(domain.Order(direction))
+		case "CreatedBy":
+			b.OrderByCreatedBy(domain.Order(direction))
+		case "UpdatedBy":
+			b.OrderByUpdatedBy(domain.Order(direction))
+		}
+	}
+	// the primary key breaks ties, so pages don't overlap
+	b.OrderByID(domain.Asc)
+	return b
+}
+
+// queryDesk adds the filter and orderBy arguments of a list of
+// Desks to b
+func queryDesk(b *desk.QueryBuilder, args map[string]interface{}) *desk.QueryBuilder {
+	filter, _ := args["filter"].(map[string]interface{})
+	if v := filter["id"]; v != nil {
+		b.WhereID(v.(string))
+	}
+	if v := filter["idIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.IDIn(vs...)
+	}
+	if v := filter["typeID"]; v != nil {
+		b.WhereTypeID(v.(string))
+	}
+	if v := filter["typeIDIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.TypeIDIn(vs...)
+	}
+	if v := filter["timestamp"]; v != nil {
+		b.WhereTimestamp(v.(time.Time))
+	}
+	if v := filter["timestampIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.TimestampIn(vs...)
+	}
+	if v := filter["timestampAfter"]; v != nil {
+		b.TimestampAfter(v.(time.Time))
+	}
+	if v := filter["timestampBefore"]; v != nil {
+		b.TimestampBefore(v.(time.Time))
+	}
+	if v := filter["name"]; v != nil {
+		b.WhereName(v.(string))
+	}
+	if v := filter["nameIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.NameIn(vs...)
+	}
+	if v := filter["nameLike"]; v != nil {
+		b.NameLike(v.(string))
+	}
+	if v := filter["lat"]; v != nil {
+		b.WhereLat(v.(float64))
+	}
+	if v := filter["latIn"]; v != nil {
+		vs := []float64{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(float64))
+		}
+		b.LatIn(vs...)
+	}
+	if v := filter["latGreaterThan"]; v != nil {
+		b.LatGreaterThan(v.(float64))
+	}
+	if v := filter["latLessThan"]; v != nil {
+		b.LatLessThan(v.(float64))
+	}
+	if v := filter["lng"]; v != nil {
+		b.WhereLng(v.(float64))
+	}
+	if v := filter["lngIn"]; v != nil {
+		vs := []float64{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(float64))
+		}
+		b.LngIn(vs...)
+	}
+	if v := filter["lngGreaterThan"]; v != nil {
+		b.LngGreaterThan(v.(float64))
+	}
+	if v := filter["lngLessThan"]; v != nil {
+		b.LngLessThan(v.(float64))
+	}
+	if v := filter["nodeID"]; v != nil {
+		b.WhereNodeID(v.(string))
+	}
+	if v := filter["nodeIDIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.NodeIDIn(vs...)
+	}
+	if v := filter["state"]; v != nil {
+		b.WhereState(enums.DeskState(v.(string)))
+	}
+	if v := filter["stateIn"]; v != nil {
+		vs := []enums.DeskState{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, enums.DeskState(v.(string)))
+		}
+		b.StateIn(vs...)
+	}
+	if v := filter["version"]; v != nil {
+		b.WhereVersion(v.(int64))
+	}
+	if v := filter["versionIn"]; v != nil {
+		vs := []int64{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(int64))
+		}
+		b.VersionIn(vs...)
+	}
+	if v := filter["versionGreaterThan"]; v != nil {
+		b.VersionGreaterThan(v.(int64))
+	}
+	if v := filter["versionLessThan"]; v != nil {
+		b.VersionLessThan(v.(int64))
+	}
+	if v := filter["createdAt"]; v != nil {
+		b.WhereCreatedAt(v.(time.Time))
+	}
+	if v := filter["createdAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.CreatedAtIn(vs...)
+	}
+	if v := filter["createdAtAfter"]; v != nil {
+		b.CreatedAtAfter(v.(time.Time))
+	}
+	if v := filter["createdAtBefore"]; v != nil {
+		b.CreatedAtBefore(v.(time.Time))
+	}
+	if v := filter["updatedAt"]; v != nil {
+		b.WhereUpdatedAt(v.(time.Time))
+	}
+	if v := filter["updatedAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.UpdatedAtIn(vs...)
+	}
+	if v := filter["updatedAtAfter"]; v != nil {
+		b.UpdatedAtAfter(v.(time.Time))
+	}
+	if v := filter["updatedAtBefore"]; v != nil {
+		b.UpdatedAtBefore(v.(time.Time))
+	}
+	if v := filter["deletedAt"]; v != nil {
+		b.WhereDeletedAt(v.(time.Time))
+	}
+	if v := filter["deletedAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.DeletedAtIn(vs...)
+	}
+	if v := filter["deletedAtAfter"]; v != nil {
+		b.DeletedAtAfter(v.(time.Time))
+	}
+	if v := filter["deletedAtBefore"]; v != nil {
+		b.DeletedAtBefore(v.(time.Time))
+	}
+	if v := filter["deletedAtIsNull"]; v != nil {
+		if v.(bool) {
+			b.DeletedAtIsNull()
+		} else {
+			b.DeletedAtIsNotNull()
+		}
+	}
+	if v := filter["createdBy"]; v != nil {
+		b.WhereCreatedBy(v.(string))
+	}
+	if v := filter["createdByIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.CreatedByIn(vs...)
+	}
+	if v := filter["createdByLike"]; v != nil {
+		b.CreatedByLike(v.(string))
+	}
+	if v := filter["updatedBy"]; v != nil {
+		b.WhereUpdatedBy(v.(string))
+	}
+	if v := filter["updatedByIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.UpdatedByIn(vs...)
+	}
+	if v := filter["updatedByLike"]; v != nil {
+		b.UpdatedByLike(v.(string))
+	}
+	orders, _ := args["orderBy"].([]interface{})
+	for _, o := range orders {
+		o := o.(map[string]interface{})
+		direction, _ := o["direction"].(string)
+		switch o["field"] {
+		case "ID":
+			b.OrderByID(domain.Order(direction))
+		case "TypeID":
+			b.OrderByTypeID(domain.Order(direction))
+		case "Timestamp":
+			b.OrderByTimestamp(domain.Order(direction))
+		case "Name":
+			b.OrderByName(domain.Order(direction))
+		case "Lat":
+			b.OrderByLat(domain.Order(direction))
+		case "Lng":
+			b.OrderByLng(domain.Order(direction))
+		case "NodeID":
+			b.OrderByNodeID(domain.Order(direction))
+		case "State":
+			b.OrderByState(domain.Order(direction))
+		case "Version":
+			b.OrderByVersion(domain.Order(direction))
+		case "CreatedAt":
+			b.OrderByCreatedAt(domain.Order(direction))
+		case "UpdatedAt":
+			b.OrderByUpdatedAt(domain.Order(direction))
+		case "DeletedAt":
+			b.OrderByDeletedAt(domain.Order(direction))
+		case "CreatedBy":
+			b.OrderByCreatedBy(domain.Order(direction))
+		case "UpdatedBy":
+			b.OrderByUpdatedBy(domain.Order(direction))
+		}
+	}
+	// the primary key breaks ties, so pages don't overlap
+	b.OrderByID(domain.Asc)
+	return b
+}
+
+// queryAttendant adds the filter and orderBy arguments of a list of
+// Attendants to b
+func queryAttendant(b *attendant.QueryBuilder, args map[string]interface{}) *attendant.QueryBuilder {
+	filter, _ := args["filter"].(map[string]interface{})
+	if v := filter["id"]; v != nil {
+		b.WhereID(v.(string))
+	}
+	if v := filter["idIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.IDIn(vs...)
+	}
+	if v := filter["typeID"]; v != nil {
+		b.WhereTypeID(v.(string))
+	}
+	if v := filter["typeIDIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.TypeIDIn(vs...)
+	}
+	if v := filter["timestamp"]; v != nil {
+		b.WhereTimestamp(v.(time.Time))
+	}
+	if v := filter["timestampIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.TimestampIn(vs...)
+	}
+	if v := filter["timestampAfter"]; v != nil {
+		b.TimestampAfter(v.(time.Time))
+	}
+	if v := filter["timestampBefore"]; v != nil {
+		b.TimestampBefore(v.(time.Time))
+	}
+	if v := filter["name"]; v != nil {
+		b.WhereName(v.(string))
+	}
+	if v := filter["nameIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.NameIn(vs...)
+	}
+	if v := filter["nameLike"]; v != nil {
+		b.NameLike(v.(string))
+	}
+	if v := filter["createdAt"]; v != nil {
+		b.WhereCreatedAt(v.(time.Time))
+	}
+	if v := filter["createdAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.CreatedAtIn(vs...)
+	}
+	if v := filter["createdAtAfter"]; v != nil {
+		b.CreatedAtAfter(v.(time.Time))
+	}
+	if v := filter["createdAtBefore"]; v != nil {
+		b.CreatedAtBefore(v.(time.Time))
+	}
+	if v := filter["updatedAt"]; v != nil {
+		b.WhereUpdatedAt(v.(time.Time))
+	}
+	if v := filter["updatedAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.UpdatedAtIn(vs...)
+	}
+	if v := filter["updatedAtAfter"]; v != nil {
+		b.UpdatedAtAfter(v.(time.Time))
+	}
+	if v := filter["updatedAtBefore"]; v != nil {
+		b.UpdatedAtBefore(v.(time.Time))
+	}
+	if v := filter["deletedAt"]; v != nil {
+		b.WhereDeletedAt(v.(time.Time))
+	}
+	if v := filter["deletedAtIn"]; v != nil {
+		vs := []time.Time{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(time.Time))
+		}
+		b.DeletedAtIn(vs...)
+	}
+	if v := filter["deletedAtAfter"]; v != nil {
+		b.DeletedAtAfter(v.(time.Time))
+	}
+	if v := filter["deletedAtBefore"]; v != nil {
+		b.DeletedAtBefore(v.(time.Time))
+	}
+	if v := filter["deletedAtIsNull"]; v != nil {
+		if v.(bool) {
+			b.DeletedAtIsNull()
+		} else {
+			b.DeletedAtIsNotNull()
+		}
+	}
+	if v := filter["createdBy"]; v != nil {
+		b.WhereCreatedBy(v.(string))
+	}
+	if v := filter["createdByIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.CreatedByIn(vs...)
+	}
+	if v := filter["createdByLike"]; v != nil {
+		b.CreatedByLike(v.(string))
+	}
+	if v := filter["updatedBy"]; v != nil {
+		b.WhereUpdatedBy(v.(string))
+	}
+	if v := filter["updatedByIn"]; v != nil {
+		vs := []string{}
+		for _, v := range v.([]interface{}) {
+			vs = append(vs, v.(string))
+		}
+		b.UpdatedByIn(vs...)
+	}
+	if v := filter["updatedByLike"]; v != nil {
+		b.UpdatedByLike(v.(string))
+	}
+	orders, _ := args["orderBy"].([]interface{})
+	for _, o := range orders {
+		o := o.(map[string]interface{})
+		direction, _ := o["direction"].(string)
+		switch o["field"] {
+		case "ID":
+			b.OrderByID(domain.Order(direction))
+		case "TypeID":
+			b.OrderByTypeID(domain.Order(direction))
+		case "Timestamp":
+			b.OrderByTimestamp(domain.Order(direction))
+		case "Name":
+			b.OrderByName(domain.Order(direction))
+		case "CreatedAt":
+			b.OrderByCreatedAt(domain.Order(direction))
+		case "UpdatedAt":
+			b.OrderByUpdatedAt(domain.Order(direction))
+		case "DeletedAt":
+			b.OrderByDeletedAt(domain.Order(direction))
+		case "CreatedBy":
+			b.OrderByCreatedBy(domain.Order(direction))
+		case "UpdatedBy":
+			b.OrderByUpdatedBy(domain.Order(direction))
+		}
+	}
+	// the primary key breaks ties, so pages don't overlap
+	b.OrderByID(domain.Asc)
+	return b
+}
@@ -0,0 +1,273 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/attendant"
+	"git.ottoq.com/otto-backend/valet/domain/desk"
+	"git.ottoq.com/otto-backend/valet/domain/domaintest"
+	"git.ottoq.com/otto-backend/valet/domain/node"
+	"git.ottoq.com/otto-backend/valet/handler"
+	"git.ottoq.com/otto-backend/valet/server"
+)
+
+type testStringer struct{}
+
+func (testStringer) String() string {
+	return "stringer"
+}
+
+func TestScalars(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	tests := []struct {
+		scalar *Scalar
+		value  interface{}
+		want   interface{} // want is nil when value can't be resolved
+	}{
+		{String, "text", "text"},
+		{String, []byte{1, 2}, "AQI="},
+		{String, testStringer{}, "stringer"},
+		{String, 1, nil},
+		{ID, "0C74", "0C74"},
+		{Int, int8(-3), int64(-3)},
+		{Int, uint32(3), uint64(3)},
+		{Int, 1.5, nil},
+		{Float, float32(1.5), 1.5},
+		{Float, 2, float64(2)},
+		{Float, math.NaN(), nil},
+		{Float, math.Inf(1), nil},
+		{Boolean, true, true},
+		{Boolean, "true", nil},
+		{Time, at, "2020-01-02T03:04:05.000000006Z"},
+		{Time, "2020-01-02", nil},
+		{JSON, []byte(`{"a":1}`), json.RawMessage(`{"a":1}`)},
+		{JSON, []byte{}, nil},
+	}
+	for _, test := range tests {
+		got, err := test.scalar.Serialize(test.value)
+		if test.want == nil {
+			if err == nil && got != nil {
+				t.Errorf("%s resolved %#v as %#v", test.scalar, test.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s of %#v: %s", test.scalar, test.value, err)
+		} else if b, _ := json.Marshal(got); string(b) != string(mustMarshal(t, test.want)) {
+			t.Errorf("%s resolved %#v as %s", test.scalar, test.value, b)
+		}
+	}
+}
+
+func TestScalarArguments(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		scalar *Scalar
+		value  interface{}
+		want   interface{} // want is nil when value isn't valid
+	}{
+		{String, "text", "text"},
+		{String, int64(1), nil},
+		{ID, "0C74", "0C74"},
+		{Int, int64(7), int64(7)},
+		{Int, 7, int64(7)},
+		{Int, float64(7), int64(7)},
+		{Int, 7.5, nil},
+		{Int, json.Number("7"), int64(7)},
+		{Int, "7", nil},
+		{Float, int64(2), float64(2)},
+		{Float, json.Number("2.5"), 2.5},
+		{Float, true, nil},
+		{Boolean, false, false},
+		{Boolean, enumLiteral("TRUE"), nil},
+		{Time, "2020-01-02T03:04:05Z", at},
+		{Time, "yesterday", nil},
+		{JSON, map[string]interface{}{"a": int64(1)}, json.RawMessage(`{"a":1}`)},
+	}
+	for _, test := range tests {
+		got, err := test.scalar.Parse(test.value)
+		switch {
+		case test.want == nil && err == nil:
+			t.Errorf("%s parsed %#v as %#v", test.scalar, test.value, got)
+		case test.want != nil && err != nil:
+			t.Errorf("%s of %#v: %s", test.scalar, test.value, err)
+		case test.want != nil && string(mustMarshal(t, got)) != string(mustMarshal(t, test.want)):
+			t.Errorf("%s parsed %#v as %#v, want %#v", test.scalar, test.value, got, test.want)
+		}
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		args          map[string]interface{}
+		limit, offset int
+		ok            bool
+	}{
+		{map[string]interface{}{}, DefaultLimit, 0, true},
+		{map[string]interface{}{"limit": nil, "offset": int64(5)}, DefaultLimit, 5, true},
+		{map[string]interface{}{"limit": int64(0)}, 0, 0, true},
+		{map[string]interface{}{"limit": int64(MaxLimit + 1)}, MaxLimit, 0, true},
+		{map[string]interface{}{"limit": int64(-1)}, 0, 0, false},
+		{map[string]interface{}{"offset": int64(-1)}, 0, 0, false},
+	}
+	for _, test := range tests {
+		limit, offset, err := bounds(test.args)
+		if (err == nil) != test.ok || limit != test.limit || offset != test.offset {
+			t.Errorf("bounds of %v got %d, %d, %v", test.args, limit, offset, err)
+		}
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	data := &orderedMap{values: map[string]interface{}{}}
+	data.set("b", 1)
+	data.set("a", []interface{}{"x", nil})
+	data.set("b", 2)
+	tests := []struct {
+		result *Result
+		want   string
+	}{
+		{&Result{Data: data, executed: true}, `{"data":{"b":2,"a":["x",null]}}`},
+		{&Result{executed: true, Errors: []*Error{{Message: "m", Path: []interface{}{"a", 0}}}}, `{"data":null,"errors":[{"message":"m","path":["a",0]}]}`},
+		{&Result{Errors: []*Error{Errorf("bad %s", "query")}}, `{"errors":[{"message":"bad query"}]}`},
+	}
+	for _, test := range tests {
+		if got := string(mustMarshal(t, test.result)); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestSchemaTypes(t *testing.T) {
+	s := testSchema()
+	for _, name := range []string{"Query", "Node", "NodePage", "NodeFilter", "NodeOrder", "NodeField", "Order", "EchoInput", "Time", "String", "Int"} {
+		if _, ok := s.Type(name); !ok {
+			t.Errorf("the schema has no %s", name)
+		}
+	}
+	if _, ok := s.Type("Desk"); ok {
+		t.Error("the schema has a Desk")
+	}
+}
+
+// TestNewSchema serves the generated schema from a database of two
+// nodes, the first with three desks
+func TestNewSchema(t *testing.T) {
+	db := domaintest.Open(t)
+	ctx := context.Background()
+	insert := func(o domain.Domain) {
+		if err := o.Insert(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nodes := []*node.Node{node.Random(), node.Random()}
+	for i, n := range nodes {
+		n.Name = string(rune('a' + i))
+		insert(n)
+	}
+	desks := []*desk.Desk{desk.Random(), desk.Random(), desk.Random(), desk.Random()}
+	for i, d := range desks {
+		d.Name = "desk" + string(rune('1'+i))
+		d.NodeID = nodes[0].ID
+		if i == 3 {
+			d.NodeID = nodes[1].ID
+		}
+		insert(d)
+	}
+	attendants := []*attendant.Attendant{attendant.Random(), attendant.Random(), attendant.Random()}
+	for i, a := range attendants {
+		a.Name = "attendant" + string(rune('1'+i))
+		insert(a)
+		if err := desks[1].AddAttendant(ctx, db, a.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := desks[2].AddAttendant(ctx, db, attendants[2].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	allow := handler.Authorized(func(ctx context.Context, op string, in server.InputDTO) error {
+		return nil
+	})
+	noDeskLists := allow
+	noDeskLists.Desk.Authorize = func(ctx context.Context, op string, in server.InputDTO) error {
+		if op == "list" {
+			return &server.Error{Status: 403}
+		}
+		return nil
+	}
+	tests := []struct {
+		name  string
+		hooks handler.Hooks
+		query string
+		want  string
+	}{
+		{
+			name:  "children paged per parent",
+			hooks: allow,
+			query: `{ nodes(orderBy: {field: Name}) { total items { name desks(limit: 1, offset: 1, orderBy: {field: Name, direction: DESC}) { total items { name } } } } }`,
+			want:  `{"data":{"nodes":{"total":2,"items":[{"name":"a","desks":{"total":3,"items":[{"name":"desk2"}]}},{"name":"b","desks":{"total":1,"items":[]}}]}}}`,
+		},
+		{
+			name:  "children filtered",
+			hooks: allow,
+			query: `{ nodes(orderBy: {field: Name}) { items { desks(filter: {nameIn: ["desk1", "desk4"]}) { total items { name } } } } }`,
+			want:  `{"data":{"nodes":{"items":[{"desks":{"total":1,"items":[{"name":"desk1"}]}},{"desks":{"total":1,"items":[{"name":"desk4"}]}}]}}}`,
+		},
+		{
+			name:  "links paged per parent",
+			hooks: allow,
+			query: `{ desks(orderBy: {field: Name}) { items { name attendants(limit: 2, orderBy: {field: Name}) { total items { name desks(orderBy: {field: Name}) { items { name } } } } } } }`,
+			want: `{"data":{"desks":{"items":[` +
+				`{"name":"desk1","attendants":{"total":0,"items":[]}},` +
+				`{"name":"desk2","attendants":{"total":3,"items":[{"name":"attendant1","desks":{"items":[{"name":"desk2"}]}},{"name":"attendant2","desks":{"items":[{"name":"desk2"}]}}]}},` +
+				`{"name":"desk3","attendants":{"total":1,"items":[{"name":"attendant3","desks":{"items":[{"name":"desk2"},{"name":"desk3"}]}}]}},` +
+				`{"name":"desk4","attendants":{"total":0,"items":[]}}]}}}`,
+		},
+		{
+			name:  "only totals",
+			hooks: allow,
+			query: `{ desks(limit: 0) { total } desk(id: "` + desks[1].ID + `") { attendants(limit: 0, offset: 1) { total items { name } } } }`,
+			want:  `{"data":{"desks":{"total":4},"desk":{"attendants":{"total":3,"items":[]}}}}`,
+		},
+		{
+			name:  "fields sharing a key",
+			hooks: allow,
+			query: `{ a: node(id:"1"){id} a: desk(id:"2"){id} }`,
+			want:  `{"errors":[{"message":"a can't be selected twice as node and desk are different fields, alias one of them","locations":[{"line":1,"column":3},{"line":1,"column":23}]}]}`,
+		},
+		{
+			name:  "refused list",
+			hooks: noDeskLists,
+			query: `{ node(id: "` + nodes[1].ID + `") { name desks { total } } }`,
+			want:  `{"data":{"node":{"name":"b","desks":null}},"errors":[{"message":"Forbidden","locations":[{"line":1,"column":55}],"path":["node","desks"]}]}`,
+		},
+		{
+			name:  "no hooks",
+			hooks: handler.Hooks{},
+			query: `{ node(id: "` + nodes[1].ID + `") { name } }`,
+			want:  `{"data":{"node":null},"errors":[{"message":"Forbidden","locations":[{"line":1,"column":3}],"path":["node"]}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := mustMarshal(t, NewSchema(db, test.hooks).Execute(ctx, test.query, "", nil))
+			if string(got) != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
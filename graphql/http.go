@@ -0,0 +1,184 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/wardn/uuid"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/dto/input"
+	"git.ottoq.com/otto-backend/valet/entity"
+	"git.ottoq.com/otto-backend/valet/registry"
+	"git.ottoq.com/otto-backend/valet/server"
+	"git.ottoq.com/otto-backend/valet/server/securecookie"
+)
+
+const (
+	// Path is where queries are served, as the query parameters of a GET
+	// or the JSON body of a POST
+	Path = "/graphql"
+	// SchemaPath is where the schema is served in the schema language
+	SchemaPath = "/graphql/schema.graphql"
+
+	TypeID = "6C0E5B1F29A84D7C9E3B52D8A41F07E6"
+
+	// DefaultLimit is the page size of a list without a limit argument
+	DefaultLimit = 50
+	// MaxLimit is the largest page size of a list
+	MaxLimit = 500
+	// MaxDepth is how deeply the fields of a query may nest
+	MaxDepth = 12
+	// MaxComplexity is the most fields a query may select, the fields
+	// under a list counting once per item of its limit
+	MaxComplexity = 100000
+	// MaxFragments is the most fragments a query may define
+	MaxFragments = 100
+)
+
+func init() {
+	registry.MustRegister(registry.Entry{TypeID: TypeID, Name: "GraphQL"})
+}
+
+// Payload is a GraphQL request
+type Payload struct {
+	id            string
+	w             http.ResponseWriter
+	r             *http.Request
+	sesh          string
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+}
+
+func (p *Payload) Writer() http.ResponseWriter {
+	return p.w
+}
+func (p *Payload) Request() *http.Request {
+	return p.r
+}
+func (p *Payload) SessionID() string {
+	return p.sesh
+}
+func (p *Payload) ID() string {
+	return p.id
+}
+func (p *Payload) TypeID() string {
+	return TypeID
+}
+
+// FromHTTPRequest reads the query, operation name and variables of a GET's
+// query parameters, variables being JSON, or of a POST's JSON body. A POST
+// sends the CSRF token like any other, see input.CSRFHeader.
+func FromHTTPRequest(w http.ResponseWriter, r *http.Request,
+	sc *securecookie.Config,
+	sessionCookieName string) (server.InputDTO, error) {
+
+	var body struct {
+		Query         string
+		OperationName string
+		Variables     map[string]interface{}
+	}
+	seshID, err := input.ParseRW(&body, w, r, sc, sessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		body.Query = q.Get("query")
+		body.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &body.Variables); err != nil {
+				return nil, &server.Error{Status: http.StatusBadRequest, Message: "variables must be a JSON object"}
+			}
+		}
+	}
+	if body.Query == "" {
+		return nil, &server.Error{Status: http.StatusBadRequest, Message: "query is required"}
+	}
+	return &Payload{
+		id:            uuid.NewNoDash(),
+		w:             w,
+		r:             r,
+		sesh:          seshID,
+		Query:         body.Query,
+		OperationName: body.OperationName,
+		Variables:     body.Variables,
+	}, nil
+}
+
+// Handler executes the queries of a Schema
+type Handler struct {
+	Schema *Schema
+}
+
+func (h *Handler) InputTypeID() string {
+	return TypeID
+}
+
+func (h *Handler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*Payload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	ctx = context.WithValue(ctx, payloadKey{}, p)
+	responses <- server.NewResponse(p.ID(), TypeID, h.Schema.Execute(ctx, p.Query, p.OperationName, p.Variables))
+	return nil
+}
+
+// Serve executes queries against schema on Path and serves its SDL on
+// SchemaPath
+func Serve(s *server.Server, schema *Schema) error {
+	s.RegisterHTTPRoute(Path, server.HTTPConverterMap{
+		"GET":  FromHTTPRequest,
+		"POST": FromHTTPRequest,
+	})
+	if err := s.RegisterHandler(&Handler{Schema: schema}); err != nil {
+		return err
+	}
+	s.Handle(SchemaPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(schema.SDL))
+	}))
+	return nil
+}
+
+type payloadKey struct{}
+
+// authorize runs the Authorize hook of the REST handlers, given the
+// request being executed, so a query is allowed what the same session is
+// allowed on the REST endpoints. Without a hook nothing is allowed.
+func authorize(ctx context.Context, hook func(context.Context, string, server.InputDTO) error, op string) error {
+	if hook == nil {
+		return &server.Error{Status: http.StatusForbidden}
+	}
+	var in server.InputDTO
+	if p, ok := ctx.Value(payloadKey{}).(*Payload); ok {
+		in = p
+	}
+	return hook(ctx, op, in)
+}
+
+// message returns the message of a resolver's error. Errors meant for the
+// client are reported as is, any other as an internal error.
+func message(err error) string {
+	switch e := err.(type) {
+	case *Error:
+		return e.Message
+	case *server.Error:
+		return e.Error()
+	case domain.ValidationError:
+		return e.Error()
+	}
+	if err == sql.ErrNoRows {
+		return http.StatusText(http.StatusNotFound)
+	}
+	return http.StatusText(http.StatusInternalServerError)
+}
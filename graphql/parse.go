@@ -0,0 +1,676 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Location is a line and column of a query, both starting at 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+////////////////////////////////////////////////////////////
+// SYNTAX TREE
+////////////////////////////////////////////////////////////
+
+// document is a parsed query document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind string // kind is query, mutation or subscription
+	name string
+	vars []*varDef
+	set  []selection
+	loc  Location
+}
+
+type varDef struct {
+	name string
+	typ  *typeRef
+	def  *value
+	loc  Location
+}
+
+// typeRef is a type as written in a query, ex. [ID!]!
+type typeRef struct {
+	name    string
+	list    *typeRef // list is the item type of a list
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.list != nil {
+		s = "[" + t.list.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// selection is a *field, *spread or *inline
+type selection interface{}
+
+type field struct {
+	alias string
+	name  string
+	args  []*argument
+	dirs  []*directive
+	set   []selection
+	loc   Location
+}
+
+// key returns the name of the field in the response
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type argument struct {
+	name  string
+	value *value
+	loc   Location
+}
+
+type directive struct {
+	name string
+	args []*argument
+	loc  Location
+}
+
+// spread is a fragment spread, ex. ...deskFields
+type spread struct {
+	name string
+	dirs []*directive
+	loc  Location
+}
+
+// inline is an inline fragment, ex. ... on Desk { name }
+type inline struct {
+	on   string
+	dirs []*directive
+	set  []selection
+	loc  Location
+}
+
+type fragment struct {
+	name string
+	on   string
+	set  []selection
+	loc  Location
+}
+
+type valueKind int
+
+const (
+	variableValue valueKind = iota
+	intValue
+	floatValue
+	stringValue
+	booleanValue
+	nullValue
+	enumValue
+	listValue
+	objectValue
+)
+
+// value is a literal or variable. raw holds the variable name or the
+// literal's text, a string's unescaped.
+type value struct {
+	kind   valueKind
+	raw    string
+	list   []*value
+	fields []*objectField
+	loc    Location
+}
+
+type objectField struct {
+	name  string
+	value *value
+}
+
+////////////////////////////////////////////////////////////
+// LEXER
+////////////////////////////////////////////////////////////
+
+type tokenKind int
+
+const (
+	eofToken tokenKind = iota
+	punctToken
+	nameToken
+	intToken
+	floatToken
+	stringToken
+)
+
+type token struct {
+	kind tokenKind
+	text string // text is a string's unescaped value
+	loc  Location
+}
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int // col is the byte offset of the line's start
+	tok  token
+}
+
+// SyntaxError is a query that can't be parsed
+type SyntaxError struct {
+	Message  string
+	Location Location
+}
+
+// Error returns the error string
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", err.Location.Line, err.Location.Column, err.Message)
+}
+
+func (l *lexer) loc() Location {
+	return Location{Line: l.line, Column: l.pos - l.col + 1}
+}
+
+func (l *lexer) fail(loc Location, format string, args ...interface{}) {
+	panic(&SyntaxError{Message: fmt.Sprintf(format, args...), Location: loc})
+}
+
+// next reads the following token into l.tok
+func (l *lexer) next() {
+	l.skipIgnored()
+	loc := l.loc()
+	if l.pos >= len(l.src) {
+		l.tok = token{kind: eofToken, loc: loc}
+		return
+	}
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		l.tok = token{kind: punctToken, text: "...", loc: loc}
+	case strings.IndexByte("!$&()=:@[]{}|", c) >= 0:
+		l.pos++
+		l.tok = token{kind: punctToken, text: string(c), loc: loc}
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		l.tok = token{kind: nameToken, text: l.src[start:l.pos], loc: loc}
+	case c == '-' || isDigit(c):
+		l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			l.blockString(loc)
+		} else {
+			l.string(loc)
+		}
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+		l.fail(loc, "unexpected character %q", r)
+	}
+}
+
+// bom is the unicode byte order mark, ignored like white space
+const bom = "\uFEFF"
+
+// skipIgnored skips white space, commas and comments
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case '\n':
+			l.pos++
+			l.line++
+			l.col = l.pos
+		case ' ', '\t', '\r', ',':
+			l.pos++
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.src[l.pos:], bom) {
+				l.pos += len(bom)
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (l *lexer) number(loc Location) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := func() {
+		if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+			l.fail(l.loc(), "expected a digit")
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	digits()
+	kind := intToken
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		digits()
+		kind = floatToken
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		digits()
+		kind = floatToken
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.fail(l.loc(), "invalid number %s", l.src[start:l.pos+1])
+	}
+	l.tok = token{kind: kind, text: l.src[start:l.pos], loc: loc}
+}
+
+func (l *lexer) string(loc Location) {
+	l.pos++
+	b := new(strings.Builder)
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			l.fail(loc, "unterminated string")
+		}
+		c := l.src[l.pos]
+		if c == '"' {
+			l.pos++
+			break
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			l.pos++
+			continue
+		}
+		if l.pos+1 >= len(l.src) {
+			l.fail(loc, "unterminated string")
+		}
+		l.pos++
+		switch e := l.src[l.pos]; e {
+		case '"', '\\', '/':
+			b.WriteByte(e)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if l.pos+5 > len(l.src) {
+				l.fail(l.loc(), "invalid unicode escape")
+			}
+			n, err := strconv.ParseUint(l.src[l.pos+1:l.pos+5], 16, 32)
+			if err != nil {
+				l.fail(l.loc(), "invalid unicode escape \\u%s", l.src[l.pos+1:l.pos+5])
+			}
+			b.WriteRune(rune(n))
+			l.pos += 4
+		default:
+			l.fail(l.loc(), "invalid escape \\%c", e)
+		}
+		l.pos++
+	}
+	l.tok = token{kind: stringToken, text: b.String(), loc: loc}
+}
+
+// blockString reads a """ string, removing the indentation its lines
+// share and its blank first and last lines
+func (l *lexer) blockString(loc Location) {
+	l.pos += 3
+	b := new(strings.Builder)
+	for {
+		if l.pos >= len(l.src) {
+			l.fail(loc, "unterminated string")
+		}
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			l.pos += 3
+			break
+		}
+		if strings.HasPrefix(l.src[l.pos:], `\"""`) {
+			b.WriteString(`"""`)
+			l.pos += 4
+			continue
+		}
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.col = l.pos + 1
+		}
+		b.WriteByte(l.src[l.pos])
+		l.pos++
+	}
+	l.tok = token{kind: stringToken, text: blockStringValue(b.String()), loc: loc}
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.Replace(raw, "\r\n", "\n", -1), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+////////////////////////////////////////////////////////////
+// PARSER
+////////////////////////////////////////////////////////////
+
+// parse reads a query document
+func parse(src string) (doc *document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, e
+		}
+	}()
+	l := &lexer{src: src, line: 1}
+	l.next()
+	doc = &document{fragments: map[string]*fragment{}}
+	for l.tok.kind != eofToken {
+		switch {
+		case l.peek("{"):
+			op := &operation{kind: "query", loc: l.tok.loc}
+			op.set = l.selectionSet()
+			doc.operations = append(doc.operations, op)
+		case l.peekName("query"), l.peekName("mutation"), l.peekName("subscription"):
+			doc.operations = append(doc.operations, l.operation())
+		case l.peekName("fragment"):
+			f := l.fragment()
+			if _, ok := doc.fragments[f.name]; ok {
+				l.fail(f.loc, "fragment %s is defined twice", f.name)
+			}
+			doc.fragments[f.name] = f
+		default:
+			l.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		l.fail(l.tok.loc, "the document has no operation")
+	}
+	return doc, nil
+}
+
+func (l *lexer) peek(punct string) bool {
+	return l.tok.kind == punctToken && l.tok.text == punct
+}
+
+func (l *lexer) peekName(name string) bool {
+	return l.tok.kind == nameToken && l.tok.text == name
+}
+
+func (l *lexer) unexpected() {
+	if l.tok.kind == eofToken {
+		l.fail(l.tok.loc, "unexpected end of document")
+	}
+	l.fail(l.tok.loc, "unexpected %q", l.tok.text)
+}
+
+func (l *lexer) expect(punct string) {
+	if !l.peek(punct) {
+		if l.tok.kind == eofToken {
+			l.fail(l.tok.loc, "expected %q, found end of document", punct)
+		}
+		l.fail(l.tok.loc, "expected %q, found %q", punct, l.tok.text)
+	}
+	l.next()
+}
+
+func (l *lexer) name() string {
+	if l.tok.kind != nameToken {
+		if l.tok.kind == eofToken {
+			l.fail(l.tok.loc, "expected a name, found end of document")
+		}
+		l.fail(l.tok.loc, "expected a name, found %q", l.tok.text)
+	}
+	name := l.tok.text
+	l.next()
+	return name
+}
+
+func (l *lexer) operation() *operation {
+	op := &operation{kind: l.tok.text, loc: l.tok.loc}
+	l.next()
+	if l.tok.kind == nameToken {
+		op.name = l.name()
+	}
+	if l.peek("(") {
+		l.next()
+		for !l.peek(")") {
+			v := &varDef{loc: l.tok.loc}
+			l.expect("$")
+			v.name = l.name()
+			l.expect(":")
+			v.typ = l.typeRef()
+			if l.peek("=") {
+				l.next()
+				v.def = l.value(true)
+			}
+			op.vars = append(op.vars, v)
+		}
+		l.next()
+	}
+	if l.peek("@") {
+		l.directives()
+	}
+	op.set = l.selectionSet()
+	return op
+}
+
+func (l *lexer) fragment() *fragment {
+	f := &fragment{loc: l.tok.loc}
+	l.next()
+	f.name = l.name()
+	if f.name == "on" {
+		l.fail(f.loc, "a fragment can't be named on")
+	}
+	if !l.peekName("on") {
+		l.fail(l.tok.loc, "expected a type condition for fragment %s", f.name)
+	}
+	l.next()
+	f.on = l.name()
+	if l.peek("@") {
+		l.directives()
+	}
+	f.set = l.selectionSet()
+	return f
+}
+
+func (l *lexer) typeRef() *typeRef {
+	t := &typeRef{}
+	if l.peek("[") {
+		l.next()
+		t.list = l.typeRef()
+		l.expect("]")
+	} else {
+		t.name = l.name()
+	}
+	if l.peek("!") {
+		l.next()
+		t.nonNull = true
+	}
+	return t
+}
+
+func (l *lexer) selectionSet() []selection {
+	l.expect("{")
+	set := []selection{}
+	for !l.peek("}") {
+		set = append(set, l.selection())
+	}
+	l.next()
+	return set
+}
+
+func (l *lexer) selection() selection {
+	loc := l.tok.loc
+	if l.peek("...") {
+		l.next()
+		if l.tok.kind == nameToken && l.tok.text != "on" {
+			s := &spread{name: l.name(), loc: loc}
+			s.dirs = l.directives()
+			return s
+		}
+		i := &inline{loc: loc}
+		if l.peekName("on") {
+			l.next()
+			i.on = l.name()
+		}
+		i.dirs = l.directives()
+		i.set = l.selectionSet()
+		return i
+	}
+	f := &field{name: l.name(), loc: loc}
+	if l.peek(":") {
+		l.next()
+		f.alias, f.name = f.name, l.name()
+	}
+	f.args = l.arguments(false)
+	f.dirs = l.directives()
+	if l.peek("{") {
+		f.set = l.selectionSet()
+	}
+	return f
+}
+
+func (l *lexer) arguments(constant bool) []*argument {
+	args := []*argument{}
+	if !l.peek("(") {
+		return args
+	}
+	l.next()
+	for !l.peek(")") {
+		// the location is read before name moves past it
+		a := &argument{loc: l.tok.loc}
+		a.name = l.name()
+		l.expect(":")
+		a.value = l.value(constant)
+		args = append(args, a)
+	}
+	l.next()
+	return args
+}
+
+func (l *lexer) directives() []*directive {
+	dirs := []*directive{}
+	for l.peek("@") {
+		d := &directive{loc: l.tok.loc}
+		l.next()
+		d.name = l.name()
+		d.args = l.arguments(false)
+		dirs = append(dirs, d)
+	}
+	return dirs
+}
+
+// value reads a value, constant ones being those that can't hold
+// variables, ex. defaults
+func (l *lexer) value(constant bool) *value {
+	v := &value{loc: l.tok.loc, raw: l.tok.text}
+	switch l.tok.kind {
+	case intToken:
+		v.kind = intValue
+	case floatToken:
+		v.kind = floatValue
+	case stringToken:
+		v.kind = stringValue
+	case nameToken:
+		switch l.tok.text {
+		case "true", "false":
+			v.kind = booleanValue
+		case "null":
+			v.kind = nullValue
+		default:
+			v.kind = enumValue
+		}
+	case punctToken:
+		switch l.tok.text {
+		case "$":
+			if constant {
+				l.fail(v.loc, "unexpected variable")
+			}
+			l.next()
+			v.kind = variableValue
+			v.raw = l.name()
+			return v
+		case "[":
+			l.next()
+			v.kind = listValue
+			for !l.peek("]") {
+				v.list = append(v.list, l.value(constant))
+			}
+			l.next()
+			return v
+		case "{":
+			l.next()
+			v.kind = objectValue
+			for !l.peek("}") {
+				f := &objectField{name: l.name()}
+				l.expect(":")
+				f.value = l.value(constant)
+				v.fields = append(v.fields, f)
+			}
+			l.next()
+			return v
+		default:
+			l.unexpected()
+		}
+	default:
+		l.unexpected()
+	}
+	l.next()
+	return v
+}
@@ -0,0 +1,167 @@
+package graphql
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := parse(`# the desks of a node
+query Desks($id: ID!, $names: [String!]! = ["a"]) @skip(if: false) {
+  node(id: $id) {
+    id
+    all: desks(limit: 10) @include(if: true) {
+      ...page
+      ... on DeskPage { total }
+    }
+  }
+}
+
+fragment page on DeskPage {
+  items { name }
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.operations) != 1 || len(doc.fragments) != 1 {
+		t.Fatalf("parsed %d operations and %d fragments", len(doc.operations), len(doc.fragments))
+	}
+	op := doc.operations[0]
+	if op.kind != "query" || op.name != "Desks" || op.loc != (Location{2, 1}) {
+		t.Errorf("operation is %s %s at %v", op.kind, op.name, op.loc)
+	}
+	if len(op.vars) != 2 {
+		t.Fatalf("operation declares %d variables", len(op.vars))
+	}
+	if v := op.vars[0]; v.name != "id" || v.typ.String() != "ID!" || v.def != nil || v.loc != (Location{2, 13}) {
+		t.Errorf("first variable is $%s: %s at %v", v.name, v.typ, v.loc)
+	}
+	if v := op.vars[1]; v.name != "names" || v.typ.String() != "[String!]!" || v.def == nil || v.def.kind != listValue {
+		t.Errorf("second variable is $%s: %s = %v", v.name, v.typ, v.def)
+	}
+
+	node := op.set[0].(*field)
+	if node.name != "node" || node.key() != "node" || len(node.args) != 1 || len(node.set) != 2 {
+		t.Fatalf("first selection is %+v", node)
+	}
+	if a := node.args[0]; a.name != "id" || a.value.kind != variableValue || a.value.raw != "id" || a.loc != (Location{3, 8}) {
+		t.Errorf("argument of node is %s: %+v at %v", a.name, a.value, a.loc)
+	}
+	desks := node.set[1].(*field)
+	if desks.alias != "all" || desks.name != "desks" || desks.key() != "all" || desks.loc != (Location{5, 5}) {
+		t.Errorf("aliased field is %s: %s at %v", desks.alias, desks.name, desks.loc)
+	}
+	if len(desks.dirs) != 1 || desks.dirs[0].name != "include" || len(desks.dirs[0].args) != 1 {
+		t.Errorf("aliased field has directives %+v", desks.dirs)
+	}
+	if s, ok := desks.set[0].(*spread); !ok || s.name != "page" {
+		t.Errorf("first selection of desks is %+v", desks.set[0])
+	}
+	if i, ok := desks.set[1].(*inline); !ok || i.on != "DeskPage" || len(i.set) != 1 {
+		t.Errorf("second selection of desks is %+v", desks.set[1])
+	}
+	if f := doc.fragments["page"]; f == nil || f.on != "DeskPage" || f.loc != (Location{12, 1}) || len(f.set) != 1 {
+		t.Errorf("fragment is %+v", f)
+	}
+
+	doc, err = parse("\n  { id }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op := doc.operations[0]; op.kind != "query" || op.loc != (Location{2, 3}) {
+		t.Errorf("shorthand operation is %s at %v", op.kind, op.loc)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		literal string
+		kind    valueKind
+		raw     string
+	}{
+		{`$v`, variableValue, "v"},
+		{`12`, intValue, "12"},
+		{`-0`, intValue, "-0"},
+		{`1.5`, floatValue, "1.5"},
+		{`-1e10`, floatValue, "-1e10"},
+		{`2.5E-3`, floatValue, "2.5E-3"},
+		{`"text"`, stringValue, "text"},
+		{`""`, stringValue, ""},
+		{`"tab\tquote\" slash\/ é"`, stringValue, "tab\tquote\" slash/ é"},
+		{`"""a "block" \"""string"""`, stringValue, `a "block" """string`},
+		{"\"\"\"\n    indented\n      more\n    \"\"\"", stringValue, "indented\n  more"},
+		{`true`, booleanValue, "true"},
+		{`false`, booleanValue, "false"},
+		{`null`, nullValue, "null"},
+		{`ASC`, enumValue, "ASC"},
+		{`[1, "a", [$v]]`, listValue, "["},
+		{`{a: 1, b: {c: $v}}`, objectValue, "{"},
+	}
+	for _, test := range tests {
+		doc, err := parse(`{ f(a: ` + test.literal + `) }`)
+		if err != nil {
+			t.Errorf("%s: %s", test.literal, err)
+			continue
+		}
+		v := doc.operations[0].set[0].(*field).args[0].value
+		if v.kind != test.kind || v.raw != test.raw {
+			t.Errorf("%s parsed as %d %q, want %d %q", test.literal, v.kind, v.raw, test.kind, test.raw)
+		}
+	}
+
+	doc, err := parse(`{ f(a: [1, {b: [$v]}]) }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := doc.operations[0].set[0].(*field).args[0].value.list
+	if len(list) != 2 || list[0].kind != intValue || list[1].kind != objectValue {
+		t.Fatalf("list parsed as %+v", list)
+	}
+	if f := list[1].fields; len(f) != 1 || f[0].name != "b" || f[0].value.list[0].kind != variableValue {
+		t.Errorf("object parsed as %+v", f)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+		at   Location
+	}{
+		{``, "the document has no operation", Location{1, 1}},
+		{`# only a comment`, "the document has no operation", Location{1, 17}},
+		{`fragment f on Node { id }`, "the document has no operation", Location{1, 26}},
+		{`{ id`, "expected a name, found end of document", Location{1, 5}},
+		{`{ id }}`, `unexpected "}"`, Location{1, 7}},
+		{`{ f(a 1) }`, `expected ":", found "1"`, Location{1, 7}},
+		{`{ f(a: ) }`, `unexpected ")"`, Location{1, 8}},
+		{`{ f(a: [1) }`, `unexpected ")"`, Location{1, 10}},
+		{"{\n  f(a: \"open)\n}", "unterminated string", Location{2, 8}},
+		{`{ f(a: """open) }`, "unterminated string", Location{1, 8}},
+		{`{ f(a: "\x") }`, `invalid escape \x`, Location{1, 10}},
+		{`{ f(a: "\u12") }`, `invalid unicode escape \u12")`, Location{1, 10}},
+		{`{ f(a: 1.) }`, "expected a digit", Location{1, 10}},
+		{`{ f(a: 01a) }`, "invalid number 01a", Location{1, 10}},
+		{`{ f(a: -) }`, "expected a digit", Location{1, 9}},
+		{`{ f ? }`, `unexpected character '?'`, Location{1, 5}},
+		{`query ($a: Int = $b) { f }`, "unexpected variable", Location{1, 18}},
+		{`query ($a) { f }`, `expected ":", found ")"`, Location{1, 10}},
+		{`query ($a: [Int) { f }`, `expected "]", found ")"`, Location{1, 16}},
+		{`{ ...on }`, `expected a name, found "}"`, Location{1, 9}},
+		{`{ f } fragment on on Node { id }`, "a fragment can't be named on", Location{1, 7}},
+		{`{ f } fragment f Node { id }`, "expected a type condition for fragment f", Location{1, 18}},
+		{`{ f } fragment f on Node { id } fragment f on Node { id }`, "fragment f is defined twice", Location{1, 33}},
+		{`subscribe { f }`, `unexpected "subscribe"`, Location{1, 1}},
+	}
+	for _, test := range tests {
+		_, err := parse(test.src)
+		e, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%q got %v", test.src, err)
+			continue
+		}
+		if e.Message != test.want || e.Location != test.at {
+			t.Errorf("%q got %q at %v, want %q at %v", test.src, e.Message, e.Location, test.want, test.at)
+		}
+	}
+}
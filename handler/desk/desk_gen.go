@@ -0,0 +1,279 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum b03829302821f56c
+
+// Package desk
+// Default handlers for the Desk REST endpoints
+package desk
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/desk"
+	"git.ottoq.com/otto-backend/valet/dto/input/desk"
+	"git.ottoq.com/otto-backend/valet/entity"
+	"git.ottoq.com/otto-backend/valet/server"
+)
+
+// Hooks add business logic to the default handlers. Every hook but
+// Authorize is optional and an error from any of them fails the request,
+// a *server.Error choosing the status.
+type Hooks struct {
+	// Authorize runs first for every operation, op being one of
+	// "create", "read", "update", "delete" or "list". Without it every
+	// request is refused, as GraphQL queries are.
+	Authorize func(ctx context.Context, op string, in server.InputDTO) error
+	// Before hooks run once the input is applied, before the write
+	BeforeCreate func(ctx context.Context, o *desk.Desk) error
+	BeforeUpdate func(ctx context.Context, o *desk.Desk) error
+	BeforeDelete func(ctx context.Context, o *desk.Desk) error
+	// After hooks run once the write succeeded, before the response
+	AfterCreate func(ctx context.Context, o *desk.Desk) error
+	AfterUpdate func(ctx context.Context, o *desk.Desk) error
+	AfterDelete func(ctx context.Context, o *desk.Desk) error
+}
+
+func (h Hooks) authorize(ctx context.Context, op string, in server.InputDTO) error {
+	if h.Authorize == nil {
+		return &server.Error{Status: http.StatusForbidden}
+	}
+	return h.Authorize(ctx, op, in)
+}
+
+func run(ctx context.Context, hook func(context.Context, *desk.Desk) error, o *desk.Desk) error {
+	if hook == nil {
+		return nil
+	}
+	return hook(ctx, o)
+}
+
+// Register serves every generated Desk endpoint on inputdesk.Route
+func Register(s *server.Server, db domain.DB, hooks Hooks) error {
+	s.RegisterHTTPRoute(inputdesk.Route, inputdesk.Converters())
+	if err := s.RegisterHandler(&CreateHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	if err := s.RegisterHandler(&ReadHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	if err := s.RegisterHandler(&UpdateHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	if err := s.RegisterHandler(&DeleteHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	if err := s.RegisterHandler(&ListHandler{DB: db, Hooks: hooks}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// httpError maps the errors of the domain package to a response status
+func httpError(err error) error {
+	switch e := err.(type) {
+	case *server.Error:
+		return e
+	case domain.ValidationError:
+		return &server.Error{Status: http.StatusBadRequest, Message: e.Error()}
+	case *domain.ErrStaleObject:
+		return &server.Error{Status: http.StatusConflict, Message: e.Error()}
+	case *domain.ErrDeleted:
+		return &server.Error{Status: http.StatusConflict, Message: e.Error()}
+	}
+	if err == sql.ErrNoRows {
+		return &server.Error{Status: http.StatusNotFound}
+	}
+	return err
+}
+
+func respond(responses chan entity.Identifier, o *desk.Desk) {
+	responses <- server.NewResponse(o.ID, desk.TypeID, o)
+}
+
+// CreateHandler inserts a new Desk
+type CreateHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *CreateHandler) InputTypeID() string {
+	return inputdesk.CreateTypeID
+}
+
+func (h *CreateHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*inputdesk.CreatePayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "create", in); err != nil {
+		return httpError(err)
+	}
+	o, err := desk.New(
+		p.Contents.Name,
+		p.Contents.Lat,
+		p.Contents.Lng,
+		p.Contents.NodeID,
+		p.Contents.State,
+	)
+	if err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.BeforeCreate, o); err != nil {
+		return httpError(err)
+	}
+	if err := o.Insert(ctx, h.DB); err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.AfterCreate, o); err != nil {
+		return httpError(err)
+	}
+	respond(responses, o)
+	return nil
+}
+
+// ReadHandler responds with a single Desk
+type ReadHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *ReadHandler) InputTypeID() string {
+	return inputdesk.ReadTypeID
+}
+
+func (h *ReadHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*inputdesk.ReadPayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "read", in); err != nil {
+		return httpError(err)
+	}
+	o, err := desk.GetByID(ctx, h.DB, p.Key.ID)
+	if err != nil {
+		return httpError(err)
+	}
+	respond(responses, o)
+	return nil
+}
+
+// UpdateHandler overwrites an existing Desk with the contents of a
+// PUT or applies a PATCH to it
+type UpdateHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *UpdateHandler) InputTypeID() string {
+	return inputdesk.UpdateTypeID
+}
+
+func (h *UpdateHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*inputdesk.UpdatePayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "update", in); err != nil {
+		return httpError(err)
+	}
+	o, err := desk.GetByID(ctx, h.DB, p.Contents.ID)
+	if err != nil {
+		return httpError(err)
+	}
+	// the version the client read, so Update fails if it's stale. A PUT
+	// always has one, a patch without one applies to the version just read.
+	if p.Patch == nil || p.Contents.Version != 0 {
+		o.Version = p.Contents.Version
+	}
+	if p.Patch != nil {
+		if err := o.Patch(p.Patch); err != nil {
+			return httpError(err)
+		}
+	} else {
+		// setters, so only the columns that differ are written
+		o.SetName(p.Contents.Name)
+		o.SetLat(p.Contents.Lat)
+		o.SetLng(p.Contents.Lng)
+		o.SetNodeID(p.Contents.NodeID)
+		o.SetState(p.Contents.State)
+	}
+	if err := run(ctx, h.Hooks.BeforeUpdate, o); err != nil {
+		return httpError(err)
+	}
+	if err := o.Update(ctx, h.DB); err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.AfterUpdate, o); err != nil {
+		return httpError(err)
+	}
+	respond(responses, o)
+	return nil
+}
+
+// DeleteHandler removes a Desk, responding with no body
+type DeleteHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *DeleteHandler) InputTypeID() string {
+	return inputdesk.DeleteTypeID
+}
+
+func (h *DeleteHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*inputdesk.DeletePayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "delete", in); err != nil {
+		return httpError(err)
+	}
+	o, err := desk.GetByID(ctx, h.DB, p.Key.ID)
+	if err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.BeforeDelete, o); err != nil {
+		return httpError(err)
+	}
+	if err := o.Delete(ctx, h.DB); err != nil {
+		return httpError(err)
+	}
+	if err := run(ctx, h.Hooks.AfterDelete, o); err != nil {
+		return httpError(err)
+	}
+	return nil
+}
+
+// ListHandler responds with a page of Desks
+type ListHandler struct {
+	DB    domain.DB
+	Hooks Hooks
+}
+
+func (h *ListHandler) InputTypeID() string {
+	return inputdesk.ListTypeID
+}
+
+func (h *ListHandler) Notify(in server.InputDTO, responses chan entity.Identifier) error {
+	p, ok := in.(*inputdesk.ListPayload)
+	if !ok {
+		return &server.Error{Status: http.StatusInternalServerError}
+	}
+	ctx := domain.WithActor(p.Request().Context(), p.SessionID())
+	if err := h.Hooks.authorize(ctx, "list", in); err != nil {
+		return httpError(err)
+	}
+	list, err := desk.List(ctx, h.DB, p.Limit, p.Offset)
+	if err != nil {
+		return httpError(err)
+	}
+	responses <- server.NewResponse("", inputdesk.ListTypeID, list)
+	return nil
+}
@@ -0,0 +1,48 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 803b9e68040a0562
+
+// Package handler
+// Registers the default handlers of every domain object with REST endpoints
+package handler
+
+import (
+	"context"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	attendanthandler "git.ottoq.com/otto-backend/valet/handler/attendant"
+	deskhandler "git.ottoq.com/otto-backend/valet/handler/desk"
+	nodehandler "git.ottoq.com/otto-backend/valet/handler/node"
+	"git.ottoq.com/otto-backend/valet/server"
+)
+
+// Hooks holds the hooks of each object's handlers
+type Hooks struct {
+	Node      nodehandler.Hooks
+	Desk      deskhandler.Hooks
+	Attendant attendanthandler.Hooks
+}
+
+// Authorized returns the Hooks authorizing every operation on every
+// object with authorize
+func Authorized(authorize func(ctx context.Context, op string, in server.InputDTO) error) Hooks {
+	return Hooks{
+		Node:      nodehandler.Hooks{Authorize: authorize},
+		Desk:      deskhandler.Hooks{Authorize: authorize},
+		Attendant: attendanthandler.Hooks{Authorize: authorize},
+	}
+}
+
+// RegisterAll serves the generated endpoints of every domain object
+func RegisterAll(s *server.Server, db domain.DB, hooks Hooks) error {
+	if err := nodehandler.Register(s, db, hooks.Node); err != nil {
+		return err
+	}
+	if err := deskhandler.Register(s, db, hooks.Desk); err != nil {
+		return err
+	}
+	if err := attendanthandler.Register(s, db, hooks.Attendant); err != nil {
+		return err
+	}
+	return nil
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"os/user"
 	"path"
@@ -14,8 +16,12 @@ import (
 	"git.ottoq.com/otto-backend/valet/database"
 	"git.ottoq.com/otto-backend/valet/dto/input/sample"
 	"git.ottoq.com/otto-backend/valet/entity"
+	"git.ottoq.com/otto-backend/valet/graphql"
+	"git.ottoq.com/otto-backend/valet/handler"
+	"git.ottoq.com/otto-backend/valet/openapi"
 	"git.ottoq.com/otto-backend/valet/server"
 	"git.ottoq.com/otto-backend/valet/server/securecookie"
+	"git.ottoq.com/otto-backend/valet/server/session"
 )
 
 const (
@@ -90,7 +96,6 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	_ = db
 
 	// SECURE COOKIE
 	cc, err := securecookie.New(c.HashKey(), c.BlockKey(),
@@ -107,7 +112,25 @@ func main() {
 		cc)
 
 	s.RegisterHTTPRoute("/test", server.HTTPConverterMap{"POST": inputsample.FromHTTPRequest})
-	s.RegisterHandler(H{})
+	if err := s.RegisterHandler(H{}); err != nil {
+		log.Fatal(err)
+	}
+	// the REST endpoints and GraphQL queries, which are authorized by the
+	// same hooks, need the session cookie of an earlier response. Their
+	// input converters check the CSRF token of requests changing data.
+	hooks := handler.Authorized(func(ctx context.Context, op string, in server.InputDTO) error {
+		if in == nil || in.Request() == nil || session.Existing(in.Request().Cookies(), cookieSessionName, cc) == nil {
+			return &server.Error{Status: http.StatusUnauthorized}
+		}
+		return nil
+	})
+	if err := handler.RegisterAll(s, db, hooks); err != nil {
+		log.Fatal(err)
+	}
+	if err := graphql.Register(s, db, hooks); err != nil {
+		log.Fatal(err)
+	}
+	openapi.Register(s)
 
 	s.Start()
 }
@@ -0,0 +1,105 @@
+// go run migrate/migrate.go [flags] up|down [steps]|status|baseline [version]
+//
+// Applies, reverts or lists the migrations in database/migrations.
+// baseline records the migrations up to version, 1 by default, as applied
+// without running them, for a database created before migrations by
+// EnsureTablesExist.
+// New migrations are written by the generator:
+// go run gen/gen.go -migration add_desk_color -dsn 'user:pass@tcp(127.0.0.1:3306)/valet'
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"git.ottoq.com/otto-backend/valet/database"
+)
+
+var (
+	addr   = flag.String("addr", "tcp(127.0.0.1:3306)", "database address")
+	dbname = flag.String("db", "test3", "database name")
+	user   = flag.String("user", "austin", "database user")
+	pass   = flag.String("pass", "", "database password")
+	dir    = flag.String("dir", "database/migrations", "directory holding migration files")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: migrate [flags] up|down [steps]|status|baseline [version]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+	}
+
+	migrations, err := database.LoadMigrations(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := database.New(*addr, *dbname, *user, *pass)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+
+	switch flag.Arg(0) {
+	case "up":
+		done, err := db.MigrateUp(ctx, migrations)
+		for _, m := range done {
+			fmt.Printf("applied  %s\n", m)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			if steps, err = strconv.Atoi(flag.Arg(1)); err != nil || steps < 1 {
+				usage()
+			}
+		}
+		done, err := db.MigrateDown(ctx, migrations, steps)
+		for _, m := range done {
+			fmt.Printf("reverted %s\n", m)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "baseline":
+		version := 1
+		if flag.NArg() > 1 {
+			if version, err = strconv.Atoi(flag.Arg(1)); err != nil || version < 1 {
+				usage()
+			}
+		}
+		done, err := db.Baseline(ctx, migrations, version)
+		for _, m := range done {
+			fmt.Printf("recorded %s\n", m)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		states, err := db.MigrationStatus(ctx, migrations)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range states {
+			if s.Applied {
+				fmt.Printf("applied  %s  %s\n", s.AppliedAt.Format("2006-01-02 15:04:05"), s.Migration)
+			} else {
+				fmt.Printf("pending  %-19s  %s\n", "", s.Migration)
+			}
+		}
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,63 @@
+// Package openapi serves the OpenAPI document of the server's routes
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"git.ottoq.com/otto-backend/valet/server"
+)
+
+// Path is where Register serves the document
+const Path = "/openapi.json"
+
+// Document returns the generated Spec narrowed to routes. Generated paths
+// that aren't registered are left out and registered routes the generator
+// doesn't know about are described without a request or response body.
+func Document(routes []server.Route) ([]byte, error) {
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(Spec), &doc); err != nil {
+		return nil, err
+	}
+	generated, _ := doc["paths"].(map[string]interface{})
+	paths := map[string]interface{}{}
+	for _, r := range routes {
+		known, _ := generated[r.Path].(map[string]interface{})
+		item := map[string]interface{}{}
+		for _, m := range r.Methods {
+			method := strings.ToLower(m)
+			if op, ok := known[method]; ok {
+				item[method] = op
+				continue
+			}
+			item[method] = map[string]interface{}{
+				"summary": m + " " + r.Path,
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{"description": "OK"},
+				},
+			}
+		}
+		paths[r.Path] = item
+	}
+	doc["paths"] = paths
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Register serves the document on Path. It's built on every request so
+// routes registered afterwards are included.
+func Register(s *server.Server) {
+	s.Handle(Path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		b, err := Document(s.Routes())
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}))
+}
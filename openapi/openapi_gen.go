@@ -0,0 +1,1222 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum d21d8f5475e84256
+
+package openapi
+
+// Spec is the OpenAPI document of every domain object and its generated
+// REST endpoints, also written to openapi.json
+const Spec = `{
+  "components": {
+    "schemas": {
+      "Attendant": {
+        "description": "Attendant parks cars and hands out their keys",
+        "properties": {
+          "CreatedAt": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "CreatedBy": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "DeletedAt": {
+            "format": "date-time",
+            "nullable": true,
+            "type": "string"
+          },
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "Timestamp": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "TypeID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "UpdatedAt": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "UpdatedBy": {
+            "maxLength": 100,
+            "type": "string"
+          }
+        },
+        "required": [
+          "ID",
+          "Name"
+        ],
+        "type": "object"
+      },
+      "AttendantCreate": {
+        "description": "The arguments creating a Attendant",
+        "properties": {
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          }
+        },
+        "required": [
+          "Name"
+        ],
+        "type": "object"
+      },
+      "AttendantPatch": {
+        "description": "A JSON merge patch of the Attendant it names",
+        "properties": {
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          }
+        },
+        "required": [
+          "ID"
+        ],
+        "type": "object"
+      },
+      "AttendantUpdate": {
+        "description": "The Attendant to update and its new values",
+        "properties": {
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          }
+        },
+        "required": [
+          "ID",
+          "Name"
+        ],
+        "type": "object"
+      },
+      "Desk": {
+        "description": "Desk where car keys can be stored",
+        "properties": {
+          "CreatedAt": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "CreatedBy": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "DeletedAt": {
+            "format": "date-time",
+            "nullable": true,
+            "type": "string"
+          },
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Lat": {
+            "format": "double",
+            "maximum": 90,
+            "minimum": -90,
+            "type": "number"
+          },
+          "Lng": {
+            "format": "double",
+            "maximum": 180,
+            "minimum": -180,
+            "type": "number"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "NodeID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "State": {
+            "enum": [
+              "open",
+              "closed",
+              "out_of_service"
+            ],
+            "type": "string"
+          },
+          "Timestamp": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "TypeID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "UpdatedAt": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "UpdatedBy": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "Version": {
+            "format": "int64",
+            "minimum": 1,
+            "type": "integer"
+          }
+        },
+        "required": [
+          "ID",
+          "Name",
+          "NodeID"
+        ],
+        "type": "object"
+      },
+      "DeskAttendant": {
+        "description": "DeskAttendant assigns an attendant to a desk they work at",
+        "properties": {
+          "AttendantID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "DeskID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          }
+        },
+        "required": [
+          "DeskID",
+          "AttendantID"
+        ],
+        "type": "object"
+      },
+      "DeskCreate": {
+        "description": "The arguments creating a Desk",
+        "properties": {
+          "Lat": {
+            "format": "double",
+            "maximum": 90,
+            "minimum": -90,
+            "type": "number"
+          },
+          "Lng": {
+            "format": "double",
+            "maximum": 180,
+            "minimum": -180,
+            "type": "number"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "NodeID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "State": {
+            "enum": [
+              "open",
+              "closed",
+              "out_of_service"
+            ],
+            "type": "string"
+          }
+        },
+        "required": [
+          "Name",
+          "NodeID"
+        ],
+        "type": "object"
+      },
+      "DeskPatch": {
+        "description": "A JSON merge patch of the Desk it names",
+        "properties": {
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Lat": {
+            "format": "double",
+            "maximum": 90,
+            "minimum": -90,
+            "type": "number"
+          },
+          "Lng": {
+            "format": "double",
+            "maximum": 180,
+            "minimum": -180,
+            "type": "number"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "NodeID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "State": {
+            "enum": [
+              "open",
+              "closed",
+              "out_of_service"
+            ],
+            "type": "string"
+          },
+          "Version": {
+            "format": "int64",
+            "minimum": 1,
+            "type": "integer"
+          }
+        },
+        "required": [
+          "ID"
+        ],
+        "type": "object"
+      },
+      "DeskUpdate": {
+        "description": "The Desk to update and its new values",
+        "properties": {
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Lat": {
+            "format": "double",
+            "maximum": 90,
+            "minimum": -90,
+            "type": "number"
+          },
+          "Lng": {
+            "format": "double",
+            "maximum": 180,
+            "minimum": -180,
+            "type": "number"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "NodeID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "State": {
+            "enum": [
+              "open",
+              "closed",
+              "out_of_service"
+            ],
+            "type": "string"
+          },
+          "Version": {
+            "format": "int64",
+            "minimum": 1,
+            "type": "integer"
+          }
+        },
+        "required": [
+          "ID",
+          "Name",
+          "NodeID"
+        ],
+        "type": "object"
+      },
+      "Node": {
+        "description": "Node represents a node in the organization permission heirarchy tree",
+        "properties": {
+          "CreatedAt": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "CreatedBy": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "DeletedAt": {
+            "format": "date-time",
+            "nullable": true,
+            "type": "string"
+          },
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          },
+          "Timestamp": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "TypeID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "UpdatedAt": {
+            "format": "date-time",
+            "type": "string"
+          },
+          "UpdatedBy": {
+            "maxLength": 100,
+            "type": "string"
+          }
+        },
+        "required": [
+          "ID",
+          "Name"
+        ],
+        "type": "object"
+      },
+      "NodeCreate": {
+        "description": "The arguments creating a Node",
+        "properties": {
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          }
+        },
+        "required": [
+          "Name"
+        ],
+        "type": "object"
+      },
+      "NodePatch": {
+        "description": "A JSON merge patch of the Node it names",
+        "properties": {
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          }
+        },
+        "required": [
+          "ID"
+        ],
+        "type": "object"
+      },
+      "NodeUpdate": {
+        "description": "The Node to update and its new values",
+        "properties": {
+          "ID": {
+            "pattern": "^[0-9A-Fa-f]{32}$",
+            "type": "string"
+          },
+          "Name": {
+            "maxLength": 100,
+            "type": "string"
+          }
+        },
+        "required": [
+          "ID",
+          "Name"
+        ],
+        "type": "object"
+      }
+    }
+  },
+  "info": {
+    "title": "valet",
+    "version": "1.0.0"
+  },
+  "openapi": "3.0.3",
+  "paths": {
+    "/attendant": {
+      "delete": {
+        "operationId": "deleteAttendant",
+        "parameters": [
+          {
+            "in": "query",
+            "name": "id",
+            "required": true,
+            "schema": {
+              "pattern": "^[0-9A-Fa-f]{32}$",
+              "type": "string"
+            }
+          },
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Delete a Attendant"
+      },
+      "get": {
+        "operationId": "getAttendant",
+        "parameters": [
+          {
+            "in": "query",
+            "name": "id",
+            "required": false,
+            "schema": {
+              "pattern": "^[0-9A-Fa-f]{32}$",
+              "type": "string"
+            }
+          },
+          {
+            "description": "Page size, 50 by default and at most 500",
+            "in": "query",
+            "name": "limit",
+            "schema": {
+              "minimum": 1,
+              "type": "integer"
+            }
+          },
+          {
+            "in": "query",
+            "name": "offset",
+            "schema": {
+              "minimum": 0,
+              "type": "integer"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "oneOf": [
+                    {
+                      "$ref": "#/components/schemas/Attendant"
+                    },
+                    {
+                      "items": {
+                        "$ref": "#/components/schemas/Attendant"
+                      },
+                      "type": "array"
+                    }
+                  ]
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Read a Attendant by its key, or list them without one"
+      },
+      "patch": {
+        "operationId": "patchAttendant",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/merge-patch+json": {
+              "schema": {
+                "$ref": "#/components/schemas/AttendantPatch"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Attendant"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Apply a JSON merge patch to a Attendant"
+      },
+      "post": {
+        "operationId": "createAttendant",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/AttendantCreate"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Attendant"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Create a Attendant"
+      },
+      "put": {
+        "operationId": "updateAttendant",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/AttendantUpdate"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Attendant"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Update a Attendant"
+      }
+    },
+    "/desk": {
+      "delete": {
+        "operationId": "deleteDesk",
+        "parameters": [
+          {
+            "in": "query",
+            "name": "id",
+            "required": true,
+            "schema": {
+              "pattern": "^[0-9A-Fa-f]{32}$",
+              "type": "string"
+            }
+          },
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Delete a Desk"
+      },
+      "get": {
+        "operationId": "getDesk",
+        "parameters": [
+          {
+            "in": "query",
+            "name": "id",
+            "required": false,
+            "schema": {
+              "pattern": "^[0-9A-Fa-f]{32}$",
+              "type": "string"
+            }
+          },
+          {
+            "description": "Page size, 50 by default and at most 500",
+            "in": "query",
+            "name": "limit",
+            "schema": {
+              "minimum": 1,
+              "type": "integer"
+            }
+          },
+          {
+            "in": "query",
+            "name": "offset",
+            "schema": {
+              "minimum": 0,
+              "type": "integer"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "oneOf": [
+                    {
+                      "$ref": "#/components/schemas/Desk"
+                    },
+                    {
+                      "items": {
+                        "$ref": "#/components/schemas/Desk"
+                      },
+                      "type": "array"
+                    }
+                  ]
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Read a Desk by its key, or list them without one"
+      },
+      "patch": {
+        "operationId": "patchDesk",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/merge-patch+json": {
+              "schema": {
+                "$ref": "#/components/schemas/DeskPatch"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Desk"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "409": {
+            "description": "Conflict"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Apply a JSON merge patch to a Desk"
+      },
+      "post": {
+        "operationId": "createDesk",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/DeskCreate"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Desk"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Create a Desk"
+      },
+      "put": {
+        "operationId": "updateDesk",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/DeskUpdate"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Desk"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "409": {
+            "description": "Conflict"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Update a Desk"
+      }
+    },
+    "/node": {
+      "delete": {
+        "operationId": "deleteNode",
+        "parameters": [
+          {
+            "in": "query",
+            "name": "id",
+            "required": true,
+            "schema": {
+              "pattern": "^[0-9A-Fa-f]{32}$",
+              "type": "string"
+            }
+          },
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Delete a Node"
+      },
+      "get": {
+        "operationId": "getNode",
+        "parameters": [
+          {
+            "in": "query",
+            "name": "id",
+            "required": false,
+            "schema": {
+              "pattern": "^[0-9A-Fa-f]{32}$",
+              "type": "string"
+            }
+          },
+          {
+            "description": "Page size, 50 by default and at most 500",
+            "in": "query",
+            "name": "limit",
+            "schema": {
+              "minimum": 1,
+              "type": "integer"
+            }
+          },
+          {
+            "in": "query",
+            "name": "offset",
+            "schema": {
+              "minimum": 0,
+              "type": "integer"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "oneOf": [
+                    {
+                      "$ref": "#/components/schemas/Node"
+                    },
+                    {
+                      "items": {
+                        "$ref": "#/components/schemas/Node"
+                      },
+                      "type": "array"
+                    }
+                  ]
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Read a Node by its key, or list them without one"
+      },
+      "patch": {
+        "operationId": "patchNode",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/merge-patch+json": {
+              "schema": {
+                "$ref": "#/components/schemas/NodePatch"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Node"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Apply a JSON merge patch to a Node"
+      },
+      "post": {
+        "operationId": "createNode",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/NodeCreate"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Node"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Create a Node"
+      },
+      "put": {
+        "operationId": "updateNode",
+        "parameters": [
+          {
+            "description": "The X-CSRF-Token header of an earlier response",
+            "in": "header",
+            "name": "X-CSRF-Token",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/NodeUpdate"
+              }
+            }
+          },
+          "required": true
+        },
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Node"
+                }
+              }
+            },
+            "description": "OK"
+          },
+          "400": {
+            "description": "Bad Request"
+          },
+          "403": {
+            "description": "Forbidden"
+          },
+          "404": {
+            "description": "Not Found"
+          },
+          "415": {
+            "description": "Unsupported Media Type"
+          },
+          "500": {
+            "description": "Internal Server Error"
+          }
+        },
+        "summary": "Update a Node"
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,96 @@
+// Package registry maps every TypeID to what it identifies. The domain
+// objects and the inputs of their REST endpoints are registered by the
+// generated code, hand written inputs register their own.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+)
+
+// Scannable is satisfied by *sql.Row and *sql.Rows
+type Scannable interface {
+	Scan(dest ...interface{}) error
+}
+
+// Entry is a registered TypeID. Domain objects have a Table and the
+// functions building them, inputs only a Name.
+type Entry struct {
+	TypeID string
+	Name   string
+	Table  string
+	// New returns an empty object, ex. to decode into
+	New func() domain.Domain
+	// Scan reads an object from a row selected with its generated columns
+	Scan func(row Scannable) (domain.Domain, error)
+	// Get reads an object by its id, nil when the primary key isn't a
+	// single column
+	Get func(ctx context.Context, db domain.DB, id string) (domain.Domain, error)
+}
+
+var (
+	mu      sync.RWMutex
+	entries = map[string]Entry{}
+)
+
+// Register adds an entry, failing if its TypeID isn't 32 uppercase hex
+// characters or is already registered
+func Register(e Entry) error {
+	if !domain.IsHexID(e.TypeID) || strings.ToUpper(e.TypeID) != e.TypeID {
+		return fmt.Errorf("registry: TypeID %q of %s must be 32 uppercase hex characters", e.TypeID, e.Name)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if other, ok := entries[e.TypeID]; ok {
+		return fmt.Errorf("registry: TypeID %s of %s is already registered by %s", e.TypeID, e.Name, other.Name)
+	}
+	entries[e.TypeID] = e
+	return nil
+}
+
+// MustRegister is Register, panicking on error. It's meant for init().
+func MustRegister(e Entry) {
+	if err := Register(e); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the entry of a TypeID
+func Lookup(typeID string) (Entry, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := entries[typeID]
+	return e, ok
+}
+
+// Entries returns every entry ordered by name
+func Entries() []Entry {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := []Entry{}
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// LoadAny reads the object of any registered type by its id, returning
+// sql.ErrNoRows if it doesn't exist
+func LoadAny(ctx context.Context, db domain.DB, typeID, id string) (domain.Domain, error) {
+	e, ok := Lookup(typeID)
+	if !ok {
+		return nil, fmt.Errorf("registry: unknown TypeID %q", typeID)
+	}
+	if e.Get == nil {
+		return nil, fmt.Errorf("registry: %s can't be loaded by a single id", e.Name)
+	}
+	return e.Get(ctx, db, id)
+}
@@ -0,0 +1,120 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum f306431747edebb2
+
+package registry
+
+import (
+	"context"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/attendant"
+	"git.ottoq.com/otto-backend/valet/domain/desk"
+	"git.ottoq.com/otto-backend/valet/domain/deskattendant"
+	"git.ottoq.com/otto-backend/valet/domain/node"
+)
+
+// init registers every domain object and the input of each of their
+// REST endpoints
+func init() {
+	for _, e := range []Entry{
+		{
+			TypeID: node.TypeID,
+			Name:   "Node",
+			Table:  node.TableName(),
+			New: func() domain.Domain {
+				return &node.Node{}
+			},
+			Scan: func(row Scannable) (domain.Domain, error) {
+				o, err := node.NewFromRow(row)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+			Get: func(ctx context.Context, db domain.DB, id string) (domain.Domain, error) {
+				o, err := node.GetByID(ctx, db, id)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+		},
+		{TypeID: "FB77C6B9087AFC6B6B8FE30CB76B5423", Name: "NodeCreate"},
+		{TypeID: "F82E683BE13A9B5CA4581349860AB61D", Name: "NodeRead"},
+		{TypeID: "63EE7BF0669E9801375B7CE8B3770480", Name: "NodeUpdate"},
+		{TypeID: "B544B65D093636E5CEDEAFEC37AAC16D", Name: "NodeDelete"},
+		{TypeID: "EFBBD82DAE7110F82C75D7BD05E5AD7D", Name: "NodeList"},
+		{
+			TypeID: desk.TypeID,
+			Name:   "Desk",
+			Table:  desk.TableName(),
+			New: func() domain.Domain {
+				return &desk.Desk{}
+			},
+			Scan: func(row Scannable) (domain.Domain, error) {
+				o, err := desk.NewFromRow(row)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+			Get: func(ctx context.Context, db domain.DB, id string) (domain.Domain, error) {
+				o, err := desk.GetByID(ctx, db, id)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+		},
+		{TypeID: "A38DEB757B4FE844646CFE0774D324D8", Name: "DeskCreate"},
+		{TypeID: "0837E576F865D06C7CE8681C8FA92B1C", Name: "DeskRead"},
+		{TypeID: "326155337E6F2ED5812F2FD7763042EA", Name: "DeskUpdate"},
+		{TypeID: "7894EC06103F2D6F7A06D68F21D28F28", Name: "DeskDelete"},
+		{TypeID: "48AE23E3561B6DDEF966DC2A462B9C08", Name: "DeskList"},
+		{
+			TypeID: attendant.TypeID,
+			Name:   "Attendant",
+			Table:  attendant.TableName(),
+			New: func() domain.Domain {
+				return &attendant.Attendant{}
+			},
+			Scan: func(row Scannable) (domain.Domain, error) {
+				o, err := attendant.NewFromRow(row)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+			Get: func(ctx context.Context, db domain.DB, id string) (domain.Domain, error) {
+				o, err := attendant.GetByID(ctx, db, id)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+		},
+		{TypeID: "CC17A6550B99D51BCA78ED6DC6DE8C36", Name: "AttendantCreate"},
+		{TypeID: "730F9D6989558936308AAB6F95E1D2C9", Name: "AttendantRead"},
+		{TypeID: "D9B416018D6B500B9B658D99849B9F08", Name: "AttendantUpdate"},
+		{TypeID: "2B90796944154D75630212E1FA3D01F4", Name: "AttendantDelete"},
+		{TypeID: "1F008B7F6B18AC746AF3D52695209C6F", Name: "AttendantList"},
+		{
+			TypeID: deskattendant.TypeID,
+			Name:   "DeskAttendant",
+			Table:  deskattendant.TableName(),
+			New: func() domain.Domain {
+				return &deskattendant.DeskAttendant{}
+			},
+			Scan: func(row Scannable) (domain.Domain, error) {
+				o, err := deskattendant.NewFromRow(row)
+				if err != nil {
+					return nil, err
+				}
+				return o, nil
+			},
+		},
+	} {
+		MustRegister(e)
+	}
+}
@@ -0,0 +1,140 @@
+// go run ./seed [flags]
+//
+// Fills a development database with -n random objects of each type. Every
+// foreign key references a row inserted before it and fields with a
+// generator in the schema get realistic values, ex. names and coordinates.
+// The same -seed inserts the same objects, apart from their timestamps.
+// Without it the seed is the time, which is printed to repeat the run.
+// Everything is inserted in a single transaction, so a failed run leaves
+// the database as it was.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"time"
+
+	"git.ottoq.com/otto-backend/valet/database"
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/entity"
+)
+
+var (
+	dialect = flag.String("dialect", "mysql", "database dialect: mysql, postgres or sqlite3")
+	dsn     = flag.String("dsn", "", "data source name, instead of -addr, -db, -user and -pass")
+	addr    = flag.String("addr", "tcp(127.0.0.1:3306)", "MySQL database address")
+	dbname  = flag.String("db", "test3", "MySQL database name")
+	user    = flag.String("user", "austin", "MySQL database user")
+	pass    = flag.String("pass", "", "MySQL database password")
+	n       = flag.Int("n", 10, "objects to insert of each type")
+	seed    = flag.Int64("seed", 0, "seed of the random values, the time if 0")
+	create  = flag.Bool("create", false, "create missing tables first, instead of migrating")
+)
+
+// maxTries is how many random objects are drawn for a row before giving
+// up on finding one whose keys aren't taken
+const maxTries = 100
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: seed [flags]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() > 0 || *n < 0 {
+		usage()
+	}
+
+	var db *database.Database
+	var err error
+	if *dsn != "" {
+		db, err = database.Open(domain.Dialect(*dialect), *dsn)
+	} else if domain.Dialect(*dialect) == domain.MySQL {
+		db, err = database.New(*addr, *dbname, *user, *pass)
+	} else {
+		log.Fatalf("-dsn is required for %s", *dialect)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	ctx := domain.WithActor(context.Background(), "seed")
+
+	if *create {
+		if err := db.CreateTables(ctx); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("seeding with -seed %d", *seed)
+	entity.Seed(*seed)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := &seeded{keys: map[string]bool{}}
+	for _, t := range tables {
+		if err := t.Seed(ctx, tx, s, *n); err != nil {
+			tx.Rollback()
+			log.Fatalf("%s: %s", t.Name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+	for _, t := range tables {
+		fmt.Printf("%-20s %d\n", t.Name, t.Count(s))
+	}
+}
+
+// claim reserves every key, reporting false and reserving none if any is
+// taken
+func (s *seeded) claim(keys ...string) bool {
+	for _, k := range keys {
+		if s.keys[k] {
+			return false
+		}
+	}
+	for _, k := range keys {
+		s.keys[k] = true
+	}
+	return true
+}
+
+// key returns a key of the named index made of values, pointers being
+// replaced by what they point to
+func key(index string, values ...interface{}) string {
+	k := index
+	for _, v := range values {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			v = rv.Elem().Interface()
+		}
+		k += fmt.Sprintf("\x00%v", v)
+	}
+	return k
+}
+
+// pick returns the index of a random row of n
+func pick(n int) int {
+	return entity.RANDintn(n)
+}
+
+// newID returns a random ID drawn from the seeded source
+func newID() string {
+	return entity.RANDuuid()
+}
+
+// noParent is the error for a required foreign key to a table without rows
+func noParent(column, table string) error {
+	return fmt.Errorf("%s references %s, which has no rows", column, table)
+}
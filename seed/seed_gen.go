@@ -0,0 +1,172 @@
+// go run gen/gen.go
+// VERY GENERATED PLZ NO MODIFY
+// checksum 16712ddec93732b6
+
+package main
+
+import (
+	"context"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/attendant"
+	"git.ottoq.com/otto-backend/valet/domain/desk"
+	"git.ottoq.com/otto-backend/valet/domain/deskattendant"
+	"git.ottoq.com/otto-backend/valet/domain/node"
+)
+
+// seeded holds the objects inserted so far, for the foreign keys of the
+// objects inserted after them
+type seeded struct {
+	Node          []*node.Node
+	Desk          []*desk.Desk
+	Attendant     []*attendant.Attendant
+	DeskAttendant []*deskattendant.DeskAttendant
+
+	keys map[string]bool // keys are the claimed primary and unique keys, see claim
+}
+
+// tables seeds each table, referenced tables first
+var tables = []struct {
+	Name  string
+	Seed  func(ctx context.Context, db domain.DB, s *seeded, n int) error
+	Count func(s *seeded) int
+}{
+	{
+		Name:  node.TableName(),
+		Seed:  seedNode,
+		Count: func(s *seeded) int { return len(s.Node) },
+	},
+	{
+		Name:  desk.TableName(),
+		Seed:  seedDesk,
+		Count: func(s *seeded) int { return len(s.Desk) },
+	},
+	{
+		Name:  attendant.TableName(),
+		Seed:  seedAttendant,
+		Count: func(s *seeded) int { return len(s.Attendant) },
+	},
+	{
+		Name:  deskattendant.TableName(),
+		Seed:  seedDeskAttendant,
+		Count: func(s *seeded) int { return len(s.DeskAttendant) },
+	},
+}
+
+// seedNode inserts n random Nodes, fewer if it runs out of
+// distinct keys
+func seedNode(ctx context.Context, db domain.DB, s *seeded, n int) error {
+	for i := 0; i < n; i++ {
+		var o *node.Node
+		for try := 0; ; try++ {
+			if try == maxTries {
+				return nil
+			}
+			o = node.Random()
+			o.ID = newID()
+			if s.claim(
+				key("Node.0", o.ID),
+			) {
+				break
+			}
+		}
+		if err := o.Insert(ctx, db); err != nil {
+			return err
+		}
+		s.Node = append(s.Node, o)
+	}
+	return nil
+}
+
+// seedDesk inserts n random Desks, fewer if it runs out of
+// distinct keys
+func seedDesk(ctx context.Context, db domain.DB, s *seeded, n int) error {
+	for i := 0; i < n; i++ {
+		var o *desk.Desk
+		for try := 0; ; try++ {
+			if try == maxTries {
+				return nil
+			}
+			o = desk.Random()
+			o.ID = newID()
+			if len(s.Node) > 0 {
+				parent := s.Node[pick(len(s.Node))]
+				o.NodeID = parent.ID
+			} else {
+				return noParent("Desk.NodeID", "Node")
+			}
+			if s.claim(
+				key("Desk.0", o.ID),
+				key("Desk.1", o.NodeID, o.Name),
+			) {
+				break
+			}
+		}
+		if err := o.Insert(ctx, db); err != nil {
+			return err
+		}
+		s.Desk = append(s.Desk, o)
+	}
+	return nil
+}
+
+// seedAttendant inserts n random Attendants, fewer if it runs out of
+// distinct keys
+func seedAttendant(ctx context.Context, db domain.DB, s *seeded, n int) error {
+	for i := 0; i < n; i++ {
+		var o *attendant.Attendant
+		for try := 0; ; try++ {
+			if try == maxTries {
+				return nil
+			}
+			o = attendant.Random()
+			o.ID = newID()
+			if s.claim(
+				key("Attendant.0", o.ID),
+			) {
+				break
+			}
+		}
+		if err := o.Insert(ctx, db); err != nil {
+			return err
+		}
+		s.Attendant = append(s.Attendant, o)
+	}
+	return nil
+}
+
+// seedDeskAttendant inserts n random DeskAttendants, fewer if it runs out of
+// distinct keys
+func seedDeskAttendant(ctx context.Context, db domain.DB, s *seeded, n int) error {
+	for i := 0; i < n; i++ {
+		var o *deskattendant.DeskAttendant
+		for try := 0; ; try++ {
+			if try == maxTries {
+				return nil
+			}
+			o = deskattendant.Random()
+			if len(s.Desk) > 0 {
+				parent := s.Desk[pick(len(s.Desk))]
+				o.DeskID = parent.ID
+			} else {
+				return noParent("DeskAttendant.DeskID", "Desk")
+			}
+			if len(s.Attendant) > 0 {
+				parent := s.Attendant[pick(len(s.Attendant))]
+				o.AttendantID = parent.ID
+			} else {
+				return noParent("DeskAttendant.AttendantID", "Attendant")
+			}
+			if s.claim(
+				key("DeskAttendant.0", o.DeskID, o.AttendantID),
+			) {
+				break
+			}
+		}
+		if err := o.Insert(ctx, db); err != nil {
+			return err
+		}
+		s.DeskAttendant = append(s.DeskAttendant, o)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"git.ottoq.com/otto-backend/valet/domain"
+	"git.ottoq.com/otto-backend/valet/domain/domaintest"
+	"git.ottoq.com/otto-backend/valet/entity"
+)
+
+// TestSeed seeds two databases with the same seed, which must get the
+// same objects, every foreign key referencing a row
+func TestSeed(t *testing.T) {
+	runs := make([]*seeded, 2)
+	for i := range runs {
+		t.Run(fmt.Sprint(i+1), func(t *testing.T) {
+			runs[i] = seedAll(t, 1, 5)
+		})
+	}
+	if runs[0] == nil || runs[1] == nil {
+		t.SkipNow()
+	}
+	a, b := reflect.ValueOf(*runs[0]), reflect.ValueOf(*runs[1])
+	for i := 0; i < a.NumField(); i++ {
+		name := a.Type().Field(i).Name
+		if a.Field(i).Kind() != reflect.Slice {
+			continue
+		}
+		if a.Field(i).Len() == 0 {
+			t.Errorf("no %s was seeded", name)
+		}
+		if a.Field(i).Len() != b.Field(i).Len() {
+			t.Fatalf("seeded %d then %d %s", a.Field(i).Len(), b.Field(i).Len(), name)
+		}
+		for j := 0; j < a.Field(i).Len(); j++ {
+			if d := domaintest.Diff(a.Field(i).Index(j).Interface(), b.Field(i).Index(j).Interface()); d != "" {
+				t.Fatalf("%s %d differs between runs, %s", name, j, d)
+			}
+		}
+	}
+}
+
+// seedAll seeds n objects of each table into a test database, checking
+// their foreign keys reference rows of it
+func seedAll(t *testing.T, seed int64, n int) *seeded {
+	db := domaintest.Open(t)
+	ctx := domain.WithActor(context.Background(), "seed")
+	entity.Seed(seed)
+	s := &seeded{keys: map[string]bool{}}
+	for _, table := range tables {
+		if err := table.Seed(ctx, db, s, n); err != nil {
+			t.Fatalf("%s: %s", table.Name, err)
+		}
+	}
+
+	// foreign keys aren't checked as the test database is written, but
+	// SQLite can list those that don't hold
+	if domain.DialectOf(db) != domain.SQLite {
+		return s
+	}
+	rows, err := db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, parent string
+		var row, fk interface{}
+		if err := rows.Scan(&table, &row, &parent, &fk); err != nil {
+			t.Fatal(err)
+		}
+		t.Errorf("row %v of %s references a missing %s", row, table, parent)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -9,20 +9,24 @@ import (
 )
 
 type httpStatusHandler struct {
-	// The "int" is the http status returned to the user.
-	fn func(http.ResponseWriter, *http.Request) int
+	// The "int" is the http status returned to the user, the string an
+	// optional message replacing the status text.
+	fn func(http.ResponseWriter, *http.Request) (int, string)
 }
 
 func (h *httpStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	status := h.fn(w, r)
+	status, msg := h.fn(w, r)
 	if status >= 400 {
-		http.Error(w, http.StatusText(status), status)
+		if msg == "" {
+			msg = http.StatusText(status)
+		}
+		http.Error(w, msg, status)
 	}
 }
 
 func (s *Server) httpRequestHandler(rm HTTPConverterMap) *httpStatusHandler {
 	return &httpStatusHandler{
-		fn: func(w http.ResponseWriter, r *http.Request) int {
+		fn: func(w http.ResponseWriter, r *http.Request) (int, string) {
 
 			////////
 			// Parse the input
@@ -31,12 +35,12 @@ func (s *Server) httpRequestHandler(rm HTTPConverterMap) *httpStatusHandler {
 			f, ok := rm[r.Method]
 			if !ok {
 				// s.logger.Log
-				return http.StatusMethodNotAllowed
+				return http.StatusMethodNotAllowed, ""
 			}
 			input, err := f(w, r, s.secureCookie, s.sessionCookieName)
 			if err != nil {
 				// s.logger.Log
-				return http.StatusBadRequest
+				return errorStatus(err, http.StatusBadRequest)
 			}
 			// s.logger.Log(req)
 
@@ -47,15 +51,13 @@ func (s *Server) httpRequestHandler(rm HTTPConverterMap) *httpStatusHandler {
 			handler, ok := s.handlers[input.TypeID()]
 			if !ok {
 				// s.logger.Log
-				return http.StatusInternalServerError
+				return http.StatusInternalServerError, ""
 			}
 			responses := make(chan entity.Identifier)
+			errs := make(chan error, 1)
 
 			go func(handler Handler, responses chan entity.Identifier) {
-				err := handler.Notify(input, responses)
-				if err != nil {
-					// s.logger.Log
-				}
+				errs <- handler.Notify(input, responses)
 			}(handler, responses)
 
 			////////
@@ -71,14 +73,30 @@ func (s *Server) httpRequestHandler(rm HTTPConverterMap) *httpStatusHandler {
 				// w.Header().Set("Access-Control-Allow-Origin", "*")
 				// prevent clickjacking
 				w.Header().Set("X-FRAME-OPTIONS", "DENY")
+				w.Header().Set("Content-Type", "application/json")
 				if err := json.NewEncoder(w).Encode(resp); err != nil {
 					// s.logger.Log
-					return http.StatusInternalServerError
+					return http.StatusInternalServerError, ""
+				}
+			case err := <-errs:
+				// the handler returned without responding
+				if err != nil {
+					// s.logger.Log
+					return errorStatus(err, http.StatusInternalServerError)
 				}
 			case <-time.After(5 * time.Second):
-				return http.StatusInternalServerError
+				return http.StatusInternalServerError, ""
 			}
-			return http.StatusOK
+			return http.StatusOK, ""
 		},
 	}
 }
+
+// errorStatus returns the status and message of an *Error, or fallback
+// for any other error
+func errorStatus(err error, fallback int) (int, string) {
+	if e, ok := err.(*Error); ok {
+		return e.Status, e.Message
+	}
+	return fallback, ""
+}
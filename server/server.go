@@ -1,16 +1,19 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 
 	"git.ottoq.com/otto-backend/valet/entity"
+	"git.ottoq.com/otto-backend/valet/registry"
 	"git.ottoq.com/otto-backend/valet/server/securecookie"
 )
 
@@ -42,6 +45,7 @@ type Server struct {
 	secureCookie *securecookie.Config
 	mux          *http.ServeMux
 	handlers     handlerMap
+	routes       map[string][]string
 	// sockets  *socketMap
 }
 
@@ -76,6 +80,7 @@ func New(
 		// logger:            logger,
 		secureCookie: sc,
 		handlers:     handlerMap{},
+		routes:       map[string][]string{},
 		// sockets:      &socketMap{s: make(map[string]struct{})},
 	}
 
@@ -157,10 +162,87 @@ type HTTPConverterMap map[string]HTTPRequestToInput
 
 // RegisterHTTPRoute registers http routes along with their route methods for handling entities
 func (s *Server) RegisterHTTPRoute(route string, methods HTTPConverterMap) {
+	names := []string{}
+	for m := range methods {
+		names = append(names, m)
+	}
+	sort.Strings(names)
+	s.routes[route] = names
 	s.mux.Handle(route, s.httpRequestHandler(methods))
 }
 
-// RegisterHandler accepts a handler and registers it in the HandlerMap
-func (s *Server) RegisterHandler(h Handler) {
-	s.handlers[h.InputTypeID()] = h
+// Route is a path registered with RegisterHTTPRoute and its methods
+type Route struct {
+	Path    string
+	Methods []string
+}
+
+// Routes returns the registered routes ordered by path
+func (s *Server) Routes() []Route {
+	routes := []Route{}
+	for p, m := range s.routes {
+		routes = append(routes, Route{Path: p, Methods: m})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
+}
+
+// Handle registers a plain http handler, for routes without an input DTO
+func (s *Server) Handle(route string, h http.Handler) {
+	s.mux.Handle(route, h)
+}
+
+// RegisterHandler accepts a handler and registers it in the HandlerMap,
+// failing if its input's TypeID isn't in the registry
+func (s *Server) RegisterHandler(h Handler) error {
+	id := h.InputTypeID()
+	if _, ok := registry.Lookup(id); !ok {
+		return fmt.Errorf("handler for unregistered TypeID %q", id)
+	}
+	s.handlers[id] = h
+	return nil
+}
+
+// Error is returned by converters and handlers to respond with a
+// specific http status. Message replaces the status text when set.
+type Error struct {
+	Status  int
+	Message string
+}
+
+// Error returns the error string
+func (err *Error) Error() string {
+	if err.Message == "" {
+		return http.StatusText(err.Status)
+	}
+	return err.Message
+}
+
+// Response is a handler response encoding body as the JSON reply
+type Response struct {
+	id     string
+	typeID string
+	body   interface{}
+}
+
+// NewResponse wraps body for sending on a handler's responses channel
+func NewResponse(id, typeID string, body interface{}) *Response {
+	return &Response{id: id, typeID: typeID, body: body}
+}
+
+// ID returns the response id
+func (r *Response) ID() string {
+	return r.id
+}
+
+// TypeID returns the response type id
+func (r *Response) TypeID() string {
+	return r.typeID
+}
+
+// MarshalJSON encodes the body
+func (r *Response) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.body)
 }
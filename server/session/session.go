@@ -35,6 +35,12 @@ func FromCookies(cookies []*http.Cookie, cookieName string,
 	}, nil
 }
 
+// Existing returns the session set in the cookies by an earlier response,
+// or nil if there's none
+func Existing(cookies []*http.Cookie, cookieName string, sc *securecookie.Config) *Session {
+	return sessionFromCookies(cookies, cookieName, sc)
+}
+
 func sessionFromCookies(cookies []*http.Cookie, cookieName string, sc *securecookie.Config) *Session {
 	for _, c := range cookies {
 		if c.Name != cookieName {
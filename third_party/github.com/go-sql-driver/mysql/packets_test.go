@@ -280,3 +280,30 @@ func TestReadPacketFail(t *testing.T) {
 		t.Errorf("expected ErrBadConn, got %v", err)
 	}
 }
+
+// The fork writes the bytes of a column named like an ID as uppercase hex
+// when a text protocol query reads it, and leaves the others as they are
+func TestReadTextRowHexID(t *testing.T) {
+	conn := new(mockConn)
+	mc := &mysqlConn{
+		buf:     newBuffer(conn),
+		netConn: conn,
+		cfg:     new(Config),
+	}
+	id := []byte{0x0c, 0x74, 0xdf, 0xc1, 0x58, 0xc6, 0x46, 0xc2, 0x80, 0xbc, 0xb0, 0xda, 0xf9, 0xe0, 0x15, 0xd1}
+	payload := append([]byte{byte(len(id))}, id...)
+	payload = append(payload, 4, 'd', 'e', 's', 'k')
+	conn.data = append([]byte{byte(len(payload)), 0x00, 0x00, 0x00}, payload...)
+	rows := &textRows{mysqlRows{mc: mc, columns: []mysqlField{{name: "ID"}, {name: "Name"}}}}
+
+	dest := make([]driver.Value, 2)
+	if err := rows.Next(dest); err != nil {
+		t.Fatal(err)
+	}
+	if dest[0] != "0C74DFC158C646C280BCB0DAF9E015D1" {
+		t.Errorf("ID read as %#v", dest[0])
+	}
+	if name, ok := dest[1].([]byte); !ok || string(name) != "desk" {
+		t.Errorf("Name read as %#v", dest[1])
+	}
+}
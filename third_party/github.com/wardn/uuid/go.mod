@@ -0,0 +1,3 @@
+module github.com/wardn/uuid
+
+go 1.21